@@ -0,0 +1,88 @@
+package arangodag
+
+import (
+	"context"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ACLInterface describes the interface a type must implement in order to
+// restrict the visibility of vertices.
+//
+// Vertices of types not implementing this interface (or returning an empty
+// list) are visible to everybody.
+type ACLInterface interface {
+	ACL() []string
+}
+
+type principalKey struct{}
+
+// WithPrincipal returns a copy of the given context carrying the id of the
+// principal (e.g. the user) on whose behalf DAG operations are executed.
+// Operations using such a context exclude vertices whose ACL (see
+// ACLInterface) does not list the principal, i.e. such vertices are treated as
+// unknown, and neither returned nor traversed by walks, queries, views and
+// exports.
+func WithPrincipal(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, principalKey{}, id)
+}
+
+// PrincipalFromContext returns the id of the principal carried by the given
+// context. The second return value is false, if there is no principal (i.e.
+// if ACLs are not to be applied).
+func PrincipalFromContext(ctx context.Context) (string, bool) {
+	id, ok := ctx.Value(principalKey{}).(string)
+	return id, ok
+}
+
+// aclFilter returns the filter (see ViewFilter) restricting vertices to the
+// ones visible to the principal carried by ctx, or nil, if there is no
+// principal.
+func aclFilter(ctx context.Context) *ViewFilter {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok {
+		return nil
+	}
+	return &ViewFilter{
+		Expression: "CURRENT.acl == null OR LENGTH(CURRENT.acl) == 0 OR @principal IN CURRENT.acl",
+		BindVars:   map[string]interface{}{"principal": principal},
+	}
+}
+
+// visible returns true, if a vertex with the given ACL is visible to the
+// principal carried by ctx.
+func visible(ctx context.Context, acl []string) bool {
+	principal, ok := PrincipalFromContext(ctx)
+	if !ok || len(acl) == 0 {
+		return true
+	}
+	for _, p := range acl {
+		if p == principal {
+			return true
+		}
+	}
+	return false
+}
+
+// IsVisible returns true, if the vertex with the given id is visible to the
+// principal carried by the context (see WithPrincipal and IsVisibleCtx).
+// IsVisible returns an error, if id is empty or unknown.
+func (d *DAG) IsVisible(id string) (bool, error) {
+	return d.IsVisibleCtx(context.Background(), id)
+}
+
+// IsVisibleCtx is like IsVisible but uses the given context.
+func (d *DAG) IsVisibleCtx(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	var doc arangoDocContainer
+	if _, err := d.vertices.ReadDocument(ctx, d.key(id), &doc); err != nil {
+		if driver.IsArangoErrorWithErrorNum(err, 1202) {
+			return false, NewUnknownKeyError(id)
+		}
+		return false, arangoError(err)
+	}
+	return visible(ctx, doc.ACL), nil
+}
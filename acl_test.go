@@ -0,0 +1,290 @@
+package arangodag
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+type aclVertex struct {
+	MyID       string
+	Principals []string
+}
+
+func (v aclVertex) ID() string {
+	return v.MyID
+}
+
+func (v aclVertex) ACL() []string {
+	return v.Principals
+}
+
+func TestDAG_IsVisible(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(aclVertex{MyID: "public"})
+	_, _ = d.AddVertex(aclVertex{MyID: "private", Principals: []string{"alice"}})
+
+	tests := []struct {
+		ctx  context.Context
+		id   string
+		want bool
+	}{
+		{context.Background(), "public", true},
+		{context.Background(), "private", true},
+		{WithPrincipal(context.Background(), "alice"), "public", true},
+		{WithPrincipal(context.Background(), "alice"), "private", true},
+		{WithPrincipal(context.Background(), "bob"), "public", true},
+		{WithPrincipal(context.Background(), "bob"), "private", false},
+	}
+	for _, test := range tests {
+		got, err := d.IsVisibleCtx(test.ctx, test.id)
+		if err != nil {
+			t.Errorf("failed to IsVisible(): %v", err)
+		}
+		if got != test.want {
+			principal, _ := PrincipalFromContext(test.ctx)
+			t.Errorf("IsVisible(%s) for '%s' = %v, want %v", test.id, principal, got, test.want)
+		}
+	}
+
+	// unknown
+	_, errUnknown := d.IsVisible("foo")
+	if !IsUnknownIDError(errUnknown) {
+		t.Errorf("want UnknownIDError, got %v", errUnknown)
+	}
+}
+
+func TestDAG_hiddenVertices(t *testing.T) {
+	d := someNewDag(t)
+
+	// a -> b -> c, a -> d (b being visible to alice only)
+	_, _ = d.AddVertex(aclVertex{MyID: "a"})
+	_, _ = d.AddVertex(aclVertex{MyID: "b", Principals: []string{"alice"}})
+	_, _ = d.AddVertex(aclVertex{MyID: "c"})
+	_, _ = d.AddVertex(aclVertex{MyID: "d"})
+	_ = d.AddEdge("a", "b")
+	_ = d.AddEdge("b", "c")
+	_ = d.AddEdge("a", "d")
+
+	alice := WithPrincipal(context.Background(), "alice")
+	bob := WithPrincipal(context.Background(), "bob")
+
+	descendants, err := d.GetDescendantsCtx(alice, "a")
+	if err != nil {
+		t.Fatalf("failed to GetDescendants(): %v", err)
+	}
+	if diff := deep.Equal(descendants, map[string]struct{}{"b": {}, "c": {}, "d": {}}); diff != nil {
+		t.Error(diff)
+	}
+	descendants, err = d.GetDescendantsCtx(bob, "a")
+	if err != nil {
+		t.Fatalf("failed to GetDescendants(): %v", err)
+	}
+	if diff := deep.Equal(descendants, map[string]struct{}{"d": {}}); diff != nil {
+		t.Error(diff)
+	}
+	children, err := d.GetChildrenCtx(bob, "a")
+	if err != nil {
+		t.Fatalf("failed to GetChildren(): %v", err)
+	}
+	if diff := deep.Equal(children, map[string]struct{}{"d": {}}); diff != nil {
+		t.Error(diff)
+	}
+
+	var visited []string
+	err = d.WalkDescendantsCtx(bob, "a", nil, func(id string, err error) error {
+		visited = append(visited, id)
+		return err
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to WalkDescendants(): %v", err)
+	}
+	if diff := deep.Equal(visited, []string{"d"}); diff != nil {
+		t.Error(diff)
+	}
+
+	it, err := d.Query().From("a").Stream(bob)
+	if err != nil {
+		t.Fatalf("failed to Stream(): %v", err)
+	}
+	visited = nil
+	for it.Next() {
+		visited = append(visited, it.ID())
+	}
+	_ = it.Close()
+	if diff := deep.Equal(visited, []string{"d"}); diff != nil {
+		t.Error(diff)
+	}
+
+	descendants, err = d.NewView(nil, nil).GetDescendantsCtx(bob, "a")
+	if err != nil {
+		t.Fatalf("failed to GetDescendants() of view: %v", err)
+	}
+	if diff := deep.Equal(descendants, map[string]struct{}{"d": {}}); diff != nil {
+		t.Error(diff)
+	}
+
+	var v aclVertex
+	if err := d.GetVertexCtx(bob, "b", &v); !IsUnknownIDError(err) {
+		t.Errorf("GetVertex() = %v, want UnknownIDError", err)
+	}
+	if _, err := d.GetDescendantsCtx(bob, "b"); !IsUnknownIDError(err) {
+		t.Errorf("GetDescendants() = %v, want UnknownIDError", err)
+	}
+
+	var buf bytes.Buffer
+	if err := d.ExportJSONCtx(bob, &buf); err != nil {
+		t.Fatalf("failed to ExportJSON(): %v", err)
+	}
+	var export struct {
+		Vertices []struct {
+			Key string `json:"_key"`
+		} `json:"vertices"`
+		Edges []json.RawMessage `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("failed to decode export: %v", err)
+	}
+	if len(export.Vertices) != 3 || len(export.Edges) != 1 {
+		t.Errorf("got %d vertices and %d edges, want 3 and 1", len(export.Vertices), len(export.Edges))
+	}
+}
+
+func TestDAG_hiddenVertices_reads(t *testing.T) {
+	d := someNewDag(t, WithMaterializedPaths())
+
+	// a -> b -> c, a -> d (b being visible to alice only)
+	_, _ = d.AddVertex(aclVertex{MyID: "a"})
+	_, _ = d.AddVertex(aclVertex{MyID: "b", Principals: []string{"alice"}})
+	_, _ = d.AddVertex(aclVertex{MyID: "c"})
+	_, _ = d.AddVertex(aclVertex{MyID: "d"})
+	_ = d.AddEdge("a", "b")
+	_ = d.AddEdge("b", "c")
+	_ = d.AddEdge("a", "d")
+
+	alice := WithPrincipal(context.Background(), "alice")
+	bob := WithPrincipal(context.Background(), "bob")
+	collect := func(name string, walk func(fn func(id string) error) error) []string {
+		ids := []string{}
+		if err := walk(func(id string) error {
+			ids = append(ids, id)
+			return nil
+		}); err != nil {
+			t.Fatalf("failed to %s(): %v", name, err)
+		}
+		return ids
+	}
+
+	if diff := deep.Equal(collect("WalkDescendantsMulti", func(fn func(string) error) error {
+		return d.WalkDescendantsMultiCtx(bob, []string{"a"}, fn)
+	}), []string{"d"}); diff != nil {
+		t.Errorf("WalkDescendantsMulti(): %v", diff)
+	}
+	if diff := deep.Equal(collect("WalkAncestorsWithDepth", func(fn func(string) error) error {
+		return d.WalkAncestorsWithDepthCtx(bob, "c", func(id string, _ int, _ []string) error { return fn(id) })
+	}), []string{}); diff != nil {
+		t.Errorf("WalkAncestorsWithDepth(): %v", diff)
+	}
+	if diff := deep.Equal(collect("WalkLeavesOf", func(fn func(string) error) error {
+		return d.WalkLeavesOfCtx(bob, "a", fn)
+	}), []string{"d"}); diff != nil {
+		t.Errorf("WalkLeavesOf(): %v", diff)
+	}
+
+	it, err := d.FrontierIteratorCtx(bob, "a")
+	if err != nil {
+		t.Fatalf("failed to FrontierIterator(): %v", err)
+	}
+	var levels [][]string
+	for it.Next() {
+		levels = append(levels, it.Level())
+	}
+	_ = it.Close()
+	if diff := deep.Equal(levels, [][]string{{"d"}}); diff != nil {
+		t.Errorf("FrontierIterator(): %v", diff)
+	}
+
+	impact, err := d.GetImpactCtx(bob, []string{"a"})
+	if err != nil {
+		t.Fatalf("failed to GetImpact(): %v", err)
+	}
+	if diff := deep.Equal(impact.Distances, map[string]int{"d": 1}); diff != nil {
+		t.Errorf("GetImpact(): %v", diff)
+	}
+	provenance, err := d.GetProvenanceCtx(bob, []string{"c"})
+	if err != nil {
+		t.Fatalf("failed to GetProvenance(): %v", err)
+	}
+	if len(provenance.Distances) != 0 {
+		t.Errorf("GetProvenance() = %v, want no contributors", provenance.Distances)
+	}
+
+	if length, _ := d.GetShortestPathLengthCtx(alice, "a", "c"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+	if length, _ := d.GetShortestPathLengthCtx(bob, "a", "c"); length != -1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, -1)
+	}
+	if weight, _ := d.GetShortestPathWeightCtx(bob, "a", "c", WeightAttribute, 1); weight != -1 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, -1)
+	}
+	if paths, _ := d.GetKShortestPathsCtx(bob, "a", "c", 2, false); len(paths) != 0 {
+		t.Errorf("GetKShortestPaths() = %v, want none", paths)
+	}
+	if path, _ := d.GetShortestPathExcludingCtx(bob, "a", "c", nil); path != nil {
+		t.Errorf("GetShortestPathExcluding() = %v, want nil", path)
+	}
+	distances, err := d.GetDistancesCtx(bob, []string{"a"}, []string{"c", "d"})
+	if err != nil {
+		t.Fatalf("failed to GetDistances(): %v", err)
+	}
+	if diff := deep.Equal(distances, map[string]map[string]int{"a": {"c": -1, "d": 1}}); diff != nil {
+		t.Errorf("GetDistances(): %v", diff)
+	}
+	if from, _ := d.GetDistancesFromCtx(bob, "a"); deep.Equal(from, map[string]int{"d": 1}) != nil {
+		t.Errorf("GetDistancesFrom() = %v, want map[d:1]", from)
+	}
+
+	if page, _ := d.GetVerticesPageCtx(bob, 0, 10); deep.Equal(page.IDs, []string{"a", "c", "d"}) != nil || page.Total != 3 {
+		t.Errorf("GetVerticesPage() = %v, want [a c d]", page)
+	}
+	if page, _ := d.GetDescendantsPageCtx(bob, "a", 0, 10); deep.Equal(page.IDs, []string{"d"}) != nil {
+		t.Errorf("GetDescendantsPage() = %v, want [d]", page)
+	}
+	if page, _ := d.GetChildrenPageCtx(bob, "a", 0, 10); deep.Equal(page.IDs, []string{"d"}) != nil {
+		t.Errorf("GetChildrenPage() = %v, want [d]", page)
+	}
+	if page, _ := d.SampleVerticesCtx(bob, 10); page.Total != 3 {
+		t.Errorf("SampleVertices() = %v, want 3 vertices", page)
+	}
+	if page, _ := d.SampleDescendantsCtx(bob, "a", 10); deep.Equal(page.IDs, []string{"d"}) != nil {
+		t.Errorf("SampleDescendants() = %v, want [d]", page)
+	}
+
+	// c's only parent and a's child b are hidden
+	if roots, _ := d.GetRootsWithCtx(bob, nil); deep.Equal(roots, []string{"a", "c"}) != nil {
+		t.Errorf("GetRootsWith() = %v, want [a c]", roots)
+	}
+	if leaves, _ := d.GetLeavesWithCtx(bob, nil); deep.Equal(leaves, []string{"c", "d"}) != nil {
+		t.Errorf("GetLeavesWith() = %v, want [c d]", leaves)
+	}
+	var docs []aclVertex
+	if err := d.GetLeavesDocumentsCtx(bob, &docs, nil); err != nil || len(docs) != 2 {
+		t.Errorf("GetLeavesDocuments() = %v (%v), want 2 documents", docs, err)
+	}
+
+	if subtree, _ := d.GetSubtreeCtx(bob, "a"); deep.Equal(subtree, []string{"d"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [d]", subtree)
+	}
+	children, err := d.BatchGetChildrenCtx(bob, []string{"a", "b"})
+	if err != nil {
+		t.Fatalf("failed to BatchGetChildren(): %v", err)
+	}
+	if diff := deep.Equal(children, map[string][]string{"a": {"d"}, "b": {}}); diff != nil {
+		t.Errorf("BatchGetChildren(): %v", diff)
+	}
+}
@@ -0,0 +1,119 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Edge is an edge given by the ids of its source and destination vertex.
+type Edge struct {
+	Src string `json:"src"`
+	Dst string `json:"dst"`
+}
+
+// AssertWouldBeAcyclic checks, whether the given edges could be added (in the
+// given order) without creating a loop, without writing anything (e.g. to
+// validate graphs drafted by users before adding them). AssertWouldBeAcyclic
+// returns a loop error (see IsLoopError) for the first edge creating a loop,
+// and an error, if any of the ids is empty or unknown or if the source and
+// the destination of an edge are equal.
+func (d *DAG) AssertWouldBeAcyclic(edges []Edge) error {
+	return d.AssertWouldBeAcyclicCtx(context.Background(), edges)
+}
+
+// AssertWouldBeAcyclicCtx is like AssertWouldBeAcyclic but uses the given
+// context.
+func (d *DAG) AssertWouldBeAcyclicCtx(ctx context.Context, edges []Edge) error {
+	var ids []string
+	seen := make(map[string]struct{})
+	for _, e := range edges {
+		if e.Src == "" || e.Dst == "" {
+			return EmptyIDError()
+		}
+		if e.Src == e.Dst {
+			return SrcDstEqualError(e.Src)
+		}
+		for _, id := range []string{e.Src, e.Dst} {
+			if _, ok := seen[id]; !ok {
+				seen[id] = struct{}{}
+				ids = append(ids, id)
+			}
+		}
+	}
+	ctx = d.context(ctx)
+	starts, err := d.vertexDocumentIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+
+	// the reachability among the endpoints (any loop consists of new edges
+	// and paths between their endpoints)
+	query := `
+FOR start IN @starts
+  FOR v IN 1..@maxDepth OUTBOUND start @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER v._id IN @starts
+    RETURN [PARSE_IDENTIFIER(start).key, v._key]`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"starts":   starts,
+		"maxDepth": maxDepth,
+	}
+	var graph [][2]string
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var edge [2]string
+		if err := json.Unmarshal(doc, &edge); err != nil {
+			return err
+		}
+		graph = append(graph, [2]string{d.id(edge[0]), d.id(edge[1])})
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	for _, e := range edges {
+		graph = append(graph, [2]string{e.Src, e.Dst})
+		if cyclic(graph) {
+			return LoopError(e.Src, e.Dst)
+		}
+	}
+	return nil
+}
+
+// VerifyAcyclicity checks, whether the stored graph is acyclic, e.g. to detect
+// loops created by writing to the collections directly (or by older versions
+// not adding edges atomically). If there is a loop, VerifyAcyclicity returns a
+// CycleError (see IsLoopError) reporting one of the loops, i.e. the edge from
+// Src to Dst together with the path from Dst to Src. The graph is read into
+// memory (within a consistent snapshot).
+func (d *DAG) VerifyAcyclicity() error {
+	return d.VerifyAcyclicityCtx(context.Background())
+}
+
+// VerifyAcyclicityCtx is like VerifyAcyclicity but uses the given context.
+func (d *DAG) VerifyAcyclicityCtx(ctx context.Context) error {
+	var edges [][2]string
+	err := d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		query := "FOR e IN @@edges RETURN [PARSE_IDENTIFIER(e._from).key, PARSE_IDENTIFIER(e._to).key]"
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+		}
+		return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var edge [2]string
+			if err := json.Unmarshal(doc, &edge); err != nil {
+				return err
+			}
+			edges = append(edges, [2]string{d.id(edge[0]), d.id(edge[1])})
+			return nil
+		})
+	})
+	if err != nil {
+		return err
+	}
+	cycle := findCycle(edges)
+	if cycle == nil {
+		return nil
+	}
+	return CycleError{Src: cycle[len(cycle)-1], Dst: cycle[0], Path: cycle}
+}
@@ -0,0 +1,109 @@
+package arangodag
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"github.com/arangodb/go-driver"
+	"github.com/go-test/deep"
+)
+
+func TestDAG_AssertWouldBeAcyclic(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("3", "4")
+
+	if err := d.AssertWouldBeAcyclic([]Edge{{"2", "3"}, {"1", "4"}}); err != nil {
+		t.Errorf("AssertWouldBeAcyclic() = %v, want nil", err)
+	}
+
+	// loop via existing edges
+	err := d.AssertWouldBeAcyclic([]Edge{{"2", "3"}, {"4", "1"}})
+	if !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if err.Error() != LoopError("4", "1").Error() {
+		t.Errorf("got %v, want %v", err, LoopError("4", "1"))
+	}
+
+	// loop among new edges
+	if err := d.AssertWouldBeAcyclic([]Edge{{"2", "3"}, {"3", "2"}}); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+
+	// nothing written
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want 2", size)
+	}
+
+	// errors
+	if err := d.AssertWouldBeAcyclic([]Edge{{"1", "foo"}}); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+	if err := d.AssertWouldBeAcyclic([]Edge{{"1", "1"}}); !IsSrcDstEqualError(err) {
+		t.Errorf("want SrcDstEqualError, got %v", err)
+	}
+}
+
+func TestFindCycle(t *testing.T) {
+	if cycle := findCycle([][2]string{{"1", "2"}, {"2", "3"}, {"1", "3"}}); cycle != nil {
+		t.Errorf("findCycle() = %v, want nil", cycle)
+	}
+	cycle := findCycle([][2]string{{"0", "1"}, {"1", "2"}, {"2", "3"}, {"3", "1"}, {"3", "4"}})
+	if diff := deep.Equal(cycle, []string{"1", "2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDAG_AddEdgeConcurrently(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+
+	// only one of two edges closing a loop may be added
+	var wg sync.WaitGroup
+	errs := make([]error, 2)
+	for i, e := range []Edge{{"1", "2"}, {"2", "1"}} {
+		wg.Add(1)
+		go func(i int, e Edge) {
+			defer wg.Done()
+			errs[i] = d.AddEdge(e.Src, e.Dst)
+		}(i, e)
+	}
+	wg.Wait()
+	if (errs[0] == nil) == (errs[1] == nil) {
+		t.Errorf("AddEdge() = %v, %v, want exactly one error", errs[0], errs[1])
+	}
+	if err := d.VerifyAcyclicity(); err != nil {
+		t.Errorf("VerifyAcyclicity() = %v, want nil", err)
+	}
+}
+
+func TestDAG_VerifyAcyclicity(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	if err := d.VerifyAcyclicity(); err != nil {
+		t.Errorf("VerifyAcyclicity() = %v, want nil", err)
+	}
+
+	// bypass the DAG checks to create a cycle
+	_, _ = d.edges.CreateDocument(context.Background(), &myEdge{From: driver.NewDocumentID(d.vertices.Name(), "3"), To: driver.NewDocumentID(d.vertices.Name(), "1")})
+	err := d.VerifyAcyclicity()
+	if !IsLoopError(err) {
+		t.Fatalf("want LoopError, got %v", err)
+	}
+	want := CycleError{Src: "3", Dst: "1", Path: []string{"1", "2", "3"}}
+	if diff := deep.Equal(err, want); diff != nil {
+		t.Error(diff)
+	}
+}
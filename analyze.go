@@ -0,0 +1,198 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"path"
+)
+
+// AnalyzeOptions configures Analyze.
+type AnalyzeOptions struct {
+
+	// HubDegree is the (total) degree from which on vertices are reported as
+	// hubs. Zero refers to 1000.
+	HubDegree int
+
+	// MaxHubs limits the number of hubs reported. Zero refers to 100.
+	MaxHubs int
+}
+
+// AnalysisReport is the report of suggested optimizations produced by Analyze.
+type AnalysisReport struct {
+
+	// RedundantEdges are the edges implied by other paths, i.e. the edges
+	// removable by a transitive reduction without changing reachability.
+	RedundantEdges []Edge `json:"redundantEdges"`
+
+	// Hubs are the vertices of high degree (ordered by descending degree),
+	// which are candidates for contracting (see Contract) or splitting.
+	Hubs []Hub `json:"hubs"`
+
+	// MissingIndexes are indexes supporting the queries of the enabled
+	// features, which don't exist yet (see EnsureVertexIndex and
+	// EnsureEdgeIndex).
+	MissingIndexes []IndexSuggestion `json:"missingIndexes"`
+}
+
+// Hub is a vertex of high degree (see AnalysisReport).
+type Hub struct {
+	ID        string `json:"id"`
+	InDegree  uint64 `json:"inDegree"`
+	OutDegree uint64 `json:"outDegree"`
+}
+
+// IndexSuggestion is a suggested index (see AnalysisReport).
+type IndexSuggestion struct {
+	Collection string   `json:"collection"`
+	Fields     []string `json:"fields"`
+	Reason     string   `json:"reason"`
+}
+
+// Analyze inspects the graph and returns a report of suggested optimizations,
+// i.e. redundant edges, hub vertices and missing indexes (see AnalysisReport).
+// Analyze doesn't change anything. Finding redundant edges traverses the
+// descendants of the sources of all edges, thus, Analyze is meant to be run
+// offline (e.g. as part of maintenance). If opts is nil, the defaults are
+// used.
+func (d *DAG) Analyze(opts *AnalyzeOptions) (*AnalysisReport, error) {
+	return d.AnalyzeCtx(context.Background(), opts)
+}
+
+// AnalyzeCtx is like Analyze but uses the given context.
+func (d *DAG) AnalyzeCtx(ctx context.Context, opts *AnalyzeOptions) (*AnalysisReport, error) {
+	if opts == nil {
+		opts = &AnalyzeOptions{}
+	}
+	hubDegree, maxHubs := opts.HubDegree, opts.MaxHubs
+	if hubDegree <= 0 {
+		hubDegree = 1000
+	}
+	if maxHubs <= 0 {
+		maxHubs = 100
+	}
+	ctx = d.context(ctx)
+	r := &AnalysisReport{
+		RedundantEdges: []Edge{},
+		Hubs:           []Hub{},
+		MissingIndexes: []IndexSuggestion{},
+	}
+
+	// redundant edges (i.e. edges whose destination is reachable otherwise)
+	query := `
+FOR e IN @@edges
+  FILTER LENGTH(
+    FOR c IN 1 OUTBOUND e._from @@edges
+      FILTER c._id != e._to
+      FOR v IN 0..@maxDepth OUTBOUND c @@edges
+        OPTIONS {bfs: true, uniqueVertices: "global"}
+        FILTER v._id == e._to
+        LIMIT 1
+        RETURN 1
+  ) > 0
+  SORT e._from, e._to
+  RETURN {src: PARSE_IDENTIFIER(e._from).key, dst: PARSE_IDENTIFIER(e._to).key}`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"maxDepth": maxDepth,
+	}
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var e Edge
+		if err := json.Unmarshal(doc, &e); err != nil {
+			return err
+		}
+		r.RedundantEdges = append(r.RedundantEdges, Edge{Src: d.id(e.Src), Dst: d.id(e.Dst)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// hubs
+	query = `
+FOR e IN @@edges
+  FOR x IN [{id: e._from, in: 0, out: 1}, {id: e._to, in: 1, out: 0}]
+    COLLECT id = x.id AGGREGATE inDegree = SUM(x.in), outDegree = SUM(x.out)
+    FILTER inDegree + outDegree >= @hubDegree
+    SORT inDegree + outDegree DESC, id
+    LIMIT @maxHubs
+    RETURN {id: PARSE_IDENTIFIER(id).key, inDegree, outDegree}`
+	bindVars = map[string]interface{}{
+		"@edges":    d.edges.Name(),
+		"hubDegree": hubDegree,
+		"maxHubs":   maxHubs,
+	}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var h Hub
+		if err := json.Unmarshal(doc, &h); err != nil {
+			return err
+		}
+		h.ID = d.id(h.ID)
+		r.Hubs = append(r.Hubs, h)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// missing indexes
+	for _, s := range d.indexSuggestions() {
+		indexed, err := d.isIndexed(ctx, s.Collection, s.Fields[0])
+		if err != nil {
+			return nil, err
+		}
+		if !indexed {
+			r.MissingIndexes = append(r.MissingIndexes, s)
+		}
+	}
+	return r, nil
+}
+
+// indexSuggestions returns the indexes supporting the queries of the enabled
+// features.
+func (d *DAG) indexSuggestions() []IndexSuggestion {
+	suggestions := []IndexSuggestion{{
+		Collection: d.vertices.Name(),
+		Fields:     []string{d.timestampAttribute},
+		Reason:     "filtering by timestamp (see PruneOlderThan)",
+	}}
+	if d.updatedAttribute != "" {
+		for _, coll := range []string{d.vertices.Name(), d.edges.Name()} {
+			suggestions = append(suggestions, IndexSuggestion{
+				Collection: coll,
+				Fields:     []string{d.updatedAttribute},
+				Reason:     "filtering by modification time (see GetRecentlyModified)",
+			})
+		}
+	}
+	return suggestions
+}
+
+// isIndexed returns true, if there is an index on the given collection whose
+// first field is the given one.
+func (d *DAG) isIndexed(ctx context.Context, collection, field string) (bool, error) {
+	conn := d.client.Connection()
+	req, err := conn.NewRequest("GET", path.Join("_db", d.db.Name(), "_api/index"))
+	if err != nil {
+		return false, arangoError(err)
+	}
+	req.SetQuery("collection", collection)
+	resp, err := conn.Do(ctx, req)
+	if err != nil {
+		return false, arangoError(err)
+	}
+	if err := resp.CheckStatus(200); err != nil {
+		return false, arangoError(err)
+	}
+	var indexes []struct {
+		Fields []string `json:"fields"`
+	}
+	if err := resp.ParseBody("indexes", &indexes); err != nil {
+		return false, arangoError(err)
+	}
+	for _, index := range indexes {
+		if len(index.Fields) > 0 && index.Fields[0] == field {
+			return true, nil
+		}
+	}
+	return false, nil
+}
@@ -0,0 +1,43 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_Analyze(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("1", "4")
+
+	r, err := d.Analyze(&AnalyzeOptions{HubDegree: 3})
+	if err != nil {
+		t.Fatalf("failed to Analyze(): %v", err)
+	}
+	if diff := deep.Equal(r.RedundantEdges, []Edge{{Src: "1", Dst: "3"}}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(r.Hubs, []Hub{{ID: "1", InDegree: 0, OutDegree: 3}}); diff != nil {
+		t.Error(diff)
+	}
+	if len(r.MissingIndexes) != 1 || r.MissingIndexes[0].Fields[0] != "payload.timestamp" {
+		t.Errorf("MissingIndexes = %+v, want the timestamp index", r.MissingIndexes)
+	}
+
+	if _, err := d.EnsureVertexIndex([]string{"payload.timestamp"}, IndexPersistent); err != nil {
+		t.Fatalf("failed to EnsureVertexIndex(): %v", err)
+	}
+	r, err = d.Analyze(nil)
+	if err != nil {
+		t.Fatalf("failed to Analyze(): %v", err)
+	}
+	if len(r.Hubs) != 0 || len(r.MissingIndexes) != 0 {
+		t.Errorf("Analyze() = %+v, want no hubs and no missing indexes", r)
+	}
+}
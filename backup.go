@@ -0,0 +1,283 @@
+package arangodag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"io"
+)
+
+// Change log operations and types (see Change).
+const (
+	changeUpsert = "upsert"
+	changeRemove = "remove"
+	changeVertex = "vertex"
+	changeEdge   = "edge"
+)
+
+// Change is a single entry of the change log (see WithChangeLog) as written by
+// IncrementalBackup and read by Restore.
+type Change struct {
+
+	// Op is either "upsert" or "remove".
+	Op string `json:"op"`
+
+	// Type is either "vertex" or "edge".
+	Type string `json:"type"`
+
+	// Key is the key of the vertex or the edge document.
+	Key string `json:"key"`
+
+	// Doc is the (upserted) document without "_id" and "_rev". For edges,
+	// "_from" and "_to" hold vertex ids (i.e. keys).
+	Doc json.RawMessage `json:"doc,omitempty"`
+}
+
+// afterCopy returns the callback of copyDocuments handling the upserts of the
+// vertices or edges (as given by typ) created (see afterWrite).
+func (d *DAG) afterCopy(ctx context.Context, typ string) func(ids ...driver.DocumentID) error {
+	return func(ids ...driver.DocumentID) error {
+		return d.afterWrite(ctx, changeUpsert, typ, ids...)
+	}
+}
+
+// afterWrite maintains the data derived from the vertices or edges (as given
+// by typ) with the given document ids after writing them (within the
+// transaction of the write): The operation is recorded in the change log (if
+// enabled). For upserts, the current documents are recorded (after stamping
+// them, see WithModificationTimestamps). The actor carried by ctx (see
+// WithActor) is recorded too. Changes of edges are mirrored to the inverse
+// edges (see WithInverseEdges) and added edges extend the reachability
+// filters (see WithReachabilityFilters) and move their destinations (and the
+// trees below them) to their new materialized paths (see
+// WithMaterializedPaths).
+func (d *DAG) afterWrite(ctx context.Context, op, typ string, ids ...driver.DocumentID) error {
+	if op == changeUpsert {
+		if err := d.stampChanges(ctx, typ, ids...); err != nil {
+			return err
+		}
+	}
+	if typ == changeEdge {
+		if err := d.mirrorChanges(ctx, op, ids...); err != nil {
+			return err
+		}
+	}
+	if typ == changeEdge && op == changeUpsert {
+		if err := d.propagateReach(ctx, ids...); err != nil {
+			return err
+		}
+		if err := d.updateEdgePaths(ctx, ids...); err != nil {
+			return err
+		}
+	}
+	if d.changes == nil || len(ids) == 0 {
+		return nil
+	}
+	query := `
+FOR id IN @ids
+  LET doc = @op == "remove" ? null : UNSET(DOCUMENT(id), "_id", "_rev")
+  INSERT {
+    op: @op,
+    type: @type,
+    key: PARSE_IDENTIFIER(id).key,
+    doc: doc == null || @type != "edge" ? doc : MERGE(doc, {
+      _from: PARSE_IDENTIFIER(doc._from).key,
+      _to: PARSE_IDENTIFIER(doc._to).key
+    }),
+    time: DATE_ISO8601(DATE_NOW()),
+    actor: @actor
+  } INTO @@changes`
+	var actor interface{}
+	if a, ok := ActorFromContext(ctx); ok {
+		actor = a
+	}
+	bindVars := map[string]interface{}{
+		"@changes": d.changes.Name(),
+		"ids":      ids,
+		"op":       op,
+		"type":     typ,
+		"actor":    actor,
+	}
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	closeCursor(cursor)
+	return nil
+}
+
+// IncrementalBackup writes the changes (see Change) since the version
+// sinceVersion (as returned by a previous backup) as newline delimited JSON to
+// w and returns the current version. If sinceVersion is empty,
+// IncrementalBackup writes a full backup (i.e. upserts of all vertices and
+// edges). Incremental backups require the change log to be enabled (see
+// WithChangeLog). The backup represents a consistent snapshot of the graph:
+// it is read within a single transaction and, as the change log is locked
+// exclusively by the transactions writing to it (see WithChangeLog), no change
+// committed later gets a key (i.e. version) lower than the returned one.
+func (d *DAG) IncrementalBackup(w io.Writer, sinceVersion string) (string, error) {
+	return d.IncrementalBackupCtx(context.Background(), w, sinceVersion)
+}
+
+// IncrementalBackupCtx is like IncrementalBackup but uses the given context.
+func (d *DAG) IncrementalBackupCtx(ctx context.Context, w io.Writer, sinceVersion string) (string, error) {
+	if sinceVersion != "" && d.changes == nil {
+		return "", InvalidParameterError("sinceVersion", "incremental backups require the change log to be enabled")
+	}
+	var version string
+	err := d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		var err error
+		if version, err = d.currentVersion(ctx); err != nil {
+			return err
+		}
+		bw := bufio.NewWriter(w)
+		write := func(doc json.RawMessage) error {
+			if _, err := bw.Write(doc); err != nil {
+				return err
+			}
+			return bw.WriteByte('\n')
+		}
+		if sinceVersion == "" {
+			if err := d.snapshotChanges(ctx)(write); err != nil {
+				return err
+			}
+		} else {
+			query := `
+FOR c IN @@changes
+  FILTER c._key > @since AND c._key <= @version
+  SORT c._key
+  RETURN KEEP(c, "op", "type", "key", "doc")`
+			bindVars := map[string]interface{}{
+				"@changes": d.changes.Name(),
+				"since":    sinceVersion,
+				"version":  version,
+			}
+			if err := d.forEachDocument(ctx, query, bindVars)(write); err != nil {
+				return err
+			}
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return "", err
+	}
+	return version, nil
+}
+
+// currentVersion returns the key of the latest change log entry (or an empty
+// string, if there is none).
+func (d *DAG) currentVersion(ctx context.Context) (string, error) {
+	if d.changes == nil {
+		return "", nil
+	}
+	query := "FOR c IN @@changes SORT c._key DESC LIMIT 1 RETURN c._key"
+	bindVars := map[string]interface{}{
+		"@changes": d.changes.Name(),
+	}
+	var version string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &version)
+	})
+	return version, err
+}
+
+// snapshotChanges returns an iterator over upserts of all vertices and edges.
+func (d *DAG) snapshotChanges(ctx context.Context) documentIterator {
+	vertices := `
+FOR v IN @@vertices
+  RETURN {op: @op, type: @type, key: v._key, doc: UNSET(v, "_id", "_rev")}`
+	edges := `
+FOR e IN @@edges
+  RETURN {op: @op, type: @type, key: e._key, doc: MERGE(UNSET(e, "_id", "_rev"), {
+    _from: PARSE_IDENTIFIER(e._from).key,
+    _to: PARSE_IDENTIFIER(e._to).key
+  })}`
+	return func(fn func(doc json.RawMessage) error) error {
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"op":        changeUpsert,
+			"type":      changeVertex,
+		}
+		if err := d.forEachDocument(ctx, vertices, bindVars)(fn); err != nil {
+			return err
+		}
+		bindVars = map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"op":     changeUpsert,
+			"type":   changeEdge,
+		}
+		return d.forEachDocument(ctx, edges, bindVars)(fn)
+	}
+}
+
+// Restore replays the changes (see Change) read from r (as written by
+// IncrementalBackup) within a single transaction. To restore a graph, restore
+// the full backup followed by all incremental backups (in order). Restore
+// doesn't check for loops, i.e. it relies on the backups being consistent.
+// With materialized paths enabled, Restore recomputes all paths (within its
+// transaction) after replaying the changes (see WithMaterializedPaths).
+func (d *DAG) Restore(r io.Reader) error {
+	return d.RestoreCtx(context.Background(), r)
+}
+
+// RestoreCtx is like Restore but uses the given context.
+func (d *DAG) RestoreCtx(ctx context.Context, r io.Reader) error {
+	return d.transaction(d.context(ctx), func(ctx context.Context) error {
+		dec := json.NewDecoder(r)
+		for {
+			var c Change
+			err := dec.Decode(&c)
+			if err == io.EOF {
+				return d.rebuildPaths(ctx)
+			}
+			if err != nil {
+				return err
+			}
+			if err := d.applyChange(ctx, c); err != nil {
+				return err
+			}
+		}
+	})
+}
+
+// applyChange applies the given change (and records it in the change log).
+func (d *DAG) applyChange(ctx context.Context, c Change) error {
+	var coll driver.Collection
+	switch c.Type {
+	case changeVertex:
+		coll = d.vertices
+	case changeEdge:
+		coll = d.edges
+	default:
+		return InvalidParameterError("r", "unknown change type '"+c.Type+"'")
+	}
+
+	var query string
+	bindVars := map[string]interface{}{
+		"@coll": coll.Name(),
+		"key":   c.Key,
+	}
+	switch c.Op {
+	case changeUpsert:
+		query = `
+LET doc = MERGE(@doc, {_key: @key}, @type != "edge" ? {} : {
+  _from: CONCAT(@vertices, "/", @doc._from),
+  _to: CONCAT(@vertices, "/", @doc._to)
+})
+UPSERT {_key: @key} INSERT doc REPLACE doc IN @@coll`
+		bindVars["doc"] = c.Doc
+		bindVars["type"] = c.Type
+		bindVars["vertices"] = d.vertices.Name()
+	case changeRemove:
+		query = "REMOVE {_key: @key} IN @@coll OPTIONS {ignoreErrors: true}"
+	default:
+		return InvalidParameterError("r", "unknown change operation '"+c.Op+"'")
+	}
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	closeCursor(cursor)
+	return d.afterWrite(ctx, c.Op, c.Type, driver.NewDocumentID(coll.Name(), c.Key))
+}
@@ -0,0 +1,110 @@
+package arangodag
+
+import (
+	"bytes"
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDAG_IncrementalBackup(t *testing.T) {
+	d := someNewDag(t, WithChangeLog())
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+
+	// full backup
+	var full bytes.Buffer
+	version, err := d.IncrementalBackup(&full, "")
+	if err != nil {
+		t.Fatalf("failed to IncrementalBackup(): %v", err)
+	}
+	if version == "" {
+		t.Errorf("IncrementalBackup() = '', want a version")
+	}
+
+	// incremental backup
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	_ = d.AddEdge("2", "3")
+	var incremental bytes.Buffer
+	version2, err := d.IncrementalBackup(&incremental, version)
+	if err != nil {
+		t.Fatalf("failed to IncrementalBackup(): %v", err)
+	}
+	if version2 <= version {
+		t.Errorf("IncrementalBackup() = '%s', want a version greater than '%s'", version2, version)
+	}
+	if lines := bytes.Count(incremental.Bytes(), []byte("\n")); lines != 2 {
+		t.Errorf("IncrementalBackup() wrote %d changes, want %d", lines, 2)
+	}
+
+	// restore
+	target := someNewDag(t)
+	if err := target.Restore(&full); err != nil {
+		t.Fatalf("failed to Restore(): %v", err)
+	}
+	if err := target.Restore(&incremental); err != nil {
+		t.Fatalf("failed to Restore(): %v", err)
+	}
+	if order, _ := target.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := target.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+	if err := target.AddEdge("3", "1"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+
+	// incremental backups require a change log
+	if _, err := target.IncrementalBackup(&bytes.Buffer{}, version); err == nil {
+		t.Errorf("IncrementalBackup() = nil, want error")
+	}
+}
+
+func TestDAG_IncrementalBackup_concurrent(t *testing.T) {
+	d := someNewDag(t, WithChangeLog())
+
+	// back up while adding vertices concurrently
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = d.AddVertex(idVertex{MyID: fmt.Sprintf("%d", i)})
+		}(i)
+	}
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+	var backups []*bytes.Buffer
+	version := ""
+	for finished := false; !finished; {
+		select {
+		case <-done:
+			finished = true
+		default:
+		}
+		var b bytes.Buffer
+		v, err := d.IncrementalBackup(&b, version)
+		if err != nil {
+			t.Fatalf("failed to IncrementalBackup(): %v", err)
+		}
+		backups = append(backups, &b)
+		version = v
+	}
+
+	// no change got lost between backups
+	target := someNewDag(t)
+	for _, b := range backups {
+		if err := target.Restore(b); err != nil {
+			t.Fatalf("failed to Restore(): %v", err)
+		}
+	}
+	if order, _ := target.GetOrder(); order != 20 {
+		t.Errorf("GetOrder() = %d, want %d", order, 20)
+	}
+}
@@ -0,0 +1,323 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arangodb/go-driver"
+)
+
+// EdgeSpec specifies an edge to be added by AddEdges.
+type EdgeSpec struct {
+	Src string
+	Dst string
+
+	// Edge holds the fields of the edge (see AddEdgeWithData) and may be nil.
+	Edge interface{}
+}
+
+// AddVertices adds the given vertices (see AddVertex) using batch requests and
+// returns their ids together with an error per vertex (i.e. ids[i] is empty,
+// if errs[i] is not nil). Failing vertices (e.g. duplicates) don't prevent the
+// others from being added. The returned error is not nil, if the batch as a
+// whole fails (in which case some vertices may have been added) or, if
+// adding the vertices would exceed the quota (see WithQuota), in which case
+// none is added.
+func (d *DAG) AddVertices(vertices []interface{}) ([]string, []error, error) {
+	return d.AddVerticesCtx(context.Background(), vertices)
+}
+
+// AddVerticesCtx is like AddVertices but uses the given context.
+func (d *DAG) AddVerticesCtx(ctx context.Context, vertices []interface{}) ([]string, []error, error) {
+	ids := make([]string, len(vertices))
+	errs := make([]error, len(vertices))
+	var docs []interface{}
+	var indexes []int
+	for i, vertex := range vertices {
+		doc, id, err := d.vertexDocument(vertex)
+		if err != nil {
+			errs[i] = err
+			continue
+		}
+		ids[i] = id
+		docs = append(docs, doc)
+		indexes = append(indexes, i)
+	}
+	var created int64
+	err := d.write(d.context(ctx), func(ctx context.Context) error {
+		if err := d.checkVertexQuota(ctx, len(docs)); err != nil {
+			return err
+		}
+		for start := 0; start < len(docs); start += cloneBatchSize {
+			end := start + cloneBatchSize
+			if end > len(docs) {
+				end = len(docs)
+			}
+			metas, batchErrs, err := d.vertices.CreateDocuments(ctx, docs[start:end])
+			if err != nil {
+				return arangoError(err)
+			}
+			var createdIDs []driver.DocumentID
+			for j, meta := range metas {
+				i := indexes[start+j]
+				if batchErrs[j] != nil {
+					errs[i] = d.vertexError(batchErrs[j], ids[i])
+					ids[i] = ""
+					continue
+				}
+				ids[i] = d.id(meta.Key)
+				createdIDs = append(createdIDs, meta.ID)
+			}
+			created += int64(len(createdIDs))
+			if err := d.afterWrite(ctx, changeUpsert, changeVertex, createdIDs...); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+
+		// some vertices may have been added (without a transaction)
+		d.counts.invalidate()
+		return nil, nil, err
+	}
+	d.counts.add(created, 0)
+	return ids, errs, nil
+}
+
+// AddEdges adds the given edges (see AddEdge) using batch requests and returns
+// an error per edge. Edges failing the usual checks (e.g. unknown vertices or
+// duplicates) are skipped, the others are added within a single transaction
+// holding an exclusive lock on the edge collection. Rather than checking each
+// edge for loops, the edges are inserted first and those closing a loop (i.e.
+// whose destination reaches their source) are detected by a single query. If
+// there are any, the transaction is rolled back and all edges but those being
+// part of a loop are added again (rejecting all edges of such loops, as none
+// of them is to blame alone), repeatedly, until no loop is found. The returned
+// error is not nil, if the batch as a whole fails or, if adding the edges
+// would exceed the quota (see WithQuota), in which case none is added. The
+// quota on the depth and on the number of children isn't enforced by
+// AddEdges.
+func (d *DAG) AddEdges(edges []EdgeSpec) ([]error, error) {
+	return d.AddEdgesCtx(context.Background(), edges)
+}
+
+// AddEdgesCtx is like AddEdges but uses the given context.
+func (d *DAG) AddEdgesCtx(ctx context.Context, edges []EdgeSpec) ([]error, error) {
+	errs := make([]error, len(edges))
+	ctx = d.context(ctx)
+
+	// unknown vertices
+	var keys []string
+	for _, e := range edges {
+		keys = append(keys, d.key(e.Src), d.key(e.Dst))
+	}
+	missing, err := d.missingVertices(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	unknown := make(map[string]struct{}, len(missing))
+	for _, key := range missing {
+		unknown[key] = struct{}{}
+	}
+
+	type candidate struct {
+		index    int
+		src, dst driver.DocumentID
+		doc      interface{}
+	}
+	var candidates []candidate
+	batch := make(map[[2]string]struct{})
+	for i, e := range edges {
+		errs[i] = func() error {
+			if e.Src == "" || e.Dst == "" {
+				return EmptyIDError()
+			}
+			if e.Src == e.Dst {
+				return SrcDstEqualError(e.Src)
+			}
+			for _, id := range []string{e.Src, e.Dst} {
+				if _, ok := unknown[d.key(id)]; ok {
+					return NewUnknownKeyError(id)
+				}
+			}
+			if _, ok := batch[[2]string{e.Src, e.Dst}]; ok {
+				return DuplicateEdgeError(e.Src, e.Dst)
+			}
+			src := driver.NewDocumentID(d.vertices.Name(), d.key(e.Src))
+			dst := driver.NewDocumentID(d.vertices.Name(), d.key(e.Dst))
+			doc, err := d.edgeDocument(src, dst, e.Edge)
+			if err != nil {
+				return err
+			}
+			if d.beforeAddEdge != nil {
+				if err := d.beforeAddEdge(d.id(src.Key()), d.id(dst.Key())); err != nil {
+					return err
+				}
+			}
+			if err := d.checkEdgeTypes(ctx, src, dst); err != nil {
+				return err
+			}
+			batch[[2]string{e.Src, e.Dst}] = struct{}{}
+			candidates = append(candidates, candidate{index: i, src: src, dst: dst, doc: doc})
+			return nil
+		}()
+	}
+	// insert the candidates and check for loops
+	insert := func(ctx context.Context, candidates []candidate) ([]int, error) {
+		if err := d.checkSizeQuota(ctx, len(candidates)); err != nil {
+			return nil, err
+		}
+
+		// existing edges
+		pairs := make([][2]driver.DocumentID, len(candidates))
+		for j, c := range candidates {
+			pairs[j] = [2]driver.DocumentID{c.src, c.dst}
+		}
+		query := `
+FOR p IN @pairs
+  FILTER LENGTH(FOR e IN @@edges FILTER e._from == p[0] AND e._to == p[1] LIMIT 1 RETURN 1) > 0
+  RETURN p`
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"pairs":  pairs,
+		}
+		existing := make(map[[2]driver.DocumentID]struct{})
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var p [2]driver.DocumentID
+			if err := json.Unmarshal(doc, &p); err != nil {
+				return err
+			}
+			existing[p] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+
+		var docs []interface{}
+		var inserted []candidate
+		for _, c := range candidates {
+			if _, ok := existing[[2]driver.DocumentID{c.src, c.dst}]; ok {
+				errs[c.index] = DuplicateEdgeError(edges[c.index].Src, edges[c.index].Dst)
+				continue
+			}
+			if err := d.setEdgeShard(ctx, c.doc, c.src); err != nil {
+				errs[c.index] = err
+				continue
+			}
+			docs = append(docs, c.doc)
+			inserted = append(inserted, c)
+		}
+		var createdIDs []driver.DocumentID
+		for start := 0; start < len(docs); start += cloneBatchSize {
+			end := start + cloneBatchSize
+			if end > len(docs) {
+				end = len(docs)
+			}
+			metas, batchErrs, err := d.edges.CreateDocuments(ctx, docs[start:end])
+			if err != nil {
+				return nil, arangoError(err)
+			}
+			for j, meta := range metas {
+				c := inserted[start+j]
+				if batchErrs[j] != nil {
+					errs[c.index] = arangoError(batchErrs[j])
+					continue
+				}
+				createdIDs = append(createdIDs, meta.ID)
+			}
+		}
+
+		// loops
+		if len(inserted) > 0 {
+			query := `
+FOR p IN @pairs
+  FILTER LENGTH(
+    FOR v IN 1..@maxDepth OUTBOUND p.dst @@edges
+      OPTIONS {bfs: true, uniqueVertices: "global"}
+      FILTER v._id == p.src
+      LIMIT 1
+      RETURN 1
+  ) > 0
+  RETURN p.index`
+			type pair struct {
+				Index int               `json:"index"`
+				Src   driver.DocumentID `json:"src"`
+				Dst   driver.DocumentID `json:"dst"`
+			}
+			pairs := make([]pair, len(inserted))
+			for j, c := range inserted {
+				pairs[j] = pair{Index: c.index, Src: c.src, Dst: c.dst}
+			}
+			bindVars := map[string]interface{}{
+				"@edges":   d.edges.Name(),
+				"pairs":    pairs,
+				"maxDepth": maxDepth,
+			}
+			var loops []int
+			err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+				var index int
+				if err := json.Unmarshal(doc, &index); err != nil {
+					return err
+				}
+				loops = append(loops, index)
+				return nil
+			})
+			if err != nil {
+				return nil, err
+			}
+			if len(loops) > 0 {
+				return loops, nil
+			}
+		}
+
+		if err := d.afterWrite(ctx, changeUpsert, changeEdge, createdIDs...); err != nil {
+			return nil, err
+		}
+		return nil, nil
+	}
+
+	// a loop found aborts the transaction (see edgeTransaction), the other
+	// edges are added again (and checked again, e.g. for concurrent additions).
+	// Within the transaction of a caller, the edges inserted can't be rolled
+	// back separately, thus the loop error is returned (aborting the caller's
+	// transaction) instead.
+	joined := ctx.Value(transactionKey{}) == d
+	rest := candidates
+	for {
+		var loops []int
+		err = d.edgeTransaction(ctx, func(ctx context.Context) error {
+			var err error
+			if loops, err = insert(ctx, rest); err != nil {
+				return err
+			}
+			if len(loops) > 0 {
+				return LoopError(edges[loops[0]].Src, edges[loops[0]].Dst)
+			}
+			return nil
+		})
+		if len(loops) == 0 || joined {
+			break
+		}
+		rejected := make(map[int]struct{}, len(loops))
+		for _, i := range loops {
+			rejected[i] = struct{}{}
+		}
+		var next []candidate
+		for _, c := range rest {
+			errs[c.index] = nil
+			if _, ok := rejected[c.index]; ok {
+				errs[c.index] = LoopError(edges[c.index].Src, edges[c.index].Dst)
+				continue
+			}
+			next = append(next, c)
+		}
+		rest = next
+	}
+	d.counts.invalidate()
+	if err != nil {
+		return nil, err
+	}
+	return errs, nil
+}
@@ -0,0 +1,74 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_AddVertices(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+
+	ids, errs, err := d.AddVertices([]interface{}{idVertex{MyID: "2"}, idVertex{MyID: "1"}, nil, someName()})
+	if err != nil {
+		t.Fatalf("failed to AddVertices(): %v", err)
+	}
+	if ids[0] != "2" || errs[0] != nil {
+		t.Errorf("AddVertices()[0] = %s, %v, want 2, nil", ids[0], errs[0])
+	}
+	if ids[1] != "" || !IsDuplicateIDError(errs[1]) {
+		t.Errorf("AddVertices()[1] = %s, %v, want DuplicateIDError", ids[1], errs[1])
+	}
+	if !IsVertexNilError(errs[2]) {
+		t.Errorf("AddVertices()[2] = %v, want VertexNilError", errs[2])
+	}
+	if ids[3] == "" || errs[3] != nil {
+		t.Errorf("AddVertices()[3] = %s, %v, want generated id", ids[3], errs[3])
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want 3", order)
+	}
+}
+
+func TestDAG_AddEdges(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+
+	errs, err := d.AddEdges([]EdgeSpec{
+		{Src: "2", Dst: "3"},
+		{Src: "1", Dst: "2"},   // duplicate
+		{Src: "1", Dst: "foo"}, // unknown
+		{Src: "3", Dst: "4"},
+		{Src: "4", Dst: "2"}, // loop (together with 2 -> 3 and 3 -> 4)
+		{Src: "4", Dst: "5"},
+		{Src: "5", Dst: "5"},
+	})
+	if err != nil {
+		t.Fatalf("failed to AddEdges(): %v", err)
+	}
+	if errs[1] == nil || !IsDuplicateEdgeError(errs[1]) {
+		t.Errorf("AddEdges()[1] = %v, want DuplicateEdgeError", errs[1])
+	}
+	if !IsUnknownIDError(errs[2]) {
+		t.Errorf("AddEdges()[2] = %v, want UnknownIDError", errs[2])
+	}
+	for _, i := range []int{0, 3, 4} {
+		if !IsLoopError(errs[i]) {
+			t.Errorf("AddEdges()[%d] = %v, want LoopError", i, errs[i])
+		}
+	}
+	if errs[5] != nil {
+		t.Errorf("AddEdges()[5] = %v, want nil", errs[5])
+	}
+	if !IsSrcDstEqualError(errs[6]) {
+		t.Errorf("AddEdges()[6] = %v, want SrcDstEqualError", errs[6])
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want 2", size)
+	}
+	if err := d.VerifyAcyclicity(); err != nil {
+		t.Errorf("VerifyAcyclicity() = %v, want nil", err)
+	}
+}
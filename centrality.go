@@ -0,0 +1,310 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"path"
+	"time"
+)
+
+// CentralityKind is a centrality measure (see ComputeCentrality). Its value is
+// the name of the vertex attribute the scores are written to.
+type CentralityKind string
+
+// Supported centrality measures.
+const (
+
+	// CentralityPageRank is the PageRank of vertices (computed via Pregel).
+	CentralityPageRank CentralityKind = "pageRank"
+
+	// CentralityBetweenness is the betweenness of vertices, i.e. the sum over
+	// all pairs of other vertices of the fraction of the shortest paths between
+	// them passing the vertex. It is estimated from the shortest paths starting
+	// at (up to) betweennessSamples randomly sampled vertices (i.e. it is exact
+	// for smaller graphs).
+	CentralityBetweenness CentralityKind = "betweenness"
+
+	// CentralityInCloseness is the closeness of vertices with regard to their
+	// ancestors, i.e. the number of ancestors divided by the sum of their
+	// distances (0 for roots).
+	CentralityInCloseness CentralityKind = "inCloseness"
+
+	// CentralityOutCloseness is the closeness of vertices with regard to
+	// their descendants, i.e. the number of descendants divided by the sum of
+	// their distances (0 for leaves).
+	CentralityOutCloseness CentralityKind = "outCloseness"
+)
+
+// pregelPollInterval is the interval for polling the state of Pregel jobs.
+const pregelPollInterval = 100 * time.Millisecond
+
+// betweennessSamples is the number of vertices the shortest paths estimating
+// the betweenness start at (see CentralityBetweenness).
+const betweennessSamples = 100
+
+// centralityScore is the score of the vertex with the given key.
+type centralityScore struct {
+	Key   string  `json:"key"`
+	Score float64 `json:"score"`
+}
+
+// ComputeCentrality computes the given centrality measure for all vertices and
+// writes the scores to the vertex attribute named after the kind (e.g.
+// "pageRank"). ComputeCentrality blocks until all scores are written. Except
+// for the PageRank (written by Pregel), the scores are written within a single
+// transaction, recording them in the change log and stamping the vertices
+// (see WithChangeLog and WithModificationTimestamps).
+// ComputeCentrality returns an InvalidParameterError, if kind is unknown.
+func (d *DAG) ComputeCentrality(kind CentralityKind) error {
+	return d.ComputeCentralityCtx(context.Background(), kind)
+}
+
+// ComputeCentralityCtx is like ComputeCentrality but uses the given context.
+func (d *DAG) ComputeCentralityCtx(ctx context.Context, kind CentralityKind) error {
+	ctx = d.context(ctx)
+	switch kind {
+	case CentralityPageRank:
+		return d.runPregel(ctx, "pagerank", string(kind), map[string]interface{}{"threshold": 0.00001})
+	case CentralityBetweenness:
+		return d.computeBetweenness(ctx, string(kind))
+	case CentralityInCloseness:
+		return d.computeCloseness(ctx, "INBOUND", string(kind))
+	case CentralityOutCloseness:
+		return d.computeCloseness(ctx, "OUTBOUND", string(kind))
+	}
+	return InvalidParameterError("kind", fmt.Sprintf("unknown centrality kind '%s'", kind))
+}
+
+// computeCloseness computes the closeness of all vertices with regard to the
+// vertices reachable in the given direction and writes it to attr.
+func (d *DAG) computeCloseness(ctx context.Context, direction, attr string) error {
+	return d.transaction(ctx, func(ctx context.Context) error {
+		query := `
+FOR v IN @@vertices
+  LET distances = (
+    FOR w, e, p IN 1..@maxDepth ` + direction + ` v @@edges
+      OPTIONS {bfs: true, uniqueVertices: "global"}
+      RETURN LENGTH(p.edges)
+  )
+  RETURN {key: v._key, score: LENGTH(distances) == 0 ? 0 : LENGTH(distances) / SUM(distances)}`
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"@edges":    d.edges.Name(),
+			"maxDepth":  maxDepth,
+		}
+		var scores []centralityScore
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var score centralityScore
+			if err := json.Unmarshal(doc, &score); err != nil {
+				return err
+			}
+			scores = append(scores, score)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		return d.writeScores(ctx, attr, scores)
+	})
+}
+
+// computeBetweenness estimates the betweenness of all vertices (see
+// CentralityBetweenness) and writes it to attr. The graph is read into memory
+// to run Brandes' algorithm from the sampled vertices.
+func (d *DAG) computeBetweenness(ctx context.Context, attr string) error {
+	return d.transaction(ctx, func(ctx context.Context) error {
+		var keys []string
+		query := "FOR v IN @@vertices RETURN v._key"
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+		}
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var key string
+			if err := json.Unmarshal(doc, &key); err != nil {
+				return err
+			}
+			keys = append(keys, key)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		var edges [][2]string
+		query = "FOR e IN @@edges RETURN [PARSE_IDENTIFIER(e._from).key, PARSE_IDENTIFIER(e._to).key]"
+		bindVars = map[string]interface{}{
+			"@edges": d.edges.Name(),
+		}
+		err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var edge [2]string
+			if err := json.Unmarshal(doc, &edge); err != nil {
+				return err
+			}
+			edges = append(edges, edge)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		rnd := rand.New(rand.NewSource(time.Now().UnixNano()))
+		return d.writeScores(ctx, attr, betweenness(keys, edges, betweennessSamples, rnd))
+	})
+}
+
+// betweenness returns the betweenness of the vertices with the given keys
+// within the graph given by edges (from and to keys) as computed by Brandes'
+// algorithm, summing the dependencies of the shortest paths starting at (up to)
+// samples vertices sampled using rnd (scaled to all vertices).
+func betweenness(keys []string, edges [][2]string, samples int, rnd *rand.Rand) []centralityScore {
+	n := len(keys)
+	index := make(map[string]int, n)
+	for i, key := range keys {
+		index[key] = i
+	}
+	adjacent := make([][]int, n)
+	for _, e := range edges {
+		from, ok1 := index[e[0]]
+		to, ok2 := index[e[1]]
+		if ok1 && ok2 {
+			adjacent[from] = append(adjacent[from], to)
+		}
+	}
+
+	pivots := rnd.Perm(n)
+	if samples < n {
+		pivots = pivots[:samples]
+	}
+	scores := make([]float64, n)
+	sigma := make([]float64, n)
+	dist := make([]int, n)
+	delta := make([]float64, n)
+	preds := make([][]int, n)
+	for _, s := range pivots {
+		for i := range keys {
+			sigma[i], dist[i], delta[i], preds[i] = 0, -1, 0, preds[i][:0]
+		}
+		sigma[s], dist[s] = 1, 0
+
+		// count the shortest paths (in order of distance)
+		order := []int{s}
+		for i := 0; i < len(order); i++ {
+			v := order[i]
+			for _, w := range adjacent[v] {
+				if dist[w] < 0 {
+					dist[w] = dist[v] + 1
+					order = append(order, w)
+				}
+				if dist[w] == dist[v]+1 {
+					sigma[w] += sigma[v]
+					preds[w] = append(preds[w], v)
+				}
+			}
+		}
+
+		// accumulate the dependencies (in reverse order of distance)
+		for i := len(order) - 1; i > 0; i-- {
+			w := order[i]
+			for _, v := range preds[w] {
+				delta[v] += sigma[v] / sigma[w] * (1 + delta[w])
+			}
+			scores[w] += delta[w]
+		}
+	}
+
+	result := make([]centralityScore, n)
+	for i, key := range keys {
+		result[i] = centralityScore{Key: key, Score: scores[i]}
+		if len(pivots) > 0 {
+			result[i].Score *= float64(n) / float64(len(pivots))
+		}
+	}
+	return result
+}
+
+// writeScores writes the given scores to the attribute attr of the vertices
+// (see afterWrite).
+func (d *DAG) writeScores(ctx context.Context, attr string, scores []centralityScore) error {
+	query := `
+FOR s IN @scores
+  UPDATE s.key WITH {[@attr]: s.score} IN @@vertices
+  RETURN NEW._id`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"scores":    scores,
+		"attr":      attr,
+	}
+	ids, err := d.queryIDs(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	return d.afterWrite(ctx, changeUpsert, changeVertex, ids...)
+}
+
+// runPregel runs the given Pregel algorithm on the graph, storing the results
+// in resultField, and waits for it to finish.
+func (d *DAG) runPregel(ctx context.Context, algorithm, resultField string, params map[string]interface{}) error {
+	if params == nil {
+		params = make(map[string]interface{})
+	}
+	params["resultField"] = resultField
+	params["store"] = true
+
+	conn := d.client.Connection()
+	base := path.Join("_db", d.db.Name(), "_api/control_pregel")
+	req, err := conn.NewRequest("POST", base)
+	if err != nil {
+		return arangoError(err)
+	}
+	body := map[string]interface{}{
+		"algorithm":         algorithm,
+		"vertexCollections": []string{d.vertices.Name()},
+		"edgeCollections":   []string{d.edges.Name()},
+		"params":            params,
+	}
+	if _, err := req.SetBody(body); err != nil {
+		return arangoError(err)
+	}
+	resp, err := conn.Do(ctx, req)
+	if err != nil {
+		return arangoError(err)
+	}
+	if err := resp.CheckStatus(200); err != nil {
+		return arangoError(err)
+	}
+	var id interface{}
+	if err := resp.ParseBody("", &id); err != nil {
+		return arangoError(err)
+	}
+
+	for {
+		req, err := conn.NewRequest("GET", path.Join(base, fmt.Sprint(id)))
+		if err != nil {
+			return arangoError(err)
+		}
+		resp, err := conn.Do(ctx, req)
+		if err != nil {
+			return arangoError(err)
+		}
+		if err := resp.CheckStatus(200); err != nil {
+			return arangoError(err)
+		}
+		var status struct {
+			State string `json:"state"`
+		}
+		if err := resp.ParseBody("", &status); err != nil {
+			return arangoError(err)
+		}
+		switch status.State {
+		case "done":
+			return nil
+		case "canceled", "fatal error", "in error":
+			return arangoError(fmt.Errorf("pregel job %v failed (%s)", id, status.State))
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(pregelPollInterval):
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package arangodag
+
+import (
+	"bytes"
+	"context"
+	"math"
+	"math/rand"
+	"testing"
+)
+
+func TestDAG_ComputeCentrality(t *testing.T) {
+	d := someNewDag(t, WithChangeLog())
+
+	// 1 -> 2 -> 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	version, _ := d.IncrementalBackup(&bytes.Buffer{}, "")
+	for _, kind := range []CentralityKind{CentralityInCloseness, CentralityOutCloseness, CentralityBetweenness, CentralityPageRank} {
+		if err := d.ComputeCentrality(kind); err != nil {
+			t.Fatalf("failed to ComputeCentrality(%s): %v", kind, err)
+		}
+	}
+
+	want := map[string]map[string]float64{
+		"1": {"inCloseness": 0, "outCloseness": 2.0 / 3.0, "betweenness": 0},
+		"2": {"inCloseness": 1, "outCloseness": 1, "betweenness": 1},
+		"3": {"inCloseness": 2.0 / 3.0, "outCloseness": 0, "betweenness": 0},
+	}
+	for id, scores := range want {
+		var doc map[string]interface{}
+		if _, err := d.vertices.ReadDocument(context.Background(), id, &doc); err != nil {
+			t.Fatalf("failed to read vertex: %v", err)
+		}
+		for attr, score := range scores {
+			got, _ := doc[attr].(float64)
+			if math.Abs(got-score) > 1e-9 {
+				t.Errorf("%s of '%s' = %v, want %v", attr, id, got, score)
+			}
+		}
+		if _, ok := doc["pageRank"]; !ok {
+			t.Errorf("pageRank of '%s' is missing", id)
+		}
+	}
+
+	// the scores are recorded in the change log
+	var changes bytes.Buffer
+	if _, err := d.IncrementalBackup(&changes, version); err != nil {
+		t.Fatalf("failed to IncrementalBackup(): %v", err)
+	}
+	if lines := bytes.Count(changes.Bytes(), []byte("\n")); lines != 9 {
+		t.Errorf("IncrementalBackup() wrote %d changes, want %d", lines, 9)
+	}
+
+	// unknown
+	if err := d.ComputeCentrality("foo"); !IsInvalidParameterError(err) {
+		t.Errorf("ComputeCentrality() = %v, want invalid parameter error", err)
+	}
+}
+
+func TestBetweenness(t *testing.T) {
+
+	// 1 -> 2 -> 4, 1 -> 3 -> 4, 4 -> 5
+	keys := []string{"1", "2", "3", "4", "5"}
+	edges := [][2]string{{"1", "2"}, {"1", "3"}, {"2", "4"}, {"3", "4"}, {"4", "5"}}
+	want := map[string]float64{"1": 0, "2": 1, "3": 1, "4": 3, "5": 0}
+	for _, score := range betweenness(keys, edges, len(keys), rand.New(rand.NewSource(1))) {
+		if math.Abs(score.Score-want[score.Key]) > 1e-9 {
+			t.Errorf("betweenness of '%s' = %v, want %v", score.Key, score.Score, want[score.Key])
+		}
+	}
+}
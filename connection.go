@@ -0,0 +1,173 @@
+package arangodag
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/arangodb/go-driver"
+	arangohttp "github.com/arangodb/go-driver/http"
+)
+
+// Connection defaults (see ConnectionOptions).
+const (
+	defaultConnTimeout = 30 * time.Second
+	defaultConnRetries = 3
+	defaultConnBackoff = 100 * time.Millisecond
+)
+
+// ConnectionOptions configures the connection built by NewDAGFromEndpoints.
+// Zero values select the defaults.
+type ConnectionOptions struct {
+
+	// TLSConfig is the TLS configuration for "https://" endpoints, e.g. with
+	// client certificates (see TLSConfigFromFiles).
+	TLSConfig *tls.Config
+
+	// Transport, if not nil, replaces the transport built from TLSConfig and
+	// Timeout (e.g. to route requests via a custom proxy). Retries still
+	// apply.
+	Transport http.RoundTripper
+
+	// Timeout limits dialing and waiting for response headers (default 30s).
+	Timeout time.Duration
+
+	// Retries is the number of retries of requests failing to connect
+	// (default 3, negative values disable retries). Requests that were sent
+	// are never retried, as they may have been applied already.
+	Retries int
+
+	// ConnLimit is the maximal number of connections per endpoint (see the
+	// driver's default).
+	ConnLimit int
+}
+
+// NewDAGFromEndpoints creates / initializes a new DAG (see NewDAG) connecting
+// to ArangoDB via the given endpoints (e.g. "http://localhost:8529") using the
+// given authentication (which may be nil, see e.g. driver.BasicAuthentication,
+// driver.JWTAuthentication and SuperUserAuthentication). The connection keeps connections
+// alive, retries requests failing to connect with a linear backoff and uses
+// the TLS configuration given by connOpts (which may be nil).
+func NewDAGFromEndpoints(endpoints []string, auth driver.Authentication, dbName, vertexCollName, edgeCollName string, connOpts *ConnectionOptions, opts ...Option) (*DAG, error) {
+	if connOpts == nil {
+		connOpts = &ConnectionOptions{}
+	}
+	timeout := connOpts.Timeout
+	if timeout <= 0 {
+		timeout = defaultConnTimeout
+	}
+	retries := connOpts.Retries
+	if retries == 0 {
+		retries = defaultConnRetries
+	}
+	var transport http.RoundTripper = &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   timeout,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		TLSClientConfig:       connOpts.TLSConfig,
+		MaxIdleConns:          100,
+		MaxIdleConnsPerHost:   arangohttp.DefaultMaxIdleConnsPerHost,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: timeout,
+		ExpectContinueTimeout: 1 * time.Second,
+	}
+	if connOpts.Transport != nil {
+		transport = connOpts.Transport
+	}
+	conn, err := arangohttp.NewConnection(arangohttp.ConnectionConfig{
+		Endpoints: endpoints,
+		TLSConfig: connOpts.TLSConfig,
+		Transport: &retryTransport{base: transport, retries: retries, backoff: defaultConnBackoff},
+		ConnLimit: connOpts.ConnLimit,
+	})
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	client, err := driver.NewClient(driver.ClientConfig{
+		Connection:     conn,
+		Authentication: auth,
+	})
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	return NewDAG(dbName, vertexCollName, edgeCollName, client, opts...)
+}
+
+// SuperUserAuthentication returns the authentication with the given (JWT)
+// superuser token, e.g. as generated from the server's JWT secret.
+func SuperUserAuthentication(token string) driver.Authentication {
+	return driver.RawAuthentication("bearer " + token)
+}
+
+// TLSConfigFromFiles returns a TLS configuration authenticating with the
+// client certificate and key in the given PEM files and trusting the CA
+// certificates in the given PEM file. Empty file names are skipped (i.e.
+// without a client certificate, or trusting the system's CAs).
+func TLSConfigFromFiles(certFile, keyFile, caFile string) (*tls.Config, error) {
+	config := &tls.Config{}
+	if certFile != "" || keyFile != "" {
+		cert, err := tls.LoadX509KeyPair(certFile, keyFile)
+		if err != nil {
+			return nil, err
+		}
+		config.Certificates = []tls.Certificate{cert}
+	}
+	if caFile != "" {
+		pem, err := ioutil.ReadFile(caFile)
+		if err != nil {
+			return nil, err
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, errors.New("no CA certificates found in " + caFile)
+		}
+		config.RootCAs = pool
+	}
+	return config, nil
+}
+
+// retryTransport retries requests failing to connect (i.e. requests not sent
+// at all) up to retries times.
+type retryTransport struct {
+	base    http.RoundTripper
+	retries int
+	backoff time.Duration
+}
+
+// RoundTrip implements http.RoundTripper.
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	for attempt := 0; ; attempt++ {
+		resp, err := t.base.RoundTrip(req)
+		if err == nil || attempt >= t.retries || !isDialError(err) {
+			return resp, err
+		}
+		if req.Body != nil {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req.Body = body
+		}
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(time.Duration(attempt+1) * t.backoff):
+		}
+	}
+}
+
+// isDialError returns true, if the given error occurred while connecting.
+func isDialError(err error) bool {
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
@@ -0,0 +1,89 @@
+package arangodag
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"testing"
+)
+
+type roundTripFunc func(req *http.Request) (*http.Response, error)
+
+func (f roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) {
+	return f(req)
+}
+
+func TestRetryTransport(t *testing.T) {
+	attempts := 0
+	dialErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	rt := &retryTransport{
+		base: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+			attempts++
+			if attempts < 3 {
+				return nil, dialErr
+			}
+			return &http.Response{StatusCode: http.StatusOK}, nil
+		}),
+		retries: 3,
+	}
+	req, _ := http.NewRequest(http.MethodGet, "http://localhost", nil)
+	if resp, err := rt.RoundTrip(req); err != nil || resp.StatusCode != http.StatusOK {
+		t.Errorf("RoundTrip() = %v, %v, want 200", resp, err)
+	}
+	if attempts != 3 {
+		t.Errorf("attempts = %d, want 3", attempts)
+	}
+
+	// errors other than dial errors aren't retried
+	attempts = 0
+	rt.base = roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		attempts++
+		return nil, &net.OpError{Op: "read", Err: errors.New("connection reset")}
+	})
+	if _, err := rt.RoundTrip(req); err == nil {
+		t.Error("want error, got nil")
+	}
+	if attempts != 1 {
+		t.Errorf("attempts = %d, want 1", attempts)
+	}
+}
+
+func TestNewDAGFromEndpoints(t *testing.T) {
+	host := os.Getenv("ARANGODB_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("ARANGODB_PORT")
+	if port == "" {
+		port = "8529"
+	}
+	endpoints := []string{fmt.Sprintf("http://%s:%s", host, port)}
+	if _, err := NewDAGFromEndpoints(endpoints, nil, someName(), someName(), someName(), nil); err != nil {
+		t.Fatalf("failed to NewDAGFromEndpoints(): %v", err)
+	}
+}
+
+func TestSuperUserAuthentication(t *testing.T) {
+	auth := SuperUserAuthentication("token")
+	if value := auth.Get("value"); value != "bearer token" {
+		t.Errorf("Get() = %s, want bearer token", value)
+	}
+}
+
+func TestTLSConfigFromFiles(t *testing.T) {
+	config, err := TLSConfigFromFiles("", "", "")
+	if err != nil {
+		t.Fatalf("failed to TLSConfigFromFiles(): %v", err)
+	}
+	if len(config.Certificates) != 0 || config.RootCAs != nil {
+		t.Errorf("TLSConfigFromFiles() = %v, want empty config", config)
+	}
+	if _, err := TLSConfigFromFiles("", "", "does-not-exist.pem"); err == nil {
+		t.Error("want error, got nil")
+	}
+	if _, err := TLSConfigFromFiles("does-not-exist.pem", "does-not-exist.key", ""); err == nil {
+		t.Error("want error, got nil")
+	}
+}
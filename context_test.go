@@ -0,0 +1,53 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDAG_context(t *testing.T) {
+	d := &DAG{}
+	WithContextDecorators(DefaultTenant("t0"))(d)
+
+	ctx, cancel := context.WithCancel(WithTenant(context.Background(), "t1"))
+	if tenant, _ := TenantFromContext(d.context(ctx)); tenant != "t1" {
+		t.Errorf("TenantFromContext() = %s, want t1", tenant)
+	}
+	cancel()
+	if d.context(ctx).Err() == nil {
+		t.Errorf("context() not cancelled")
+	}
+}
+
+func TestDetachedContext(t *testing.T) {
+	ctx, cancel := context.WithTimeout(WithTenant(context.Background(), "t1"), time.Millisecond)
+	cancel()
+	detached := detachedContext{ctx}
+	if detached.Err() != nil || detached.Done() != nil {
+		t.Errorf("detachedContext is cancelled")
+	}
+	if _, ok := detached.Deadline(); ok {
+		t.Errorf("detachedContext has a deadline")
+	}
+	if tenant, _ := TenantFromContext(detached); tenant != "t1" {
+		t.Errorf("TenantFromContext() = %s, want t1", tenant)
+	}
+}
+
+func TestDAG_CtxCancelled(t *testing.T) {
+	d := someNewDag(t)
+	id, _ := d.AddVertex(someName())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := d.AddVertexCtx(ctx, someName()); err == nil {
+		t.Errorf("AddVertexCtx() with cancelled context succeeded")
+	}
+	if err := d.GetVertexCtx(ctx, id, nil); err == nil {
+		t.Errorf("GetVertexCtx() with cancelled context succeeded")
+	}
+	if err := d.GetVertex(id, nil); err != nil {
+		t.Errorf("failed to GetVertex(): %v", err)
+	}
+}
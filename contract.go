@@ -0,0 +1,163 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"sort"
+)
+
+// SuperVertex is the payload of the vertices created by Contract for groups of
+// vertices.
+type SuperVertex struct {
+
+	// Members are the ids of the vertices of the group (in the detailed DAG).
+	Members []string `json:"members"`
+}
+
+// Contract materializes the condensed DAG where each of the given groups of
+// vertices (mapping group names to member ids) is collapsed into a single
+// vertex (a super-node). The condensed DAG is stored as a new DAG (in the same
+// database) using the collections with the given names. Super-nodes are
+// identified by the group name and hold the ids of their members (see
+// SuperVertex). Vertices not being member of any group are copied as is.
+// There is an edge between two vertices of the condensed DAG, if there is an
+// edge between (members of) them in d. The attribute "weight" (see
+// WeightAttribute) of these edges holds the number of edges condensed. Edges
+// within groups are dropped. The DAG d itself is left untouched. Contract
+// returns an error, if any of the members is empty or unknown, if a vertex is
+// member of multiple groups, if a group name collides with the id of a vertex
+// not being member of this group, or if contracting would create a loop.
+func (d *DAG) Contract(groups map[string][]string, vertexCollName, edgeCollName string) (*DAG, error) {
+	return d.ContractCtx(context.Background(), groups, vertexCollName, edgeCollName)
+}
+
+// ContractCtx is like Contract but uses the given context.
+func (d *DAG) ContractCtx(ctx context.Context, groups map[string][]string, vertexCollName, edgeCollName string) (*DAG, error) {
+	ctx = d.context(ctx)
+
+	// membership
+	group := make(map[string]string)
+	var members []string
+	names := make([]string, 0, len(groups))
+	for name, ids := range groups {
+		if name == "" {
+			return nil, EmptyIDError()
+		}
+		names = append(names, name)
+		for _, id := range ids {
+			if _, ok := group[id]; ok {
+				return nil, DuplicateIDError(id)
+			}
+			group[id] = name
+			members = append(members, id)
+		}
+	}
+	sort.Strings(names)
+	if _, err := d.vertexDocumentIDs(ctx, members); err != nil {
+		return nil, err
+	}
+	missing, err := d.missingVertices(ctx, names)
+	if err != nil {
+		return nil, err
+	}
+	isMissing := make(map[string]struct{}, len(missing))
+	for _, name := range missing {
+		isMissing[name] = struct{}{}
+	}
+	for _, name := range names {
+		if _, ok := isMissing[name]; !ok && group[name] != name {
+			return nil, DuplicateIDError(name)
+		}
+	}
+
+	var vertices []json.RawMessage
+	for _, name := range names {
+		ids := append([]string(nil), groups[name]...)
+		sort.Strings(ids)
+		doc, err := json.Marshal(arangoDocKeyContainer{Key: name, Payload: SuperVertex{Members: ids}})
+		if err != nil {
+			return nil, err
+		}
+		vertices = append(vertices, doc)
+	}
+	var target *DAG
+	err = d.readTransaction(ctx, func(rctx context.Context) error {
+
+		// condensed edges
+		query := `
+FOR e IN @@edges
+  LET from = PARSE_IDENTIFIER(e._from).key
+  LET to = PARSE_IDENTIFIER(e._to).key
+  LET src = HAS(@group, from) ? @group[from] : from
+  LET dst = HAS(@group, to) ? @group[to] : to
+  FILTER src != dst
+  COLLECT s = src, t = dst WITH COUNT INTO count
+  RETURN {src: s, dst: t, count: count}`
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"group":  group,
+		}
+		var edges [][2]string
+		var edgeDocs []json.RawMessage
+		err := d.forEachDocument(rctx, query, bindVars)(func(doc json.RawMessage) error {
+			var item struct {
+				Src   string `json:"src"`
+				Dst   string `json:"dst"`
+				Count int    `json:"count"`
+			}
+			if err := json.Unmarshal(doc, &item); err != nil {
+				return err
+			}
+			edges = append(edges, [2]string{item.Src, item.Dst})
+			edgeDoc, err := json.Marshal(map[string]interface{}{
+				"_from":         vertexCollName + "/" + item.Src,
+				"_to":           vertexCollName + "/" + item.Dst,
+				WeightAttribute: item.Count,
+			})
+			if err != nil {
+				return err
+			}
+			edgeDocs = append(edgeDocs, edgeDoc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if cyclic(edges) {
+			return NewError(ErrLoop, "contracting the groups would create a loop")
+		}
+
+		// vertices not being member of any group
+		query = `
+FOR v IN @@vertices
+  FILTER !HAS(@group, v._key)
+  RETURN UNSET(v, "_id", "_rev", @attr)`
+		bindVars = map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"group":     group,
+			"attr":      MaterializedPathAttribute,
+		}
+		err = d.forEachDocument(rctx, query, bindVars)(func(doc json.RawMessage) error {
+			vertices = append(vertices, doc)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		target, err = NewDAGWithContext(ctx, d.db.Name(), vertexCollName, edgeCollName, d.client)
+		if err != nil {
+			return err
+		}
+		return target.transaction(target.context(ctx), func(tctx context.Context) error {
+			if err := copyDocuments(tctx, target.vertices, sliceIterator(vertices), target.afterCopy(tctx, changeVertex)); err != nil {
+				return err
+			}
+			return copyDocuments(tctx, target.edges, sliceIterator(edgeDocs), target.afterCopy(tctx, changeEdge))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
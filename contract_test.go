@@ -0,0 +1,63 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_Contract(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 1 -> 3, 2 -> 4, 3 -> 4, 4 -> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("4", "5")
+
+	c, err := d.Contract(map[string][]string{"mid": {"2", "3"}}, someName(), someName())
+	if err != nil {
+		t.Fatalf("failed to Contract(): %v", err)
+	}
+	if order, _ := c.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+	if size, _ := c.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want %d", size, 3)
+	}
+	if weight, _ := c.GetShortestPathWeight("1", "mid", WeightAttribute, 0); weight != 2 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, 2)
+	}
+	var super SuperVertex
+	if err := c.GetVertex("mid", &super); err != nil {
+		t.Fatalf("failed to GetVertex(): %v", err)
+	}
+	if diff := deep.Equal(super.Members, []string{"2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// the detailed DAG is left untouched
+	if order, _ := d.GetOrder(); order != 5 {
+		t.Errorf("GetOrder() = %d, want %d", order, 5)
+	}
+
+	// loops
+	if _, err := d.Contract(map[string][]string{"g": {"1", "5"}}, someName(), someName()); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+
+	// multiple groups
+	groups := map[string][]string{"g1": {"1", "2"}, "g2": {"2"}}
+	if _, err := d.Contract(groups, someName(), someName()); !IsDuplicateIDError(err) {
+		t.Errorf("want DuplicateIDError, got %v", err)
+	}
+
+	// unknown
+	if _, err := d.Contract(map[string][]string{"g": {"foo"}}, someName(), someName()); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
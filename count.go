@@ -0,0 +1,80 @@
+package arangodag
+
+import (
+	"sync"
+	"time"
+)
+
+// WithCountCache enables caching the results of GetOrder and GetSize. The
+// cached counts are maintained on adding vertices and adding or deleting
+// edges (via this DAG), invalidated by all other mutations, and refreshed
+// lazily after maxAge (to account for mutations by other clients).
+func WithCountCache(maxAge time.Duration) Option {
+	return func(d *DAG) {
+		d.counts = &countCache{maxAge: maxAge}
+	}
+}
+
+// indexes of the cached counts
+const (
+	countOrder = iota
+	countSize
+)
+
+// countCache caches the number of vertices and edges. All methods may be
+// called on a nil cache (i.e. with caching disabled).
+type countCache struct {
+	mu     sync.Mutex
+	maxAge time.Duration
+	counts [2]cachedCount
+}
+
+type cachedCount struct {
+	valid     bool
+	refreshed time.Time
+	value     int64
+}
+
+// get returns the cached count with the given index, refreshing it via fetch,
+// if it is invalid or expired.
+func (c *countCache) get(i int, fetch func() (int64, error)) (uint64, error) {
+	if c == nil {
+		count, err := fetch()
+		if err != nil {
+			return 0, err
+		}
+		return uint64(count), nil
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	cc := &c.counts[i]
+	if !cc.valid || time.Since(cc.refreshed) > c.maxAge {
+		count, err := fetch()
+		if err != nil {
+			return 0, err
+		}
+		*cc = cachedCount{valid: true, refreshed: time.Now(), value: count}
+	}
+	return uint64(cc.value), nil
+}
+
+// add adds the given differences to the (valid) cached counts.
+func (c *countCache) add(order, size int64) {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts[countOrder].value += order
+	c.counts[countSize].value += size
+}
+
+// invalidate invalidates the cached counts.
+func (c *countCache) invalidate() {
+	if c == nil {
+		return
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.counts = [2]cachedCount{}
+}
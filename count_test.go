@@ -0,0 +1,43 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestWithCountCache(t *testing.T) {
+	d := someNewDag(t, WithCountCache(time.Hour))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+
+	// maintained on local mutations
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want %d", order, 2)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+	_ = d.DeleteEdge("1", "2")
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+
+	// mutations by others are not seen (until maxAge)
+	_, _ = d.vertices.CreateDocument(context.Background(), arangoDocKeyContainer{Key: "3"})
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want %d", order, 2)
+	}
+
+	// other mutations invalidate
+	if _, err := d.MarkAndSweep([]string{"1"}); err != nil {
+		t.Fatalf("failed to MarkAndSweep(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+}
@@ -2,7 +2,16 @@ package arangodag
 
 import (
 	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
 	"github.com/arangodb/go-driver"
+	"io"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
 )
 
 // IDInterface describes the interface a type must implement in order to
@@ -16,323 +25,1115 @@ type IDInterface interface {
 
 // DAG implements the data structure of the DAG.
 type DAG struct {
+	db       driver.Database
 	vertices driver.Collection
 	edges    driver.Collection
+	changes  driver.Collection
 	client   driver.Client
+
+	timestampAttribute string
+	changeLog          bool
+	refCounting        bool
+	edgeType           reflect.Type
+	counts             *countCache
+	beforeAddEdge      func(srcID, dstID string) error
+	beforeDeleteVertex func(id string) error
+	typeAttribute      string
+	allowedEdges       map[string]map[string]struct{}
+	progress           func(p Progress)
+	keyEncoding        bool
+	createdAttribute   string
+	updatedAttribute   string
+	locks              driver.Collection
+	locksMu            *sync.Mutex
+	queries            driver.Collection
+	materializedPaths  bool
+	shardPath          string
+	numberOfShards     int
+	inverseEdges       bool
+	inverse            driver.Collection
+	decorators         []ContextDecorator
+	externalIDPath     string
+	reachFilters       bool
+	queriesMu          *sync.Mutex
+	quota              Quota
+	opts               []Option
+}
+
+// Option configures a DAG (as of creating it via NewDAG).
+type Option func(*DAG)
+
+// WithTimestampAttribute sets the (dot separated) path of the vertex attribute
+// holding the vertex timestamp (e.g. as used by PruneOlderThan). The path is
+// relative to the stored document, thus, the default "payload.timestamp"
+// refers to the attribute "timestamp" of the vertex itself.
+func WithTimestampAttribute(path string) Option {
+	return func(d *DAG) {
+		d.timestampAttribute = path
+	}
+}
+
+// WithChangeLog enables recording all mutations in a change log collection
+// (named after the vertex collection with the suffix "_changes"), as needed
+// by IncrementalBackup. With the change log enabled, each mutation and its
+// log entry are written within a single transaction. The transaction locks the
+// change log exclusively, such that the (increasing) keys of the log entries
+// follow the order the transactions commit in (at the price of serializing
+// mutations).
+func WithChangeLog() Option {
+	return func(d *DAG) {
+		d.changeLog = true
+	}
 }
 
 // NewDAG creates / initializes a new DAG.
-func NewDAG(dbName, vertexCollName, edgeCollName string, client driver.Client) (*DAG, error) {
+func NewDAG(dbName, vertexCollName, edgeCollName string, client driver.Client, opts ...Option) (*DAG, error) {
+	return NewDAGWithContext(context.Background(), dbName, vertexCollName, edgeCollName, client, opts...)
+}
+
+// NewDAGWithContext creates / initializes a new DAG (see NewDAG) using the
+// given context for the requests needed to do so (e.g. to apply a timeout).
+// Later operations don't use ctx, but the context passed to them (see e.g.
+// AddVertexCtx).
+func NewDAGWithContext(ctx context.Context, dbName, vertexCollName, edgeCollName string, client driver.Client, opts ...Option) (*DAG, error) {
+	d := &DAG{
+		client:             client,
+		timestampAttribute: "payload.timestamp",
+		locksMu:            &sync.Mutex{},
+		queriesMu:          &sync.Mutex{},
+		opts:               opts,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	ctx = d.context(ctx)
 
 	// use or create database
 	var db driver.Database
-	exists, err := client.DatabaseExists(context.Background(), dbName)
+	exists, err := client.DatabaseExists(ctx, dbName)
 	if err != nil {
-		return nil, err
+		return nil, arangoError(err)
 	}
 	if exists {
-		db, err = client.Database(context.Background(), dbName)
+		db, err = client.Database(ctx, dbName)
 	} else {
-		db, err = client.CreateDatabase(context.Background(), dbName, nil)
+		db, err = client.CreateDatabase(ctx, dbName, nil)
 	}
 	if err != nil {
-		return nil, err
+		return nil, arangoError(err)
 	}
+	d.db = db
 
 	// use or create vertex collection
-	var vertices driver.Collection
-	exists, err = db.CollectionExists(context.Background(), vertexCollName)
+	vertexOptions, edgeOptions := d.collectionOptions(vertexCollName)
+	d.vertices, err = useOrCreateCollection(ctx, db, vertexCollName, vertexOptions)
 	if err != nil {
-		return nil, err
-	}
-	if exists {
-		vertices, err = db.Collection(context.Background(), vertexCollName)
-	} else {
-		vertices, err = db.CreateCollection(context.Background(), vertexCollName, nil)
-	}
-	if err != nil {
-		return nil, err
+		return nil, arangoError(err)
 	}
 
 	// use or create edge collection
-	var edges driver.Collection
-	exists, err = db.CollectionExists(context.Background(), edgeCollName)
+	d.edges, err = useOrCreateCollection(ctx, db, edgeCollName, edgeOptions)
 	if err != nil {
-		return nil, err
+		return nil, arangoError(err)
 	}
-	if exists {
-		edges, err = db.Collection(context.Background(), edgeCollName)
-	} else {
-		options := &driver.CreateCollectionOptions{Type: driver.CollectionTypeEdge}
-		edges, err = db.CreateCollection(context.Background(), edgeCollName, options)
+
+	// use or create inverse edge collection
+	if d.inverseEdges {
+		d.inverse, err = useOrCreateCollection(ctx, db, edgeCollName+"_inverse", edgeOptions)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+	}
+
+	if d.materializedPaths {
+		if err := d.ensureMaterializedPathIndex(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if d.externalIDPath != "" {
+		if err := d.ensureExternalIDIndex(ctx); err != nil {
+			return nil, err
+		}
+	}
+
+	// use or create change log collection
+	if d.changeLog {
+		options := &driver.CreateCollectionOptions{
+			KeyOptions: &driver.CollectionKeyOptions{Type: "padded"},
+		}
+		d.changes, err = useOrCreateCollection(ctx, db, vertexCollName+"_changes", options)
+		if err != nil {
+			return nil, arangoError(err)
+		}
 	}
+
+	return d, nil
+}
+
+// derive returns the DAG using the collections with the given names (see
+// NewDAG) in the database of d, configured by the options d was created with.
+func (d *DAG) derive(ctx context.Context, vertexCollName, edgeCollName string) (*DAG, error) {
+	return NewDAGWithContext(ctx, d.db.Name(), vertexCollName, edgeCollName, d.client, d.opts...)
+}
+
+// Database returns the database of the DAG.
+func (d *DAG) Database() driver.Database {
+	return d.db
+}
+
+// VertexCollection returns the collection holding the vertices. Vertex
+// documents hold the vertex itself in the attribute "payload". Writing to the
+// collection directly bypasses the change log (see WithChangeLog) and the ACL
+// handling (see ACLInterface). Removing vertices directly leaves dangling
+// edges.
+func (d *DAG) VertexCollection() driver.Collection {
+	return d.vertices
+}
+
+// EdgeCollection returns the collection holding the edges. Edges must only
+// connect vertices of the vertex collection and must not create loops (i.e.
+// prefer AddEdge to writing to the collection directly). Writing to the
+// collection directly bypasses the change log (see WithChangeLog).
+func (d *DAG) EdgeCollection() driver.Collection {
+	return d.edges
+}
+
+// edgeCollectionOptions returns the options for creating edge collections.
+func edgeCollectionOptions() *driver.CreateCollectionOptions {
+	return &driver.CreateCollectionOptions{Type: driver.CollectionTypeEdge}
+}
+
+// useOrCreateCollection returns the collection with the given name, creating
+// it (using the given options), if it doesn't exist.
+func useOrCreateCollection(ctx context.Context, db driver.Database, name string, options *driver.CreateCollectionOptions) (driver.Collection, error) {
+	exists, err := db.CollectionExists(ctx, name)
 	if err != nil {
 		return nil, err
 	}
-
-	return &DAG{vertices: vertices, edges: edges, client: client}, nil
+	if exists {
+		return db.Collection(ctx, name)
+	}
+	return db.CreateCollection(ctx, name, options)
 }
 
 type arangoDocContainer struct {
 	Payload interface{} `json:"payload"`
+	ACL     []string    `json:"acl,omitempty"`
+	Shard   string      `json:"shard,omitempty"`
 }
 type arangoDocKeyContainer struct {
 	Key     string      `json:"_key"`
 	Payload interface{} `json:"payload"`
+	ACL     []string    `json:"acl,omitempty"`
+	Shard   string      `json:"shard,omitempty"`
+}
+type myEdge struct {
+	From  driver.DocumentID `json:"_from"`
+	To    driver.DocumentID `json:"_to"`
+	Shard string            `json:"shard,omitempty"`
 }
 
 // AddVertex adds the given vertex to the DAG and returns its id. AddVertex
 // returns an error, if the vertex is nil. If the vertex implements the
 // IDInterface, the key will be taken from the vertex (itself). In this case,
 // AddVertex returns an error, if the extracted id is empty or already exists.
+// If the vertex implements the ACLInterface, the vertex will only be visible
+// to the principals listed (see WithPrincipal).
 func (d *DAG) AddVertex(vertex interface{}) (string, error) {
+	return d.AddVertexCtx(context.Background(), vertex)
+}
+
+// AddVertexCtx is like AddVertex but uses the given context.
+func (d *DAG) AddVertexCtx(ctx context.Context, vertex interface{}) (string, error) {
+	meta, err := d.addVertex(ctx, vertex)
+	if err != nil {
+		return "", err
+	}
+	return d.id(meta.Key), nil
+}
+
+// addVertex adds the given vertex (see AddVertex) and returns its meta data.
+func (d *DAG) addVertex(ctx context.Context, vertex interface{}) (driver.DocumentMeta, error) {
+	doc, id, err := d.vertexDocument(vertex)
+	if err != nil {
+		return driver.DocumentMeta{}, err
+	}
+
+	var meta driver.DocumentMeta
+	err = d.mutateCounted(d.context(ctx), 1, 0, func(ctx context.Context) error {
+		if err := d.checkVertexQuota(ctx, 1); err != nil {
+			return err
+		}
+		var err error
+		meta, err = d.vertices.CreateDocument(ctx, doc)
+		if err != nil {
+			return d.vertexError(err, id)
+		}
+		return d.afterWrite(ctx, changeUpsert, changeVertex, meta.ID)
+	})
+	if err != nil {
+		return driver.DocumentMeta{}, err
+	}
+	return meta, nil
+}
+
+// vertexDocument returns the document to store for the given vertex together
+// with its id (or an empty id, if the vertex doesn't implement IDInterface).
+func (d *DAG) vertexDocument(vertex interface{}) (interface{}, string, error) {
 
 	// sanity checking
 	if vertex == nil {
-		return "", VertexNilError()
+		return nil, "", VertexNilError()
+	}
+
+	var acl []string
+	if a, ok := vertex.(ACLInterface); ok {
+		acl = a.ACL()
+	}
+
+	var shard string
+	if d.shardPath != "" {
+		var err error
+		if shard, err = d.vertexShard(vertex); err != nil {
+			return nil, "", err
+		}
 	}
 
-	var doc interface{}
-	var id string
 	if i, ok := vertex.(IDInterface); ok {
-		id = i.ID()
-		doc = &arangoDocKeyContainer{Payload: vertex, Key: id}
-	} else {
-		doc = &arangoDocContainer{Payload: vertex}
-		id = ""
+		id := i.ID()
+		return &arangoDocKeyContainer{Payload: vertex, Key: d.key(id), ACL: acl, Shard: shard}, id, nil
 	}
+	return &arangoDocContainer{Payload: vertex, ACL: acl, Shard: shard}, "", nil
+}
 
-	ctx := driver.WithQueryCount(context.Background())
-	meta, err := d.vertices.CreateDocument(ctx, doc)
-	if err != nil {
-		if driver.IsArangoErrorWithErrorNum(err, 1210) {
-			return "", DuplicateIDError(id)
-		}
-		if driver.IsArangoError(err) {
-			return "", Error{
-				IsDAGError:   true,
-				ErrorNum:     ErrArango,
-				ErrorMessage: "",
-				Err:          err,
-			}
+// vertexError maps the given error of creating the vertex with the given id
+// to a DAG error.
+func (d *DAG) vertexError(err error, id string) error {
+	if driver.IsArangoErrorWithErrorNum(err, 1210) {
+		if d.externalIDPath != "" && !isPrimaryIndexViolation(err) {
+			return DuplicateExternalIDError()
 		}
-		return "", err
+		return DuplicateIDError(id)
 	}
-	return meta.Key, nil
+	if driver.IsArangoErrorWithErrorNum(err, 1221) {
+		return InvalidIDError(id)
+	}
+	return arangoError(err)
 }
 
 // GetVertex returns the vertex with the given id. GetVertex returns an error, if
 // id is empty or unknown.
 func (d *DAG) GetVertex(id string, vertex interface{}) error {
+	return d.GetVertexCtx(context.Background(), id, vertex)
+}
+
+// GetVertexCtx is like GetVertex but uses the given context.
+func (d *DAG) GetVertexCtx(ctx context.Context, id string, vertex interface{}) error {
+	_, err := d.getVertex(ctx, id, vertex)
+	return err
+}
+
+// getVertex reads the vertex with the given id (see GetVertex) and returns its
+// meta data.
+func (d *DAG) getVertex(ctx context.Context, id string, vertex interface{}) (driver.DocumentMeta, error) {
 	if id == "" {
-		return EmptyIDError()
+		return driver.DocumentMeta{}, EmptyIDError()
 	}
 
-	ctx := context.Background()
+	ctx = d.context(ctx)
 	doc := arangoDocContainer{Payload: vertex}
-	_, err := d.vertices.ReadDocument(ctx, id, &doc)
+	meta, err := d.vertices.ReadDocument(ctx, d.key(id), &doc)
 	if err != nil {
 		if driver.IsArangoErrorWithErrorNum(err, 1202) {
-			return NewUnknownKeyError(id)
-		}
-		if driver.IsArangoError(err) {
-			return Error{
-				IsDAGError:   true,
-				ErrorNum:     ErrArango,
-				ErrorMessage: "",
-				Err:          err,
-			}
+			return driver.DocumentMeta{}, NewUnknownKeyError(id)
 		}
-		return err
+		return driver.DocumentMeta{}, arangoError(err)
 	}
-	//vertex = doc.Payload
-	return nil
+	if !visible(ctx, doc.ACL) {
+		return driver.DocumentMeta{}, NewUnknownKeyError(id)
+	}
+	return meta, nil
 }
 
-// GetOrder returns the number of vertices in the graph.
-func (d *DAG) GetOrder() (uint64, error) {
-	count, err := d.vertices.Count(context.Background())
+// HaveVertices returns for each of the given ids, whether it refers to a
+// vertex. All ids are checked by a single query. HaveVertices returns an error,
+// if any of the ids is empty.
+func (d *DAG) HaveVertices(ids []string) (map[string]bool, error) {
+	return d.HaveVerticesCtx(context.Background(), ids)
+}
+
+// HaveVerticesCtx is like HaveVertices but uses the given context.
+func (d *DAG) HaveVerticesCtx(ctx context.Context, ids []string) (map[string]bool, error) {
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			return nil, EmptyIDError()
+		}
+		keys[i] = d.key(id)
+	}
+	missing, err := d.missingVertices(d.context(ctx), keys)
 	if err != nil {
-		return 0, err
+		return nil, err
+	}
+	have := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		have[id] = true
 	}
-	return uint64(count), nil
+	for _, key := range missing {
+		have[d.id(key)] = false
+	}
+	return have, nil
+}
+
+// GetOrder returns the number of vertices in the graph.
+func (d *DAG) GetOrder() (uint64, error) {
+	return d.GetOrderCtx(context.Background())
+}
+
+// GetOrderCtx is like GetOrder but uses the given context.
+func (d *DAG) GetOrderCtx(ctx context.Context) (uint64, error) {
+	return d.counts.get(countOrder, func() (int64, error) {
+		count, err := d.vertices.Count(d.context(ctx))
+		return count, arangoError(err)
+	})
 
 }
 
 // GetSize returns the number of edges in the graph.
 func (d *DAG) GetSize() (uint64, error) {
-	count, err := d.edges.Count(context.Background())
-	if err != nil {
-		return 0, err
+	return d.GetSizeCtx(context.Background())
+}
+
+// GetSizeCtx is like GetSize but uses the given context.
+func (d *DAG) GetSizeCtx(ctx context.Context) (uint64, error) {
+	return d.counts.get(countSize, func() (int64, error) {
+		count, err := d.edges.Count(d.context(ctx))
+		return count, arangoError(err)
+	})
+}
+
+// AddEdge adds an edge from the vertex with the id srcID to the vertex with the
+// id dstID. AddEdge returns an error, if srcID or dstID are empty strings or
+// unknown, if the edge already exists, or if the new edge would create a loop.
+// Checking for loops and inserting the edge is atomic (i.e. serialized with
+// concurrent additions of edges, also by other processes), such that
+// concurrent calls can't create a loop together (see VerifyAcyclicity).
+func (d *DAG) AddEdge(srcID, dstID string) error {
+	return d.AddEdgeCtx(context.Background(), srcID, dstID)
+}
+
+// AddEdgeCtx is like AddEdge but uses the given context.
+func (d *DAG) AddEdgeCtx(ctx context.Context, srcID, dstID string) error {
+	return d.addEdgeBetween(ctx, srcID, dstID, nil)
+}
+
+// addEdgeBetween resolves srcID and dstID and adds an edge (holding the fields
+// of edge, if not nil) between them.
+func (d *DAG) addEdgeBetween(ctx context.Context, srcID, dstID string, edge interface{}) error {
+
+	// sanity checking
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
 	}
-	return uint64(count), nil
+	if srcID == dstID {
+		return SrcDstEqualError(srcID)
+	}
+
+	// the vertices are checked to exist within the transaction of addEdge
+	src := driver.NewDocumentID(d.vertices.Name(), d.key(srcID))
+	dst := driver.NewDocumentID(d.vertices.Name(), d.key(dstID))
+	return d.addEdge(d.context(ctx), src, dst, edge)
 }
 
-/*
-func (d *DAG) GetLeaves() (map[string]struct{}, error) {
-	// TODO: use bind variables
-	query := fmt.Sprintf("FOR d IN %s RETURN d", d.vertices.Name())
-	db := d.vertices.Database()
-	cursor, err := db.Query(nil, query, nil)
+// AddEdgeByID adds an edge from the vertex with the document id srcID to the
+// vertex with the document id dstID (e.g. as known from a prior query), thus,
+// saving the translation of ids. Both vertices are checked to exist (by a
+// single lookup) within the transaction adding the edge. AddEdgeByID returns
+// an error, if srcID or dstID are invalid, don't refer to the vertex
+// collection or are unknown, if the edge already exists, or if the new edge
+// would create a loop.
+func (d *DAG) AddEdgeByID(srcID, dstID driver.DocumentID) error {
+	return d.AddEdgeByIDCtx(context.Background(), srcID, dstID)
+}
+
+// AddEdgeByIDCtx is like AddEdgeByID but uses the given context.
+func (d *DAG) AddEdgeByIDCtx(ctx context.Context, srcID, dstID driver.DocumentID) error {
+
+	// sanity checking
+	for _, id := range []driver.DocumentID{srcID, dstID} {
+		if id.IsEmpty() || id.Key() == "" {
+			return EmptyIDError()
+		}
+		if id.Validate() != nil || id.Collection() != d.vertices.Name() {
+			return NewUnknownKeyError(string(id))
+		}
+	}
+	if srcID == dstID {
+		return SrcDstEqualError(d.id(srcID.Key()))
+	}
+	return d.addEdge(d.context(ctx), srcID, dstID, nil)
+}
+
+// addEdge adds an edge from src to dst (after checking for both vertices to
+// exist, for duplicates and for loops). If edge is not nil, the edge document
+// holds its fields too.
+func (d *DAG) addEdge(ctx context.Context, src, dst driver.DocumentID, edge interface{}) error {
+	doc, err := d.edgeDocument(src, dst, edge)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if d.beforeAddEdge != nil {
+		if err := d.beforeAddEdge(d.id(src.Key()), d.id(dst.Key())); err != nil {
+			return err
+		}
 	}
-	defer cursor.Close()
 
-	leaves := make(map[string]struct{})
-	var i map[string]interface{}
-	for {
-		meta, err := cursor.ReadDocument(nil, &i)
-		if driver.IsNoMoreDocuments(err) {
-			break
-		} else if err != nil {
-			return nil, err
+	// check and insert atomically (i.e. serialized with concurrent additions
+	// of edges, which could create a loop together with this one)
+	err = d.edgeTransaction(ctx, func(ctx context.Context) error {
+		missing, err := d.missingVertices(ctx, []string{src.Key(), dst.Key()})
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			return NewUnknownKeyError(d.id(missing[0]))
+		}
+		if err := d.checkEdgeTypes(ctx, src, dst); err != nil {
+			return err
 		}
 
-		childCount, err := d.getChildCount(meta.ID)
+		// duplicate check
+		exists, err := d.edgeExists(ctx, src, dst)
 		if err != nil {
-			return nil, err
+			return err
 		}
-		if childCount == 0 {
-			leaves[meta.Key] = struct{}{}
+		if exists {
+			return DuplicateEdgeError(d.id(src.Key()), d.id(dst.Key()))
 		}
+
+		// loop check (i.e. whether there is a path from dst to src)
+		if err := d.checkLoop(ctx, src, dst); err != nil {
+			return err
+		}
+
+		if err := d.checkEdgeQuota(ctx, src, dst); err != nil {
+			return err
+		}
+
+		if err := d.setEdgeShard(ctx, doc, src); err != nil {
+			return err
+		}
+
+		meta, err := d.edges.CreateDocument(ctx, doc)
+		if err != nil {
+			return arangoError(err)
+		}
+		return d.afterWrite(ctx, changeUpsert, changeEdge, meta.ID)
+	})
+	if err != nil {
+		return err
 	}
-	return leaves, nil
+	d.counts.add(0, 1)
+	return nil
+}
+
+// DeleteEdge deletes the edge from the vertex with the id srcID to the vertex
+// with the id dstID. If reference counting is enabled (see WithRefCounting) and
+// the edge was the last inbound edge of dstID, dstID and all of its
+// descendants that are left without parents are deleted too. DeleteEdge
+// returns an error, if srcID or dstID are empty or unknown, or if there is no
+// such edge.
+func (d *DAG) DeleteEdge(srcID, dstID string) error {
+	return d.DeleteEdgeCtx(context.Background(), srcID, dstID)
 }
 
-func (d *DAG) getChildCount(id driver.DocumentID) (uint64, error) {
-	// TODO: use bind variables
-	ctx := driver.WithQueryCount(context.Background())
-	query := fmt.Sprintf("FOR d IN %s FILTER d._from == %s RETURN d", d.edges.Name(), id)
-	db := d.edges.Database()
-	cursor, err := db.Query(ctx, query, nil)
+// DeleteEdgeCtx is like DeleteEdge but uses the given context.
+func (d *DAG) DeleteEdgeCtx(ctx context.Context, srcID, dstID string) error {
+
+	// sanity checking
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+
+	ctx = d.context(ctx)
+	src, err := d.vertexDocumentID(ctx, srcID)
 	if err != nil {
-		return 0, err
+		return err
+	}
+	dst, err := d.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return err
+	}
+
+	fn := func(ctx context.Context) error {
+		return d.deleteEdge(ctx, src, dst)
+	}
+	if d.refCounting {
+		return d.transaction(ctx, fn)
 	}
-	defer cursor.Close()
-	return uint64(cursor.Count()), nil
+	return d.mutateCounted(ctx, 0, -1, fn)
 }
 
-func (d *DAG) GetRoots() (map[string]struct{}, error) {
-	// TODO: use bind variables
-	query := fmt.Sprintf("FOR d IN %s RETURN d", d.vertices.Name())
-	db := d.vertices.Database()
-	cursor, err := db.Query(nil, query, nil)
+// deleteEdge deletes the edge from src to dst (see DeleteEdge).
+func (d *DAG) deleteEdge(ctx context.Context, src, dst driver.DocumentID) error {
+	query := `
+FOR e IN @@edges
+  FILTER e._from == @src AND e._to == @dst
+  REMOVE e IN @@edges
+  RETURN OLD._id`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    src,
+		"dst":    dst,
+	}
+	ids, err := d.queryIDs(ctx, query, bindVars)
 	if err != nil {
-		return nil, err
+		return err
+	}
+	if len(ids) == 0 {
+		return UnknownEdgeError(d.id(src.Key()), d.id(dst.Key()))
+	}
+	if err := d.afterWrite(ctx, changeRemove, changeEdge, ids...); err != nil {
+		return err
+	}
+	if d.refCounting {
+		if err := d.deleteOrphans(ctx, []driver.DocumentID{dst}); err != nil {
+			return err
+		}
 	}
-	defer cursor.Close()
+	if d.materializedPaths {
+		return d.updatePaths(ctx, dst)
+	}
+	return nil
+}
 
-	leaves := make(map[string]struct{})
-	var i map[string]interface{}
-	for {
-		meta, err := cursor.ReadDocument(nil, &i)
-		if driver.IsNoMoreDocuments(err) {
-			break
-		} else if err != nil {
-			return nil, err
+// DeleteVertex deletes the vertex with the given id and all of its inbound and
+// outbound edges within a single transaction. If reference counting is
+// enabled (see WithRefCounting), children left without parents are deleted
+// too. DeleteVertex returns an error, if id is empty or unknown, or if the
+// deletion is vetoed (see WithBeforeDeleteVertex).
+func (d *DAG) DeleteVertex(id string) error {
+	return d.DeleteVertexCtx(context.Background(), id)
+}
+
+// DeleteVertexCtx is like DeleteVertex but uses the given context.
+func (d *DAG) DeleteVertexCtx(ctx context.Context, id string) error {
+	return d.deleteVertex(ctx, id, false)
+}
+
+// DeleteLeafVertex deletes the vertex with the given id like DeleteVertex, but
+// only if it has no children (i.e. it refuses to leave former children behind,
+// which may be unexpected without reference counting). The check and the
+// deletion happen within a single transaction. DeleteLeafVertex returns an
+// error, if the vertex has children (see IsVertexHasChildrenError).
+func (d *DAG) DeleteLeafVertex(id string) error {
+	return d.DeleteLeafVertexCtx(context.Background(), id)
+}
+
+// DeleteLeafVertexCtx is like DeleteLeafVertex but uses the given context.
+func (d *DAG) DeleteLeafVertexCtx(ctx context.Context, id string) error {
+	return d.deleteVertex(ctx, id, true)
+}
+
+// deleteVertex deletes the vertex with the given id (see DeleteVertex),
+// refusing to do so, if leafOnly is true and the vertex has children.
+func (d *DAG) deleteVertex(ctx context.Context, id string, leafOnly bool) error {
+
+	// sanity checking
+	if id == "" {
+		return EmptyIDError()
+	}
+
+	ctx = d.context(ctx)
+	docID, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if d.beforeDeleteVertex != nil {
+		if err := d.beforeDeleteVertex(id); err != nil {
+			return err
 		}
+	}
 
-		childCount, err := d.getParentCount(meta.ID)
+	return d.transaction(ctx, func(ctx context.Context) error {
+		if leafOnly {
+			query := "FOR e IN @@edges FILTER e._from == @id LIMIT 1 RETURN 1"
+			bindVars := map[string]interface{}{
+				"@edges": d.edges.Name(),
+				"id":     docID,
+			}
+			hasChildren, err := d.queryHasResult(ctx, query, bindVars)
+			if err != nil {
+				return err
+			}
+			if hasChildren {
+				return VertexHasChildrenError(id)
+			}
+		}
+		_, children, err := d.removeVertex(ctx, docID)
 		if err != nil {
-			return nil, err
+			return err
+		}
+		if d.refCounting {
+			if err := d.deleteOrphans(ctx, children); err != nil {
+				return err
+			}
 		}
-		if childCount == 0 {
-			leaves[meta.Key] = struct{}{}
+		if d.materializedPaths {
+			return d.updatePaths(ctx, children...)
 		}
+		return nil
+	})
+}
+
+// removeVertex removes the vertex with the given document id and all of its
+// edges and returns the document ids of its (former) parents and children.
+func (d *DAG) removeVertex(ctx context.Context, docID driver.DocumentID) ([]driver.DocumentID, []driver.DocumentID, error) {
+
+	// edges
+	query := `
+FOR e IN @@edges
+  FILTER e._from == @id OR e._to == @id
+  REMOVE e IN @@edges
+  RETURN {id: OLD._id, parent: OLD._to == @id ? OLD._from : null, child: OLD._from == @id ? OLD._to : null}`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"id":     docID,
+	}
+	var edgeIDs, parents, children []driver.DocumentID
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID     driver.DocumentID `json:"id"`
+			Parent driver.DocumentID `json:"parent"`
+			Child  driver.DocumentID `json:"child"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		edgeIDs = append(edgeIDs, item.ID)
+		if item.Parent != "" {
+			parents = append(parents, item.Parent)
+		}
+		if item.Child != "" {
+			children = append(children, item.Child)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := d.afterWrite(ctx, changeRemove, changeEdge, edgeIDs...); err != nil {
+		return nil, nil, err
 	}
-	return leaves, nil
+
+	// vertex
+	if _, err := d.vertices.RemoveDocument(ctx, docID.Key()); err != nil {
+		return nil, nil, arangoError(err)
+	}
+	if err := d.afterWrite(ctx, changeRemove, changeVertex, docID); err != nil {
+		return nil, nil, err
+	}
+	return parents, children, nil
 }
 
-func (d *DAG) getParentCount(id driver.DocumentID) (uint64, error) {
-	// TODO: use bind variables
-	ctx := driver.WithQueryCount(context.Background())
-	query := fmt.Sprintf("FOR d IN %s FILTER d._to == %s RETURN d", d.edges.Name(), id)
-	db := d.edges.Database()
-	cursor, err := db.Query(ctx, query, nil)
+// vertexDocumentID returns the document id of the vertex with the given id
+// (i.e. key). vertexDocumentID returns an error, if the vertex is unknown (or
+// not visible to the principal carried by ctx, see WithPrincipal).
+func (d *DAG) vertexDocumentID(ctx context.Context, id string) (driver.DocumentID, error) {
+	key := d.key(id)
+	exists, err := d.vertices.DocumentExists(ctx, key)
 	if err != nil {
-		return 0, err
+		return "", arangoError(err)
+	}
+	if exists && aclFilter(ctx) != nil {
+		missing, err := d.missingVertices(ctx, []string{key})
+		if err != nil {
+			return "", err
+		}
+		exists = len(missing) == 0
+	}
+	if !exists {
+		return "", NewUnknownKeyError(id)
 	}
-	defer cursor.Close()
-	return uint64(cursor.Count()), nil
+	return driver.NewDocumentID(d.vertices.Name(), key), nil
 }
 
-func (d *DAG) GetVertices() (map[string]struct{}, error) {
-	// TODO: implement paging
-	query := fmt.Sprintf("FOR d IN %s RETURN d", d.vertices.Name())
-	db := d.vertices.Database()
-	cursor, err := db.Query(nil, query, nil)
+// vertexDocumentIDs returns the document ids of the vertices with the given
+// ids (i.e. keys) using a single query. vertexDocumentIDs returns an error, if
+// any of the ids is empty or unknown.
+func (d *DAG) vertexDocumentIDs(ctx context.Context, ids []string) ([]driver.DocumentID, error) {
+	docIDs := make([]driver.DocumentID, len(ids))
+	keys := make([]string, len(ids))
+	for i, id := range ids {
+		if id == "" {
+			return nil, EmptyIDError()
+		}
+		keys[i] = d.key(id)
+		docIDs[i] = driver.NewDocumentID(d.vertices.Name(), keys[i])
+	}
+	missing, err := d.missingVertices(ctx, keys)
 	if err != nil {
 		return nil, err
 	}
-	defer cursor.Close()
+	if len(missing) > 0 {
+		return nil, NewUnknownKeyError(d.id(missing[0]))
+	}
+	return docIDs, nil
+}
 
-	vertices := make(map[string]struct{})
-	var i map[string]interface{}
-	for {
-		meta, err := cursor.ReadDocument(nil, &i)
-		if driver.IsNoMoreDocuments(err) {
-			break
-		} else if err != nil {
-			return nil, err
+// missingVertices returns those of the given ids (i.e. keys) not referring to
+// a vertex (visible to the principal carried by ctx, see WithPrincipal).
+func (d *DAG) missingVertices(ctx context.Context, ids []string) ([]string, error) {
+	acl := aclFilter(ctx)
+	query := `
+FOR id IN @ids
+  LET v = DOCUMENT(@@vertices, id)
+  FILTER v == null OR NOT (` + allMatch(acl, "[v]") + `)
+  RETURN id`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"ids":       ids,
+	}
+	addBindVars(bindVars, acl)
+	var missing []string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var id string
+		if err := json.Unmarshal(doc, &id); err != nil {
+			return err
 		}
-		vertices[meta.Key] = struct{}{}
+		missing = append(missing, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
 	}
-	return vertices, nil
+	return missing, nil
 }
 
+// edgeExists returns true, if there is an edge from src to dst.
+func (d *DAG) edgeExists(ctx context.Context, src, dst driver.DocumentID) (bool, error) {
+	query := "FOR e IN @@edges FILTER e._from == @src AND e._to == @dst LIMIT 1 RETURN 1"
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    src,
+		"dst":    dst,
+	}
+	return d.queryHasResult(ctx, query, bindVars)
+}
 
+// checkLoop returns a CycleError, if an edge from src to dst would create a
+// loop (i.e. if there is a path from dst to src). With reachability filters
+// enabled, the traversal is skipped, if the filter of src rules out dst as
+// its ancestor.
+func (d *DAG) checkLoop(ctx context.Context, src, dst driver.DocumentID) error {
+	maybe, err := d.mayReach(ctx, dst, src)
+	if err != nil || !maybe {
+		return err
+	}
+	query := "FOR v IN OUTBOUND SHORTEST_PATH @src TO @dst @@edges RETURN v._key"
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    dst,
+		"dst":    src,
+	}
+	var path []string
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		path = append(path, d.id(key))
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	if len(path) > 0 {
+		return CycleError{Src: d.id(src.Key()), Dst: d.id(dst.Key()), Path: path}
+	}
+	return nil
+}
 
-/*
-func (d *DAG) DeleteVertex(key string) error {
-	panic("implement me")
+// exec runs the given query ignoring its results.
+func (d *DAG) exec(ctx context.Context, query string, bindVars map[string]interface{}) error {
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	closeCursor(cursor)
+	return nil
 }
 
-func (d *DAG) AddEdge(srcKey, dstKey string) error {
-	panic("implement me")
+// queryHasResult returns true, if the given query yields at least one result.
+func (d *DAG) queryHasResult(ctx context.Context, query string, bindVars map[string]interface{}) (bool, error) {
+	cursor, err := d.db.Query(driver.WithQueryCount(ctx), query, bindVars)
+	if err != nil {
+		return false, arangoError(err)
+	}
+	defer closeCursor(cursor)
+	return cursor.Count() > 0, nil
 }
 
-func (d *DAG) IsEdge(srcKey, dstKey string) (bool, error) {
-	panic("implement me")
+// upsertVertex adds a vertex with the given id and payload (see AddVertex) or
+// replaces the payload of the vertex, if it already exists. As AddVertex,
+// upsertVertex enforces the quota (see WithQuota) when adding the vertex.
+func (d *DAG) upsertVertex(ctx context.Context, id string, payload interface{}) error {
+	doc, _, err := d.vertexDocument(payload)
+	if err != nil {
+		return err
+	}
+	switch c := doc.(type) {
+	case *arangoDocContainer:
+		doc = &arangoDocKeyContainer{Key: d.key(id), Payload: c.Payload, ACL: c.ACL, Shard: c.Shard}
+	case *arangoDocKeyContainer:
+		c.Key = d.key(id)
+	}
+	query := `
+UPSERT {_key: @doc._key}
+  INSERT @doc
+  UPDATE {payload: @doc.payload}
+  IN @@vertices OPTIONS {mergeObjects: false}`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"doc":       doc,
+	}
+	return d.mutate(ctx, func(ctx context.Context) error {
+		missing, err := d.missingVertices(ctx, []string{d.key(id)})
+		if err != nil {
+			return err
+		}
+		if len(missing) > 0 {
+			if err := d.checkVertexQuota(ctx, 1); err != nil {
+				return err
+			}
+		}
+		if err := d.exec(ctx, query, bindVars); err != nil {
+			return err
+		}
+		return d.afterWrite(ctx, changeUpsert, changeVertex, driver.NewDocumentID(d.vertices.Name(), d.key(id)))
+	})
 }
 
-func (d *DAG) DeleteEdge(srcKey, dstKey string) error {
-	panic("implement me")
+// hashKey returns a (valid) vertex key derived from the given parts.
+func hashKey(parts ...string) string {
+	h := sha1.New()
+	_, _ = io.WriteString(h, strings.Join(parts, "\x00"))
+	return hex.EncodeToString(h.Sum(nil))
 }
 
-func (d *DAG) GetParents(key string) (map[string]struct{}, error) {
-	panic("implement me")
+// transaction runs fn within a stream transaction writing to the vertex and
+// the edge collection. The transaction is committed, if fn returns nil, and
+// aborted otherwise.
+func (d *DAG) transaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := d.writeTransaction(ctx, fn); err != nil {
+		return err
+	}
+	d.counts.invalidate()
+	return nil
 }
 
-func (d *DAG) GetChildren(key string) (map[string]struct{}, error) {
-	panic("implement me")
+// writeTransaction runs fn within a stream transaction writing to the vertex
+// and the edge collection (without invalidating the cached counts). With
+// reachability filters enabled, the edges are locked exclusively (see
+// edgeTransaction and WithReachabilityFilters).
+func (d *DAG) writeTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d.reachFilters {
+		return d.edgeTransaction(ctx, fn)
+	}
+	return d.runTransaction(ctx, d.transactionCollections(), fn)
 }
 
-func (d *DAG) GetAncestors(key string) (map[string]struct{}, error) {
-	panic("implement me")
+// transactionCollections returns the collections of write transactions, i.e.
+// all collections of the DAG, locking the given ones exclusively. If the
+// number of vertices is limited, the vertex collection is locked exclusively
+// too (such that checking the quota and adding vertices is atomic, see
+// WithQuota). So is the change log, such that the order of its keys is the
+// commit order (see IncrementalBackup).
+func (d *DAG) transactionCollections(exclusive ...string) driver.TransactionCollections {
+	if d.quota.MaxVertices > 0 {
+		exclusive = append(exclusive, d.vertices.Name())
+	}
+	if d.changes != nil {
+		exclusive = append(exclusive, d.changes.Name())
+	}
+	cols := driver.TransactionCollections{
+		Exclusive: exclusive,
+	}
+	for _, name := range d.collectionNames() {
+		locked := false
+		for _, e := range exclusive {
+			locked = locked || e == name
+		}
+		if !locked {
+			cols.Write = append(cols.Write, name)
+		}
+	}
+	return cols
 }
 
-func (d *DAG) GetOrderedAncestors(key string) ([]string, error) {
-	panic("implement me")
+// exclusiveTransaction runs fn like transaction, but holding an exclusive
+// lock on the edge collection (see edgeTransaction), i.e. for transactions
+// adding edges.
+func (d *DAG) exclusiveTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := d.edgeTransaction(ctx, fn); err != nil {
+		return err
+	}
+	d.counts.invalidate()
+	return nil
 }
 
-func (d *DAG) AncestorsWalker(key string) (chan string, chan bool, error) {
-	panic("implement me")
+// edgeTransaction runs fn within a stream transaction writing to the
+// collections of the DAG and holding an exclusive lock on the edge collection,
+// such that checking for loops and inserting edges is atomic. If ctx already
+// belongs to a transaction of d, fn joins this transaction, provided it holds
+// the exclusive lock too (joining any other transaction would silently lose
+// the lock, thus edgeTransaction returns an error instead).
+func (d *DAG) edgeTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) == d && ctx.Value(exclusiveEdgesKey{}) != d {
+		return NewError(ErrStorage, "can't add edges within a transaction not locking the edges exclusively")
+	}
+	return d.runTransaction(ctx, d.transactionCollections(d.edges.Name()), func(ctx context.Context) error {
+		return fn(context.WithValue(ctx, exclusiveEdgesKey{}, d))
+	})
 }
 
-func (d *DAG) GetDescendants(key string) (map[string]struct{}, error) {
-	panic("implement me")
+// exclusiveEdgesKey is the context key marking contexts of transactions
+// holding an exclusive lock on the edge collection (see edgeTransaction). The
+// value is the DAG running the transaction.
+type exclusiveEdgesKey struct{}
+
+// readTransaction runs fn within a stream transaction reading from the vertex
+// and the edge collection. All reads within fn see the same snapshot of the
+// graph (i.e. they are not affected by concurrent writes).
+func (d *DAG) readTransaction(ctx context.Context, fn func(ctx context.Context) error) error {
+	cols := driver.TransactionCollections{
+		Read: d.collectionNames(),
+	}
+	return d.runTransaction(ctx, cols, fn)
 }
 
-func (d *DAG) GetOrderedDescendants(key string) ([]string, error) {
-	panic("implement me")
+// mutate runs fn within a (write) transaction, if mutations have to be
+// recorded in the change log or stamped (see WithModificationTimestamps), and
+// directly otherwise.
+func (d *DAG) mutate(ctx context.Context, fn func(ctx context.Context) error) error {
+	if err := d.write(ctx, fn); err != nil {
+		return err
+	}
+	d.counts.invalidate()
+	return nil
+}
+
+// mutateCounted runs fn like mutate, but, rather than invalidating the cached
+// counts (see WithCountCache), adds the given differences to them.
+func (d *DAG) mutateCounted(ctx context.Context, order, size int64, fn func(ctx context.Context) error) error {
+	if err := d.write(ctx, fn); err != nil {
+		return err
+	}
+	d.counts.add(order, size)
+	return nil
+}
+
+// write runs fn within a (write) transaction, if mutations have to be
+// recorded in the change log, stamped, mirrored, have derived data (i.e.
+// materialized paths or reachability filters) to be maintained or the vertex
+// quota to be checked, and directly otherwise.
+func (d *DAG) write(ctx context.Context, fn func(ctx context.Context) error) error {
+	if d.changes == nil && d.updatedAttribute == "" && !d.materializedPaths && d.inverse == nil && !d.reachFilters && d.quota.MaxVertices == 0 {
+		return fn(ctx)
+	}
+	return d.writeTransaction(ctx, fn)
+}
+
+// collectionNames returns the names of all collections backing the DAG.
+func (d *DAG) collectionNames() []string {
+	names := []string{d.vertices.Name(), d.edges.Name()}
+	if d.changes != nil {
+		names = append(names, d.changes.Name())
+	}
+	if d.inverse != nil {
+		names = append(names, d.inverse.Name())
+	}
+	return names
+}
+
+// transactionKey is the context key marking contexts of transactions (see
+// runTransaction). The value is the DAG running the transaction.
+type transactionKey struct{}
+
+// runTransaction runs fn within a stream transaction on the given collections.
+// If ctx already belongs to a transaction of d, fn joins this transaction.
+func (d *DAG) runTransaction(ctx context.Context, cols driver.TransactionCollections, fn func(ctx context.Context) error) error {
+	if ctx.Value(transactionKey{}) == d {
+		return fn(ctx)
+	}
+	var options *driver.BeginTransactionOptions
+	if consistentRead(ctx) {
+		options = &driver.BeginTransactionOptions{WaitForSync: true}
+	}
+	tid, err := d.db.BeginTransaction(ctx, cols, options)
+	if err != nil {
+		return arangoError(err)
+	}
+	tctx := context.WithValue(driver.WithTransactionID(ctx, tid), transactionKey{}, d)
+	if err := fn(tctx); err != nil {
+		_ = d.db.AbortTransaction(detachedContext{ctx}, tid, nil)
+		return err
+	}
+	if err := d.db.CommitTransaction(ctx, tid, nil); err != nil {
+		_ = d.db.AbortTransaction(detachedContext{ctx}, tid, nil)
+		return arangoError(err)
+	}
+	return nil
 }
 
-func (d *DAG) DescendantsWalker(v string) (chan string, chan bool, error) {
-	panic("implement me")
+// detachedContext carries the values of its parent context, but is neither
+// cancelled nor has a deadline, e.g. for aborting transactions (and thus
+// releasing their locks) after ctx got cancelled.
+type detachedContext struct {
+	context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}       { return nil }
+func (detachedContext) Err() error                  { return nil }
+
+// context returns the context for operations on d called with the given
+// context, i.e. ctx decorated by the decorators of d and, if asked for (see
+// WithConsistentRead), pinned to the first endpoint and waiting for syncs.
+func (d *DAG) context(ctx context.Context) context.Context {
+	ctx = d.decorate(ctx)
+	if !consistentRead(ctx) {
+		return ctx
+	}
+	if endpoints := d.client.Connection().Endpoints(); len(endpoints) > 0 {
+		ctx = driver.WithEndpoint(ctx, endpoints[0])
+	}
+	return driver.WithWaitForSync(ctx)
+}
+
+// closeCursor closes the given cursor (ignoring errors).
+func closeCursor(cursor driver.Cursor) {
+	_ = cursor.Close()
+}
+
+// arangoError wraps errors returned by the driver (i.e. errors reported by
+// ArangoDB as well as connection errors) into DAG errors with an error number
+// equal to ErrStorage. DAG errors are returned as is.
+func arangoError(err error) error {
+	var e Error
+	if err == nil || errors.As(err, &e) && e.IsDAGError {
+		return err
+	}
+	return Error{
+		IsDAGError:   true,
+		ErrorNum:     ErrStorage,
+		ErrorMessage: "",
+		Err:          err,
+	}
 }
 
-func (d *DAG) String() string {
-	panic("implement me")
+// attributePath splits the given (dot separated) attribute path, such that it
+// may be used as bind parameter for (sub-) attribute access in AQL.
+func attributePath(path string) []string {
+	return strings.Split(path, ".")
 }
-*/
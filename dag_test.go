@@ -1,6 +1,8 @@
 package arangodag
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"github.com/arangodb/go-driver"
 	"github.com/arangodb/go-driver/http"
@@ -16,7 +18,7 @@ func someName() string {
 	return fmt.Sprintf("test_%d", time.Now().UnixNano())
 }
 
-func someNewDag(t *testing.T) *DAG {
+func someNewDag(t *testing.T, opts ...Option) *DAG {
 
 	// get arangdb host and port from environment
 	host := os.Getenv("ARANGODB_HOST")
@@ -48,7 +50,7 @@ func someNewDag(t *testing.T) *DAG {
 	vertexCollName := someName()
 	edgeCollName := someName()
 
-	d, err := NewDAG(dbName, vertexCollName, edgeCollName, client)
+	d, err := NewDAG(dbName, vertexCollName, edgeCollName, client, opts...)
 	if err != nil {
 		t.Fatalf("failed to setup new dag: %v", err)
 	}
@@ -57,6 +59,31 @@ func someNewDag(t *testing.T) *DAG {
 
 func TestNewDAG(t *testing.T) {
 	someNewDag(t)
+
+	// connection errors are storage errors
+	conn, _ := http.NewConnection(http.ConnectionConfig{
+		Endpoints: []string{"http://localhost:1"},
+	})
+	client, _ := driver.NewClient(driver.ClientConfig{
+		Connection: conn,
+	})
+	_, err := NewDAG(someName(), someName(), someName(), client)
+	if !IsStorageError(err) {
+		t.Errorf("NewDAG() = %v, want storage error", err)
+	}
+}
+
+func TestDAG_Handles(t *testing.T) {
+	d := someNewDag(t)
+	if d.Database().Name() != d.db.Name() {
+		t.Errorf("Database() = %s, want %s", d.Database().Name(), d.db.Name())
+	}
+	if d.VertexCollection().Name() != d.vertices.Name() {
+		t.Errorf("VertexCollection() = %s, want %s", d.VertexCollection().Name(), d.vertices.Name())
+	}
+	if d.EdgeCollection().Name() != d.edges.Name() {
+		t.Errorf("EdgeCollection() = %s, want %s", d.EdgeCollection().Name(), d.edges.Name())
+	}
 }
 
 type idVertex struct {
@@ -153,7 +180,7 @@ func TestDAG_GetVertex(t *testing.T) {
 
 	// unknown
 	errUnknown := d.GetVertex("foo", v)
-	if !IsUnknownIDError(errUnknown) {
+	if !IsUnknownIDError(errUnknown) || !IsVertexNotFoundError(errUnknown) || IsStorageError(errUnknown) {
 		t.Errorf("want IsUnknownIDError, got %v", errUnknown)
 	}
 
@@ -164,6 +191,26 @@ func TestDAG_GetVertex(t *testing.T) {
 	}
 }
 
+func TestDAG_HaveVertices(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+
+	have, err := d.HaveVertices([]string{"1", "2", "3"})
+	if err != nil {
+		t.Fatalf("failed to HaveVertices(): %v", err)
+	}
+	if diff := deep.Equal(have, map[string]bool{"1": true, "2": true, "3": false}); diff != nil {
+		t.Error(diff)
+	}
+
+	// empty
+	if _, err := d.HaveVertices([]string{"1", ""}); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+}
+
 func TestDAG_GetOrder(t *testing.T) {
 	d := someNewDag(t)
 	order, err := d.GetOrder()
@@ -196,23 +243,169 @@ func TestDAG_GetSize(t *testing.T) {
 		t.Errorf("GetSize() = %d, want %d", size, 0)
 	}
 
-	/*
-		for i := 1; i <= 9; i++ {
-			id1, _ := d.AddVertex(i*10)
-			id2, _ := d.AddVertex(i*10+1)
-			d.AddEdge(id1, id2)
-			size, err := d.GetSize()
-			if err != nil {
-				t.Errorf("failed to GetSize(): %v", err)
-			}
-			if size != 0 {
-				t.Errorf("GetSize() = %d, want %d", size, 0)
-			}
-			if int(size) != i {
-				t.Errorf("GetSize() = %d, want %d", size, 1)
-			}
+	for i := 1; i <= 9; i++ {
+		id1, _ := d.AddVertex(i * 10)
+		id2, _ := d.AddVertex(i*10 + 1)
+		_ = d.AddEdge(id1, id2)
+		size, err := d.GetSize()
+		if err != nil {
+			t.Errorf("failed to GetSize(): %v", err)
+		}
+		if int(size) != i {
+			t.Errorf("GetSize() = %d, want %d", size, i)
 		}
-	*/
+	}
+}
+
+func TestDAG_AddEdge(t *testing.T) {
+	d := someNewDag(t)
+
+	id1, _ := d.AddVertex(1)
+	id2, _ := d.AddVertex(2)
+	id3, _ := d.AddVertex(3)
+
+	// simple edges
+	if err := d.AddEdge(id1, id2); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge(id2, id3); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+
+	// duplicate
+	errDuplicate := d.AddEdge(id1, id2)
+	if !IsDuplicateEdgeError(errDuplicate) {
+		t.Errorf("want DuplicateEdgeError, got %v", errDuplicate)
+	}
+
+	// loop
+	errLoop := d.AddEdge(id3, id1)
+	if !IsLoopError(errLoop) {
+		t.Errorf("want LoopError, got %v", errLoop)
+	}
+
+	// src == dst
+	errSrcDst := d.AddEdge(id1, id1)
+	if !IsSrcDstEqualError(errSrcDst) {
+		t.Errorf("want SrcDstEqualError, got %v", errSrcDst)
+	}
+
+	// unknown
+	errUnknown := d.AddEdge(id1, "foo")
+	if !IsUnknownIDError(errUnknown) {
+		t.Errorf("want UnknownIDError, got %v", errUnknown)
+	}
+
+	// empty
+	errEmpty := d.AddEdge("", id1)
+	if !IsEmptyIDError(errEmpty) {
+		t.Errorf("want EmptyIDError, got %v", errEmpty)
+	}
+}
+
+func TestDAG_AddEdgeByID(t *testing.T) {
+	d := someNewDag(t)
+	id1, _ := d.AddVertex(idVertex{MyID: "1"})
+	id2, _ := d.AddVertex(idVertex{MyID: "2"})
+	src := driver.NewDocumentID(d.vertices.Name(), id1)
+	dst := driver.NewDocumentID(d.vertices.Name(), id2)
+
+	if err := d.AddEdgeByID(src, dst); err != nil {
+		t.Fatalf("failed to AddEdgeByID(): %v", err)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+
+	// duplicate
+	if err := d.AddEdgeByID(src, dst); !IsDuplicateEdgeError(err) {
+		t.Errorf("want DuplicateEdgeError, got %v", err)
+	}
+
+	// loop
+	if err := d.AddEdgeByID(dst, src); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+
+	// foreign collection
+	foreign := driver.NewDocumentID("foo", id2)
+	if err := d.AddEdgeByID(src, foreign); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+
+	// unknown
+	unknown := driver.NewDocumentID(d.vertices.Name(), "foo")
+	if err := d.AddEdgeByID(src, unknown); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want 1", size)
+	}
+
+	// empty
+	if err := d.AddEdgeByID("", dst); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+}
+
+func TestDAG_DeleteVertex(t *testing.T) {
+	d := someNewDag(t)
+
+	id1, _ := d.AddVertex(1)
+	id2, _ := d.AddVertex(2)
+	id3, _ := d.AddVertex(3)
+	_ = d.AddEdge(id1, id2)
+	_ = d.AddEdge(id2, id3)
+
+	// deleting a vertex deletes its edges too
+	if err := d.DeleteVertex(id2); err != nil {
+		t.Fatalf("failed to DeleteVertex(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want 2", order)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want 0", size)
+	}
+
+	// unknown
+	errUnknown := d.DeleteVertex("foo")
+	if !IsUnknownIDError(errUnknown) {
+		t.Errorf("want UnknownIDError, got %v", errUnknown)
+	}
+
+	// empty
+	errEmpty := d.DeleteVertex("")
+	if !IsEmptyIDError(errEmpty) {
+		t.Errorf("want EmptyIDError, got %v", errEmpty)
+	}
+}
+
+func TestDAG_DeleteLeafVertex(t *testing.T) {
+	d := someNewDag(t)
+
+	id1, _ := d.AddVertex(1)
+	id2, _ := d.AddVertex(2)
+	_ = d.AddEdge(id1, id2)
+
+	// vertices with children are kept
+	if err := d.DeleteLeafVertex(id1); !IsVertexHasChildrenError(err) {
+		t.Errorf("want VertexHasChildrenError, got %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want 2", order)
+	}
+
+	// leaves are deleted with their inbound edges
+	if err := d.DeleteLeafVertex(id2); err != nil {
+		t.Fatalf("failed to DeleteLeafVertex(): %v", err)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want 0", size)
+	}
+	if err := d.DeleteLeafVertex(id1); err != nil {
+		t.Errorf("failed to DeleteLeafVertex(): %v", err)
+	}
 }
 
 /*
@@ -805,3 +998,74 @@ func InterfaceTests(t *testing.T, newFn func() dag.DAG, tests []func(dag.DAG, *t
 }
 
 */
+
+func TestWithConsistentRead(t *testing.T) {
+	d := someNewDag(t)
+	ctx := WithConsistentRead(context.Background())
+	_, _ = d.AddVertexCtx(ctx, idVertex{MyID: "1"})
+	_, _ = d.AddVertexCtx(ctx, idVertex{MyID: "2"})
+	if err := d.AddEdgeCtx(ctx, "1", "2"); err != nil {
+		t.Fatalf("failed to AddEdgeCtx(): %v", err)
+	}
+	if length, _ := d.GetShortestPathLengthCtx(ctx, "1", "2"); length != 1 {
+		t.Errorf("GetShortestPathLengthCtx() = %d, want %d", length, 1)
+	}
+
+	// also as decorator
+	d = someNewDag(t, WithContextDecorators(WithConsistentRead))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+}
+
+func TestDAG_AddEdge_CycleError(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	err := d.AddEdge("3", "1")
+	if !IsLoopError(err) {
+		t.Fatalf("want LoopError, got %v", err)
+	}
+	var cycle CycleError
+	if !errors.As(err, &cycle) {
+		t.Fatalf("want CycleError, got %T", err)
+	}
+	if diff := deep.Equal(cycle, CycleError{Src: "3", Dst: "1", Path: []string{"1", "2", "3"}}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDAG_edgeTransaction_joined(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+
+	// joining a transaction not locking the edges exclusively is refused
+	err := d.transaction(context.Background(), func(ctx context.Context) error {
+		return d.AddEdgeCtx(ctx, "2", "3")
+	})
+	if !IsStorageError(err) {
+		t.Errorf("want StorageError, got %v", err)
+	}
+
+	// a loop added within an exclusive transaction aborts it as a whole
+	err = d.exclusiveTransaction(context.Background(), func(ctx context.Context) error {
+		_, err := d.AddEdgesCtx(ctx, []EdgeSpec{{Src: "2", Dst: "3"}, {Src: "2", Dst: "1"}})
+		return err
+	})
+	if !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+}
@@ -0,0 +1,129 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Attributes holding the deadlines computed by ComputeDeadlines.
+const (
+	LatestStartAttribute  = "latestStart"
+	LatestFinishAttribute = "latestFinish"
+)
+
+// ComputeDeadlines computes, for the vertex with the id finalID and all of its
+// ancestors, the latest time they have to start (and finish) such that the
+// final vertex finishes by finishBy, given the expected durations of the
+// vertices (vertices without duration take no time). Deadlines are
+// back-propagated, i.e. a vertex has to finish before the latest start of each
+// of its children leading to the final vertex. The deadlines are stored (as
+// ISO 8601 strings) in the document attributes LatestStartAttribute and
+// LatestFinishAttribute (e.g. for alerting on vertices at risk) and the latest
+// starts are returned. ComputeDeadlines returns an error, if finalID is empty
+// or unknown.
+func (d *DAG) ComputeDeadlines(finalID string, finishBy time.Time, durations map[string]time.Duration) (map[string]time.Time, error) {
+	return d.ComputeDeadlinesCtx(context.Background(), finalID, finishBy, durations)
+}
+
+// ComputeDeadlinesCtx is like ComputeDeadlines but uses the given context.
+func (d *DAG) ComputeDeadlinesCtx(ctx context.Context, finalID string, finishBy time.Time, durations map[string]time.Duration) (map[string]time.Time, error) {
+	if finalID == "" {
+		return nil, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	final, err := d.vertexDocumentID(ctx, finalID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the edges among the final vertex and its ancestors
+	query := `
+LET ancestors = (
+  FOR v IN 1..@maxDepth INBOUND @final @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    RETURN v._id
+)
+LET relevant = APPEND(ancestors, [@final])
+FOR a IN ancestors
+  FOR c IN 1 OUTBOUND a @@edges
+    FILTER c._id IN relevant
+    RETURN [PARSE_IDENTIFIER(a).key, c._key]`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"final":    final,
+		"maxDepth": maxDepth,
+	}
+	children := make(map[string][]string)
+	outDegree := make(map[string]int)
+	parents := make(map[string][]string)
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var edge [2]string
+		if err := json.Unmarshal(doc, &edge); err != nil {
+			return err
+		}
+		src, dst := d.id(edge[0]), d.id(edge[1])
+		children[src] = append(children[src], dst)
+		parents[dst] = append(parents[dst], src)
+		outDegree[src]++
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// back-propagate in reverse topological order (starting at the final vertex)
+	finish := map[string]time.Time{finalID: finishBy}
+	starts := make(map[string]time.Time)
+	queue := []string{finalID}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		starts[id] = finish[id].Add(-durations[id])
+		for _, parent := range parents[id] {
+			if f, ok := finish[parent]; !ok || starts[id].Before(f) {
+				finish[parent] = starts[id]
+			}
+			outDegree[parent]--
+			if outDegree[parent] == 0 {
+				queue = append(queue, parent)
+			}
+		}
+	}
+
+	// store the deadlines
+	type deadline struct {
+		Key          string `json:"key"`
+		LatestStart  string `json:"latestStart"`
+		LatestFinish string `json:"latestFinish"`
+	}
+	deadlines := make([]deadline, 0, len(starts))
+	for id, start := range starts {
+		deadlines = append(deadlines, deadline{
+			Key:          d.key(id),
+			LatestStart:  start.UTC().Format(time.RFC3339Nano),
+			LatestFinish: finish[id].UTC().Format(time.RFC3339Nano),
+		})
+	}
+	query = `
+FOR dl IN @deadlines
+  UPDATE dl.key WITH {[@latestStart]: dl.latestStart, [@latestFinish]: dl.latestFinish} IN @@vertices
+  RETURN NEW._id`
+	bindVars = map[string]interface{}{
+		"@vertices":    d.vertices.Name(),
+		"deadlines":    deadlines,
+		"latestStart":  LatestStartAttribute,
+		"latestFinish": LatestFinishAttribute,
+	}
+	err = d.mutate(ctx, func(ctx context.Context) error {
+		ids, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		return d.afterWrite(ctx, changeUpsert, changeVertex, ids...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return starts, nil
+}
@@ -0,0 +1,65 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+)
+
+func TestDAG_ComputeDeadlines(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 4, 3 -> 4, 1 -> 3, 4 -> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("4", "5")
+
+	finishBy := time.Date(2020, 1, 1, 12, 0, 0, 0, time.UTC)
+	durations := map[string]time.Duration{
+		"1": time.Hour,
+		"2": 3 * time.Hour,
+		"3": time.Hour,
+		"4": time.Hour,
+	}
+	starts, err := d.ComputeDeadlines("4", finishBy, durations)
+	if err != nil {
+		t.Fatalf("failed to ComputeDeadlines(): %v", err)
+	}
+	want := map[string]time.Time{
+		"4": finishBy.Add(-1 * time.Hour),
+		"2": finishBy.Add(-4 * time.Hour),
+		"3": finishBy.Add(-2 * time.Hour),
+		"1": finishBy.Add(-5 * time.Hour),
+	}
+	if len(starts) != len(want) {
+		t.Errorf("ComputeDeadlines() = %v, want %v", starts, want)
+	}
+	for id, start := range want {
+		if !starts[id].Equal(start) {
+			t.Errorf("latest start of %s = %v, want %v", id, starts[id], start)
+		}
+	}
+
+	// stored
+	query := "RETURN DOCUMENT(@@vertices, \"1\")[@attr]"
+	bindVars := map[string]interface{}{"@vertices": d.vertices.Name(), "attr": LatestStartAttribute}
+	var stored string
+	err = d.forEachDocument(context.Background(), query, bindVars)(func(doc json.RawMessage) error {
+		stored = string(doc)
+		return nil
+	})
+	if err != nil || stored != `"2020-01-01T07:00:00Z"` {
+		t.Errorf("stored %s (%v), want \"2020-01-01T07:00:00Z\"", stored, err)
+	}
+
+	// unknown
+	if _, err := d.ComputeDeadlines("foo", finishBy, nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
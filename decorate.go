@@ -0,0 +1,100 @@
+package arangodag
+
+import (
+	"context"
+)
+
+// ContextDecorator decorates the contexts of operations on a DAG, e.g. to
+// inject the tenant, the actor (see WithActor) or tracing baggage.
+type ContextDecorator func(ctx context.Context) context.Context
+
+// WithContextDecorators adds decorators applied (in the given order) to the
+// context of every operation on the DAG, such that cross-cutting metadata
+// doesn't have to be threaded through every call. Decorators are applied to
+// the contexts passed to methods taking contexts as well, thus, decorators
+// should not override values present already (see DefaultActor).
+func WithContextDecorators(decorators ...ContextDecorator) Option {
+	return func(d *DAG) {
+		d.decorators = append(d.decorators, decorators...)
+	}
+}
+
+// decorate applies the decorators of d to the given context.
+func (d *DAG) decorate(ctx context.Context) context.Context {
+	for _, decorator := range d.decorators {
+		ctx = decorator(ctx)
+	}
+	return ctx
+}
+
+type actorKey struct{}
+
+// WithActor returns a copy of the given context carrying the actor (e.g. the
+// user or service) performing DAG operations for auditing purposes. The actor
+// is recorded with each entry of the change log (see WithChangeLog).
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor carried by the given context. The second
+// return value is false, if there is no actor.
+func ActorFromContext(ctx context.Context) (string, bool) {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	return actor, ok
+}
+
+type tenantKey struct{}
+
+// WithTenant returns a copy of the given context carrying the tenant on whose
+// behalf DAG operations are executed (e.g. for hooks or decorators).
+func WithTenant(ctx context.Context, tenant string) context.Context {
+	return context.WithValue(ctx, tenantKey{}, tenant)
+}
+
+// TenantFromContext returns the tenant carried by the given context. The
+// second return value is false, if there is no tenant.
+func TenantFromContext(ctx context.Context) (string, bool) {
+	tenant, ok := ctx.Value(tenantKey{}).(string)
+	return tenant, ok
+}
+
+type consistentReadKey struct{}
+
+// WithConsistentRead returns a copy of the given context making DAG operations
+// see all prior writes done with such contexts (i.e. by this client), also in
+// cluster setups. To do so, the requests are sent to the same coordinator
+// (the first endpoint of the client's connection) and writes wait for being
+// synced. To apply it to all operations on a DAG, pass it as decorator (see
+// WithContextDecorators).
+func WithConsistentRead(ctx context.Context) context.Context {
+	return context.WithValue(ctx, consistentReadKey{}, true)
+}
+
+// consistentRead returns true, if the given context asks for consistent reads
+// (see WithConsistentRead).
+func consistentRead(ctx context.Context) bool {
+	consistent, _ := ctx.Value(consistentReadKey{}).(bool)
+	return consistent
+}
+
+// DefaultActor returns a decorator setting the given actor, unless the context
+// carries an actor already.
+func DefaultActor(actor string) ContextDecorator {
+	return func(ctx context.Context) context.Context {
+		if _, ok := ActorFromContext(ctx); ok {
+			return ctx
+		}
+		return WithActor(ctx, actor)
+	}
+}
+
+// DefaultTenant returns a decorator setting the given tenant, unless the
+// context carries a tenant already.
+func DefaultTenant(tenant string) ContextDecorator {
+	return func(ctx context.Context) context.Context {
+		if _, ok := TenantFromContext(ctx); ok {
+			return ctx
+		}
+		return WithTenant(ctx, tenant)
+	}
+}
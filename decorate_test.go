@@ -0,0 +1,50 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestDAG_decorate(t *testing.T) {
+	d := &DAG{}
+	WithContextDecorators(DefaultActor("service"), DefaultTenant("acme"))(d)
+
+	ctx := d.decorate(context.Background())
+	if actor, _ := ActorFromContext(ctx); actor != "service" {
+		t.Errorf("ActorFromContext() = %s, want service", actor)
+	}
+	if tenant, _ := TenantFromContext(ctx); tenant != "acme" {
+		t.Errorf("TenantFromContext() = %s, want acme", tenant)
+	}
+
+	// values present already aren't overridden
+	ctx = d.decorate(WithActor(context.Background(), "alice"))
+	if actor, _ := ActorFromContext(ctx); actor != "alice" {
+		t.Errorf("ActorFromContext() = %s, want alice", actor)
+	}
+}
+
+func TestWithContextDecorators(t *testing.T) {
+	d := someNewDag(t, WithChangeLog(), WithContextDecorators(DefaultActor("service")))
+	if _, err := d.AddVertex(idVertex{MyID: "1"}); err != nil {
+		t.Fatalf("failed to AddVertex(): %v", err)
+	}
+	query := "FOR c IN @@changes RETURN c.actor"
+	bindVars := map[string]interface{}{"@changes": d.changes.Name()}
+	var actors []string
+	err := d.forEachDocument(context.Background(), query, bindVars)(func(doc json.RawMessage) error {
+		var actor string
+		if err := json.Unmarshal(doc, &actor); err != nil {
+			return err
+		}
+		actors = append(actors, actor)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read change log: %v", err)
+	}
+	if len(actors) != 1 || actors[0] != "service" {
+		t.Errorf("actors = %v, want [service]", actors)
+	}
+}
@@ -0,0 +1,224 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// DoctorReport is the health / consistency report produced by Doctor.
+type DoctorReport struct {
+
+	// Collections maps the names of the collections used by the DAG to whether
+	// they exist.
+	Collections map[string]bool `json:"collections"`
+
+	// Indexes lists the indexes of the (existing) collections.
+	Indexes []IndexInfo `json:"indexes"`
+
+	// DanglingEdges is the number of edges referring to missing vertices.
+	DanglingEdges uint64 `json:"danglingEdges"`
+
+	// Cyclic is true, if the graph contains a cycle.
+	Cyclic bool `json:"cyclic"`
+
+	// Order is the number of vertices.
+	Order uint64 `json:"order"`
+
+	// Size is the number of edges.
+	Size uint64 `json:"size"`
+}
+
+// IndexInfo describes an index (see DoctorReport).
+type IndexInfo struct {
+	Collection string `json:"collection"`
+	Name       string `json:"name"`
+	Type       string `json:"type"`
+}
+
+// Healthy returns true, if all collections exist and the graph is consistent
+// (i.e. there are no dangling edges and no cycles).
+func (r *DoctorReport) Healthy() bool {
+	for _, exists := range r.Collections {
+		if !exists {
+			return false
+		}
+	}
+	return r.DanglingEdges == 0 && !r.Cyclic
+}
+
+// Doctor checks the collections and the consistency of the DAG and returns a
+// report thereof (e.g. to be used by readiness probes). Doctor doesn't return
+// an error for problems found, but only if checking fails. Checks requiring a
+// missing collection are skipped.
+func (d *DAG) Doctor() (*DoctorReport, error) {
+	return d.DoctorCtx(context.Background())
+}
+
+// DoctorCtx is like Doctor but uses the given context.
+func (d *DAG) DoctorCtx(ctx context.Context) (*DoctorReport, error) {
+	ctx = d.context(ctx)
+	r := &DoctorReport{
+		Collections: make(map[string]bool),
+		Indexes:     []IndexInfo{},
+	}
+
+	// collections and indexes
+	for _, name := range d.collectionNames() {
+		exists, err := d.db.CollectionExists(ctx, name)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+		r.Collections[name] = exists
+		if !exists {
+			continue
+		}
+		coll, err := d.db.Collection(ctx, name)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+		indexes, err := coll.Indexes(ctx)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+		for _, index := range indexes {
+			r.Indexes = append(r.Indexes, IndexInfo{
+				Collection: name,
+				Name:       index.Name(),
+				Type:       string(index.Type()),
+			})
+		}
+	}
+	if !r.Collections[d.vertices.Name()] || !r.Collections[d.edges.Name()] {
+		return r, nil
+	}
+
+	// sizes
+	var err error
+	if r.Order, err = d.GetOrderCtx(ctx); err != nil {
+		return nil, err
+	}
+	if r.Size, err = d.GetSizeCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	// dangling edges
+	query := `
+FOR e IN @@edges
+  FILTER DOCUMENT(e._from) == null OR DOCUMENT(e._to) == null
+  COLLECT WITH COUNT INTO count
+  RETURN count`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &r.DanglingEdges)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// cycles
+	if r.Cyclic, err = d.isCyclic(ctx); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// isCyclic returns true, if the graph contains a cycle (see cyclic).
+func (d *DAG) isCyclic(ctx context.Context) (bool, error) {
+	query := "FOR e IN @@edges RETURN [e._from, e._to]"
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	var edges [][2]string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var edge [2]string
+		if err := json.Unmarshal(doc, &edge); err != nil {
+			return err
+		}
+		edges = append(edges, edge)
+		return nil
+	})
+	if err != nil {
+		return false, err
+	}
+	return cyclic(edges), nil
+}
+
+// cyclic returns true, if the graph given by the edges contains a cycle.
+func cyclic(edges [][2]string) bool {
+	return findCycle(edges) != nil
+}
+
+// findCycle returns the vertices of a cycle (in edge direction, starting at
+// its smallest vertex) of the graph given by the edges, or nil if there is
+// none. findCycle (repeatedly) removes vertices without inbound
+// edges (i.e. Kahn's algorithm). If there are vertices left, each of them has
+// a parent left, thus, following parents eventually closes a cycle.
+func findCycle(edges [][2]string) []string {
+	children := make(map[string][]string)
+	parents := make(map[string][]string)
+	inDegree := make(map[string]int)
+	for _, edge := range edges {
+		children[edge[0]] = append(children[edge[0]], edge[1])
+		parents[edge[1]] = append(parents[edge[1]], edge[0])
+		if _, ok := inDegree[edge[0]]; !ok {
+			inDegree[edge[0]] = 0
+		}
+		inDegree[edge[1]]++
+	}
+	var queue []string
+	for id, degree := range inDegree {
+		if degree == 0 {
+			queue = append(queue, id)
+		}
+	}
+	for len(queue) > 0 {
+		id := queue[0]
+		queue = queue[1:]
+		delete(inDegree, id)
+		for _, child := range children[id] {
+			inDegree[child]--
+			if inDegree[child] == 0 {
+				queue = append(queue, child)
+			}
+		}
+	}
+	if len(inDegree) == 0 {
+		return nil
+	}
+
+	// follow parents (left) from the smallest vertex left until closing a cycle
+	var start string
+	for id := range inDegree {
+		if start == "" || id < start {
+			start = id
+		}
+	}
+	index := make(map[string]int)
+	var walk []string
+	for id := start; ; {
+		if i, ok := index[id]; ok {
+			cycle := make([]string, 0, len(walk)-i)
+			for j := len(walk) - 1; j >= i; j-- {
+				cycle = append(cycle, walk[j])
+			}
+			first := 0
+			for j, id := range cycle {
+				if id < cycle[first] {
+					first = j
+				}
+			}
+			return append(append([]string{}, cycle[first:]...), cycle[:first]...)
+		}
+		index[id] = len(walk)
+		walk = append(walk, id)
+		next := ""
+		for _, parent := range parents[id] {
+			if _, left := inDegree[parent]; left && (next == "" || parent < next) {
+				next = parent
+			}
+		}
+		id = next
+	}
+}
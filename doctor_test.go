@@ -0,0 +1,38 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+	"testing"
+)
+
+func TestDAG_Doctor(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	r, err := d.Doctor()
+	if err != nil {
+		t.Fatalf("failed to Doctor(): %v", err)
+	}
+	if !r.Healthy() {
+		t.Errorf("Healthy() = %v, want %v (%+v)", false, true, r)
+	}
+	if r.Order != 3 || r.Size != 2 {
+		t.Errorf("Doctor() = %+v, want order 3 and size 2", r)
+	}
+	if len(r.Indexes) == 0 {
+		t.Errorf("Doctor() = %+v, want indexes", r)
+	}
+
+	// bypass the DAG checks to create a cycle and a dangling edge
+	ctx := context.Background()
+	_, _ = d.edges.CreateDocument(ctx, &myEdge{From: driver.NewDocumentID(d.vertices.Name(), "3"), To: driver.NewDocumentID(d.vertices.Name(), "1")})
+	_, _ = d.edges.CreateDocument(ctx, &myEdge{From: driver.NewDocumentID(d.vertices.Name(), "3"), To: driver.NewDocumentID(d.vertices.Name(), "foo")})
+	if r, _ = d.Doctor(); r.Healthy() || !r.Cyclic || r.DanglingEdges != 1 {
+		t.Errorf("Doctor() = %+v, want cyclic graph with one dangling edge", r)
+	}
+}
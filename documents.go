@@ -0,0 +1,54 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// GetRootsDocuments decodes the payloads of all roots (i.e. vertices without
+// parents), ordered by id, into out, which must be a pointer to a slice (e.g.
+// *[]MyVertex, see GetVertex). The payloads are restricted by projection (which
+// may be nil).
+func (d *DAG) GetRootsDocuments(out interface{}, projection *PayloadProjection) error {
+	return d.GetRootsDocumentsCtx(context.Background(), out, projection)
+}
+
+// GetRootsDocumentsCtx is like GetRootsDocuments but uses the given context.
+func (d *DAG) GetRootsDocumentsCtx(ctx context.Context, out interface{}, projection *PayloadProjection) error {
+	return d.getTerminalDocuments(ctx, "INBOUND", out, projection)
+}
+
+// GetLeavesDocuments decodes the payloads of all leaves (i.e. vertices without
+// children) into out (see GetRootsDocuments).
+func (d *DAG) GetLeavesDocuments(out interface{}, projection *PayloadProjection) error {
+	return d.GetLeavesDocumentsCtx(context.Background(), out, projection)
+}
+
+// GetLeavesDocumentsCtx is like GetLeavesDocuments but uses the given context.
+func (d *DAG) GetLeavesDocumentsCtx(ctx context.Context, out interface{}, projection *PayloadProjection) error {
+	return d.getTerminalDocuments(ctx, "OUTBOUND", out, projection)
+}
+
+// getTerminalDocuments decodes the payloads of all vertices without neighbours
+// in the given direction ("OUTBOUND" or "INBOUND") into out.
+func (d *DAG) getTerminalDocuments(ctx context.Context, direction string, out interface{}, projection *PayloadProjection) error {
+	payload, bindVars := projection.expression("v.payload")
+	ctx = d.context(ctx)
+	query, vars := d.terminalQuery(ctx, direction, nil, payload)
+	for name, value := range vars {
+		bindVars[name] = value
+	}
+	payloads := []json.RawMessage{}
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		payloads = append(payloads, doc)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payloads)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
@@ -0,0 +1,36 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetRootsDocuments(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 3 -> 2, 2 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(foobarKey{MyID: id, A: "a" + id, B: "b" + id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("3", "2")
+	_ = d.AddEdge("2", "4")
+
+	var roots []foobarKey
+	if err := d.GetRootsDocuments(&roots, nil); err != nil {
+		t.Fatalf("failed to GetRootsDocuments(): %v", err)
+	}
+	want := []foobarKey{{MyID: "1", A: "a1", B: "b1"}, {MyID: "3", A: "a3", B: "b3"}}
+	if diff := deep.Equal(roots, want); diff != nil {
+		t.Error(diff)
+	}
+
+	var leaves []foobarKey
+	if err := d.GetLeavesDocuments(&leaves, &PayloadProjection{Exclude: []string{"B"}}); err != nil {
+		t.Fatalf("failed to GetLeavesDocuments(): %v", err)
+	}
+	if diff := deep.Equal(leaves, []foobarKey{{MyID: "4", A: "a4"}}); diff != nil {
+		t.Error(diff)
+	}
+}
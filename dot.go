@@ -0,0 +1,233 @@
+package arangodag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"github.com/arangodb/go-driver"
+	"io"
+	"sort"
+	"strings"
+)
+
+// DOTOptions configures ExportDOT. All fields are optional.
+type DOTOptions struct {
+
+	// Name is the name of the graph.
+	Name string
+
+	// GraphAttrs are the attributes of the graph (e.g. "rankdir": "LR").
+	GraphAttrs map[string]string
+
+	// VertexAttrs returns the attributes (e.g. "color", "shape", "label") of
+	// the vertex with the given id and (decoded) payload.
+	VertexAttrs func(id string, payload interface{}) map[string]string
+
+	// EdgeAttrs returns the attributes of the edge between the vertices with
+	// the given ids.
+	EdgeAttrs func(srcID, dstID string) map[string]string
+
+	// EdgeDataAttrs returns the attributes of the edge between the vertices
+	// with the given ids based on the (decoded) edge (see WithEdgeType).
+	// Attributes returned by EdgeDataAttrs take precedence over those
+	// returned by EdgeAttrs.
+	EdgeDataAttrs func(srcID, dstID string, edge interface{}) map[string]string
+
+	// Payload restricts the payload fields passed to VertexAttrs and Cluster.
+	Payload *PayloadProjection
+
+	// Cluster returns the name of the cluster the vertex with the given id
+	// and payload belongs to. Vertices with an empty cluster name are not
+	// clustered.
+	Cluster func(id string, payload interface{}) string
+}
+
+// ExportDOT writes the graph in the Graphviz DOT format to w. Vertices are
+// labeled with their id, unless the styling hooks of opts (which may be nil)
+// say otherwise. Vertices and edges are written sorted by their keys (i.e.
+// the output is deterministic). The export represents a consistent snapshot
+// of the graph (i.e. it is not affected by concurrent writes).
+func (d *DAG) ExportDOT(w io.Writer, opts *DOTOptions) error {
+	return d.ExportDOTCtx(context.Background(), w, opts)
+}
+
+// ExportDOTCtx is like ExportDOT but uses the given context.
+func (d *DAG) ExportDOTCtx(ctx context.Context, w io.Writer, opts *DOTOptions) error {
+	if opts == nil {
+		opts = &DOTOptions{}
+	}
+	return d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		return d.exportDOT(ctx, w, opts)
+	})
+}
+
+func (d *DAG) exportDOT(ctx context.Context, w io.Writer, opts *DOTOptions) error {
+	bw := bufio.NewWriter(w)
+
+	name := opts.Name
+	if name == "" {
+		name = d.vertices.Name()
+	}
+	_, _ = fmt.Fprintf(bw, "digraph %s {\n", dotID(name))
+	for _, k := range sortedKeys(opts.GraphAttrs) {
+		_, _ = fmt.Fprintf(bw, "  %s=%s;\n", k, dotID(opts.GraphAttrs[k]))
+	}
+
+	// vertices (grouped by cluster)
+	clusters := make(map[string][]string)
+	err := d.forEachVertex(ctx, opts.Payload, func(id string, payload interface{}) error {
+		attrs := map[string]string{"label": id}
+		if opts.VertexAttrs != nil {
+			for k, v := range opts.VertexAttrs(id, payload) {
+				attrs[k] = v
+			}
+		}
+		line := dotID(id) + dotAttrs(attrs) + ";"
+		cluster := ""
+		if opts.Cluster != nil {
+			cluster = opts.Cluster(id, payload)
+		}
+		clusters[cluster] = append(clusters[cluster], line)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	names := make([]string, 0, len(clusters))
+	for cluster := range clusters {
+		names = append(names, cluster)
+	}
+	sort.Strings(names)
+	for i, cluster := range names {
+		if cluster == "" {
+			for _, line := range clusters[cluster] {
+				_, _ = fmt.Fprintf(bw, "  %s\n", line)
+			}
+			continue
+		}
+		_, _ = fmt.Fprintf(bw, "  subgraph cluster_%d {\n    label=%s;\n", i, dotID(cluster))
+		for _, line := range clusters[cluster] {
+			_, _ = fmt.Fprintf(bw, "    %s\n", line)
+		}
+		_, _ = fmt.Fprint(bw, "  }\n")
+	}
+
+	// edges
+	err = d.forEachEdge(ctx, func(srcID, dstID string, data json.RawMessage) error {
+		attrs := make(map[string]string)
+		if opts.EdgeAttrs != nil {
+			for k, v := range opts.EdgeAttrs(srcID, dstID) {
+				attrs[k] = v
+			}
+		}
+		if opts.EdgeDataAttrs != nil {
+			edge, err := d.decodeEdge(data)
+			if err != nil {
+				return err
+			}
+			for k, v := range opts.EdgeDataAttrs(srcID, dstID, edge) {
+				attrs[k] = v
+			}
+		}
+		_, err := fmt.Fprintf(bw, "  %s -> %s%s;\n", dotID(srcID), dotID(dstID), dotAttrs(attrs))
+		return err
+	})
+	if err != nil {
+		return err
+	}
+	_, _ = fmt.Fprint(bw, "}\n")
+	return bw.Flush()
+}
+
+// forEachVertex calls fn for each vertex with its id and its (decoded)
+// payload restricted by projection (which may be nil).
+func (d *DAG) forEachVertex(ctx context.Context, projection *PayloadProjection, fn func(id string, payload interface{}) error) error {
+	payload, bindVars := projection.expression("v.payload")
+	query := "FOR v IN @@vertices SORT v._key RETURN {id: v._key, payload: " + payload + "}"
+	bindVars["@vertices"] = d.vertices.Name()
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	defer closeCursor(cursor)
+	for {
+		var item struct {
+			ID      string      `json:"id"`
+			Payload interface{} `json:"payload"`
+		}
+		_, err := cursor.ReadDocument(ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			return nil
+		} else if err != nil {
+			return arangoError(err)
+		}
+		if err := fn(d.id(item.ID), item.Payload); err != nil {
+			return err
+		}
+	}
+}
+
+// forEachEdge calls fn for each edge with the ids of its source and
+// destination vertex and its (encoded) fields.
+func (d *DAG) forEachEdge(ctx context.Context, fn func(srcID, dstID string, data json.RawMessage) error) error {
+	query := `
+FOR e IN @@edges
+  SORT e._from, e._to
+  RETURN {
+    src: PARSE_IDENTIFIER(e._from).key,
+    dst: PARSE_IDENTIFIER(e._to).key,
+    data: UNSET(e, "_id", "_key", "_rev", "_from", "_to")
+  }`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	defer closeCursor(cursor)
+	for {
+		var item struct {
+			Src  string          `json:"src"`
+			Dst  string          `json:"dst"`
+			Data json.RawMessage `json:"data"`
+		}
+		_, err := cursor.ReadDocument(ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			return nil
+		} else if err != nil {
+			return arangoError(err)
+		}
+		if err := fn(d.id(item.Src), d.id(item.Dst), item.Data); err != nil {
+			return err
+		}
+	}
+}
+
+// dotID returns s as quoted DOT id.
+func dotID(s string) string {
+	return `"` + strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`).Replace(s) + `"`
+}
+
+// dotAttrs returns the DOT attribute list for the given attributes.
+func dotAttrs(attrs map[string]string) string {
+	if len(attrs) == 0 {
+		return ""
+	}
+	var parts []string
+	for _, k := range sortedKeys(attrs) {
+		parts = append(parts, k+"="+dotID(attrs[k]))
+	}
+	return " [" + strings.Join(parts, ", ") + "]"
+}
+
+// sortedKeys returns the sorted keys of the given map.
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,82 @@
+package arangodag
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+type statusVertex struct {
+	MyID   string `json:"id"`
+	Status string `json:"status"`
+	Team   string `json:"team"`
+}
+
+func (v statusVertex) ID() string {
+	return v.MyID
+}
+
+func TestDAG_ExportDOT(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(statusVertex{MyID: "1", Status: "ok", Team: "a"})
+	_, _ = d.AddVertex(statusVertex{MyID: "2", Status: "failed", Team: "a"})
+	_, _ = d.AddVertex(statusVertex{MyID: "3", Status: "ok"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+
+	// unstyled
+	var buf bytes.Buffer
+	if err := d.ExportDOT(&buf, nil); err != nil {
+		t.Fatalf("failed to ExportDOT(): %v", err)
+	}
+	for _, want := range []string{`"1" [label="1"];`, `"1" -> "2";`, `"1" -> "3";`} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ExportDOT() = %s, want it to contain %s", buf.String(), want)
+		}
+	}
+
+	// styled
+	field := func(payload interface{}, name string) string {
+		m, _ := payload.(map[string]interface{})
+		s, _ := m[name].(string)
+		return s
+	}
+	opts := &DOTOptions{
+		Name:       "styled",
+		GraphAttrs: map[string]string{"rankdir": "LR"},
+		VertexAttrs: func(id string, payload interface{}) map[string]string {
+			if field(payload, "status") == "failed" {
+				return map[string]string{"color": "red"}
+			}
+			return nil
+		},
+		EdgeAttrs: func(srcID, dstID string) map[string]string {
+			return map[string]string{"style": "dashed"}
+		},
+		Cluster: func(id string, payload interface{}) string {
+			return field(payload, "team")
+		},
+	}
+	buf.Reset()
+	if err := d.ExportDOT(&buf, opts); err != nil {
+		t.Fatalf("failed to ExportDOT(): %v", err)
+	}
+	for _, want := range []string{
+		`digraph "styled" {`,
+		`rankdir="LR";`,
+		`"2" [color="red", label="2"];`,
+		`label="a";`,
+		`"1" -> "2" [style="dashed"];`,
+	} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("ExportDOT() = %s, want it to contain %s", buf.String(), want)
+		}
+	}
+}
+
+func Test_dotID(t *testing.T) {
+	if got, want := dotID(`a "b"`), `"a \"b\""`; got != want {
+		t.Errorf("dotID() = %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,178 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"reflect"
+)
+
+// WithEdgeType registers the (struct) type of the given prototype (or of the
+// struct it points to) as edge type. Edges added via AddEdgeWithData must be
+// of this type and edges read (via GetEdge, QueryIterator.Edge, or by
+// ExportDOT) are decoded into (pointers to) this type. The JSON fields of the type are stored next to
+// "_from" and "_to" of the edge documents (e.g. a field named "weight" is
+// used by weighted path queries).
+func WithEdgeType(prototype interface{}) Option {
+	return func(d *DAG) {
+		t := reflect.TypeOf(prototype)
+		if t != nil && t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		d.edgeType = t
+	}
+}
+
+// AddEdgeWithData adds an edge from the vertex with the id srcID to the vertex
+// with the id dstID (see AddEdge) holding the fields of edge. If an edge type
+// is registered (see WithEdgeType), AddEdgeWithData returns an error, if edge
+// is not of this type.
+func (d *DAG) AddEdgeWithData(srcID, dstID string, edge interface{}) error {
+	return d.AddEdgeWithDataCtx(context.Background(), srcID, dstID, edge)
+}
+
+// AddEdgeWithDataCtx is like AddEdgeWithData but uses the given context.
+func (d *DAG) AddEdgeWithDataCtx(ctx context.Context, srcID, dstID string, edge interface{}) error {
+	if edge == nil {
+		return VertexNilError()
+	}
+	return d.addEdgeBetween(ctx, srcID, dstID, edge)
+}
+
+// GetEdge returns the fields of the edge from the vertex with the id srcID to
+// the vertex with the id dstID. If an edge type is registered (see
+// WithEdgeType), the edge is returned as pointer to this type and as
+// map[string]interface{} otherwise. GetEdge returns an error, if srcID or
+// dstID are empty or unknown, or if there is no such edge.
+func (d *DAG) GetEdge(srcID, dstID string) (interface{}, error) {
+	return d.GetEdgeCtx(context.Background(), srcID, dstID)
+}
+
+// GetEdgeCtx is like GetEdge but uses the given context.
+func (d *DAG) GetEdgeCtx(ctx context.Context, srcID, dstID string) (interface{}, error) {
+	if srcID == "" || dstID == "" {
+		return nil, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	ids, err := d.vertexDocumentIDs(ctx, []string{srcID, dstID})
+	if err != nil {
+		return nil, err
+	}
+	query := `
+FOR e IN @@edges
+  FILTER e._from == @src AND e._to == @dst
+  LIMIT 1
+  RETURN UNSET(e, "_id", "_key", "_rev", "_from", "_to")`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    ids[0],
+		"dst":    ids[1],
+	}
+	var edge interface{}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var err error
+		edge, err = d.decodeEdge(doc)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	if edge == nil {
+		return nil, UnknownEdgeError(srcID, dstID)
+	}
+	return edge, nil
+}
+
+// GetEdgesBetween returns the edges from any of the vertices with the ids
+// srcIDs to any of the vertices with the ids dstIDs (ordered by source and
+// destination), e.g. to inspect the cut between two subsystems. The edges are
+// collected by a single query. GetEdgesBetween returns an error, if any of the
+// ids is empty or unknown.
+func (d *DAG) GetEdgesBetween(srcIDs, dstIDs []string) ([]Edge, error) {
+	return d.GetEdgesBetweenCtx(context.Background(), srcIDs, dstIDs)
+}
+
+// GetEdgesBetweenCtx is like GetEdgesBetween but uses the given context.
+func (d *DAG) GetEdgesBetweenCtx(ctx context.Context, srcIDs, dstIDs []string) ([]Edge, error) {
+	ctx = d.context(ctx)
+	srcs, err := d.vertexDocumentIDs(ctx, srcIDs)
+	if err != nil {
+		return nil, err
+	}
+	dsts, err := d.vertexDocumentIDs(ctx, dstIDs)
+	if err != nil {
+		return nil, err
+	}
+	query := `
+FOR src IN @srcs
+  FOR e IN @@edges
+    FILTER e._from == src AND e._to IN @dsts
+    SORT e._from, e._to
+    RETURN {src: PARSE_IDENTIFIER(e._from).key, dst: PARSE_IDENTIFIER(e._to).key}`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"srcs":   srcs,
+		"dsts":   dsts,
+	}
+	edges := []Edge{}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var e Edge
+		if err := json.Unmarshal(doc, &e); err != nil {
+			return err
+		}
+		edges = append(edges, Edge{Src: d.id(e.Src), Dst: d.id(e.Dst)})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return edges, nil
+}
+
+// edgeDocument returns the document of the edge from src to dst holding the
+// fields of edge (if not nil).
+func (d *DAG) edgeDocument(src, dst driver.DocumentID, edge interface{}) (interface{}, error) {
+	if edge == nil {
+		return &myEdge{From: src, To: dst}, nil
+	}
+	if d.edgeType != nil {
+		t := reflect.TypeOf(edge)
+		if t.Kind() == reflect.Ptr {
+			t = t.Elem()
+		}
+		if t != d.edgeType {
+			return nil, EdgeTypeError(t.String(), d.edgeType.String())
+		}
+	}
+	data, err := json.Marshal(edge)
+	if err != nil {
+		return nil, err
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, err
+	}
+	for _, attr := range []string{"_id", "_key", "_rev"} {
+		delete(doc, attr)
+	}
+	doc["_from"] = src
+	doc["_to"] = dst
+	return doc, nil
+}
+
+// decodeEdge decodes the given edge fields into a pointer to the registered
+// edge type (or into a map, if there is none).
+func (d *DAG) decodeEdge(data json.RawMessage) (interface{}, error) {
+	if d.edgeType == nil {
+		var edge map[string]interface{}
+		if err := json.Unmarshal(data, &edge); err != nil {
+			return nil, err
+		}
+		return edge, nil
+	}
+	edge := reflect.New(d.edgeType).Interface()
+	if err := json.Unmarshal(data, edge); err != nil {
+		return nil, err
+	}
+	return edge, nil
+}
@@ -0,0 +1,117 @@
+package arangodag
+
+import (
+	"bytes"
+	"context"
+	"github.com/go-test/deep"
+	"strings"
+	"testing"
+)
+
+type labeledEdge struct {
+	Label  string  `json:"label"`
+	Weight float64 `json:"weight"`
+}
+
+func TestDAG_AddEdgeWithData(t *testing.T) {
+	d := someNewDag(t, WithEdgeType(labeledEdge{}))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+
+	if err := d.AddEdgeWithData("1", "2", labeledEdge{Label: "uses", Weight: 2}); err != nil {
+		t.Fatalf("failed to AddEdgeWithData(): %v", err)
+	}
+	_ = d.AddEdge("2", "3")
+
+	edge, err := d.GetEdge("1", "2")
+	if err != nil {
+		t.Fatalf("failed to GetEdge(): %v", err)
+	}
+	if e, ok := edge.(*labeledEdge); !ok || e.Label != "uses" || e.Weight != 2 {
+		t.Errorf("GetEdge() = %v, want %v", edge, labeledEdge{Label: "uses", Weight: 2})
+	}
+	if edge, _ = d.GetEdge("2", "3"); *edge.(*labeledEdge) != (labeledEdge{}) {
+		t.Errorf("GetEdge() = %v, want %v", edge, labeledEdge{})
+	}
+	if weight, _ := d.GetShortestPathWeight("1", "2", WeightAttribute, 1); weight != 2 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, 2)
+	}
+
+	// exporters surface the fields
+	var buf bytes.Buffer
+	err = d.ExportDOT(&buf, &DOTOptions{
+		EdgeDataAttrs: func(srcID, dstID string, edge interface{}) map[string]string {
+			return map[string]string{"label": edge.(*labeledEdge).Label}
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to ExportDOT(): %v", err)
+	}
+	if want := `"1" -> "2" [label="uses"];`; !strings.Contains(buf.String(), want) {
+		t.Errorf("ExportDOT() = %s, want it to contain %s", buf.String(), want)
+	}
+
+	// wrong type
+	if err := d.AddEdgeWithData("1", "3", idVertex{MyID: "foo"}); !IsEdgeTypeError(err) {
+		t.Errorf("want EdgeTypeError, got %v", err)
+	}
+
+	// unknown
+	if _, err := d.GetEdge("1", "3"); !IsUnknownEdgeError(err) {
+		t.Errorf("want UnknownEdgeError, got %v", err)
+	}
+}
+
+func TestDAG_GetEdgesBetween(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "4")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "5")
+	_ = d.AddEdge("4", "5")
+
+	edges, err := d.GetEdgesBetween([]string{"1", "2", "3"}, []string{"4", "5"})
+	if err != nil {
+		t.Fatalf("failed to GetEdgesBetween(): %v", err)
+	}
+	want := []Edge{{Src: "1", Dst: "4"}, {Src: "2", Dst: "4"}, {Src: "3", Dst: "5"}}
+	if len(edges) != len(want) {
+		t.Fatalf("GetEdgesBetween() = %v, want %v", edges, want)
+	}
+	for i := range want {
+		if edges[i] != want[i] {
+			t.Errorf("GetEdgesBetween() = %v, want %v", edges, want)
+		}
+	}
+
+	if _, err := d.GetEdgesBetween([]string{"1"}, []string{"foo"}); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestQueryIterator_Edge(t *testing.T) {
+	d := someNewDag(t, WithEdgeType(labeledEdge{}))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdgeWithData("1", "2", labeledEdge{Label: "a", Weight: 2})
+
+	it, err := d.DescendantsWalker(context.Background(), "1", nil)
+	if err != nil {
+		t.Fatalf("failed to DescendantsWalker(): %v", err)
+	}
+	defer it.Close()
+	if !it.Next() {
+		t.Fatalf("Next() = false, want true (%v)", it.Err())
+	}
+	edge, err := it.Edge()
+	if err != nil {
+		t.Fatalf("failed to Edge(): %v", err)
+	}
+	if diff := deep.Equal(edge, &labeledEdge{Label: "a", Weight: 2}); diff != nil {
+		t.Error(diff)
+	}
+}
@@ -3,6 +3,7 @@ package arangodag
 import (
 	"errors"
 	"fmt"
+	"strings"
 )
 
 // Error constants
@@ -12,13 +13,32 @@ const (
 	ErrEmptyID     = 1201
 	ErrDuplicateID = 1202
 	ErrUnknownID   = 1203
+	ErrInvalidID   = 1204
+	ErrLocked      = 1205
 
-	//ErrDuplicateEdge  = 1301
-	//ErrUnknownEdge    = 1301
-	//ErrLoop           = 1302
-	//ErrSrcDstEqual    = 1303
+	ErrDuplicateExternalID = 1206
+	ErrVertexHasChildren   = 1207
+
+	ErrDuplicateEdge = 1301
+	ErrUnknownEdge   = 1302
+	ErrLoop          = 1303
+	ErrSrcDstEqual   = 1304
+	ErrEdgeType      = 1305
+	ErrEdgeForbidden = 1306
 
 	ErrArango = 1401
+
+	ErrInvalidIndex     = 1501
+	ErrInvalidParameter = 1502
+
+	ErrQuotaExceeded = 1601
+
+	// ErrVertexNotFound is an alias of ErrUnknownID.
+	ErrVertexNotFound = ErrUnknownID
+
+	// ErrStorage is an alias of ErrArango. Errors with this number wrap the
+	// error returned by the driver (e.g. connection errors).
+	ErrStorage = ErrArango
 )
 
 // Error is the type for DAG errors.
@@ -43,7 +63,7 @@ func (e Error) Error() string {
 	if e.ErrorMessage != "" {
 		return e.ErrorMessage
 	}
-	if e.ErrorNum == ErrArango {
+	if e.ErrorNum == ErrStorage {
 		return fmt.Sprintf("Arango Error: %v", e.Err)
 	}
 	return fmt.Sprintf("Error: ErrorNum %d", e.ErrorNum)
@@ -106,26 +126,94 @@ func IsEmptyIDError(err error) bool {
 	return IsErrorWithErrorNum(err, ErrEmptyID)
 }
 
+// InvalidIDError creates a new DAG error with an error number equal to
+// ErrInvalidID and an appropriate error message.
+func InvalidIDError(id string) Error {
+	return NewError(ErrInvalidID, "'%s' is not a valid key (see WithKeyEncoding)", id)
+}
+
+// IsInvalidIDError returns true, if the given error is a DAG error
+// with an error number equal to ErrInvalidID.
+func IsInvalidIDError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrInvalidID)
+}
+
+// DuplicateExternalIDError creates a new DAG error with an error number equal
+// to ErrDuplicateExternalID and an appropriate error message.
+func DuplicateExternalIDError() Error {
+	return NewError(ErrDuplicateExternalID, "the external id is already known")
+}
+
+// IsDuplicateExternalIDError returns true, if the given error is a DAG error
+// with an error number equal to ErrDuplicateExternalID.
+func IsDuplicateExternalIDError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrDuplicateExternalID)
+}
+
+// VertexHasChildrenError creates a new DAG error with an error number equal
+// to ErrVertexHasChildren and an appropriate error message.
+func VertexHasChildrenError(id string) Error {
+	return NewError(ErrVertexHasChildren, "'%s' has children", id)
+}
+
+// IsVertexHasChildrenError returns true, if the given error is a DAG error
+// with an error number equal to ErrVertexHasChildren.
+func IsVertexHasChildrenError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrVertexHasChildren)
+}
+
+// VertexLockedError creates a new DAG error with an error number equal to
+// ErrLocked and an appropriate error message.
+func VertexLockedError(id string) Error {
+	return NewError(ErrLocked, "'%s' is locked", id)
+}
+
+// IsVertexLockedError returns true, if the given error is a DAG error
+// with an error number equal to ErrLocked.
+func IsVertexLockedError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrLocked)
+}
+
+// QuotaExceededError creates a new DAG error with an error number equal to
+// ErrQuotaExceeded and an appropriate error message.
+func QuotaExceededError(kind string, limit int64) Error {
+	return NewError(ErrQuotaExceeded, "quota of %d %s exceeded", limit, kind)
+}
+
+// IsQuotaExceededError returns true, if the given error is a DAG error
+// with an error number equal to ErrQuotaExceeded.
+func IsQuotaExceededError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrQuotaExceeded)
+}
+
 // IsUnknownIDError returns true, if the given error is a DAG error
 // with an error number equal to ErrUnknownID.
 func IsUnknownIDError(err error) bool {
 	return IsErrorWithErrorNum(err, ErrUnknownID)
 }
 
+// IsVertexNotFoundError returns true, if the given error is a DAG error
+// with an error number equal to ErrVertexNotFound.
+func IsVertexNotFoundError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrVertexNotFound)
+}
+
+// IsStorageError returns true, if the given error is a DAG error with an
+// error number equal to ErrStorage (i.e. an error returned by the driver).
+func IsStorageError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrStorage)
+}
+
 // NewUnknownKeyError creates a new DAG error with an error number equal to
 // ErrUnknownID and an appropriate error message.
 func NewUnknownKeyError(key string) Error {
 	return NewError(ErrUnknownID, "'%s' is unknown", key)
 }
 
-/*
-
-
-
-// IsLoopError returns true, if the given error is a DAG error
-// with an error number equal to ErrLoop.
-func IsLoopError(err error) bool {
-	return IsErrorWithErrorNum(err, ErrLoop)
+// DuplicateEdgeError creates a new DAG error with an error number equal to
+// ErrDuplicateEdge and an appropriate error message.
+func DuplicateEdgeError(src string, dst string) Error {
+	return NewError(ErrDuplicateEdge, "edge between '%s' and '%s' is already known", src, dst)
 }
 
 // IsDuplicateEdgeError returns true, if the given error is a DAG error
@@ -134,44 +222,110 @@ func IsDuplicateEdgeError(err error) bool {
 	return IsErrorWithErrorNum(err, ErrDuplicateEdge)
 }
 
+// UnknownEdgeError creates a new DAG error with an error number equal to
+// ErrUnknownEdge and an appropriate error message.
+func UnknownEdgeError(src string, dst string) Error {
+	return NewError(ErrUnknownEdge, "edge between '%s' and '%s' is unknown", src, dst)
+}
+
 // IsUnknownEdgeError returns true, if the given error is a DAG error
 // with an error number equal to ErrUnknownEdge.
 func IsUnknownEdgeError(err error) bool {
 	return IsErrorWithErrorNum(err, ErrUnknownEdge)
 }
 
-// IsSrcDstEqualError returns true, if the given error is a DAG error
-// with an error number equal to ErrSrcDstEqual.
-func IsSrcDstEqualError(err error) bool {
-	return IsErrorWithErrorNum(err, ErrSrcDstEqual)
+// LoopError creates a new DAG error with an error number equal to
+// ErrLoop and an appropriate error message.
+func LoopError(src string, dst string) Error {
+	return NewError(ErrLoop, "edge between '%s' and '%s' would create a loop", src, dst)
 }
 
+// CycleError is the loop error (see IsLoopError) returned, if adding the edge
+// from Src to Dst would create a loop. Path holds the ids of the vertices of the
+// existing path from Dst to Src (both ends included) closing the loop.
+type CycleError struct {
+	Src  string
+	Dst  string
+	Path []string
+}
 
+// Implements the error interface.
+func (e CycleError) Error() string {
+	return fmt.Sprintf("%s (via the path %s)", LoopError(e.Src, e.Dst).Error(), strings.Join(e.Path, " -> "))
+}
 
-
-// NewDuplicateEdgeError creates a new DAG error with an error number equal to
-// ErrDuplicateEdge and an appropriate error message.
-func NewDuplicateEdgeError(src string, dst string) Error {
-	return NewError(ErrDuplicateEdge, "edge between '%s' and '%s' is already known", src, dst)
+// Is makes CycleErrors loop errors (see IsLoopError).
+func (e CycleError) Is(target error) bool {
+	return LoopError(e.Src, e.Dst).Is(target)
 }
 
-// NewUnknownEdgeError creates a new DAG error with an error number equal to
-// ErrUnknownEdge and an appropriate error message.
-func NewUnknownEdgeError(src string, dst string) Error {
-	return NewError(ErrUnknownEdge, "edge between '%s' and '%s' is unknown", src, dst)
+// Unwrap returns the underlying loop error.
+func (e CycleError) Unwrap() error {
+	return LoopError(e.Src, e.Dst)
 }
 
-// NewLoopError creates a new DAG error with an error number equal to
-// ErrLoop and an appropriate error message.
-func NewLoopError(src string, dst string) Error {
-	return NewError(ErrLoop, "edge between '%s' and '%s' would create a loop", src, dst)
+// IsLoopError returns true, if the given error is a DAG error
+// with an error number equal to ErrLoop.
+func IsLoopError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrLoop)
 }
 
-// NewSrcDstEqualError creates a new DAG error with an error number equal to
+// SrcDstEqualError creates a new DAG error with an error number equal to
 // ErrSrcDstEqual and an appropriate error message.
-func NewSrcDstEqualError(key string) Error {
-	return NewError(ErrSrcDstEqual, "source and destination are equal ('%s')", key)
+func SrcDstEqualError(id string) Error {
+	return NewError(ErrSrcDstEqual, "source and destination are equal ('%s')", id)
 }
 
+// EdgeTypeError creates a new DAG error with an error number equal to
+// ErrEdgeType and an appropriate error message.
+func EdgeTypeError(got, want string) Error {
+	return NewError(ErrEdgeType, "edge of type '%s' given, but '%s' registered", got, want)
+}
+
+// IsEdgeTypeError returns true, if the given error is a DAG error
+// with an error number equal to ErrEdgeType.
+func IsEdgeTypeError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrEdgeType)
+}
+
+// EdgeForbiddenError creates a new DAG error with an error number equal to
+// ErrEdgeForbidden and an appropriate error message.
+func EdgeForbiddenError(srcType, dstType string) Error {
+	return NewError(ErrEdgeForbidden, "edges from '%s' to '%s' are not allowed", srcType, dstType)
+}
+
+// IsEdgeForbiddenError returns true, if the given error is a DAG error
+// with an error number equal to ErrEdgeForbidden.
+func IsEdgeForbiddenError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrEdgeForbidden)
+}
+
+// IsSrcDstEqualError returns true, if the given error is a DAG error
+// with an error number equal to ErrSrcDstEqual.
+func IsSrcDstEqualError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrSrcDstEqual)
+}
+
+// InvalidIndexError creates a new DAG error with an error number equal to
+// ErrInvalidIndex and the given reason as error message.
+func InvalidIndexError(reason string) Error {
+	return NewError(ErrInvalidIndex, "invalid index: %s", reason)
+}
 
-*/
+// IsInvalidIndexError returns true, if the given error is a DAG error
+// with an error number equal to ErrInvalidIndex.
+func IsInvalidIndexError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrInvalidIndex)
+}
+
+// InvalidParameterError creates a new DAG error with an error number equal to
+// ErrInvalidParameter and the given reason as error message.
+func InvalidParameterError(name, reason string) Error {
+	return NewError(ErrInvalidParameter, "invalid parameter '%s': %s", name, reason)
+}
+
+// IsInvalidParameterError returns true, if the given error is a DAG error
+// with an error number equal to ErrInvalidParameter.
+func IsInvalidParameterError(err error) bool {
+	return IsErrorWithErrorNum(err, ErrInvalidParameter)
+}
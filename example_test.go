@@ -0,0 +1,264 @@
+package arangodag_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"github.com/arangodb/go-driver"
+	"github.com/arangodb/go-driver/http"
+	"github.com/heimdalr/arangodag"
+	"os"
+	"time"
+)
+
+// The examples of DAG need a running ArangoDB (given by ARANGODB_HOST and
+// ARANGODB_PORT, localhost:8529 by default). Thus, go test only compiles them
+// and the output shown is the one they print when run against a database. The
+// examples of MemoryStore don't need a database and are run by go test.
+
+type exampleVertex struct {
+	Key  string `json:"key"`
+	Team string `json:"team"`
+}
+
+func (v exampleVertex) ID() string {
+	return v.Key
+}
+
+// newExampleDAG returns a new DAG (within a new database) together with a
+// function dropping the database again.
+func newExampleDAG(opts ...arangodag.Option) (*arangodag.DAG, func(), error) {
+	host := os.Getenv("ARANGODB_HOST")
+	if host == "" {
+		host = "localhost"
+	}
+	port := os.Getenv("ARANGODB_PORT")
+	if port == "" {
+		port = "8529"
+	}
+	conn, err := http.NewConnection(http.ConnectionConfig{
+		Endpoints: []string{fmt.Sprintf("http://%s:%s", host, port)},
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	client, err := driver.NewClient(driver.ClientConfig{Connection: conn})
+	if err != nil {
+		return nil, nil, err
+	}
+	name := fmt.Sprintf("example_%d", time.Now().UnixNano())
+	d, err := arangodag.NewDAG(name, name+"_vertices", name+"_edges", client, opts...)
+	if err != nil {
+		return nil, nil, err
+	}
+	drop := func() {
+		ctx := context.Background()
+		if db, err := client.Database(ctx, name); err == nil {
+			_ = db.Remove(ctx)
+		}
+	}
+	return d, drop, nil
+}
+
+func ExampleNewDAG() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+
+	id, _ := d.AddVertex(exampleVertex{Key: "a"})
+	order, _ := d.GetOrder()
+	fmt.Println(id, order)
+	// a 1
+}
+
+func ExampleDAG_AddEdge() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	_, _ = d.AddVertex(exampleVertex{Key: "a"})
+	_, _ = d.AddVertex(exampleVertex{Key: "b"})
+
+	_ = d.AddEdge("a", "b")
+
+	// edges creating a loop are rejected
+	err = d.AddEdge("b", "a")
+	fmt.Println(arangodag.IsLoopError(err))
+	// true
+}
+
+func ExampleDAG_AddEdges() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	for _, key := range []string{"a", "b", "c"} {
+		_, _ = d.AddVertex(exampleVertex{Key: key})
+	}
+
+	// the edges are added within a single transaction, the edges closing the
+	// loop a -> b -> a are rejected together
+	errs, _ := d.AddEdges([]arangodag.EdgeSpec{
+		{Src: "a", Dst: "b"},
+		{Src: "b", Dst: "a"},
+		{Src: "b", Dst: "c"},
+	})
+	for _, err := range errs {
+		fmt.Println(arangodag.IsLoopError(err))
+	}
+	size, _ := d.GetSize()
+	fmt.Println(size)
+	// true
+	// true
+	// false
+	// 1
+}
+
+func ExampleDAG_WalkAncestors() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	_, _ = d.AddVertex(exampleVertex{Key: "a", Team: "x"})
+	_, _ = d.AddVertex(exampleVertex{Key: "b", Team: "y"})
+	_, _ = d.AddVertex(exampleVertex{Key: "c", Team: "x"})
+	_ = d.AddEdge("a", "b")
+	_ = d.AddEdge("b", "c")
+
+	// the payload of each ancestor is decoded into v before calling fn
+	var v exampleVertex
+	_ = d.WalkAncestors("c", &v, func(id string, err error) error {
+		if err != nil {
+			return err
+		}
+		fmt.Println(id, v.Team)
+		return nil
+	})
+	// b y
+	// a x
+}
+
+func ExampleDAG_GetShortestPathLength() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	for _, key := range []string{"a", "b", "c"} {
+		_, _ = d.AddVertex(exampleVertex{Key: key})
+	}
+	_ = d.AddEdge("a", "b")
+	_ = d.AddEdge("b", "c")
+
+	length, _ := d.GetShortestPathLength("a", "c")
+	unreachable, _ := d.GetShortestPathLength("c", "a")
+	fmt.Println(length, unreachable)
+	// 2 -1
+}
+
+func ExampleDAG_NewView() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	_, _ = d.AddVertex(exampleVertex{Key: "a", Team: "x"})
+	_, _ = d.AddVertex(exampleVertex{Key: "b", Team: "y"})
+	_, _ = d.AddVertex(exampleVertex{Key: "c", Team: "x"})
+	_ = d.AddEdge("a", "b")
+	_ = d.AddEdge("b", "c")
+
+	// the view of team x doesn't contain b, thus, c is not a descendant of a
+	v := d.NewView(&arangodag.ViewFilter{
+		Expression: "CURRENT.payload.team == @team",
+		BindVars:   map[string]interface{}{"team": "x"},
+	}, nil)
+	descendants, _ := v.GetDescendants("a")
+	order, _ := v.GetOrder()
+	fmt.Println(len(descendants), order)
+	// 0 2
+}
+
+func ExampleDAG_ExportDOT() {
+	d, drop, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	_, _ = d.AddVertex(exampleVertex{Key: "b"})
+	_, _ = d.AddVertex(exampleVertex{Key: "a"})
+	_ = d.AddEdge("a", "b")
+
+	var buf bytes.Buffer
+	_ = d.ExportDOT(&buf, &arangodag.DOTOptions{Name: "example"})
+	fmt.Print(buf.String())
+	// digraph "example" {
+	//   "a" [label="a"];
+	//   "b" [label="b"];
+	//   "a" -> "b";
+	// }
+}
+
+func ExampleDAG_IncrementalBackup() {
+	d, drop, err := newExampleDAG(arangodag.WithChangeLog())
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer drop()
+	_, _ = d.AddVertex(exampleVertex{Key: "a"})
+
+	// full snapshot
+	var full bytes.Buffer
+	version, _ := d.IncrementalBackup(&full, "")
+
+	// changes since the snapshot
+	_, _ = d.AddVertex(exampleVertex{Key: "b"})
+	var incremental bytes.Buffer
+	_, _ = d.IncrementalBackup(&incremental, version)
+
+	// restore both into a new DAG
+	restored, dropRestored, err := newExampleDAG()
+	if err != nil {
+		fmt.Println(err)
+		return
+	}
+	defer dropRestored()
+	_ = restored.Restore(&full)
+	_ = restored.Restore(&incremental)
+	order, _ := restored.GetOrder()
+	fmt.Println(order)
+	// 2
+}
+
+func ExampleMemoryStore() {
+	s := arangodag.NewMemoryStore()
+	for _, key := range []string{"a", "b", "c"} {
+		_, _ = s.AddVertex(exampleVertex{Key: key})
+	}
+	_ = s.AddEdge("a", "b")
+	_ = s.AddEdge("b", "c")
+
+	length, _ := s.GetShortestPathLength("a", "c")
+	_ = s.WalkDescendantsMulti([]string{"a"}, func(id string) error {
+		fmt.Println(id)
+		return nil
+	})
+	fmt.Println(length, arangodag.IsLoopError(s.AddEdge("c", "a")))
+	// Output:
+	// b
+	// c
+	// 2 true
+}
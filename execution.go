@@ -0,0 +1,364 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// NodeState is the state of a node of a run (see Run).
+type NodeState string
+
+// Node states.
+const (
+	NodePending   NodeState = "pending"
+	NodeRunning   NodeState = "running"
+	NodeSucceeded NodeState = "succeeded"
+	NodeFailed    NodeState = "failed"
+	NodeSkipped   NodeState = "skipped"
+)
+
+// ExecutionNode is the payload of the vertices of execution DAGs (see Run).
+type ExecutionNode struct {
+
+	// Node is the id of the vertex of the definition DAG.
+	Node string `json:"node"`
+
+	State    NodeState `json:"state"`
+	Attempts int       `json:"attempts"`
+
+	// Started and Finished are the times the (last) attempt was started and
+	// finished.
+	Started  *time.Time `json:"started,omitempty"`
+	Finished *time.Time `json:"finished,omitempty"`
+
+	// Output is the output of a succeeded node, Error the error message of a
+	// failed one.
+	Output interface{} `json:"output,omitempty"`
+	Error  string      `json:"error,omitempty"`
+}
+
+// Run is a run (i.e. an execution) of a definition DAG (e.g. of a workflow).
+// The state of the run is kept in an execution DAG mirroring the structure of
+// the definition, each vertex holding the state of the respective node (see
+// ExecutionNode). Nodes are referred to by the ids of the definition's
+// vertices.
+type Run struct {
+	def  *DAG
+	exec *DAG
+	id   string
+}
+
+// StartRun creates a new run of d with all nodes pending. The execution DAG is
+// stored in the collections named after the collections of d, suffixed by
+// "_run_" and the id of the run. The structure is copied within a single
+// transaction (reading a consistent snapshot of d).
+func (d *DAG) StartRun() (*Run, error) {
+	return d.StartRunCtx(context.Background())
+}
+
+// StartRunCtx is like StartRun but uses the given context.
+func (d *DAG) StartRunCtx(ctx context.Context) (*Run, error) {
+	r, err := d.run(ctx, randomKey())
+	if err != nil {
+		return nil, err
+	}
+	err = d.readTransaction(d.context(ctx), func(rctx context.Context) error {
+		exec := r.exec
+		return exec.transaction(exec.context(ctx), func(tctx context.Context) error {
+			query := `
+FOR v IN @@vertices
+  RETURN {_key: v._key, payload: {node: v._key, state: @state, attempts: 0}}`
+			bindVars := map[string]interface{}{
+				"@vertices": d.vertices.Name(),
+				"state":     NodePending,
+			}
+			if err := copyDocuments(tctx, exec.vertices, d.forEachDocument(rctx, query, bindVars), exec.afterCopy(tctx, changeVertex)); err != nil {
+				return err
+			}
+			return copyDocuments(tctx, exec.edges, d.forEachEdgeDocument(rctx, exec.vertices.Name(), false), exec.afterCopy(tctx, changeEdge))
+		})
+	})
+	if err != nil {
+		r.exec.drop(ctx)
+		return nil, err
+	}
+	return r, nil
+}
+
+// OpenRun returns the run of d with the given id. OpenRun returns an error, if
+// runID is empty or unknown.
+func (d *DAG) OpenRun(runID string) (*Run, error) {
+	return d.OpenRunCtx(context.Background(), runID)
+}
+
+// OpenRunCtx is like OpenRun but uses the given context.
+func (d *DAG) OpenRunCtx(ctx context.Context, runID string) (*Run, error) {
+	if runID == "" {
+		return nil, EmptyIDError()
+	}
+	exists, err := d.db.CollectionExists(d.context(ctx), d.vertices.Name()+"_run_"+runID)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	if !exists {
+		return nil, NewUnknownKeyError(runID)
+	}
+	return d.run(ctx, runID)
+}
+
+// run returns the run with the given id (creating its collections, if they
+// don't exist).
+func (d *DAG) run(ctx context.Context, runID string) (*Run, error) {
+	suffix := "_run_" + runID
+	exec, err := NewDAGWithContext(ctx, d.db.Name(), d.vertices.Name()+suffix, d.edges.Name()+suffix, d.client)
+	if err != nil {
+		return nil, err
+	}
+	return &Run{def: d, exec: exec, id: runID}, nil
+}
+
+// ID returns the id of the run.
+func (r *Run) ID() string {
+	return r.id
+}
+
+// DAG returns the execution DAG of the run (whose vertex ids are the keys of
+// the definition's vertices).
+func (r *Run) DAG() *DAG {
+	return r.exec
+}
+
+// Delete deletes the execution DAG of the run.
+func (r *Run) Delete() error {
+	return r.DeleteCtx(context.Background())
+}
+
+// DeleteCtx is like Delete but uses the given context.
+func (r *Run) DeleteCtx(ctx context.Context) error {
+	ctx = r.exec.context(ctx)
+	if err := r.exec.edges.Remove(ctx); err != nil {
+		return arangoError(err)
+	}
+	return arangoError(r.exec.vertices.Remove(ctx))
+}
+
+// GetNode returns the state of the node with the given id. GetNode returns an
+// error, if nodeID is empty or unknown.
+func (r *Run) GetNode(nodeID string) (*ExecutionNode, error) {
+	return r.GetNodeCtx(context.Background(), nodeID)
+}
+
+// GetNodeCtx is like GetNode but uses the given context.
+func (r *Run) GetNodeCtx(ctx context.Context, nodeID string) (*ExecutionNode, error) {
+	if nodeID == "" {
+		return nil, EmptyIDError()
+	}
+	var n ExecutionNode
+	if err := r.exec.GetVertexCtx(ctx, r.def.key(nodeID), &n); err != nil {
+		if IsUnknownIDError(err) {
+			return nil, NewUnknownKeyError(nodeID)
+		}
+		return nil, err
+	}
+	n.Node = r.def.id(n.Node)
+	return &n, nil
+}
+
+// Start marks the node with the given id as running (i.e. starts a new
+// attempt).
+func (r *Run) Start(nodeID string) error {
+	return r.StartCtx(context.Background(), nodeID)
+}
+
+// StartCtx is like Start but uses the given context.
+func (r *Run) StartCtx(ctx context.Context, nodeID string) error {
+	return r.update(ctx, nodeID, NodeRunning, map[string]interface{}{
+		"started":  timestamp(time.Now()),
+		"finished": nil,
+		"output":   nil,
+		"error":    nil,
+	}, 1)
+}
+
+// Succeed marks the node with the given id as succeeded with the given output
+// (which may be nil).
+func (r *Run) Succeed(nodeID string, output interface{}) error {
+	return r.SucceedCtx(context.Background(), nodeID, output)
+}
+
+// SucceedCtx is like Succeed but uses the given context.
+func (r *Run) SucceedCtx(ctx context.Context, nodeID string, output interface{}) error {
+	return r.update(ctx, nodeID, NodeSucceeded, map[string]interface{}{
+		"finished": timestamp(time.Now()),
+		"output":   output,
+	}, 0)
+}
+
+// Fail marks the node with the given id as failed with the given error.
+func (r *Run) Fail(nodeID string, err error) error {
+	return r.FailCtx(context.Background(), nodeID, err)
+}
+
+// FailCtx is like Fail but uses the given context.
+func (r *Run) FailCtx(ctx context.Context, nodeID string, err error) error {
+	return r.update(ctx, nodeID, NodeFailed, map[string]interface{}{
+		"finished": timestamp(time.Now()),
+		"error":    err.Error(),
+	}, 0)
+}
+
+// Skip marks the node with the given id as skipped.
+func (r *Run) Skip(nodeID string) error {
+	return r.SkipCtx(context.Background(), nodeID)
+}
+
+// SkipCtx is like Skip but uses the given context.
+func (r *Run) SkipCtx(ctx context.Context, nodeID string) error {
+	return r.update(ctx, nodeID, NodeSkipped, map[string]interface{}{
+		"finished": timestamp(time.Now()),
+	}, 0)
+}
+
+// update sets the state of the node with the given id (together with the
+// fields of patch, removing nil fields) and increments its attempts by inc.
+// update returns an error, if nodeID is empty or unknown.
+func (r *Run) update(ctx context.Context, nodeID string, state NodeState, patch map[string]interface{}, inc int) error {
+	if nodeID == "" {
+		return EmptyIDError()
+	}
+	exec := r.exec
+	patch["state"] = state
+	query := `
+LET v = DOCUMENT(@@vertices, @key)
+FILTER v != null
+UPDATE v WITH {
+  payload: MERGE(v.payload, @patch, {attempts: v.payload.attempts + @inc})
+} IN @@vertices OPTIONS {keepNull: false}
+RETURN NEW._id`
+	bindVars := map[string]interface{}{
+		"@vertices": exec.vertices.Name(),
+		"key":       r.def.key(nodeID),
+		"patch":     patch,
+		"inc":       inc,
+	}
+	return exec.mutate(exec.context(ctx), func(ctx context.Context) error {
+		ids, err := exec.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		if len(ids) == 0 {
+			return NewUnknownKeyError(nodeID)
+		}
+		return exec.afterWrite(ctx, changeUpsert, changeVertex, ids...)
+	})
+}
+
+// GetNodesInState returns the (sorted) ids of the nodes in the given state.
+func (r *Run) GetNodesInState(state NodeState) ([]string, error) {
+	return r.GetNodesInStateCtx(context.Background(), state)
+}
+
+// GetNodesInStateCtx is like GetNodesInState but uses the given context.
+func (r *Run) GetNodesInStateCtx(ctx context.Context, state NodeState) ([]string, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER v.payload.state == @state
+  SORT v._key
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": r.exec.vertices.Name(),
+		"state":     state,
+	}
+	return r.queryNodes(ctx, query, bindVars)
+}
+
+// GetBlocked returns the (sorted) ids of the pending nodes downstream of
+// failed nodes (i.e. the nodes that can't run, unless the failed nodes are
+// retried).
+func (r *Run) GetBlocked() ([]string, error) {
+	return r.GetBlockedCtx(context.Background())
+}
+
+// GetBlockedCtx is like GetBlocked but uses the given context.
+func (r *Run) GetBlockedCtx(ctx context.Context) ([]string, error) {
+	query := `
+FOR f IN @@vertices
+  FILTER f.payload.state == @failed
+  FOR v IN 1..@maxDepth OUTBOUND f @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER v.payload.state == @pending
+    COLLECT key = v._key
+    SORT key
+    RETURN key`
+	bindVars := map[string]interface{}{
+		"@vertices": r.exec.vertices.Name(),
+		"@edges":    r.exec.edges.Name(),
+		"failed":    NodeFailed,
+		"pending":   NodePending,
+		"maxDepth":  maxDepth,
+	}
+	return r.queryNodes(ctx, query, bindVars)
+}
+
+// queryNodes returns the node ids of the keys returned by the given query.
+func (r *Run) queryNodes(ctx context.Context, query string, bindVars map[string]interface{}) ([]string, error) {
+	ids := []string{}
+	err := r.exec.forEachDocument(r.exec.context(ctx), query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		ids = append(ids, r.def.id(key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// ResumeRun returns the (sorted) ids of the nodes of the run of d with the
+// given id that are ready to run (see Run.Ready), e.g. to resume the run after
+// a crash. ResumeRun returns an error, if runID is empty or unknown.
+func (d *DAG) ResumeRun(runID string) ([]string, error) {
+	return d.ResumeRunCtx(context.Background(), runID)
+}
+
+// ResumeRunCtx is like ResumeRun but uses the given context.
+func (d *DAG) ResumeRunCtx(ctx context.Context, runID string) ([]string, error) {
+	r, err := d.OpenRunCtx(ctx, runID)
+	if err != nil {
+		return nil, err
+	}
+	return r.ReadyCtx(ctx)
+}
+
+// Ready returns the (sorted) ids of the nodes ready to run, i.e. the nodes
+// not having succeeded or being skipped, whose parents all succeeded or were
+// skipped. Besides pending nodes, these are failed nodes (to be retried) and
+// running nodes (whose attempt may have been interrupted).
+func (r *Run) Ready() ([]string, error) {
+	return r.ReadyCtx(context.Background())
+}
+
+// ReadyCtx is like Ready but uses the given context.
+func (r *Run) ReadyCtx(ctx context.Context) ([]string, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER v.payload.state NOT IN @done
+  FILTER LENGTH(
+    FOR p IN 1 INBOUND v @@edges
+      FILTER p.payload.state NOT IN @done
+      LIMIT 1
+      RETURN 1
+  ) == 0
+  SORT v._key
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": r.exec.vertices.Name(),
+		"@edges":    r.exec.edges.Name(),
+		"done":      []NodeState{NodeSucceeded, NodeSkipped},
+	}
+	return r.queryNodes(ctx, query, bindVars)
+}
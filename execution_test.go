@@ -0,0 +1,137 @@
+package arangodag
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_StartRun(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 1 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "4")
+
+	run, err := d.StartRun()
+	if err != nil {
+		t.Fatalf("failed to StartRun(): %v", err)
+	}
+	defer func() { _ = run.Delete() }()
+	if size, _ := run.DAG().GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want 3", size)
+	}
+
+	if err := run.Start("1"); err != nil {
+		t.Fatalf("failed to Start(): %v", err)
+	}
+	if err := run.Succeed("1", map[string]interface{}{"artifact": "a.tgz"}); err != nil {
+		t.Fatalf("failed to Succeed(): %v", err)
+	}
+	_ = run.Start("2")
+	if err := run.Fail("2", errors.New("boom")); err != nil {
+		t.Fatalf("failed to Fail(): %v", err)
+	}
+
+	n, err := run.GetNode("1")
+	if err != nil {
+		t.Fatalf("failed to GetNode(): %v", err)
+	}
+	if n.Node != "1" || n.State != NodeSucceeded || n.Attempts != 1 || n.Started == nil || n.Finished == nil {
+		t.Errorf("GetNode() = %+v, want a succeeded node 1", n)
+	}
+	n, _ = run.GetNode("2")
+	if n.State != NodeFailed || n.Error != "boom" {
+		t.Errorf("GetNode() = %+v, want a failed node 2", n)
+	}
+
+	failed, err := run.GetNodesInState(NodeFailed)
+	if err != nil {
+		t.Fatalf("failed to GetNodesInState(): %v", err)
+	}
+	if diff := deep.Equal(failed, []string{"2"}); diff != nil {
+		t.Error(diff)
+	}
+	blocked, err := run.GetBlocked()
+	if err != nil {
+		t.Fatalf("failed to GetBlocked(): %v", err)
+	}
+	if diff := deep.Equal(blocked, []string{"3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// reopen
+	reopened, err := d.OpenRun(run.ID())
+	if err != nil {
+		t.Fatalf("failed to OpenRun(): %v", err)
+	}
+	if n, _ := reopened.GetNode("4"); n == nil || n.State != NodePending {
+		t.Errorf("GetNode() = %+v, want a pending node 4", n)
+	}
+
+	// unknown
+	if _, err := d.OpenRun("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+	if err := run.Start("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_ResumeRun(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 1 -> 4, 4 -> 5, 6
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "4")
+	_ = d.AddEdge("4", "5")
+
+	run, err := d.StartRun()
+	if err != nil {
+		t.Fatalf("failed to StartRun(): %v", err)
+	}
+	defer func() { _ = run.Delete() }()
+
+	ready, err := d.ResumeRun(run.ID())
+	if err != nil {
+		t.Fatalf("failed to ResumeRun(): %v", err)
+	}
+	if diff := deep.Equal(ready, []string{"1", "6"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// 1 succeeded, 2 failed, 4 interrupted, 6 skipped
+	_ = run.Succeed("1", nil)
+	_ = run.Fail("2", errors.New("boom"))
+	_ = run.Start("4")
+	_ = run.Skip("6")
+	ready, err = d.ResumeRun(run.ID())
+	if err != nil {
+		t.Fatalf("failed to ResumeRun(): %v", err)
+	}
+	if diff := deep.Equal(ready, []string{"2", "4"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// 2 and 4 succeeded
+	_ = run.Succeed("2", nil)
+	_ = run.Succeed("4", nil)
+	ready, _ = run.Ready()
+	if diff := deep.Equal(ready, []string{"3", "5"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	if _, err := d.ResumeRun("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
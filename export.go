@@ -0,0 +1,265 @@
+package arangodag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"io"
+)
+
+// cloneBatchSize is the number of documents written per request by Clone.
+const cloneBatchSize = 1000
+
+// ExportJSON writes the graph as JSON object to w. The object holds the
+// (stored) vertex documents (i.e. "_key", "payload", ...) in "vertices" and the
+// edge documents, whose "_from" and "_to" refer to vertex ids (i.e. keys), in
+// "edges". The export represents a consistent snapshot of the graph (i.e. it
+// is not affected by concurrent writes).
+func (d *DAG) ExportJSON(w io.Writer) error {
+	return d.ExportJSONCtx(context.Background(), w)
+}
+
+// ExportJSONCtx is like ExportJSON but uses the given context.
+func (d *DAG) ExportJSONCtx(ctx context.Context, w io.Writer) error {
+	return d.ExportJSONWithCtx(ctx, w, nil)
+}
+
+// JSONOptions configures ExportJSONWith. All fields are optional.
+type JSONOptions struct {
+
+	// Payload restricts the exported payload fields.
+	Payload *PayloadProjection
+}
+
+// ExportJSONWith writes the graph as JSON object to w (see ExportJSON) as
+// configured by opts (which may be nil).
+func (d *DAG) ExportJSONWith(w io.Writer, opts *JSONOptions) error {
+	return d.ExportJSONWithCtx(context.Background(), w, opts)
+}
+
+// ExportJSONWithCtx is like ExportJSONWith but uses the given context.
+func (d *DAG) ExportJSONWithCtx(ctx context.Context, w io.Writer, opts *JSONOptions) error {
+	if opts == nil {
+		opts = &JSONOptions{}
+	}
+	progress := d.newProgress(ProgressExport, d.estimatedTotal(ctx))
+	err := d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		bw := bufio.NewWriter(w)
+		if _, err := io.WriteString(bw, `{"vertices":[`); err != nil {
+			return err
+		}
+		if err := writeJSONArray(bw, progress.iterator(d.forEachVertexDocument(ctx, opts.Payload))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(bw, `],"edges":[`); err != nil {
+			return err
+		}
+		if err := writeJSONArray(bw, progress.iterator(d.forEachEdgeDocument(ctx, "", false))); err != nil {
+			return err
+		}
+		if _, err := io.WriteString(bw, "]}\n"); err != nil {
+			return err
+		}
+		return bw.Flush()
+	})
+	if err != nil {
+		return err
+	}
+	progress.done()
+	return nil
+}
+
+// Clone copies all vertices and edges to the (empty) DAG target. Clone reads
+// a consistent snapshot of the graph (i.e. it is not affected by concurrent
+// writes) and writes to target within a single transaction.
+func (d *DAG) Clone(target *DAG) error {
+	return d.CloneCtx(context.Background(), target)
+}
+
+// CloneCtx is like Clone but uses the given context.
+func (d *DAG) CloneCtx(ctx context.Context, target *DAG) error {
+	return d.clone(ctx, target, false)
+}
+
+// Invert copies all vertices and all edges with swapped directions (i.e.
+// the transposed graph) to the (empty) DAG target. As Clone, Invert reads a
+// consistent snapshot of the graph and writes to target within a single
+// transaction.
+func (d *DAG) Invert(target *DAG) error {
+	return d.InvertCtx(context.Background(), target)
+}
+
+// InvertCtx is like Invert but uses the given context.
+func (d *DAG) InvertCtx(ctx context.Context, target *DAG) error {
+	return d.clone(ctx, target, true)
+}
+
+// clone copies all vertices and edges (with swapped directions, if inverted
+// is true) to target.
+func (d *DAG) clone(ctx context.Context, target *DAG, inverted bool) error {
+	progress := d.newProgress(ProgressClone, d.estimatedTotal(ctx))
+	err := d.readTransaction(d.context(ctx), func(rctx context.Context) error {
+		return target.transaction(target.context(ctx), func(tctx context.Context) error {
+			if err := copyDocuments(tctx, target.vertices, progress.iterator(d.forEachVertexDocument(rctx, nil)), target.afterCopy(tctx, changeVertex)); err != nil {
+				return err
+			}
+			return copyDocuments(tctx, target.edges, progress.iterator(d.forEachEdgeDocument(rctx, target.vertices.Name(), inverted)), target.afterCopy(tctx, changeEdge))
+		})
+	})
+	if err != nil {
+		return err
+	}
+	progress.done()
+	return nil
+}
+
+// PayloadProjection restricts the (top-level) payload fields exported. If
+// Include is not empty, only the listed fields are exported. Fields listed in
+// Exclude are never exported. Payloads not being objects are exported as is.
+type PayloadProjection struct {
+	Include []string
+	Exclude []string
+}
+
+// expression returns an AQL expression projecting the given payload (and the
+// corresponding bind variables). p may be nil.
+func (p *PayloadProjection) expression(payload string) (string, map[string]interface{}) {
+	bindVars := make(map[string]interface{})
+	if p == nil {
+		return payload, bindVars
+	}
+	expr := payload
+	if len(p.Include) > 0 {
+		expr = "KEEP(" + expr + ", @payloadInclude)"
+		bindVars["payloadInclude"] = p.Include
+	}
+	if len(p.Exclude) > 0 {
+		expr = "UNSET(" + expr + ", @payloadExclude)"
+		bindVars["payloadExclude"] = p.Exclude
+	}
+	return "(IS_OBJECT(" + payload + ") ? " + expr + " : " + payload + ")", bindVars
+}
+
+// documentIterator calls fn for each document (see forEachVertexDocument and
+// forEachEdgeDocument).
+type documentIterator func(fn func(doc json.RawMessage) error) error
+
+// forEachVertexDocument returns an iterator over the vertex documents (without
+// "_id", "_rev" and the derived reachability filters and materialized paths,
+// see WithReachabilityFilters and WithMaterializedPaths) visible to the
+// principal carried by ctx (see WithPrincipal), with their payloads
+// restricted by projection (which may be nil).
+func (d *DAG) forEachVertexDocument(ctx context.Context, projection *PayloadProjection) documentIterator {
+	payload, bindVars := projection.expression("v.payload")
+	acl := aclFilter(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + allMatch(acl, "[v]") + `
+  RETURN MERGE(UNSET(v, "_id", "_rev", @reach, @path), {payload: ` + payload + `})`
+	bindVars["@vertices"] = d.vertices.Name()
+	bindVars["reach"] = ReachabilityAttribute
+	bindVars["path"] = MaterializedPathAttribute
+	addBindVars(bindVars, acl)
+	return d.forEachDocument(ctx, query, bindVars)
+}
+
+// forEachEdgeDocument returns an iterator over the edge documents (without
+// "_id", "_key", and "_rev"). If vertexCollName is empty, "_from" and "_to"
+// hold vertex keys. Otherwise, they hold document ids referring to the
+// collection with the given name. If inverted is true, "_from" and "_to" are
+// swapped. Edges incident to vertices not visible to the principal carried by
+// ctx (see WithPrincipal) are skipped.
+func (d *DAG) forEachEdgeDocument(ctx context.Context, vertexCollName string, inverted bool) documentIterator {
+	acl := aclFilter(ctx)
+	query := `
+FOR e IN @@edges
+  FILTER ` + allMatch(acl, "[DOCUMENT(e._from), DOCUMENT(e._to)]") + `
+  LET from = PARSE_IDENTIFIER(@inverted ? e._to : e._from).key
+  LET to = PARSE_IDENTIFIER(@inverted ? e._from : e._to).key
+  RETURN MERGE(UNSET(e, "_id", "_key", "_rev"), {
+    _from: @coll == "" ? from : CONCAT(@coll, "/", from),
+    _to: @coll == "" ? to : CONCAT(@coll, "/", to)
+  })`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"coll":     vertexCollName,
+		"inverted": inverted,
+	}
+	addBindVars(bindVars, acl)
+	return d.forEachDocument(ctx, query, bindVars)
+}
+
+// forEachDocument returns an iterator over the results of the given query.
+func (d *DAG) forEachDocument(ctx context.Context, query string, bindVars map[string]interface{}) documentIterator {
+	return func(fn func(doc json.RawMessage) error) error {
+		cursor, err := d.db.Query(ctx, query, bindVars)
+		if err != nil {
+			return arangoError(err)
+		}
+		defer closeCursor(cursor)
+		for {
+			var doc json.RawMessage
+			_, err := cursor.ReadDocument(ctx, &doc)
+			if driver.IsNoMoreDocuments(err) {
+				return nil
+			} else if err != nil {
+				return arangoError(err)
+			}
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+	}
+}
+
+// writeJSONArray writes the documents of the given iterator as (comma
+// separated) array elements to w.
+func writeJSONArray(w io.Writer, it documentIterator) error {
+	first := true
+	return it(func(doc json.RawMessage) error {
+		if !first {
+			if _, err := io.WriteString(w, ","); err != nil {
+				return err
+			}
+		}
+		first = false
+		_, err := w.Write(doc)
+		return err
+	})
+}
+
+// copyDocuments creates the documents of the given iterator (in batches) in
+// the given collection. If created is not nil, it is called with the ids of
+// the documents of each batch created.
+func copyDocuments(ctx context.Context, coll driver.Collection, it documentIterator, created func(ids ...driver.DocumentID) error) error {
+	batch := make([]json.RawMessage, 0, cloneBatchSize)
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		metas, errs, err := coll.CreateDocuments(ctx, batch)
+		if err != nil {
+			return arangoError(err)
+		}
+		if err := errs.FirstNonNil(); err != nil {
+			return arangoError(err)
+		}
+		batch = batch[:0]
+		if created != nil {
+			return created(metas.IDs()...)
+		}
+		return nil
+	}
+	err := it(func(doc json.RawMessage) error {
+		batch = append(batch, doc)
+		if len(batch) == cloneBatchSize {
+			return flush()
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return flush()
+}
@@ -0,0 +1,118 @@
+package arangodag
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_ExportJSON(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+
+	var buf bytes.Buffer
+	if err := d.ExportJSON(&buf); err != nil {
+		t.Fatalf("failed to ExportJSON(): %v", err)
+	}
+	var export struct {
+		Vertices []map[string]interface{} `json:"vertices"`
+		Edges    []map[string]interface{} `json:"edges"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("failed to unmarshal export: %v", err)
+	}
+	if len(export.Vertices) != 2 {
+		t.Errorf("ExportJSON() exported %d vertices, want %d", len(export.Vertices), 2)
+	}
+	if len(export.Edges) != 1 {
+		t.Fatalf("ExportJSON() exported %d edges, want %d", len(export.Edges), 1)
+	}
+	if export.Edges[0]["_from"] != "1" || export.Edges[0]["_to"] != "2" {
+		t.Errorf("ExportJSON() exported edge %v, want 1 -> 2", export.Edges[0])
+	}
+}
+
+func TestDAG_ExportJSONWith(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(statusVertex{MyID: "1", Status: "ok", Team: "a"})
+	_, _ = d.AddVertex(1)
+
+	var buf bytes.Buffer
+	opts := &JSONOptions{Payload: &PayloadProjection{Include: []string{"id", "team"}, Exclude: []string{"team"}}}
+	if err := d.ExportJSONWith(&buf, opts); err != nil {
+		t.Fatalf("failed to ExportJSONWith(): %v", err)
+	}
+	var export struct {
+		Vertices []struct {
+			Key     string      `json:"_key"`
+			Payload interface{} `json:"payload"`
+		} `json:"vertices"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &export); err != nil {
+		t.Fatalf("failed to unmarshal export: %v", err)
+	}
+	for _, v := range export.Vertices {
+		if v.Key == "1" {
+			if diff := deep.Equal(v.Payload, map[string]interface{}{"id": "1"}); diff != nil {
+				t.Error(diff)
+			}
+		} else if v.Payload != float64(1) {
+			t.Errorf("ExportJSONWith() exported payload %v, want %v", v.Payload, 1)
+		}
+	}
+}
+
+func TestDAG_Clone(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	target := someNewDag(t)
+	if err := d.Clone(target); err != nil {
+		t.Fatalf("failed to Clone(): %v", err)
+	}
+	if order, _ := target.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := target.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+
+	// the clone is a DAG on its own
+	if err := target.AddEdge("3", "1"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+}
+
+func TestDAG_Invert(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	target := someNewDag(t)
+	if err := d.Invert(target); err != nil {
+		t.Fatalf("failed to Invert(): %v", err)
+	}
+	if order, _ := target.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if length, _ := target.GetShortestPathLength("3", "1"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+	if length, _ := target.GetShortestPathLength("1", "3"); length != -1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, -1)
+	}
+}
@@ -0,0 +1,134 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ExternalIDAttribute is the default (dot separated) path of the attribute
+// holding external ids (see WithExternalIDs).
+const ExternalIDAttribute = "payload.externalId"
+
+// WithExternalIDs enables external (i.e. business) ids held by the vertex
+// attribute at the given (dot separated) path relative to the stored document
+// (ExternalIDAttribute, if empty). A unique (sparse) index guarantees, that
+// no two vertices share an external id (see GetVertexByExternalID and
+// AddEdgeByExternalIDs). Vertices without external id are allowed. Adding a
+// vertex with an external id known already returns an error (see
+// IsDuplicateExternalIDError).
+func WithExternalIDs(path string) Option {
+	return func(d *DAG) {
+		if path == "" {
+			path = ExternalIDAttribute
+		}
+		d.externalIDPath = path
+	}
+}
+
+// ensureExternalIDIndex creates the unique index on external ids.
+func (d *DAG) ensureExternalIDIndex(ctx context.Context) error {
+	options := &driver.EnsurePersistentIndexOptions{Unique: true, Sparse: true}
+	if _, _, err := d.vertices.EnsurePersistentIndex(d.context(ctx), []string{d.externalIDPath}, options); err != nil {
+		return arangoError(err)
+	}
+	return nil
+}
+
+// GetVertexByExternalID returns the id of the vertex with the given external
+// id (see WithExternalIDs) and, if vertex is not nil, decodes the vertex into
+// vertex (see GetVertex). GetVertexByExternalID returns an error, if
+// externalID is empty or unknown, or if external ids are not enabled.
+func (d *DAG) GetVertexByExternalID(externalID string, vertex interface{}) (string, error) {
+	return d.GetVertexByExternalIDCtx(context.Background(), externalID, vertex)
+}
+
+// GetVertexByExternalIDCtx is like GetVertexByExternalID but uses the given
+// context.
+func (d *DAG) GetVertexByExternalIDCtx(ctx context.Context, externalID string, vertex interface{}) (string, error) {
+	ctx = d.context(ctx)
+	docIDs, payloads, err := d.resolveExternalIDs(ctx, []string{externalID})
+	if err != nil {
+		return "", err
+	}
+	if vertex != nil {
+		if err := json.Unmarshal(payloads[0], vertex); err != nil {
+			return "", err
+		}
+	}
+	return d.id(docIDs[0].Key()), nil
+}
+
+// AddEdgeByExternalIDs adds an edge between the vertices with the given
+// external ids (see AddEdge). Both vertices are resolved by a single query.
+// AddEdgeByExternalIDs returns an error, if any of the external ids is empty
+// or unknown, or if external ids are not enabled.
+func (d *DAG) AddEdgeByExternalIDs(srcExternalID, dstExternalID string) error {
+	return d.AddEdgeByExternalIDsCtx(context.Background(), srcExternalID, dstExternalID)
+}
+
+// AddEdgeByExternalIDsCtx is like AddEdgeByExternalIDs but uses the given
+// context.
+func (d *DAG) AddEdgeByExternalIDsCtx(ctx context.Context, srcExternalID, dstExternalID string) error {
+	if srcExternalID == dstExternalID && srcExternalID != "" {
+		return SrcDstEqualError(srcExternalID)
+	}
+	ctx = d.context(ctx)
+	docIDs, _, err := d.resolveExternalIDs(ctx, []string{srcExternalID, dstExternalID})
+	if err != nil {
+		return err
+	}
+	return d.addEdge(ctx, docIDs[0], docIDs[1], nil)
+}
+
+// resolveExternalIDs returns the document ids and the payloads of the vertices
+// with the given external ids.
+func (d *DAG) resolveExternalIDs(ctx context.Context, externalIDs []string) ([]driver.DocumentID, []json.RawMessage, error) {
+	if d.externalIDPath == "" {
+		return nil, nil, InvalidParameterError("externalID", "external ids are not enabled")
+	}
+	for _, externalID := range externalIDs {
+		if externalID == "" {
+			return nil, nil, EmptyIDError()
+		}
+	}
+	query := `
+FOR externalID IN @externalIDs
+  LET v = FIRST(FOR v IN @@vertices FILTER v.@attr == externalID LIMIT 1 RETURN v)
+  RETURN {id: v._id, payload: v.payload}`
+	bindVars := map[string]interface{}{
+		"@vertices":   d.vertices.Name(),
+		"attr":        attributePath(d.externalIDPath),
+		"externalIDs": externalIDs,
+	}
+	var docIDs []driver.DocumentID
+	var payloads []json.RawMessage
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID      driver.DocumentID `json:"id"`
+			Payload json.RawMessage   `json:"payload"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		if item.ID == "" {
+			return NewUnknownKeyError(externalIDs[len(docIDs)])
+		}
+		docIDs = append(docIDs, item.ID)
+		payloads = append(payloads, item.Payload)
+		return nil
+	})
+	if err != nil {
+		return nil, nil, err
+	}
+	return docIDs, payloads, nil
+}
+
+// isPrimaryIndexViolation returns true, if the given unique constraint
+// violation refers to the primary index (i.e. the key).
+func isPrimaryIndexViolation(err error) bool {
+	ae, ok := driver.Cause(err).(driver.ArangoError)
+	return ok && strings.Contains(ae.ErrorMessage, "primary")
+}
@@ -0,0 +1,55 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+type externalVertex struct {
+	ExternalID string `json:"externalId,omitempty"`
+	Name       string `json:"name"`
+}
+
+func TestDAG_GetVertexByExternalID(t *testing.T) {
+	d := someNewDag(t, WithExternalIDs(""))
+
+	id1, err := d.AddVertex(externalVertex{ExternalID: "SKU-1", Name: "one"})
+	if err != nil {
+		t.Fatalf("failed to AddVertex(): %v", err)
+	}
+	id2, _ := d.AddVertex(externalVertex{ExternalID: "SKU-2", Name: "two"})
+	if _, err := d.AddVertex(externalVertex{Name: "anonymous"}); err != nil {
+		t.Errorf("failed to AddVertex() without external id: %v", err)
+	}
+	if _, err := d.AddVertex(externalVertex{ExternalID: "SKU-1"}); !IsDuplicateExternalIDError(err) {
+		t.Errorf("want DuplicateExternalIDError, got %v", err)
+	}
+
+	var v externalVertex
+	id, err := d.GetVertexByExternalID("SKU-1", &v)
+	if err != nil {
+		t.Fatalf("failed to GetVertexByExternalID(): %v", err)
+	}
+	if id != id1 || v.Name != "one" {
+		t.Errorf("GetVertexByExternalID() = %s, %v, want %s, one", id, v, id1)
+	}
+	if _, err := d.GetVertexByExternalID("SKU-3", nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+
+	if err := d.AddEdgeByExternalIDs("SKU-1", "SKU-2"); err != nil {
+		t.Fatalf("failed to AddEdgeByExternalIDs(): %v", err)
+	}
+	if _, err := d.GetEdge(id1, id2); err != nil {
+		t.Errorf("failed to GetEdge(): %v", err)
+	}
+	if err := d.AddEdgeByExternalIDs("SKU-2", "SKU-1"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if err := d.AddEdgeByExternalIDs("", "SKU-1"); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+
+	if _, err := someNewDag(t).GetVertexByExternalID("SKU-1", nil); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+}
@@ -0,0 +1,205 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/arangodb/go-driver"
+	"strings"
+)
+
+// Federation links multiple DAGs (within the same database) by edges whose
+// endpoints live in different DAGs (i.e. cross edges, e.g. linking a service
+// DAG to an infrastructure DAG). Cross edges are stored in a separate edge
+// collection, thus, they are not seen by the individual DAGs. In particular,
+// the loop checks of the individual DAGs don't consider cross edges.
+type Federation struct {
+	dags  []*DAG
+	links driver.Collection
+}
+
+// NewFederation returns the federation of the given DAGs, storing cross edges
+// in the edge collection with the given name (which is created, if it doesn't
+// exist yet). NewFederation returns an error, if the DAGs don't share the same
+// database.
+func NewFederation(edgeCollName string, dags ...*DAG) (*Federation, error) {
+	if len(dags) == 0 {
+		return nil, errors.New("federation without DAGs")
+	}
+	for _, d := range dags[1:] {
+		if d.db.Name() != dags[0].db.Name() {
+			return nil, fmt.Errorf("DAGs of different databases ('%s' and '%s')", dags[0].db.Name(), d.db.Name())
+		}
+	}
+	links, err := useOrCreateCollection(dags[0].context(context.Background()), dags[0].db, edgeCollName, edgeCollectionOptions())
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	return &Federation{dags: dags, links: links}, nil
+}
+
+// AddEdge adds a cross edge from the vertex with the id srcID of the DAG src to
+// the vertex with the id dstID of the DAG dst. AddEdge returns an error, if
+// srcID or dstID are empty or unknown, if src or dst are not part of the
+// federation or are the same, if the edge already exists, or if the new edge
+// would create a loop (considering the edges of all DAGs and all cross
+// edges).
+func (f *Federation) AddEdge(src *DAG, srcID string, dst *DAG, dstID string) error {
+	return f.AddEdgeCtx(context.Background(), src, srcID, dst, dstID)
+}
+
+// AddEdgeCtx is like AddEdge but uses the given context.
+func (f *Federation) AddEdgeCtx(ctx context.Context, src *DAG, srcID string, dst *DAG, dstID string) error {
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+	if !f.contains(src) || !f.contains(dst) {
+		return errors.New("DAG is not part of the federation")
+	}
+	if src == dst {
+		return errors.New("cross edges must connect different DAGs (see AddEdge of DAG)")
+	}
+	d := f.dags[0]
+	ctx = d.context(ctx)
+	from, err := src.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return err
+	}
+	to, err := dst.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return err
+	}
+
+	query := "FOR e IN @@links FILTER e._from == @from AND e._to == @to LIMIT 1 RETURN 1"
+	bindVars := map[string]interface{}{
+		"@links": f.links.Name(),
+		"from":   from,
+		"to":     to,
+	}
+	exists, err := d.queryHasResult(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return DuplicateEdgeError(string(from), string(to))
+	}
+	query = "FOR v IN OUTBOUND SHORTEST_PATH @to TO @from " + f.edgeCollections() + " LIMIT 1 RETURN 1"
+	bindVars = f.bindVars(map[string]interface{}{
+		"from": from,
+		"to":   to,
+	})
+	loop, err := d.queryHasResult(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	if loop {
+		return LoopError(string(from), string(to))
+	}
+
+	if _, err := f.links.CreateDocument(ctx, &myEdge{From: from, To: to}); err != nil {
+		return arangoError(err)
+	}
+	return nil
+}
+
+// GetDescendants returns the document ids of the descendants of the vertex
+// with the given id of the DAG d. If cross is true, the traversal follows
+// cross edges (and the edges of the other DAGs) and, otherwise, stays within
+// d. GetDescendants returns an error, if id is empty or unknown, or if d is
+// not part of the federation.
+func (f *Federation) GetDescendants(d *DAG, id string, cross bool) ([]driver.DocumentID, error) {
+	return f.GetDescendantsCtx(context.Background(), d, id, cross)
+}
+
+// GetDescendantsCtx is like GetDescendants but uses the given context.
+func (f *Federation) GetDescendantsCtx(ctx context.Context, d *DAG, id string, cross bool) ([]driver.DocumentID, error) {
+	return f.traverse(ctx, d, id, "OUTBOUND", cross)
+}
+
+// GetAncestors returns the document ids of the ancestors of the vertex with
+// the given id of the DAG d (see GetDescendants).
+func (f *Federation) GetAncestors(d *DAG, id string, cross bool) ([]driver.DocumentID, error) {
+	return f.GetAncestorsCtx(context.Background(), d, id, cross)
+}
+
+// GetAncestorsCtx is like GetAncestors but uses the given context.
+func (f *Federation) GetAncestorsCtx(ctx context.Context, d *DAG, id string, cross bool) ([]driver.DocumentID, error) {
+	return f.traverse(ctx, d, id, "INBOUND", cross)
+}
+
+func (f *Federation) traverse(ctx context.Context, d *DAG, id, direction string, cross bool) ([]driver.DocumentID, error) {
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	if !f.contains(d) {
+		return nil, errors.New("DAG is not part of the federation")
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	collections := "@@edges"
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	if cross {
+		collections = f.edgeCollections()
+		bindVars = f.bindVars(nil)
+	}
+	bindVars["start"] = start
+	bindVars["maxDepth"] = maxDepth
+	query := `
+FOR v IN 1..@maxDepth ` + direction + ` @start ` + collections + `
+  OPTIONS {bfs: true, uniqueVertices: "global"}
+  RETURN v._id`
+	ids := []driver.DocumentID{}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var id driver.DocumentID
+		if err := json.Unmarshal(doc, &id); err != nil {
+			return err
+		}
+		ids = append(ids, id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// contains returns true, if d is part of the federation.
+func (f *Federation) contains(d *DAG) bool {
+	for _, dag := range f.dags {
+		if dag == d {
+			return true
+		}
+	}
+	return false
+}
+
+// edgeCollections returns the (comma separated) bind parameters of the edge
+// collections of all DAGs and of the cross edges (see bindVars).
+func (f *Federation) edgeCollections() string {
+	params := []string{"@@links"}
+	for i := range f.dags {
+		params = append(params, fmt.Sprintf("@@edges%d", i))
+	}
+	return strings.Join(params, ", ")
+}
+
+// bindVars returns the given bind variables together with those of
+// edgeCollections.
+func (f *Federation) bindVars(vars map[string]interface{}) map[string]interface{} {
+	bindVars := map[string]interface{}{
+		"@links": f.links.Name(),
+	}
+	for i, d := range f.dags {
+		bindVars[fmt.Sprintf("@edges%d", i)] = d.edges.Name()
+	}
+	for k, v := range vars {
+		bindVars[k] = v
+	}
+	return bindVars
+}
@@ -0,0 +1,74 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/arangodb/go-driver"
+	"github.com/go-test/deep"
+)
+
+func TestFederation(t *testing.T) {
+	services := someNewDag(t)
+	infra, err := NewDAG(services.db.Name(), someName(), someName(), services.client)
+	if err != nil {
+		t.Fatalf("failed to NewDAG(): %v", err)
+	}
+
+	// services: api -> db-client, infra: vm -> disk
+	_, _ = services.AddVertex(idVertex{MyID: "api"})
+	_, _ = services.AddVertex(idVertex{MyID: "db-client"})
+	_ = services.AddEdge("api", "db-client")
+	_, _ = infra.AddVertex(idVertex{MyID: "vm"})
+	_, _ = infra.AddVertex(idVertex{MyID: "disk"})
+	_ = infra.AddEdge("vm", "disk")
+
+	f, err := NewFederation(someName(), services, infra)
+	if err != nil {
+		t.Fatalf("failed to NewFederation(): %v", err)
+	}
+	if err := f.AddEdge(services, "db-client", infra, "vm"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+
+	// crossing boundaries
+	descendants, err := f.GetDescendants(services, "api", true)
+	if err != nil {
+		t.Fatalf("failed to GetDescendants(): %v", err)
+	}
+	want := []driver.DocumentID{
+		driver.NewDocumentID(services.vertices.Name(), "db-client"),
+		driver.NewDocumentID(infra.vertices.Name(), "vm"),
+		driver.NewDocumentID(infra.vertices.Name(), "disk"),
+	}
+	if diff := deep.Equal(descendants, want); diff != nil {
+		t.Error(diff)
+	}
+	ancestors, _ := f.GetAncestors(infra, "disk", true)
+	if len(ancestors) != 3 {
+		t.Errorf("GetAncestors() = %v, want 3 ancestors", ancestors)
+	}
+
+	// staying within the DAG
+	descendants, _ = f.GetDescendants(services, "api", false)
+	if diff := deep.Equal(descendants, want[:1]); diff != nil {
+		t.Error(diff)
+	}
+
+	// duplicates and loops
+	if err := f.AddEdge(services, "db-client", infra, "vm"); !IsDuplicateEdgeError(err) {
+		t.Errorf("want DuplicateEdgeError, got %v", err)
+	}
+	if err := f.AddEdge(infra, "disk", services, "api"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+
+	// unknown
+	if err := f.AddEdge(services, "api", infra, "foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+
+	// different databases
+	if _, err := NewFederation(someName(), services, someNewDag(t)); err == nil {
+		t.Errorf("NewFederation() = nil, want error")
+	}
+}
@@ -0,0 +1,83 @@
+package arangodag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+)
+
+// Fingerprint returns a deterministic hash over the structure of the graph
+// (i.e. the sorted vertex ids and edges), e.g. to verify two DAGs are
+// identical. If withPayloads is true, the (canonically encoded) vertex payloads
+// are included too. Fingerprint is computed over a consistent snapshot of the
+// graph.
+func (d *DAG) Fingerprint(withPayloads bool) (string, error) {
+	return d.FingerprintCtx(context.Background(), withPayloads)
+}
+
+// FingerprintCtx is like Fingerprint but uses the given context.
+func (d *DAG) FingerprintCtx(ctx context.Context, withPayloads bool) (string, error) {
+	h := sha256.New()
+	err := d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		return d.fingerprint(ctx, h, withPayloads)
+	})
+	if err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
+
+func (d *DAG) fingerprint(ctx context.Context, w io.Writer, withPayloads bool) error {
+	query := `
+FOR v IN @@vertices
+  SORT v._key
+  RETURN {id: v._key, payload: @withPayloads ? v.payload : null}`
+	bindVars := map[string]interface{}{
+		"@vertices":    d.vertices.Name(),
+		"withPayloads": withPayloads,
+	}
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID      string      `json:"id"`
+			Payload interface{} `json:"payload"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		_, _ = io.WriteString(w, "v\x00"+item.ID+"\x00")
+		if withPayloads {
+
+			// encoding/json sorts the keys of maps, thus, the encoding is canonical
+			payload, err := json.Marshal(item.Payload)
+			if err != nil {
+				return err
+			}
+			_, _ = w.Write(payload)
+		}
+		_, _ = io.WriteString(w, "\n")
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	query = `
+FOR e IN @@edges
+  LET from = PARSE_IDENTIFIER(e._from).key
+  LET to = PARSE_IDENTIFIER(e._to).key
+  SORT from, to
+  RETURN [from, to]`
+	bindVars = map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var edge [2]string
+		if err := json.Unmarshal(doc, &edge); err != nil {
+			return err
+		}
+		_, err := io.WriteString(w, "e\x00"+edge[0]+"\x00"+edge[1]+"\n")
+		return err
+	})
+}
@@ -0,0 +1,37 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDAG_Fingerprint(t *testing.T) {
+	d1 := someNewDag(t)
+	d2 := someNewDag(t)
+
+	// same graph, different insertion order
+	_, _ = d1.AddVertex(foobarKey{MyID: "1", A: "a"})
+	_, _ = d1.AddVertex(foobarKey{MyID: "2"})
+	_ = d1.AddEdge("1", "2")
+	_, _ = d2.AddVertex(foobarKey{MyID: "2"})
+	_, _ = d2.AddVertex(foobarKey{MyID: "1", A: "a"})
+	_ = d2.AddEdge("1", "2")
+
+	f1, err := d1.Fingerprint(true)
+	if err != nil {
+		t.Fatalf("failed to Fingerprint(): %v", err)
+	}
+	if f2, _ := d2.Fingerprint(true); f1 != f2 {
+		t.Errorf("Fingerprint() = %s, want %s", f2, f1)
+	}
+
+	// payloads only matter, if requested
+	s1, _ := d1.Fingerprint(false)
+	_ = d2.upsertVertex(context.Background(), "1", foobarKey{MyID: "1", A: "b"})
+	if s2, _ := d2.Fingerprint(false); s1 != s2 {
+		t.Errorf("Fingerprint() = %s, want %s", s2, s1)
+	}
+	if f2, _ := d2.Fingerprint(true); f1 == f2 {
+		t.Errorf("Fingerprint() = %s, want it to differ", f2)
+	}
+}
@@ -0,0 +1,129 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+)
+
+// FrontierIterator iterates the descendants of a vertex level by level (see
+// DAG.FrontierIterator). Typical usage:
+//
+//	it, err := d.FrontierIterator(id)
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		level := it.Level()
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+type FrontierIterator struct {
+	d      *DAG
+	ctx    context.Context
+	cursor driver.Cursor
+	level  []string
+	next   *frontierItem
+	depth  int
+	err    error
+	closed bool
+}
+
+type frontierItem struct {
+	ID    string `json:"id"`
+	Depth int    `json:"depth"`
+}
+
+// FrontierIterator returns an iterator over the descendants of the vertex with
+// the given id grouped by their depth (i.e. their distance from the given
+// vertex). Each descendant is part of exactly one level (the one of its
+// shortest distance). The descendants are read by a single, streamed query,
+// thus, the iterator must be closed after use. FrontierIterator returns an
+// error, if id is empty or unknown.
+func (d *DAG) FrontierIterator(id string) (*FrontierIterator, error) {
+	return d.FrontierIteratorCtx(context.Background(), id)
+}
+
+// FrontierIteratorCtx is like FrontierIterator but uses the given context.
+func (d *DAG) FrontierIteratorCtx(ctx context.Context, id string) (*FrontierIterator, error) {
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars) + `
+  RETURN {id: v._key, depth: LENGTH(p.edges)}`
+	cursor, err := d.db.Query(driver.WithQueryStream(ctx), query, bindVars)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	return &FrontierIterator{d: d, ctx: ctx, cursor: cursor}, nil
+}
+
+// Next advances the iterator to the next level. Next returns false, if there
+// are no more levels or an error occurred (see Err).
+func (it *FrontierIterator) Next() bool {
+	if it.closed || it.err != nil {
+		return false
+	}
+	it.level = nil
+	if it.next != nil {
+		it.level = append(it.level, it.d.id(it.next.ID))
+		it.depth = it.next.Depth
+		it.next = nil
+	}
+	for {
+		var item frontierItem
+		_, err := it.cursor.ReadDocument(it.ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			it.err = arangoError(err)
+			return false
+		}
+		if it.level != nil && item.Depth != it.depth {
+			it.next = &item
+			break
+		}
+		it.level = append(it.level, it.d.id(item.ID))
+		it.depth = item.Depth
+	}
+	return it.level != nil
+}
+
+// Level returns the ids of the vertices of the current level.
+func (it *FrontierIterator) Level() []string {
+	return it.level
+}
+
+// Depth returns the depth of the current level (i.e. 1 for the children).
+func (it *FrontierIterator) Depth() int {
+	return it.depth
+}
+
+// Err returns the error occurred while iterating (if any).
+func (it *FrontierIterator) Err() error {
+	return it.err
+}
+
+// Close closes the iterator (i.e. the underlying cursor).
+func (it *FrontierIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	return arangoError(it.cursor.Close())
+}
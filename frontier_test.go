@@ -0,0 +1,58 @@
+package arangodag
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_FrontierIterator(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 1 -> 3, 2 -> 4, 3 -> 4, 4 -> 5, 1 -> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("4", "5")
+	_ = d.AddEdge("1", "5")
+
+	it, err := d.FrontierIterator("1")
+	if err != nil {
+		t.Fatalf("failed to FrontierIterator(): %v", err)
+	}
+	defer func() {
+		_ = it.Close()
+	}()
+	var levels [][]string
+	for it.Next() {
+		level := it.Level()
+		sort.Strings(level)
+		levels = append(levels, level)
+		if it.Depth() != len(levels) {
+			t.Errorf("Depth() = %d, want %d", it.Depth(), len(levels))
+		}
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("failed to iterate: %v", err)
+	}
+	if diff := deep.Equal(levels, [][]string{{"2", "3", "5"}, {"4"}}); diff != nil {
+		t.Error(diff)
+	}
+
+	// leaves have no frontier
+	leaf, _ := d.FrontierIterator("5")
+	if leaf.Next() {
+		t.Errorf("Next() = true, want false")
+	}
+	_ = leaf.Close()
+
+	// unknown
+	if _, err := d.FrontierIterator("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
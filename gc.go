@@ -0,0 +1,141 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// maxDepth is used as (practically unlimited) maximal depth of traversals.
+const maxDepth = 1000000
+
+// MarkAndSweep deletes all vertices not reachable from (i.e. not being
+// descendants of) any of the vertices with the given ids, together with their
+// edges. The given vertices themselves are kept (i.e. calling MarkAndSweep
+// without roots deletes all unpinned vertices). Pinned vertices (see Pin) are
+// treated as additional roots. MarkAndSweep runs within a single transaction
+// and returns the number of deleted vertices. MarkAndSweep returns an error,
+// if any of the given ids is empty or unknown.
+func (d *DAG) MarkAndSweep(roots []string) (uint64, error) {
+	return d.MarkAndSweepCtx(context.Background(), roots)
+}
+
+// MarkAndSweepCtx is like MarkAndSweep but uses the given context.
+func (d *DAG) MarkAndSweepCtx(ctx context.Context, roots []string) (uint64, error) {
+	var count uint64
+	err := d.transaction(d.context(ctx), func(ctx context.Context) error {
+		starts := make([]driver.DocumentID, 0, len(roots))
+		for _, root := range roots {
+			if root == "" {
+				return EmptyIDError()
+			}
+			id, err := d.vertexDocumentID(ctx, root)
+			if err != nil {
+				return err
+			}
+			starts = append(starts, id)
+		}
+
+		// pinned vertices are roots too
+		pinned, err := d.pinnedIDs(ctx)
+		if err != nil {
+			return err
+		}
+		starts = append(starts, pinned...)
+
+		// mark
+		reachable, err := d.reachableIDs(ctx, starts, "OUTBOUND")
+		if err != nil {
+			return err
+		}
+
+		// sweep
+		query := `
+LET reachable = ZIP(@reachable, @reachable)
+FOR v IN @@vertices
+  FILTER !HAS(reachable, v._id)
+  REMOVE v IN @@vertices
+  RETURN OLD._id`
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"reachable": reachable,
+		}
+		ids, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		if err := d.afterWrite(ctx, changeRemove, changeVertex, ids...); err != nil {
+			return err
+		}
+
+		// reachable vertices losing parents
+		var orphaned []driver.DocumentID
+		if d.materializedPaths {
+			query = `
+LET reachable = ZIP(@reachable, @reachable)
+FOR e IN @@edges
+  FILTER !HAS(reachable, e._from) AND HAS(reachable, e._to)
+  RETURN DISTINCT e._to`
+			bindVars = map[string]interface{}{
+				"@edges":    d.edges.Name(),
+				"reachable": reachable,
+			}
+			if orphaned, err = d.queryIDs(ctx, query, bindVars); err != nil {
+				return err
+			}
+		}
+
+		// edges between reachable vertices start at reachable vertices
+		query = `
+LET reachable = ZIP(@reachable, @reachable)
+FOR e IN @@edges
+  FILTER !HAS(reachable, e._from)
+  REMOVE e IN @@edges
+  RETURN OLD._id`
+		bindVars = map[string]interface{}{
+			"@edges":    d.edges.Name(),
+			"reachable": reachable,
+		}
+		edgeIDs, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		count = uint64(len(ids))
+		if err := d.afterWrite(ctx, changeRemove, changeEdge, edgeIDs...); err != nil {
+			return err
+		}
+		return d.updatePaths(ctx, orphaned...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// reachableIDs returns the (unique) document ids of the given vertices and of
+// all vertices reachable from them in the given direction ("OUTBOUND" or
+// "INBOUND").
+func (d *DAG) reachableIDs(ctx context.Context, starts []driver.DocumentID, direction string) ([]driver.DocumentID, error) {
+	query := `
+RETURN UNIQUE(FLATTEN(
+  FOR start IN @starts
+    RETURN APPEND([start], (
+      FOR v IN 1..@maxDepth ` + direction + ` start @@edges
+        OPTIONS {bfs: true, uniqueVertices: "global"}
+        RETURN v._id
+    ))
+))`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"starts":   starts,
+		"maxDepth": maxDepth,
+	}
+	var ids []driver.DocumentID
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &ids)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
@@ -0,0 +1,41 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_MarkAndSweep(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3, 4 -> 3, 4 -> 5, 6
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("4", "3")
+	_ = d.AddEdge("4", "5")
+
+	count, err := d.MarkAndSweep([]string{"1"})
+	if err != nil {
+		t.Fatalf("failed to MarkAndSweep(): %v", err)
+	}
+	if count != 3 {
+		t.Errorf("MarkAndSweep() = %d, want %d", count, 3)
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+
+	// unknown
+	_, errUnknown := d.MarkAndSweep([]string{"foo"})
+	if !IsUnknownIDError(errUnknown) {
+		t.Errorf("want UnknownIDError, got %v", errUnknown)
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+}
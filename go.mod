@@ -5,4 +5,5 @@ go 1.12
 require (
 	github.com/arangodb/go-driver v0.0.0-20201202080739-c41c94f2de00
 	github.com/go-test/deep v1.0.7
+	gopkg.in/yaml.v2 v2.4.0
 )
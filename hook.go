@@ -0,0 +1,24 @@
+package arangodag
+
+// WithBeforeAddEdge registers fn to be called with the ids of the source and
+// the destination vertex before adding an edge (e.g. via AddEdge or
+// AddEdgeWithData). If fn returns an error, the edge is not added and the
+// error is returned as is. This allows to enforce application-level
+// invariants (e.g. type compatibility between endpoints). Bulk operations
+// (e.g. ImportJSON or Restore) don't call fn.
+func WithBeforeAddEdge(fn func(srcID, dstID string) error) Option {
+	return func(d *DAG) {
+		d.beforeAddEdge = fn
+	}
+}
+
+// WithBeforeDeleteVertex registers fn to be called with the id of the vertex
+// to be deleted by DeleteVertex. If fn returns an error, the vertex is not
+// deleted and the error is returned as is. Vertices deleted by bulk operations
+// (e.g. MarkAndSweep, PruneOlderThan, or the cascading deletes of the
+// reference counting mode) don't call fn.
+func WithBeforeDeleteVertex(fn func(id string) error) Option {
+	return func(d *DAG) {
+		d.beforeDeleteVertex = fn
+	}
+}
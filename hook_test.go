@@ -0,0 +1,52 @@
+package arangodag
+
+import (
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestWithBeforeAddEdge(t *testing.T) {
+	errForbidden := errors.New("artifacts must not depend on builds")
+	d := someNewDag(t, WithBeforeAddEdge(func(srcID, dstID string) error {
+		if strings.HasPrefix(srcID, "artifact") && strings.HasPrefix(dstID, "build") {
+			return errForbidden
+		}
+		return nil
+	}))
+	_, _ = d.AddVertex(idVertex{MyID: "build1"})
+	_, _ = d.AddVertex(idVertex{MyID: "artifact1"})
+
+	if err := d.AddEdge("build1", "artifact1"); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+	_ = d.DeleteEdge("build1", "artifact1")
+	if err := d.AddEdge("artifact1", "build1"); !errors.Is(err, errForbidden) {
+		t.Errorf("AddEdge() = %v, want %v", err, errForbidden)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+}
+
+func TestWithBeforeDeleteVertex(t *testing.T) {
+	errProtected := errors.New("protected")
+	d := someNewDag(t, WithBeforeDeleteVertex(func(id string) error {
+		if id == "1" {
+			return errProtected
+		}
+		return nil
+	}))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+
+	if err := d.DeleteVertex("1"); !errors.Is(err, errProtected) {
+		t.Errorf("DeleteVertex() = %v, want %v", err, errProtected)
+	}
+	if err := d.DeleteVertex("2"); err != nil {
+		t.Errorf("failed to DeleteVertex(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+}
@@ -0,0 +1,246 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// Impact is the result of GetImpact.
+type Impact struct {
+
+	// Distances maps the ids of all affected vertices to their shortest
+	// distance from any of the changed vertices.
+	Distances map[string]int `json:"distances"`
+
+	// Levels holds the ids of the affected vertices grouped by their distance
+	// (i.e. Levels[0] holds the vertices with distance 1), each level being
+	// sorted.
+	Levels [][]string `json:"levels"`
+}
+
+// GetImpact returns the vertices affected by changing the vertices with the
+// given ids (i.e. the union of their descendants), computed by a single query.
+// Changed vertices being descendants of other changed vertices are affected
+// too. GetImpact returns an error, if any of the ids is empty or unknown.
+func (d *DAG) GetImpact(ids []string) (*Impact, error) {
+	return d.GetImpactCtx(context.Background(), ids)
+}
+
+// GetImpactCtx is like GetImpact but uses the given context.
+func (d *DAG) GetImpactCtx(ctx context.Context, ids []string) (*Impact, error) {
+	ctx = d.context(ctx)
+	starts, err := d.vertexDocumentIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bindVars := map[string]interface{}{
+		"starts":   starts,
+		"maxDepth": maxDepth,
+	}
+	query := `
+FOR start IN @starts
+  ` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "    ", bindVars) + `
+    COLLECT id = v._key AGGREGATE distance = MIN(LENGTH(p.edges))
+    SORT distance, id
+    RETURN {id, distance}`
+	impact := &Impact{
+		Distances: make(map[string]int),
+		Levels:    [][]string{},
+	}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID       string `json:"id"`
+			Distance int    `json:"distance"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		id := d.id(item.ID)
+		impact.Distances[id] = item.Distance
+		for len(impact.Levels) < item.Distance {
+			impact.Levels = append(impact.Levels, []string{})
+		}
+		impact.Levels[item.Distance-1] = append(impact.Levels[item.Distance-1], id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return impact, nil
+}
+
+// Provenance is the result of GetProvenance.
+type Provenance struct {
+
+	// Distances maps the ids of all contributing vertices to their shortest
+	// distance to any of the given vertices.
+	Distances map[string]int `json:"distances"`
+
+	// Levels holds the ids of the contributing vertices grouped by their
+	// distance (i.e. Levels[0] holds the vertices with distance 1), each level
+	// being sorted.
+	Levels [][]string `json:"levels"`
+
+	// Paths maps the ids of all contributing vertices to a shortest path
+	// (i.e. the ids of the vertices in between, both ends included) from the
+	// contributing vertex to one of the given vertices.
+	Paths map[string][]string `json:"paths"`
+}
+
+// GetProvenance returns the vertices contributing to the vertices with the
+// given ids (i.e. the union of their ancestors) together with a contribution
+// path for each, computed by a single query. GetProvenance returns an error,
+// if any of the ids is empty or unknown.
+func (d *DAG) GetProvenance(ids []string) (*Provenance, error) {
+	return d.GetProvenanceCtx(context.Background(), ids)
+}
+
+// GetProvenanceCtx is like GetProvenance but uses the given context.
+func (d *DAG) GetProvenanceCtx(ctx context.Context, ids []string) (*Provenance, error) {
+	ctx = d.context(ctx)
+	starts, err := d.vertexDocumentIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+	bindVars := map[string]interface{}{
+		"starts":   starts,
+		"maxDepth": maxDepth,
+	}
+	query := `
+FOR start IN @starts
+  ` + d.traverse(ctx, traversalSpec{
+		direction: "INBOUND",
+		start:     "start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "    ", bindVars) + `
+    COLLECT id = v._key INTO paths = REVERSE(p.vertices[*]._key)
+    LET path = FIRST(FOR path IN paths SORT LENGTH(path) RETURN path)
+    SORT LENGTH(path), id
+    RETURN {id, path}`
+	provenance := &Provenance{
+		Distances: make(map[string]int),
+		Levels:    [][]string{},
+		Paths:     make(map[string][]string),
+	}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID   string   `json:"id"`
+			Path []string `json:"path"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		for i, key := range item.Path {
+			item.Path[i] = d.id(key)
+		}
+		id := d.id(item.ID)
+		distance := len(item.Path) - 1
+		provenance.Distances[id] = distance
+		provenance.Paths[id] = item.Path
+		for len(provenance.Levels) < distance {
+			provenance.Levels = append(provenance.Levels, []string{})
+		}
+		provenance.Levels[distance-1] = append(provenance.Levels[distance-1], id)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return provenance, nil
+}
+
+// Reachability is a pair of vertices, where Descendant is reachable from
+// Ancestor (see SimulateAddEdge).
+type Reachability struct {
+	Ancestor   string `json:"ancestor"`
+	Descendant string `json:"descendant"`
+}
+
+// SimulateAddEdge returns the (ancestor, descendant) pairs that adding an edge
+// from the vertex with the id srcID to the vertex with the id dstID would make
+// reachable (i.e. that aren't reachable yet), without writing anything. The
+// pairs are sorted by ancestor and descendant. SimulateAddEdge returns an
+// error, if AddEdge would do so (i.e. if srcID or dstID are empty, equal or
+// unknown, if the edge already exists, or if it would create a loop).
+func (d *DAG) SimulateAddEdge(srcID, dstID string) ([]Reachability, error) {
+	return d.SimulateAddEdgeCtx(context.Background(), srcID, dstID)
+}
+
+// SimulateAddEdgeCtx is like SimulateAddEdge but uses the given context.
+func (d *DAG) SimulateAddEdgeCtx(ctx context.Context, srcID, dstID string) ([]Reachability, error) {
+	if srcID == "" || dstID == "" {
+		return nil, EmptyIDError()
+	}
+	if srcID == dstID {
+		return nil, SrcDstEqualError(srcID)
+	}
+	ctx = d.context(ctx)
+	src, err := d.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return nil, err
+	}
+	dst, err := d.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return nil, err
+	}
+	exists, err := d.edgeExists(ctx, src, dst)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, DuplicateEdgeError(srcID, dstID)
+	}
+	if err := d.checkLoop(ctx, src, dst); err != nil {
+		return nil, err
+	}
+
+	query := `
+LET ancestors = APPEND([@src], (
+  FOR v IN 1..@maxDepth INBOUND @src @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    RETURN v._id
+))
+LET descendants = APPEND([@dst], (
+  FOR v IN 1..@maxDepth OUTBOUND @dst @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    RETURN v._id
+))
+FOR a IN ancestors
+  LET reachable = (
+    FOR v IN 1..@maxDepth OUTBOUND a @@edges
+      OPTIONS {bfs: true, uniqueVertices: "global"}
+      RETURN v._id
+  )
+  FOR b IN descendants
+    FILTER b NOT IN reachable
+    LET ancestor = PARSE_IDENTIFIER(a).key
+    LET descendant = PARSE_IDENTIFIER(b).key
+    SORT ancestor, descendant
+    RETURN {ancestor, descendant}`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"src":      src,
+		"dst":      dst,
+		"maxDepth": maxDepth,
+	}
+	pairs := []Reachability{}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var r Reachability
+		if err := json.Unmarshal(doc, &r); err != nil {
+			return err
+		}
+		r.Ancestor, r.Descendant = d.id(r.Ancestor), d.id(r.Descendant)
+		pairs = append(pairs, r)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return pairs, nil
+}
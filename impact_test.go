@@ -0,0 +1,113 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetImpact(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 3 -> 4, 5 -> 4, 6
+	for _, id := range []string{"1", "2", "3", "4", "5", "6"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("5", "4")
+
+	impact, err := d.GetImpact([]string{"1", "5"})
+	if err != nil {
+		t.Fatalf("failed to GetImpact(): %v", err)
+	}
+	want := &Impact{
+		Distances: map[string]int{"2": 1, "3": 2, "4": 1},
+		Levels:    [][]string{{"2", "4"}, {"3"}},
+	}
+	if diff := deep.Equal(impact, want); diff != nil {
+		t.Error(diff)
+	}
+
+	// no descendants
+	impact, err = d.GetImpact([]string{"6"})
+	if err != nil {
+		t.Fatalf("failed to GetImpact(): %v", err)
+	}
+	if len(impact.Distances) != 0 || len(impact.Levels) != 0 {
+		t.Errorf("GetImpact() = %v, want no impact", impact)
+	}
+
+	// unknown
+	if _, err := d.GetImpact([]string{"1", "foo"}); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_GetProvenance(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 4 -> 3, 4 -> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("4", "3")
+	_ = d.AddEdge("4", "5")
+
+	provenance, err := d.GetProvenance([]string{"3", "5"})
+	if err != nil {
+		t.Fatalf("failed to GetProvenance(): %v", err)
+	}
+	if diff := deep.Equal(provenance.Distances, map[string]int{"1": 2, "2": 1, "4": 1}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(provenance.Levels, [][]string{{"2", "4"}, {"1"}}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(provenance.Paths["1"], []string{"1", "2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	if _, err := d.GetProvenance([]string{"foo"}); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_SimulateAddEdge(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 3 -> 4, 1 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "4")
+
+	pairs, err := d.SimulateAddEdge("2", "3")
+	if err != nil {
+		t.Fatalf("failed to SimulateAddEdge(): %v", err)
+	}
+	want := []Reachability{{"1", "3"}, {"2", "3"}, {"2", "4"}}
+	if diff := deep.Equal(pairs, want); diff != nil {
+		t.Error(diff)
+	}
+	if size, _ := d.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want 3", size)
+	}
+
+	// errors
+	if _, err := d.SimulateAddEdge("4", "1"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if _, err := d.SimulateAddEdge("1", "2"); !IsDuplicateEdgeError(err) {
+		t.Errorf("want DuplicateEdgeError, got %v", err)
+	}
+	if _, err := d.SimulateAddEdge("1", "foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
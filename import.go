@@ -0,0 +1,285 @@
+package arangodag
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ImportOptions configures ImportJSON. All fields are optional.
+type ImportOptions struct {
+
+	// MapKey returns the key the vertex with the given key (as of the import
+	// stream) is imported with. MapKey is called once per vertex and edges
+	// are rewritten accordingly (see PrefixKeys and RegenerateKeys).
+	MapKey func(key string) string
+}
+
+// PrefixKeys returns a key mapping (see ImportOptions) prefixing all keys
+// with the given prefix.
+func PrefixKeys(prefix string) func(key string) string {
+	return func(key string) string {
+		return prefix + key
+	}
+}
+
+// RegenerateKeys returns a key mapping (see ImportOptions) replacing all keys
+// by random ones.
+func RegenerateKeys() func(key string) string {
+	return func(string) string {
+		return randomKey()
+	}
+}
+
+// randomKey returns a random (valid) key.
+func randomKey() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// jsonImport is the format written by ExportJSON and read by ImportJSON.
+type jsonImport struct {
+	Vertices []map[string]interface{} `json:"vertices"`
+	Edges    []map[string]interface{} `json:"edges"`
+}
+
+// ImportJSON reads a graph (as written by ExportJSON) from r and adds its
+// vertices and edges within a single transaction. Keys are remapped as
+// configured by opts (which may be nil). Edge endpoints not referring to
+// imported vertices are kept as is (i.e. they refer to existing vertices).
+// Within the transaction, holding an exclusive lock on the edge collection,
+// the imported edges are checked for loops (also via existing edges).
+// ImportJSON returns a loop error (see IsLoopError) and imports nothing, if
+// any of the imported edges closes a loop (see ValidateImport for checking
+// an import in advance).
+func (d *DAG) ImportJSON(r io.Reader, opts *ImportOptions) error {
+	return d.ImportJSONCtx(context.Background(), r, opts)
+}
+
+// ImportJSONCtx is like ImportJSON but uses the given context.
+func (d *DAG) ImportJSONCtx(ctx context.Context, r io.Reader, opts *ImportOptions) error {
+	if opts == nil {
+		opts = &ImportOptions{}
+	}
+	var in jsonImport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return err
+	}
+
+	// remap keys
+	keys := make(map[string]string, len(in.Vertices))
+	for _, v := range in.Vertices {
+		key, ok := v["_key"].(string)
+		if !ok || key == "" {
+			return InvalidParameterError("r", "vertex without key")
+		}
+		if opts.MapKey != nil {
+			keys[key] = opts.MapKey(key)
+		} else {
+			keys[key] = key
+		}
+		v["_key"] = keys[key]
+	}
+	vertexID := func(ref interface{}) (string, error) {
+		key, ok := ref.(string)
+		if !ok || key == "" {
+			return "", InvalidParameterError("r", "edge without source or destination")
+		}
+		if mapped, ok := keys[key]; ok {
+			key = mapped
+		}
+		return string(driver.NewDocumentID(d.vertices.Name(), key)), nil
+	}
+	for _, e := range in.Edges {
+		for _, attr := range []string{"_from", "_to"} {
+			id, err := vertexID(e[attr])
+			if err != nil {
+				return err
+			}
+			e[attr] = id
+		}
+	}
+
+	progress := d.newProgress(ProgressImport, int64(len(in.Vertices)+len(in.Edges)))
+	err := d.edgeTransaction(d.context(ctx), func(ctx context.Context) error {
+		if err := copyDocuments(ctx, d.vertices, progress.iterator(mapIterator(in.Vertices)), d.afterCopy(ctx, changeVertex)); err != nil {
+			return err
+		}
+		if err := copyDocuments(ctx, d.edges, progress.iterator(mapIterator(in.Edges)), d.afterCopy(ctx, changeEdge)); err != nil {
+			return err
+		}
+		return d.checkImportedLoops(ctx, in.Edges)
+	})
+	if err != nil {
+		return err
+	}
+	d.counts.invalidate()
+	progress.done()
+	return nil
+}
+
+// checkImportedLoops returns a loop error, if any of the given (imported)
+// edges closes a loop, i.e. if its destination reaches its source.
+func (d *DAG) checkImportedLoops(ctx context.Context, edges []map[string]interface{}) error {
+	if len(edges) == 0 {
+		return nil
+	}
+	pairs := make([][2]interface{}, len(edges))
+	for i, e := range edges {
+		pairs[i] = [2]interface{}{e["_from"], e["_to"]}
+	}
+	query := `
+FOR p IN @pairs
+  FILTER LENGTH(
+    FOR v IN 1..@maxDepth OUTBOUND p[1] @@edges
+      OPTIONS {bfs: true, uniqueVertices: "global"}
+      FILTER v._id == p[0]
+      LIMIT 1
+      RETURN 1
+  ) > 0
+  LIMIT 1
+  RETURN p`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"pairs":    pairs,
+		"maxDepth": maxDepth,
+	}
+	var loop *[2]driver.DocumentID
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		loop = new([2]driver.DocumentID)
+		return json.Unmarshal(doc, loop)
+	})
+	if err != nil {
+		return err
+	}
+	if loop != nil {
+		return LoopError(d.id(loop[0].Key()), d.id(loop[1].Key()))
+	}
+	return nil
+}
+
+// mapIterator returns an iterator over the given (decoded) documents.
+func mapIterator(docs []map[string]interface{}) documentIterator {
+	return func(fn func(doc json.RawMessage) error) error {
+		for _, doc := range docs {
+			data, err := json.Marshal(doc)
+			if err != nil {
+				return err
+			}
+			if err := fn(data); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// ImportReport is the result of ValidateImport.
+type ImportReport struct {
+
+	// Vertices and Edges are the numbers of vertices and edges to import.
+	Vertices int `json:"vertices"`
+	Edges    int `json:"edges"`
+
+	// DuplicateKeys are the vertex keys occurring multiple times in the import
+	// stream or already existing in the DAG.
+	DuplicateKeys []string `json:"duplicateKeys"`
+
+	// MissingVertices are the keys referred to by edges, but neither
+	// imported nor existing in the DAG.
+	MissingVertices []string `json:"missingVertices"`
+
+	// Cyclic is true, if the imported edges create a cycle.
+	Cyclic bool `json:"cyclic"`
+}
+
+// Valid returns true, if the import passed all checks.
+func (r *ImportReport) Valid() bool {
+	return len(r.DuplicateKeys) == 0 && len(r.MissingVertices) == 0 && !r.Cyclic
+}
+
+// ValidateImport reads a graph (as written by ExportJSON) from r and checks
+// whether importing it (via ImportJSON without key remapping) would succeed
+// and result in a consistent graph, without writing anything. Cycles are
+// checked among the imported edges only. ValidateImport returns an error, if
+// the stream can't be read or decoded (but not for problems found).
+func (d *DAG) ValidateImport(r io.Reader) (*ImportReport, error) {
+	return d.ValidateImportCtx(context.Background(), r)
+}
+
+// ValidateImportCtx is like ValidateImport but uses the given context.
+func (d *DAG) ValidateImportCtx(ctx context.Context, r io.Reader) (*ImportReport, error) {
+	var in jsonImport
+	if err := json.NewDecoder(r).Decode(&in); err != nil {
+		return nil, err
+	}
+	report := &ImportReport{
+		Vertices:        len(in.Vertices),
+		Edges:           len(in.Edges),
+		DuplicateKeys:   []string{},
+		MissingVertices: []string{},
+	}
+	ctx = d.context(ctx)
+	progress := d.newProgress(ProgressValidate, int64(len(in.Vertices)+len(in.Edges)))
+
+	// duplicate keys
+	imported := make(map[string]struct{}, len(in.Vertices))
+	keys := make([]string, 0, len(in.Vertices))
+	for _, v := range in.Vertices {
+		key, _ := v["_key"].(string)
+		if _, ok := imported[key]; ok {
+			report.DuplicateKeys = append(report.DuplicateKeys, key)
+			continue
+		}
+		imported[key] = struct{}{}
+		keys = append(keys, key)
+	}
+	missing, err := d.missingVertices(ctx, keys)
+	if err != nil {
+		return nil, err
+	}
+	isMissing := make(map[string]struct{}, len(missing))
+	for _, key := range missing {
+		isMissing[key] = struct{}{}
+	}
+	for _, key := range keys {
+		if _, ok := isMissing[key]; !ok {
+			report.DuplicateKeys = append(report.DuplicateKeys, key)
+		}
+	}
+	progress.add(len(in.Vertices))
+
+	// missing vertices and cycles
+	edges := make([][2]string, 0, len(in.Edges))
+	var refs []string
+	referenced := make(map[string]struct{})
+	for _, e := range in.Edges {
+		from, _ := e["_from"].(string)
+		to, _ := e["_to"].(string)
+		edges = append(edges, [2]string{from, to})
+		for _, key := range []string{from, to} {
+			if _, ok := imported[key]; ok {
+				continue
+			}
+			if _, ok := referenced[key]; !ok {
+				referenced[key] = struct{}{}
+				refs = append(refs, key)
+			}
+		}
+	}
+	if report.MissingVertices, err = d.missingVertices(ctx, refs); err != nil {
+		return nil, err
+	}
+	if report.MissingVertices == nil {
+		report.MissingVertices = []string{}
+	}
+	report.Cyclic = cyclic(edges)
+	progress.add(len(in.Edges))
+	progress.done()
+	return report, nil
+}
@@ -0,0 +1,116 @@
+package arangodag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestDAG_ImportJSON(t *testing.T) {
+	src := someNewDag(t)
+	_, _ = src.AddVertex(idVertex{MyID: "1"})
+	_, _ = src.AddVertex(idVertex{MyID: "2"})
+	_ = src.AddEdge("1", "2")
+	var buf bytes.Buffer
+	_ = src.ExportJSON(&buf)
+	fixture := buf.String()
+
+	d := someNewDag(t)
+	if err := d.ImportJSON(bytes.NewBufferString(fixture), nil); err != nil {
+		t.Fatalf("failed to ImportJSON(): %v", err)
+	}
+
+	// importing the same fixture again collides
+	if err := d.ImportJSON(bytes.NewBufferString(fixture), nil); err == nil {
+		t.Errorf("ImportJSON() = nil, want error")
+	}
+
+	// unless keys are remapped
+	if err := d.ImportJSON(bytes.NewBufferString(fixture), &ImportOptions{MapKey: PrefixKeys("a_")}); err != nil {
+		t.Fatalf("failed to ImportJSON(): %v", err)
+	}
+	if err := d.ImportJSON(bytes.NewBufferString(fixture), &ImportOptions{MapKey: RegenerateKeys()}); err != nil {
+		t.Fatalf("failed to ImportJSON(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 6 {
+		t.Errorf("GetOrder() = %d, want %d", order, 6)
+	}
+	if size, _ := d.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want %d", size, 3)
+	}
+	if length, _ := d.GetShortestPathLength("a_1", "a_2"); length != 1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 1)
+	}
+}
+
+func TestDAG_ImportJSON_loop(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+
+	// the imported edge closes a loop via the existing edge
+	fixture := `{"vertices": [{"_key": "3"}], "edges": [{"_from": "2", "_to": "3"}, {"_from": "3", "_to": "1"}]}`
+	if err := d.ImportJSON(bytes.NewBufferString(fixture), nil); !IsLoopError(err) {
+		t.Errorf("ImportJSON() = %v, want loop error", err)
+	}
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want %d", order, 2)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+}
+
+func TestDAG_ValidateImport(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+
+	fixture := `{
+  "vertices": [{"_key": "1"}, {"_key": "2"}, {"_key": "3"}, {"_key": "3"}],
+  "edges": [
+    {"_from": "2", "_to": "3"},
+    {"_from": "3", "_to": "2"},
+    {"_from": "1", "_to": "2"},
+    {"_from": "2", "_to": "4"}
+  ]
+}`
+	report, err := d.ValidateImport(bytes.NewBufferString(fixture))
+	if err != nil {
+		t.Fatalf("failed to ValidateImport(): %v", err)
+	}
+	if report.Valid() {
+		t.Errorf("Valid() = true, want false")
+	}
+	if report.Vertices != 4 || report.Edges != 4 {
+		t.Errorf("Vertices, Edges = %d, %d, want 4, 4", report.Vertices, report.Edges)
+	}
+	if len(report.DuplicateKeys) != 2 {
+		t.Errorf("DuplicateKeys = %v, want [3 1]", report.DuplicateKeys)
+	}
+	if len(report.MissingVertices) != 1 || report.MissingVertices[0] != "4" {
+		t.Errorf("MissingVertices = %v, want [4]", report.MissingVertices)
+	}
+	if !report.Cyclic {
+		t.Errorf("Cyclic = false, want true")
+	}
+
+	// nothing was written
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+
+	// a valid import
+	fixture = `{"vertices": [{"_key": "2"}], "edges": [{"_from": "1", "_to": "2"}]}`
+	report, err = d.ValidateImport(bytes.NewBufferString(fixture))
+	if err != nil {
+		t.Fatalf("failed to ValidateImport(): %v", err)
+	}
+	if !report.Valid() {
+		t.Errorf("Valid() = false, want true (%+v)", report)
+	}
+
+	// a broken stream
+	if _, err := d.ValidateImport(bytes.NewBufferString("{")); err == nil {
+		t.Errorf("ValidateImport() = nil, want error")
+	}
+}
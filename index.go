@@ -0,0 +1,78 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+)
+
+// IndexType is the type of an index (see EnsureVertexIndex).
+type IndexType string
+
+// Index types
+const (
+	IndexPersistent IndexType = "persistent"
+	IndexHash       IndexType = "hash"
+	IndexSkipList   IndexType = "skiplist"
+	IndexFullText   IndexType = "fulltext"
+)
+
+// EnsureVertexIndex creates an index of the given type on the given (dot
+// separated) attribute paths of the vertex collection, unless such an index
+// exists already. The paths are relative to the stored document, thus,
+// "payload.team" refers to the attribute "team" of the vertices themselves.
+// EnsureVertexIndex returns true, if the index was created. EnsureVertexIndex
+// returns an error, if fields is empty or if the index type is unknown.
+func (d *DAG) EnsureVertexIndex(fields []string, typ IndexType) (bool, error) {
+	return d.EnsureVertexIndexCtx(context.Background(), fields, typ)
+}
+
+// EnsureVertexIndexCtx is like EnsureVertexIndex but uses the given context.
+func (d *DAG) EnsureVertexIndexCtx(ctx context.Context, fields []string, typ IndexType) (bool, error) {
+	return d.ensureIndex(ctx, d.vertices, fields, typ)
+}
+
+// EnsureEdgeIndex creates an index of the given type on the given attributes
+// of the edge collection (see EnsureVertexIndex).
+func (d *DAG) EnsureEdgeIndex(fields []string, typ IndexType) (bool, error) {
+	return d.EnsureEdgeIndexCtx(context.Background(), fields, typ)
+}
+
+// EnsureEdgeIndexCtx is like EnsureEdgeIndex but uses the given context.
+func (d *DAG) EnsureEdgeIndexCtx(ctx context.Context, fields []string, typ IndexType) (bool, error) {
+	return d.ensureIndex(ctx, d.edges, fields, typ)
+}
+
+// ensureIndex creates an index of the given type on the given fields of the
+// given collection, unless such an index exists already.
+func (d *DAG) ensureIndex(ctx context.Context, coll driver.Collection, fields []string, typ IndexType) (bool, error) {
+	if len(fields) == 0 {
+		return false, InvalidIndexError("no fields given")
+	}
+	for _, field := range fields {
+		if field == "" {
+			return false, InvalidIndexError("empty field")
+		}
+	}
+	ctx = d.context(ctx)
+	var created bool
+	var err error
+	switch typ {
+	case IndexPersistent:
+		_, created, err = coll.EnsurePersistentIndex(ctx, fields, nil)
+	case IndexHash:
+		_, created, err = coll.EnsureHashIndex(ctx, fields, nil)
+	case IndexSkipList:
+		_, created, err = coll.EnsureSkipListIndex(ctx, fields, nil)
+	case IndexFullText:
+		if len(fields) != 1 {
+			return false, InvalidIndexError("fulltext indexes cover exactly one field")
+		}
+		_, created, err = coll.EnsureFullTextIndex(ctx, fields, nil)
+	default:
+		return false, InvalidIndexError("unknown index type '" + string(typ) + "'")
+	}
+	if err != nil {
+		return false, arangoError(err)
+	}
+	return created, nil
+}
@@ -0,0 +1,42 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_EnsureVertexIndex(t *testing.T) {
+	d := someNewDag(t)
+
+	created, err := d.EnsureVertexIndex([]string{"payload.team"}, IndexPersistent)
+	if err != nil {
+		t.Fatalf("failed to EnsureVertexIndex(): %v", err)
+	}
+	if !created {
+		t.Errorf("EnsureVertexIndex() = %v, want %v", created, true)
+	}
+
+	// idempotent
+	if created, _ = d.EnsureVertexIndex([]string{"payload.team"}, IndexPersistent); created {
+		t.Errorf("EnsureVertexIndex() = %v, want %v", created, false)
+	}
+
+	// invalid
+	if _, err := d.EnsureVertexIndex(nil, IndexPersistent); !IsInvalidIndexError(err) {
+		t.Errorf("want InvalidIndexError, got %v", err)
+	}
+	if _, err := d.EnsureVertexIndex([]string{"payload.team"}, "foo"); !IsInvalidIndexError(err) {
+		t.Errorf("want InvalidIndexError, got %v", err)
+	}
+}
+
+func TestDAG_EnsureEdgeIndex(t *testing.T) {
+	d := someNewDag(t)
+
+	created, err := d.EnsureEdgeIndex([]string{"weight"}, IndexSkipList)
+	if err != nil {
+		t.Fatalf("failed to EnsureEdgeIndex(): %v", err)
+	}
+	if !created {
+		t.Errorf("EnsureEdgeIndex() = %v, want %v", created, true)
+	}
+}
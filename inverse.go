@@ -0,0 +1,92 @@
+package arangodag
+
+import (
+	"context"
+
+	"github.com/arangodb/go-driver"
+)
+
+// WithInverseEdges enables maintaining an inverse edge collection (named after
+// the edge collection with the suffix "_inverse"), mirroring each edge with
+// "_from" and "_to" swapped (and the same key). The inverse edges are written
+// within the transaction of each mutation. Ancestor walks (e.g.
+// WalkAncestorsWithDepth and WalkRootsOf) traverse the inverse edges
+// outbound rather than the edges inbound, which may perform better for
+// inbound heavy workloads (depending on the setup). Mutations not recorded
+// as changes (e.g. writing to the edge collection directly) aren't mirrored,
+// see RebuildInverseEdges.
+func WithInverseEdges() Option {
+	return func(d *DAG) {
+		d.inverseEdges = true
+	}
+}
+
+// InverseEdgeCollection returns the inverse edge collection (see
+// WithInverseEdges), or nil, if not enabled.
+func (d *DAG) InverseEdgeCollection() driver.Collection {
+	return d.inverse
+}
+
+// traversal returns the direction and the name of the edge collection to
+// traverse the edges in the given direction ("OUTBOUND" or "INBOUND") with.
+// With inverse edges enabled, inbound traversals become outbound traversals
+// of the inverse edges.
+func (d *DAG) traversal(direction string) (string, string) {
+	if d.inverse != nil && direction == "INBOUND" {
+		return "OUTBOUND", d.inverse.Name()
+	}
+	return direction, d.edges.Name()
+}
+
+// mirrorChanges applies the given operation on the edges with the given
+// document ids to the inverse edges (if enabled).
+func (d *DAG) mirrorChanges(ctx context.Context, op string, ids ...driver.DocumentID) error {
+	if d.inverse == nil || len(ids) == 0 {
+		return nil
+	}
+	query := `
+FOR id IN @ids
+  LET e = DOCUMENT(id)
+  FILTER e != null
+  LET inverse = MERGE(UNSET(e, "_id", "_rev"), {_from: e._to, _to: e._from})
+  UPSERT {_key: e._key} INSERT inverse REPLACE inverse IN @@inverse`
+	if op == changeRemove {
+		query = `
+FOR id IN @ids
+  REMOVE PARSE_IDENTIFIER(id).key IN @@inverse OPTIONS {ignoreErrors: true}`
+	}
+	bindVars := map[string]interface{}{
+		"@inverse": d.inverse.Name(),
+		"ids":      ids,
+	}
+	return d.exec(ctx, query, bindVars)
+}
+
+// RebuildInverseEdges replaces the inverse edges (see WithInverseEdges) by the
+// mirrored edges within a single transaction. RebuildInverseEdges returns an
+// error, if inverse edges are not enabled.
+func (d *DAG) RebuildInverseEdges() error {
+	return d.RebuildInverseEdgesCtx(context.Background())
+}
+
+// RebuildInverseEdgesCtx is like RebuildInverseEdges but uses the given
+// context.
+func (d *DAG) RebuildInverseEdgesCtx(ctx context.Context) error {
+	if d.inverse == nil {
+		return InvalidParameterError("inverse", "inverse edges are not enabled")
+	}
+	return d.transaction(d.context(ctx), func(ctx context.Context) error {
+		query := "FOR e IN @@inverse REMOVE e IN @@inverse"
+		bindVars := map[string]interface{}{
+			"@inverse": d.inverse.Name(),
+		}
+		if err := d.exec(ctx, query, bindVars); err != nil {
+			return err
+		}
+		query = `
+FOR e IN @@edges
+  INSERT MERGE(UNSET(e, "_id", "_rev"), {_from: e._to, _to: e._from}) INTO @@inverse`
+		bindVars["@edges"] = d.edges.Name()
+		return d.exec(ctx, query, bindVars)
+	})
+}
@@ -0,0 +1,51 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestWithInverseEdges(t *testing.T) {
+	d := someNewDag(t, WithInverseEdges())
+	ctx := context.Background()
+
+	// 1 -> 2 -> 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	if count, _ := d.InverseEdgeCollection().Count(ctx); count != 2 {
+		t.Errorf("inverse edges = %d, want 2", count)
+	}
+	var roots []string
+	err := d.WalkRootsOf("3", func(id string) error {
+		roots = append(roots, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkRootsOf(): %v", err)
+	}
+	if diff := deep.Equal(roots, []string{"1"}); diff != nil {
+		t.Error(diff)
+	}
+
+	_ = d.DeleteEdge("1", "2")
+	if count, _ := d.InverseEdgeCollection().Count(ctx); count != 1 {
+		t.Errorf("inverse edges = %d, want 1", count)
+	}
+
+	if err := d.RebuildInverseEdges(); err != nil {
+		t.Fatalf("failed to RebuildInverseEdges(): %v", err)
+	}
+	if count, _ := d.InverseEdgeCollection().Count(ctx); count != 1 {
+		t.Errorf("inverse edges = %d, want 1", count)
+	}
+
+	if err := someNewDag(t).RebuildInverseEdges(); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+}
@@ -0,0 +1,240 @@
+package arangodag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// structure is the in-memory structure of a (small or medium) graph, as used
+// for canonical hashing and isomorphism checks.
+type structure struct {
+	ids      []string
+	labels   map[string]string
+	children map[string]map[string]struct{}
+	parents  map[string]map[string]struct{}
+	size     int
+}
+
+// loadStructure reads the structure of the graph (and the canonically encoded
+// vertex payloads as labels, if withPayloads is true) within a consistent
+// snapshot.
+func (d *DAG) loadStructure(ctx context.Context, withPayloads bool) (*structure, error) {
+	s := &structure{
+		labels:   make(map[string]string),
+		children: make(map[string]map[string]struct{}),
+		parents:  make(map[string]map[string]struct{}),
+	}
+	err := d.readTransaction(d.context(ctx), func(ctx context.Context) error {
+		query := `
+FOR v IN @@vertices
+  SORT v._key
+  RETURN {id: v._key, payload: @withPayloads ? v.payload : null}`
+		bindVars := map[string]interface{}{
+			"@vertices":    d.vertices.Name(),
+			"withPayloads": withPayloads,
+		}
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var item struct {
+				ID      string      `json:"id"`
+				Payload interface{} `json:"payload"`
+			}
+			if err := json.Unmarshal(doc, &item); err != nil {
+				return err
+			}
+
+			// encoding/json sorts the keys of maps, thus, the encoding is canonical
+			label, err := json.Marshal(item.Payload)
+			if err != nil {
+				return err
+			}
+			s.ids = append(s.ids, item.ID)
+			s.labels[item.ID] = string(label)
+			s.children[item.ID] = make(map[string]struct{})
+			s.parents[item.ID] = make(map[string]struct{})
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		query = "FOR e IN @@edges RETURN [PARSE_IDENTIFIER(e._from).key, PARSE_IDENTIFIER(e._to).key]"
+		bindVars = map[string]interface{}{
+			"@edges": d.edges.Name(),
+		}
+		return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var edge [2]string
+			if err := json.Unmarshal(doc, &edge); err != nil {
+				return err
+			}
+			if s.children[edge[0]] == nil || s.parents[edge[1]] == nil {
+				return nil // dangling
+			}
+			s.children[edge[0]][edge[1]] = struct{}{}
+			s.parents[edge[1]][edge[0]] = struct{}{}
+			s.size++
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// colors returns a color per vertex, such that isomorphic vertices (i.e.
+// vertices mapped onto each other by an isomorphism) have the same color. The
+// colors are refined (Weisfeiler-Lehman like) by the colors of the parents
+// and the children until the partition is stable.
+func (s *structure) colors() map[string]string {
+	colors := make(map[string]string, len(s.ids))
+	for _, id := range s.ids {
+		colors[id] = hash(s.labels[id])
+	}
+	classes := countDistinct(colors)
+	for {
+		next := make(map[string]string, len(s.ids))
+		for _, id := range s.ids {
+			next[id] = hash(colors[id], neighbourColors(colors, s.parents[id]), neighbourColors(colors, s.children[id]))
+		}
+		colors = next
+		n := countDistinct(colors)
+		if n == classes {
+			return colors
+		}
+		classes = n
+	}
+}
+
+// canonicalHash returns a hash over the (final) colors of the vertices and the
+// edges, which doesn't depend on the vertex ids.
+func (s *structure) canonicalHash(colors map[string]string) string {
+	vertices := make([]string, 0, len(s.ids))
+	var edges []string
+	for _, id := range s.ids {
+		vertices = append(vertices, colors[id])
+		for child := range s.children[id] {
+			edges = append(edges, colors[id]+">"+colors[child])
+		}
+	}
+	sort.Strings(vertices)
+	sort.Strings(edges)
+	return hash(strings.Join(vertices, ","), strings.Join(edges, ","))
+}
+
+// neighbourColors returns the sorted colors of the given neighbours.
+func neighbourColors(colors map[string]string, neighbours map[string]struct{}) string {
+	c := make([]string, 0, len(neighbours))
+	for id := range neighbours {
+		c = append(c, colors[id])
+	}
+	sort.Strings(c)
+	return strings.Join(c, ",")
+}
+
+// countDistinct returns the number of distinct colors.
+func countDistinct(colors map[string]string) int {
+	distinct := make(map[string]struct{})
+	for _, c := range colors {
+		distinct[c] = struct{}{}
+	}
+	return len(distinct)
+}
+
+// hash returns the (hex encoded) SHA-256 hash of the given parts.
+func hash(parts ...string) string {
+	h := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return hex.EncodeToString(h[:])
+}
+
+// CanonicalHash returns a hash over the structure of the graph not depending on
+// the vertex ids, i.e. isomorphic graphs have the same hash (e.g. to
+// deduplicate template graphs). If withPayloads is true, the (canonically
+// encoded) vertex payloads are taken into account too. Graphs with the same
+// hash are very likely isomorphic (see IsIsomorphicTo). The graph is read into
+// memory, thus, CanonicalHash is meant for small to medium graphs.
+func (d *DAG) CanonicalHash(withPayloads bool) (string, error) {
+	return d.CanonicalHashCtx(context.Background(), withPayloads)
+}
+
+// CanonicalHashCtx is like CanonicalHash but uses the given context.
+func (d *DAG) CanonicalHashCtx(ctx context.Context, withPayloads bool) (string, error) {
+	s, err := d.loadStructure(ctx, withPayloads)
+	if err != nil {
+		return "", err
+	}
+	return s.canonicalHash(s.colors()), nil
+}
+
+// IsIsomorphicTo returns true, if the graph is isomorphic to the graph of
+// other, i.e. if there is a bijection of the vertices preserving the edges (and
+// the payloads, if withPayloads is true). Both graphs are read into memory,
+// thus, IsIsomorphicTo is meant for small to medium graphs.
+func (d *DAG) IsIsomorphicTo(other *DAG, withPayloads bool) (bool, error) {
+	return d.IsIsomorphicToCtx(context.Background(), other, withPayloads)
+}
+
+// IsIsomorphicToCtx is like IsIsomorphicTo but uses the given context.
+func (d *DAG) IsIsomorphicToCtx(ctx context.Context, other *DAG, withPayloads bool) (bool, error) {
+	s1, err := d.loadStructure(ctx, withPayloads)
+	if err != nil {
+		return false, err
+	}
+	s2, err := other.loadStructure(ctx, withPayloads)
+	if err != nil {
+		return false, err
+	}
+	if len(s1.ids) != len(s2.ids) || s1.size != s2.size {
+		return false, nil
+	}
+	c1, c2 := s1.colors(), s2.colors()
+	if s1.canonicalHash(c1) != s2.canonicalHash(c2) {
+		return false, nil
+	}
+
+	// backtracking over the candidates of the same color
+	candidates := make(map[string][]string)
+	for _, id := range s2.ids {
+		candidates[c2[id]] = append(candidates[c2[id]], id)
+	}
+	mapping := make(map[string]string, len(s1.ids))
+	used := make(map[string]bool, len(s2.ids))
+	var match func(i int) bool
+	match = func(i int) bool {
+		if i == len(s1.ids) {
+			return true
+		}
+		id := s1.ids[i]
+		for _, candidate := range candidates[c1[id]] {
+			if used[candidate] || !s1.consistent(s2, mapping, id, candidate) {
+				continue
+			}
+			mapping[id] = candidate
+			used[candidate] = true
+			if match(i + 1) {
+				return true
+			}
+			delete(mapping, id)
+			used[candidate] = false
+		}
+		return false
+	}
+	return match(0), nil
+}
+
+// consistent returns true, if mapping id (of s) to candidate (of other)
+// preserves the edges between id and the vertices mapped already.
+func (s *structure) consistent(other *structure, mapping map[string]string, id, candidate string) bool {
+	for mapped, image := range mapping {
+		_, child := s.children[id][mapped]
+		_, otherChild := other.children[candidate][image]
+		_, parent := s.parents[id][mapped]
+		_, otherParent := other.parents[candidate][image]
+		if child != otherChild || parent != otherParent {
+			return false
+		}
+	}
+	return true
+}
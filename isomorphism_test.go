@@ -0,0 +1,66 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_IsIsomorphicTo(t *testing.T) {
+	d1 := someNewDag(t)
+	d2 := someNewDag(t)
+	d3 := someNewDag(t)
+
+	// diamonds with different ids: a -> b, a -> c, b -> d, c -> d
+	for _, id := range []string{"a", "b", "c", "d"} {
+		_, _ = d1.AddVertex(idVertex{MyID: id})
+		_, _ = d2.AddVertex(idVertex{MyID: "x" + id})
+		_, _ = d3.AddVertex(idVertex{MyID: id})
+	}
+	for _, e := range [][2]string{{"a", "b"}, {"a", "c"}, {"b", "d"}, {"c", "d"}} {
+		_ = d1.AddEdge(e[0], e[1])
+		_ = d2.AddEdge("x"+e[1], "x"+e[0])
+	}
+
+	// chain with a shortcut: a -> b, b -> c, c -> d, a -> d
+	for _, e := range [][2]string{{"a", "b"}, {"b", "c"}, {"c", "d"}, {"a", "d"}} {
+		_ = d3.AddEdge(e[0], e[1])
+	}
+
+	iso, err := d1.IsIsomorphicTo(d2, false)
+	if err != nil {
+		t.Fatalf("failed to IsIsomorphicTo(): %v", err)
+	}
+	if !iso {
+		t.Error("IsIsomorphicTo() = false, want true")
+	}
+	if iso, _ := d1.IsIsomorphicTo(d3, false); iso {
+		t.Error("IsIsomorphicTo() = true, want false")
+	}
+
+	// payloads differ (by their ids)
+	if iso, _ := d1.IsIsomorphicTo(d2, true); iso {
+		t.Error("IsIsomorphicTo() = true, want false")
+	}
+
+	h1, err := d1.CanonicalHash(false)
+	if err != nil {
+		t.Fatalf("failed to CanonicalHash(): %v", err)
+	}
+	h2, _ := d2.CanonicalHash(false)
+	h3, _ := d3.CanonicalHash(false)
+	if h1 != h2 || h1 == h3 {
+		t.Errorf("CanonicalHash() = %s, %s, %s, want the first two to be equal only", h1, h2, h3)
+	}
+}
+
+func TestStructure_Colors(t *testing.T) {
+	s := &structure{
+		ids:      []string{"1", "2", "3"},
+		labels:   map[string]string{"1": "null", "2": "null", "3": "null"},
+		children: map[string]map[string]struct{}{"1": {"2": {}, "3": {}}, "2": {}, "3": {}},
+		parents:  map[string]map[string]struct{}{"1": {}, "2": {"1": {}}, "3": {"1": {}}},
+	}
+	colors := s.colors()
+	if colors["2"] != colors["3"] || colors["1"] == colors["2"] {
+		t.Errorf("colors() = %v, want 2 and 3 to be colored alike only", colors)
+	}
+}
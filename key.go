@@ -0,0 +1,120 @@
+package arangodag
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// keySeparator separates the parts of composite keys (see ComposeKey).
+const keySeparator = ':'
+
+// WithKeyEncoding enables encoding vertex ids into valid keys (see EncodeKey),
+// such that arbitrary (business) identifiers (e.g. "pkg:npm/lodash@4.17.21")
+// may be used as ids. Ids passed to the API are encoded and all ids returned
+// by the API (including those of errors, DOT exports, and rewrite matches)
+// are decoded again. Only raw documents (e.g. JSON exports and backups) hold
+// the encoded keys (see DecodeKey).
+func WithKeyEncoding() Option {
+	return func(d *DAG) {
+		d.keyEncoding = true
+	}
+}
+
+// isKeyChar returns true, if c is a valid character of keys (besides "%",
+// which is used for escaping).
+func isKeyChar(c byte) bool {
+	return 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z' || '0' <= c && c <= '9' ||
+		strings.IndexByte("_-:.@()+,=;$!*'", c) >= 0
+}
+
+// EncodeKey returns the given identifier as valid key. All characters not
+// allowed in keys (as well as "%") are replaced by "%" followed by the two
+// hex digits of their (UTF-8) bytes. Thus, valid keys without "%" are left
+// as is.
+func EncodeKey(id string) string {
+	return encodeKey(id, "")
+}
+
+// encodeKey encodes id (see EncodeKey), also escaping the given characters.
+func encodeKey(id, escape string) string {
+	var b strings.Builder
+	for i := 0; i < len(id); i++ {
+		c := id[i]
+		if isKeyChar(c) && strings.IndexByte(escape, c) < 0 {
+			b.WriteByte(c)
+		} else {
+			_, _ = fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+// DecodeKey returns the identifier encoded by EncodeKey. DecodeKey returns an
+// error, if key contains invalid escape sequences.
+func DecodeKey(key string) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(key); i++ {
+		if key[i] != '%' {
+			b.WriteByte(key[i])
+			continue
+		}
+		if i+2 >= len(key) {
+			return "", fmt.Errorf("invalid escape sequence in key '%s'", key)
+		}
+		c, err := strconv.ParseUint(key[i+1:i+3], 16, 8)
+		if err != nil {
+			return "", fmt.Errorf("invalid escape sequence in key '%s'", key)
+		}
+		b.WriteByte(byte(c))
+		i += 2
+	}
+	return b.String(), nil
+}
+
+// ComposeKey returns a valid key composed of the given parts (e.g. a namespace
+// and an identifier). The parts are encoded (see EncodeKey, additionally
+// escaping ":") and separated by ":", thus, different parts never result in
+// the same key.
+func ComposeKey(parts ...string) string {
+	encoded := make([]string, len(parts))
+	for i, part := range parts {
+		encoded[i] = encodeKey(part, string(keySeparator))
+	}
+	return strings.Join(encoded, string(keySeparator))
+}
+
+// SplitKey returns the parts of a key composed by ComposeKey. SplitKey returns
+// an error, if key contains invalid escape sequences.
+func SplitKey(key string) ([]string, error) {
+	encoded := strings.Split(key, string(keySeparator))
+	parts := make([]string, len(encoded))
+	for i, part := range encoded {
+		var err error
+		if parts[i], err = DecodeKey(part); err != nil {
+			return nil, err
+		}
+	}
+	return parts, nil
+}
+
+// key returns the key of the vertex with the given id (see WithKeyEncoding).
+func (d *DAG) key(id string) string {
+	if !d.keyEncoding {
+		return id
+	}
+	return EncodeKey(id)
+}
+
+// id returns the id of the vertex with the given key (see WithKeyEncoding).
+// Keys not being valid encodings are returned as is.
+func (d *DAG) id(key string) string {
+	if !d.keyEncoding {
+		return key
+	}
+	id, err := DecodeKey(key)
+	if err != nil {
+		return key
+	}
+	return id
+}
@@ -0,0 +1,94 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestEncodeKey(t *testing.T) {
+	for _, id := range []string{"foo", "pkg:npm/lodash@4.17.21", "a b/c?d#e", "100%", "äöü", ""} {
+		key := EncodeKey(id)
+		for i := 0; i < len(key); i++ {
+			if !isKeyChar(key[i]) && key[i] != '%' {
+				t.Errorf("EncodeKey(%q) = %q, contains invalid character %q", id, key, key[i])
+			}
+		}
+		decoded, err := DecodeKey(key)
+		if err != nil {
+			t.Errorf("DecodeKey(%q) failed: %v", key, err)
+		}
+		if decoded != id {
+			t.Errorf("DecodeKey(EncodeKey(%q)) = %q", id, decoded)
+		}
+	}
+	if key := EncodeKey("foo:bar"); key != "foo:bar" {
+		t.Errorf("EncodeKey(\"foo:bar\") = %q, want \"foo:bar\"", key)
+	}
+	for _, key := range []string{"%", "%4", "%zz"} {
+		if _, err := DecodeKey(key); err == nil {
+			t.Errorf("DecodeKey(%q) should fail", key)
+		}
+	}
+}
+
+func TestComposeKey(t *testing.T) {
+	a := ComposeKey("a:b", "c")
+	b := ComposeKey("a", "b:c")
+	if a == b {
+		t.Errorf("ComposeKey() collision: %q", a)
+	}
+	parts, err := SplitKey(a)
+	if err != nil {
+		t.Fatalf("failed to SplitKey(): %v", err)
+	}
+	if diff := deep.Equal(parts, []string{"a:b", "c"}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDAG_WithKeyEncoding(t *testing.T) {
+	d := someNewDag(t, WithKeyEncoding())
+
+	id1 := "pkg:npm/lodash@4.17.21"
+	id2 := "pkg:npm/left pad"
+	for _, id := range []string{id1, id2} {
+		got, err := d.AddVertex(idVertex{MyID: id})
+		if err != nil {
+			t.Fatalf("failed to AddVertex(): %v", err)
+		}
+		if got != id {
+			t.Errorf("AddVertex() = %q, want %q", got, id)
+		}
+	}
+	if err := d.AddEdge(id1, id2); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	var v idVertex
+	if err := d.GetVertex(id2, &v); err != nil {
+		t.Fatalf("failed to GetVertex(): %v", err)
+	}
+	var visited []string
+	err := d.WalkDescendantsMulti([]string{id1}, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsMulti(): %v", err)
+	}
+	if diff := deep.Equal(visited, []string{id2}); diff != nil {
+		t.Error(diff)
+	}
+
+	// errors report the ids (rather than the keys)
+	err = d.DeleteEdge(id2, id1)
+	if diff := deep.Equal(err, UnknownEdgeError(id2, id1)); diff != nil {
+		t.Errorf("DeleteEdge(): %v", diff)
+	}
+
+	// without encoding
+	d2 := someNewDag(t)
+	if _, err := d2.AddVertex(idVertex{MyID: id2}); !IsInvalidIDError(err) {
+		t.Errorf("want InvalidIDError, got %v", err)
+	}
+}
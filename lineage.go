@@ -0,0 +1,237 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"io"
+	"time"
+)
+
+// Types of vertices created from OpenLineage events.
+const (
+	LineageJob     = "job"
+	LineageDataset = "dataset"
+)
+
+const (
+	lineageProducer  = "https://github.com/heimdalr/arangodag"
+	lineageSchemaURL = "https://openlineage.io/spec/1-0-5/OpenLineage.json#/definitions/RunEvent"
+)
+
+// LineageEvent is an OpenLineage run event (see https://openlineage.io).
+type LineageEvent struct {
+	EventType string          `json:"eventType,omitempty"`
+	EventTime time.Time       `json:"eventTime"`
+	Run       LineageRun      `json:"run"`
+	Job       LineageEntity   `json:"job"`
+	Inputs    []LineageEntity `json:"inputs,omitempty"`
+	Outputs   []LineageEntity `json:"outputs,omitempty"`
+	Producer  string          `json:"producer"`
+	SchemaURL string          `json:"schemaURL"`
+}
+
+// LineageRun is the run of an OpenLineage event.
+type LineageRun struct {
+	RunID  string                 `json:"runId"`
+	Facets map[string]interface{} `json:"facets,omitempty"`
+}
+
+// LineageEntity is a job or a dataset of an OpenLineage event.
+type LineageEntity struct {
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+}
+
+// LineageVertex is the payload of vertices created from OpenLineage events.
+// For jobs, Run and EventTime refer to the most recent event imported.
+type LineageVertex struct {
+	Type      string                 `json:"type"`
+	Namespace string                 `json:"namespace"`
+	Name      string                 `json:"name"`
+	Facets    map[string]interface{} `json:"facets,omitempty"`
+	Run       *LineageRun            `json:"run,omitempty"`
+	EventTime *time.Time             `json:"eventTime,omitempty"`
+}
+
+// LineageKey returns the id of the vertex representing the job or dataset
+// (as given by typ) with the given namespace and name.
+func LineageKey(typ, namespace, name string) string {
+	return hashKey(typ, namespace, name)
+}
+
+// ImportLineageEvent adds the job and the datasets of the given OpenLineage
+// event to the DAG. Inputs are connected to the job and the job is connected
+// to the outputs. Known vertices are updated (i.e. the facets and the run are
+// replaced) and known edges are kept. The event is imported within a single
+// transaction. ImportLineageEvent returns an error (importing nothing), if
+// connecting the datasets would create a loop.
+func (d *DAG) ImportLineageEvent(e LineageEvent) error {
+	return d.ImportLineageEventCtx(context.Background(), e)
+}
+
+// ImportLineageEventCtx is like ImportLineageEvent but uses the given context.
+func (d *DAG) ImportLineageEventCtx(ctx context.Context, e LineageEvent) error {
+	err := d.edgeTransaction(d.context(ctx), func(ctx context.Context) error {
+		return d.importLineageEvent(ctx, e)
+	})
+	d.counts.invalidate()
+	return err
+}
+
+// importLineageEvent imports the given event (see ImportLineageEvent).
+func (d *DAG) importLineageEvent(ctx context.Context, e LineageEvent) error {
+	eventTime := e.EventTime
+	run := e.Run
+	jobID, err := d.upsertLineageVertex(ctx, LineageVertex{
+		Type:      LineageJob,
+		Namespace: e.Job.Namespace,
+		Name:      e.Job.Name,
+		Facets:    e.Job.Facets,
+		Run:       &run,
+		EventTime: &eventTime,
+	})
+	if err != nil {
+		return err
+	}
+	for _, input := range e.Inputs {
+		id, err := d.upsertLineageDataset(ctx, input)
+		if err != nil {
+			return err
+		}
+		if err := d.AddEdgeCtx(ctx, id, jobID); err != nil && !IsDuplicateEdgeError(err) {
+			return err
+		}
+	}
+	for _, output := range e.Outputs {
+		id, err := d.upsertLineageDataset(ctx, output)
+		if err != nil {
+			return err
+		}
+		if err := d.AddEdgeCtx(ctx, jobID, id); err != nil && !IsDuplicateEdgeError(err) {
+			return err
+		}
+	}
+	return nil
+}
+
+// ImportLineage imports the (newline delimited or concatenated) OpenLineage
+// events read from r (see ImportLineageEvent) within a single transaction.
+func (d *DAG) ImportLineage(r io.Reader) error {
+	return d.ImportLineageCtx(context.Background(), r)
+}
+
+// ImportLineageCtx is like ImportLineage but uses the given context.
+func (d *DAG) ImportLineageCtx(ctx context.Context, r io.Reader) error {
+	dec := json.NewDecoder(r)
+	err := d.edgeTransaction(d.context(ctx), func(ctx context.Context) error {
+		for {
+			var e LineageEvent
+			err := dec.Decode(&e)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			if err := d.importLineageEvent(ctx, e); err != nil {
+				return err
+			}
+		}
+	})
+	d.counts.invalidate()
+	return err
+}
+
+// ExportLineage writes one OpenLineage event (newline delimited) per job to w.
+// The event's inputs and outputs are the job's parents and children.
+func (d *DAG) ExportLineage(w io.Writer) error {
+	return d.ExportLineageCtx(context.Background(), w)
+}
+
+// ExportLineageCtx is like ExportLineage but uses the given context.
+func (d *DAG) ExportLineageCtx(ctx context.Context, w io.Writer) error {
+	ctx = d.context(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER v.payload.type == @job
+  LET inputs = (FOR p IN 1..1 INBOUND v @@edges FILTER p.payload.type == @dataset RETURN p.payload)
+  LET outputs = (FOR c IN 1..1 OUTBOUND v @@edges FILTER c.payload.type == @dataset RETURN c.payload)
+  RETURN {job: v.payload, inputs: inputs, outputs: outputs}`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+		"job":       LineageJob,
+		"dataset":   LineageDataset,
+	}
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	defer closeCursor(cursor)
+
+	enc := json.NewEncoder(w)
+	for {
+		var item struct {
+			Job     LineageVertex   `json:"job"`
+			Inputs  []LineageVertex `json:"inputs"`
+			Outputs []LineageVertex `json:"outputs"`
+		}
+		_, err := cursor.ReadDocument(ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			return nil
+		} else if err != nil {
+			return arangoError(err)
+		}
+		if err := enc.Encode(item.Job.event(item.Inputs, item.Outputs)); err != nil {
+			return err
+		}
+	}
+}
+
+// event returns an OpenLineage event for the (job) vertex v.
+func (v LineageVertex) event(inputs, outputs []LineageVertex) LineageEvent {
+	e := LineageEvent{
+		EventType: "COMPLETE",
+		Job:       v.entity(),
+		Producer:  lineageProducer,
+		SchemaURL: lineageSchemaURL,
+	}
+	if v.Run != nil {
+		e.Run = *v.Run
+	}
+	if v.EventTime != nil {
+		e.EventTime = *v.EventTime
+	}
+	for _, input := range inputs {
+		e.Inputs = append(e.Inputs, input.entity())
+	}
+	for _, output := range outputs {
+		e.Outputs = append(e.Outputs, output.entity())
+	}
+	return e
+}
+
+func (v LineageVertex) entity() LineageEntity {
+	return LineageEntity{Namespace: v.Namespace, Name: v.Name, Facets: v.Facets}
+}
+
+func (d *DAG) upsertLineageDataset(ctx context.Context, e LineageEntity) (string, error) {
+	return d.upsertLineageVertex(ctx, LineageVertex{
+		Type:      LineageDataset,
+		Namespace: e.Namespace,
+		Name:      e.Name,
+		Facets:    e.Facets,
+	})
+}
+
+// upsertLineageVertex adds or updates the vertex representing v and returns
+// its id.
+func (d *DAG) upsertLineageVertex(ctx context.Context, v LineageVertex) (string, error) {
+	id := LineageKey(v.Type, v.Namespace, v.Name)
+	if err := d.upsertVertex(ctx, id, v); err != nil {
+		return "", err
+	}
+	return id, nil
+}
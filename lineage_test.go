@@ -0,0 +1,74 @@
+package arangodag
+
+import (
+	"bytes"
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+const lineageEvents = `
+{"eventType": "COMPLETE", "eventTime": "2021-01-01T10:00:00Z", "run": {"runId": "r1"}, "job": {"namespace": "airflow", "name": "extract"}, "inputs": [{"namespace": "pg", "name": "raw"}], "outputs": [{"namespace": "s3", "name": "staged"}]}
+{"eventType": "COMPLETE", "eventTime": "2021-01-01T11:00:00Z", "run": {"runId": "r2"}, "job": {"namespace": "airflow", "name": "load"}, "inputs": [{"namespace": "s3", "name": "staged"}], "outputs": [{"namespace": "dwh", "name": "facts"}]}
+`
+
+func TestDAG_ImportLineage(t *testing.T) {
+	d := someNewDag(t)
+
+	// import twice to check that known vertices and edges are kept
+	for i := 0; i < 2; i++ {
+		if err := d.ImportLineage(strings.NewReader(lineageEvents)); err != nil {
+			t.Fatalf("failed to ImportLineage(): %v", err)
+		}
+	}
+	if order, _ := d.GetOrder(); order != 5 {
+		t.Errorf("GetOrder() = %d, want %d", order, 5)
+	}
+	if size, _ := d.GetSize(); size != 4 {
+		t.Errorf("GetSize() = %d, want %d", size, 4)
+	}
+
+	var v LineageVertex
+	if err := d.GetVertex(LineageKey(LineageJob, "airflow", "load"), &v); err != nil {
+		t.Fatalf("failed to GetVertex(): %v", err)
+	}
+	if v.Run == nil || v.Run.RunID != "r2" {
+		t.Errorf("GetVertex() = %v, want run 'r2'", v)
+	}
+
+	var buf bytes.Buffer
+	if err := d.ExportLineage(&buf); err != nil {
+		t.Fatalf("failed to ExportLineage(): %v", err)
+	}
+	dec := json.NewDecoder(&buf)
+	events := make(map[string]LineageEvent)
+	for dec.More() {
+		var e LineageEvent
+		if err := dec.Decode(&e); err != nil {
+			t.Fatalf("failed to decode event: %v", err)
+		}
+		events[e.Job.Name] = e
+	}
+	if len(events) != 2 {
+		t.Fatalf("ExportLineage() exported %d events, want %d", len(events), 2)
+	}
+	load := events["load"]
+	if len(load.Inputs) != 1 || load.Inputs[0].Name != "staged" {
+		t.Errorf("ExportLineage() inputs = %v, want 'staged'", load.Inputs)
+	}
+	if len(load.Outputs) != 1 || load.Outputs[0].Name != "facts" {
+		t.Errorf("ExportLineage() outputs = %v, want 'facts'", load.Outputs)
+	}
+}
+
+func TestDAG_ImportLineage_quota(t *testing.T) {
+	d := someNewDag(t, WithQuota(Quota{MaxVertices: 4}))
+
+	// the second event exceeds the quota, nothing is imported
+	if err := d.ImportLineage(strings.NewReader(lineageEvents)); !IsQuotaExceededError(err) {
+		t.Errorf("want QuotaExceededError, got %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 0 {
+		t.Errorf("GetOrder() = %d, want %d", order, 0)
+	}
+}
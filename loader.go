@@ -0,0 +1,277 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+	"sync"
+	"time"
+)
+
+// BatchGetChildren returns the ids of the children of each of the given
+// vertices using a single query. Unknown vertices map to an empty list.
+func (d *DAG) BatchGetChildren(ids []string) (map[string][]string, error) {
+	return d.BatchGetChildrenCtx(context.Background(), ids)
+}
+
+// BatchGetChildrenCtx is like BatchGetChildren but uses the given context.
+func (d *DAG) BatchGetChildrenCtx(ctx context.Context, ids []string) (map[string][]string, error) {
+	return d.batchGetNeighbours(d.context(ctx), ids, "_from", "_to")
+}
+
+// BatchGetParents returns the ids of the parents of each of the given
+// vertices using a single query. Unknown vertices map to an empty list.
+func (d *DAG) BatchGetParents(ids []string) (map[string][]string, error) {
+	return d.BatchGetParentsCtx(context.Background(), ids)
+}
+
+// BatchGetParentsCtx is like BatchGetParents but uses the given context.
+func (d *DAG) BatchGetParentsCtx(ctx context.Context, ids []string) (map[string][]string, error) {
+	return d.batchGetNeighbours(d.context(ctx), ids, "_to", "_from")
+}
+
+// batchGetNeighbours returns the ids of the neighbours of each of the given
+// vertices, i.e. the "other" ends of the edges whose "self" end is the vertex
+// (where "self" is either "_from" or "_to" and "other" is the opposite).
+// Vertices hidden from the principal carried by ctx (see WithPrincipal) have
+// no neighbours and aren't neighbours.
+func (d *DAG) batchGetNeighbours(ctx context.Context, ids []string, self, other string) (map[string][]string, error) {
+	acl := aclFilter(ctx)
+	query := `
+FOR id IN @ids
+  LET visible = ` + allMatch(acl, "[DOCUMENT(id)]") + `
+  LET neighbours = (
+    FOR e IN @@edges
+      FILTER visible AND e.@self == id
+      FILTER ` + allMatch(acl, "[DOCUMENT(e.@other)]") + `
+      RETURN PARSE_IDENTIFIER(e.@other).key
+  )
+  RETURN {id: PARSE_IDENTIFIER(id).key, neighbours: neighbours}`
+	docIDs := make([]driver.DocumentID, len(ids))
+	byKey := make(map[string]string, len(ids))
+	for i, id := range ids {
+		docIDs[i] = driver.NewDocumentID(d.vertices.Name(), d.key(id))
+		byKey[docIDs[i].Key()] = id
+	}
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"ids":    docIDs,
+		"self":   self,
+		"other":  other,
+	}
+	addBindVars(bindVars, acl)
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	defer closeCursor(cursor)
+
+	result := make(map[string][]string, len(ids))
+	for {
+		var item struct {
+			ID         string   `json:"id"`
+			Neighbours []string `json:"neighbours"`
+		}
+		_, err := cursor.ReadDocument(ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return nil, arangoError(err)
+		}
+		for i, key := range item.Neighbours {
+			item.Neighbours[i] = d.id(key)
+		}
+		result[byKey[item.ID]] = item.Neighbours
+	}
+	return result, nil
+}
+
+// Loader batches and caches lookups of related vertices (e.g. children or
+// parents) in the spirit of DataLoader. Calls to Load issued (concurrently)
+// within the configured wait duration are combined into a single query,
+// avoiding N+1 queries when resolving nested GraphQL queries. Loader is meant
+// to be used for the lifetime of a single (GraphQL) request.
+//
+// Loads are only batched with loads sharing the same context, and results are
+// cached per principal (see WithPrincipal), such that a batch is dispatched
+// with the context (i.e. deadline and principal) of the loads it contains.
+type Loader struct {
+	fetch    func(ctx context.Context, ids []string) (map[string][]string, error)
+	wait     time.Duration
+	maxBatch int
+
+	mu      sync.Mutex
+	cache   map[loaderKey]*loaderResult
+	batches map[context.Context]*loaderBatch
+}
+
+type loaderKey struct {
+	principal string
+	id        string
+}
+
+type loaderResult struct {
+	done chan struct{}
+	ids  []string
+	err  error
+}
+
+type loaderBatch struct {
+	ctx     context.Context
+	ids     []string
+	results map[string]*loaderResult
+	closed  bool
+}
+
+// NewChildrenLoader returns a Loader resolving the children of vertices. Loads
+// are collected for wait before being dispatched. maxBatch limits the number of
+// vertices per query (0 means unlimited).
+func NewChildrenLoader(d *DAG, wait time.Duration, maxBatch int) *Loader {
+	return newLoader(d.BatchGetChildrenCtx, wait, maxBatch)
+}
+
+// NewParentsLoader returns a Loader resolving the parents of vertices. Loads
+// are collected for wait before being dispatched. maxBatch limits the number of
+// vertices per query (0 means unlimited).
+func NewParentsLoader(d *DAG, wait time.Duration, maxBatch int) *Loader {
+	return newLoader(d.BatchGetParentsCtx, wait, maxBatch)
+}
+
+func newLoader(fetch func(ctx context.Context, ids []string) (map[string][]string, error), wait time.Duration, maxBatch int) *Loader {
+	return &Loader{
+		fetch:    fetch,
+		wait:     wait,
+		maxBatch: maxBatch,
+		cache:    make(map[loaderKey]*loaderResult),
+		batches:  make(map[context.Context]*loaderBatch),
+	}
+}
+
+// Load returns the ids of the vertices related to the vertex with the given
+// id. Load blocks until the batch containing id was dispatched.
+func (l *Loader) Load(id string) ([]string, error) {
+	return l.LoadCtx(context.Background(), id)
+}
+
+// LoadCtx is like Load but uses the given context.
+func (l *Loader) LoadCtx(ctx context.Context, id string) ([]string, error) {
+	principal, _ := PrincipalFromContext(ctx)
+	key := loaderKey{principal: principal, id: id}
+	l.mu.Lock()
+	r, ok := l.cache[key]
+	if !ok {
+		r = &loaderResult{done: make(chan struct{})}
+		l.cache[key] = r
+		l.enqueue(ctx, id, r)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.ids, r.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// LoadAll returns the related vertices for each of the given ids.
+func (l *Loader) LoadAll(ids []string) (map[string][]string, error) {
+	return l.LoadAllCtx(context.Background(), ids)
+}
+
+// LoadAllCtx is like LoadAll but uses the given context.
+func (l *Loader) LoadAllCtx(ctx context.Context, ids []string) (map[string][]string, error) {
+	type item struct {
+		id  string
+		ids []string
+		err error
+	}
+	items := make(chan item, len(ids))
+	for _, id := range ids {
+		go func(id string) {
+			ids, err := l.LoadCtx(ctx, id)
+			items <- item{id: id, ids: ids, err: err}
+		}(id)
+	}
+	result := make(map[string][]string, len(ids))
+	var err error
+	for range ids {
+		i := <-items
+		if i.err != nil {
+			err = i.err
+		}
+		result[i.id] = i.ids
+	}
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Clear removes the given id from the cache (e.g. after mutating its edges).
+func (l *Loader) Clear(id string) {
+	l.mu.Lock()
+	for key := range l.cache {
+		if key.id == id {
+			delete(l.cache, key)
+		}
+	}
+	l.mu.Unlock()
+}
+
+// enqueue adds id to the current batch of ctx (starting a new one if needed).
+// l.mu must be held.
+func (l *Loader) enqueue(ctx context.Context, id string, r *loaderResult) {
+	b, ok := l.batches[ctx]
+	if !ok {
+		b = &loaderBatch{ctx: ctx, results: make(map[string]*loaderResult)}
+		l.batches[ctx] = b
+		go l.dispatchAfterWait(b)
+	}
+	b.ids = append(b.ids, id)
+	b.results[id] = r
+	if l.maxBatch > 0 && len(b.ids) >= l.maxBatch {
+		delete(l.batches, ctx)
+		b.closed = true
+		go l.dispatch(b)
+	}
+}
+
+func (l *Loader) dispatchAfterWait(b *loaderBatch) {
+	time.Sleep(l.wait)
+	l.mu.Lock()
+	if b.closed {
+		l.mu.Unlock()
+		return
+	}
+	b.closed = true
+	if l.batches[b.ctx] == b {
+		delete(l.batches, b.ctx)
+	}
+	l.mu.Unlock()
+	l.dispatch(b)
+}
+
+func (l *Loader) dispatch(b *loaderBatch) {
+	related, err := l.fetch(b.ctx, b.ids)
+	for _, id := range b.ids {
+		r := b.results[id]
+		r.err = err
+		if err == nil {
+			r.ids = related[id]
+		}
+		close(r.done)
+	}
+
+	// don't cache errors
+	if err != nil {
+		principal, _ := PrincipalFromContext(b.ctx)
+		l.mu.Lock()
+		for _, id := range b.ids {
+			key := loaderKey{principal: principal, id: id}
+			if l.cache[key] == b.results[id] {
+				delete(l.cache, key)
+			}
+		}
+		l.mu.Unlock()
+	}
+}
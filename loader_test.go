@@ -0,0 +1,123 @@
+package arangodag
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_BatchGetChildren(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+
+	children, err := d.BatchGetChildren([]string{"1", "2", "foo"})
+	if err != nil {
+		t.Fatalf("failed to BatchGetChildren(): %v", err)
+	}
+	sort.Strings(children["1"])
+	want := map[string][]string{"1": {"2", "3"}, "2": {}, "foo": {}}
+	if diff := deep.Equal(children, want); diff != nil {
+		t.Errorf("BatchGetChildren() = %v, want %v", children, want)
+	}
+
+	parents, err := d.BatchGetParents([]string{"1", "2"})
+	if err != nil {
+		t.Fatalf("failed to BatchGetParents(): %v", err)
+	}
+	want = map[string][]string{"1": {}, "2": {"1"}}
+	if diff := deep.Equal(parents, want); diff != nil {
+		t.Errorf("BatchGetParents() = %v, want %v", parents, want)
+	}
+}
+
+func TestLoader_Load(t *testing.T) {
+	var mu sync.Mutex
+	var batches [][]string
+	fetch := func(_ context.Context, ids []string) (map[string][]string, error) {
+		mu.Lock()
+		batches = append(batches, ids)
+		mu.Unlock()
+		result := make(map[string][]string)
+		for _, id := range ids {
+			result[id] = []string{id + "-child"}
+		}
+		return result, nil
+	}
+	l := newLoader(fetch, 10*time.Millisecond, 0)
+
+	result, err := l.LoadAll([]string{"1", "2", "3", "1"})
+	if err != nil {
+		t.Fatalf("failed to LoadAll(): %v", err)
+	}
+	if len(result) != 3 || result["2"][0] != "2-child" {
+		t.Errorf("LoadAll() = %v", result)
+	}
+	if len(batches) != 1 {
+		t.Errorf("got %d batches, want %d", len(batches), 1)
+	}
+
+	// cached
+	if _, err := l.Load("1"); err != nil {
+		t.Errorf("failed to Load(): %v", err)
+	}
+	if len(batches) != 1 {
+		t.Errorf("got %d batches, want %d", len(batches), 1)
+	}
+
+	// max batch size
+	l = newLoader(fetch, time.Second, 2)
+	batches = nil
+	if _, err := l.LoadAll([]string{"1", "2"}); err != nil {
+		t.Errorf("failed to LoadAll(): %v", err)
+	}
+	if len(batches) != 1 {
+		t.Errorf("got %d batches, want %d", len(batches), 1)
+	}
+}
+
+func TestLoader_LoadCtx(t *testing.T) {
+	var mu sync.Mutex
+	principals := map[string]int{}
+	fetch := func(ctx context.Context, ids []string) (map[string][]string, error) {
+		principal, _ := PrincipalFromContext(ctx)
+		mu.Lock()
+		principals[principal]++
+		mu.Unlock()
+		result := make(map[string][]string)
+		for _, id := range ids {
+			result[id] = []string{principal + "-" + id}
+		}
+		return result, nil
+	}
+	l := newLoader(fetch, 10*time.Millisecond, 0)
+
+	alice := WithPrincipal(context.Background(), "alice")
+	bob := WithPrincipal(context.Background(), "bob")
+	if ids, err := l.LoadCtx(alice, "1"); err != nil || ids[0] != "alice-1" {
+		t.Errorf("LoadCtx() = %v (%v), want [alice-1]", ids, err)
+	}
+
+	// not served from alice's cache
+	if ids, err := l.LoadCtx(bob, "1"); err != nil || ids[0] != "bob-1" {
+		t.Errorf("LoadCtx() = %v (%v), want [bob-1]", ids, err)
+	}
+	if diff := deep.Equal(principals, map[string]int{"alice": 1, "bob": 1}); diff != nil {
+		t.Errorf("fetched for %v", principals)
+	}
+
+	// canceled
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := l.LoadCtx(ctx, "2"); err != context.Canceled {
+		t.Errorf("want context.Canceled, got %v", err)
+	}
+}
@@ -0,0 +1,162 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/arangodb/go-driver"
+	"net/http"
+	"time"
+)
+
+// VertexLock is an advisory lock of a vertex (see LockVertex).
+type VertexLock struct {
+	d     *DAG
+	id    string
+	token string
+}
+
+// ID returns the id of the locked vertex.
+func (l *VertexLock) ID() string {
+	return l.id
+}
+
+// LockVertex acquires the advisory lock of the vertex with the given id for
+// the given duration. Locks are cooperative, i.e. they don't prevent any
+// mutations, but only other workers from acquiring the same lock. Locks are
+// stored in the collection named after the vertex collection with the suffix
+// "_locks" (created on first use) and expire after ttl (as of the clock of the
+// database), such that crashed workers don't block others forever. LockVertex
+// returns an error, if id is empty or unknown, or if the vertex is locked
+// already (see IsVertexLockedError).
+func (d *DAG) LockVertex(ctx context.Context, id string, ttl time.Duration) (*VertexLock, error) {
+	ctx = d.context(ctx)
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	docID, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	token := randomKey()
+	acquired, err := d.acquireLease(ctx, vertexLockKey(docID.Key()), token, ttl)
+	if err != nil {
+		return nil, err
+	}
+	if !acquired {
+		return nil, VertexLockedError(id)
+	}
+	return &VertexLock{d: d, id: id, token: token}, nil
+}
+
+// Extend extends the lock to expire after ttl (from now on). Extend returns an
+// error, if the lock expired and was acquired by someone else meanwhile.
+func (l *VertexLock) Extend(ctx context.Context, ttl time.Duration) error {
+	ctx = l.d.context(ctx)
+	acquired, err := l.d.acquireLease(ctx, l.key(), l.token, ttl)
+	if err != nil {
+		return err
+	}
+	if !acquired {
+		return VertexLockedError(l.id)
+	}
+	return nil
+}
+
+// Unlock releases the lock. Unlock returns an error, if the lock expired and
+// was acquired by someone else meanwhile.
+func (l *VertexLock) Unlock(ctx context.Context) error {
+	ctx = l.d.context(ctx)
+	released, err := l.d.releaseLease(ctx, l.key(), l.token)
+	if err != nil {
+		return err
+	}
+	if !released {
+		return VertexLockedError(l.id)
+	}
+	return nil
+}
+
+// key returns the key of the lock document.
+func (l *VertexLock) key() string {
+	return vertexLockKey(l.d.key(l.id))
+}
+
+// vertexLockKey returns the key of the lock document of the vertex with the
+// given key.
+func vertexLockKey(key string) string {
+	return ComposeKey(changeVertex, key)
+}
+
+// lockCollection returns the collection holding locks and leases, creating it,
+// if it doesn't exist.
+func (d *DAG) lockCollection(ctx context.Context) (driver.Collection, error) {
+	d.locksMu.Lock()
+	defer d.locksMu.Unlock()
+	if d.locks == nil {
+		locks, err := useOrCreateCollection(d.context(ctx), d.db, d.vertices.Name()+"_locks", nil)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+		d.locks = locks
+	}
+	return d.locks, nil
+}
+
+// acquireLease acquires (or extends) the lease with the given key for the
+// holder identified by token and returns true, if the lease wasn't held by
+// someone else (or expired).
+func (d *DAG) acquireLease(ctx context.Context, key, token string, ttl time.Duration) (bool, error) {
+	locks, err := d.lockCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := `
+UPSERT {_key: @key}
+  INSERT {_key: @key, token: @token, expires: DATE_NOW() + @ttl}
+  UPDATE OLD.token == @token OR OLD.expires <= DATE_NOW()
+    ? {token: @token, expires: DATE_NOW() + @ttl}
+    : {}
+  IN @@locks
+  RETURN NEW.token == @token`
+	bindVars := map[string]interface{}{
+		"@locks": locks.Name(),
+		"key":    key,
+		"token":  token,
+		"ttl":    int64(ttl / time.Millisecond),
+	}
+	var acquired bool
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		acquired = string(doc) == "true"
+		return nil
+	})
+	var ae driver.ArangoError
+	if errors.As(err, &ae) && ae.Code == http.StatusConflict {
+		// concurrently acquired by someone else
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+	return acquired, nil
+}
+
+// releaseLease releases the lease with the given key held by the holder
+// identified by token and returns true, if the lease was held by the holder.
+func (d *DAG) releaseLease(ctx context.Context, key, token string) (bool, error) {
+	locks, err := d.lockCollection(ctx)
+	if err != nil {
+		return false, err
+	}
+	query := `
+FOR l IN @@locks
+  FILTER l._key == @key AND l.token == @token
+  REMOVE l IN @@locks
+  RETURN 1`
+	bindVars := map[string]interface{}{
+		"@locks": locks.Name(),
+		"key":    key,
+		"token":  token,
+	}
+	return d.queryHasResult(ctx, query, bindVars)
+}
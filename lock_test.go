@@ -0,0 +1,55 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestDAG_LockVertex(t *testing.T) {
+	d := someNewDag(t)
+	ctx := context.Background()
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+
+	lock, err := d.LockVertex(ctx, "1", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to LockVertex(): %v", err)
+	}
+	if lock.ID() != "1" {
+		t.Errorf("ID() = %s, want 1", lock.ID())
+	}
+	if _, err := d.LockVertex(ctx, "1", time.Minute); !IsVertexLockedError(err) {
+		t.Errorf("want VertexLockedError, got %v", err)
+	}
+	if err := lock.Extend(ctx, time.Minute); err != nil {
+		t.Errorf("failed to Extend(): %v", err)
+	}
+	if err := lock.Unlock(ctx); err != nil {
+		t.Fatalf("failed to Unlock(): %v", err)
+	}
+	if err := lock.Unlock(ctx); !IsVertexLockedError(err) {
+		t.Errorf("want VertexLockedError, got %v", err)
+	}
+
+	// expiry
+	lock, err = d.LockVertex(ctx, "1", time.Millisecond)
+	if err != nil {
+		t.Fatalf("failed to LockVertex(): %v", err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	other, err := d.LockVertex(ctx, "1", time.Minute)
+	if err != nil {
+		t.Fatalf("failed to LockVertex() after expiry: %v", err)
+	}
+	if err := lock.Unlock(ctx); !IsVertexLockedError(err) {
+		t.Errorf("want VertexLockedError, got %v", err)
+	}
+	if err := other.Unlock(ctx); err != nil {
+		t.Errorf("failed to Unlock(): %v", err)
+	}
+
+	// unknown
+	if _, err := d.LockVertex(ctx, "foo", time.Minute); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
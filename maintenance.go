@@ -0,0 +1,123 @@
+package arangodag
+
+import (
+	"context"
+	"time"
+)
+
+// MaintenanceJob is a (heavy) job run periodically by a MaintenanceScheduler.
+type MaintenanceJob struct {
+
+	// Name identifies the job across all instances sharing the DAG.
+	Name string
+
+	// Interval is the minimal time between two runs of the job (by any
+	// instance).
+	Interval time.Duration
+
+	// Run runs the job (with the context passed to Step).
+	Run func(ctx context.Context, d *DAG) error
+}
+
+// PruneJob returns a maintenance job pruning vertices older than maxAge (see
+// PruneOlderThan).
+func PruneJob(interval, maxAge time.Duration) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "prune",
+		Interval: interval,
+		Run: func(ctx context.Context, d *DAG) error {
+			_, err := d.PruneOlderThanCtx(ctx, time.Now().Add(-maxAge))
+			return err
+		},
+	}
+}
+
+// CentralityJob returns a maintenance job recomputing the given centrality
+// measure (see ComputeCentrality).
+func CentralityJob(interval time.Duration, kind CentralityKind) MaintenanceJob {
+	return MaintenanceJob{
+		Name:     "centrality:" + string(kind),
+		Interval: interval,
+		Run: func(ctx context.Context, d *DAG) error {
+			return d.ComputeCentralityCtx(ctx, kind)
+		},
+	}
+}
+
+// JobRun describes a run of a maintenance job (see MaintenanceScheduler).
+type JobRun struct {
+	Job      string
+	Started  time.Time
+	Duration time.Duration
+	Err      error
+}
+
+// MaintenanceScheduler runs maintenance jobs periodically, such that each job
+// is run by only one of multiple instances (e.g. of a horizontally scaled
+// service) sharing the DAG. Before running a job, an instance acquires the
+// job's lease (stored in the lock collection, see LockVertex) for the job's
+// interval. As the lease isn't released after the run, no instance runs the
+// job again before the interval elapsed. Thus, the interval should exceed the
+// duration of the job.
+type MaintenanceScheduler struct {
+	d     *DAG
+	jobs  []MaintenanceJob
+	onRun func(run JobRun)
+}
+
+// NewMaintenanceScheduler returns a scheduler for the given jobs.
+func (d *DAG) NewMaintenanceScheduler(jobs ...MaintenanceJob) *MaintenanceScheduler {
+	return &MaintenanceScheduler{d: d, jobs: jobs}
+}
+
+// OnRun sets a hook called after each job run by this instance (e.g. for
+// logging or metrics).
+func (s *MaintenanceScheduler) OnRun(fn func(run JobRun)) {
+	s.onRun = fn
+}
+
+// Step runs all jobs being due (i.e. whose lease could be acquired) once and
+// returns the number of jobs run. Errors of jobs are reported to the hook (see
+// OnRun), Step only returns errors acquiring leases.
+func (s *MaintenanceScheduler) Step(ctx context.Context) (int, error) {
+	ctx = s.d.context(ctx)
+	count := 0
+	for _, job := range s.jobs {
+
+		// a fresh token per attempt, such that leases held by this instance
+		// aren't extended
+		acquired, err := s.d.acquireLease(ctx, ComposeKey("job", job.Name), randomKey(), job.Interval)
+		if err != nil {
+			return count, err
+		}
+		if !acquired {
+			continue
+		}
+		run := JobRun{Job: job.Name, Started: time.Now()}
+		run.Err = job.Run(ctx, s.d)
+		run.Duration = time.Since(run.Started)
+		count++
+		if s.onRun != nil {
+			s.onRun(run)
+		}
+	}
+	return count, nil
+}
+
+// Run calls Step every pollInterval until ctx is done, returning the context's
+// error. Errors acquiring leases are reported to the hook (see OnRun) as runs
+// of the job "".
+func (s *MaintenanceScheduler) Run(ctx context.Context, pollInterval time.Duration) error {
+	ticker := time.NewTicker(pollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := s.Step(ctx); err != nil && s.onRun != nil {
+			s.onRun(JobRun{Started: time.Now(), Err: err})
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-ticker.C:
+		}
+	}
+}
@@ -0,0 +1,56 @@
+package arangodag
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestMaintenanceScheduler(t *testing.T) {
+	d := someNewDag(t)
+	other, err := NewDAG(d.db.Name(), d.vertices.Name(), d.edges.Name(), d.client)
+	if err != nil {
+		t.Fatalf("failed to NewDAG(): %v", err)
+	}
+
+	fail := errors.New("fail")
+	runs := 0
+	job := MaintenanceJob{
+		Name:     "test",
+		Interval: time.Minute,
+		Run: func(context.Context, *DAG) error {
+			runs++
+			return fail
+		},
+	}
+	var observed []JobRun
+	s1 := d.NewMaintenanceScheduler(job)
+	s1.OnRun(func(run JobRun) {
+		observed = append(observed, run)
+	})
+	s2 := other.NewMaintenanceScheduler(job)
+	ctx := context.Background()
+
+	// only one instance runs the job per interval
+	for _, s := range []*MaintenanceScheduler{s1, s2, s1} {
+		if _, err := s.Step(ctx); err != nil {
+			t.Fatalf("failed to Step(): %v", err)
+		}
+	}
+	if runs != 1 {
+		t.Errorf("job ran %d times, want 1", runs)
+	}
+	if len(observed) != 1 || observed[0].Job != "test" || observed[0].Err != fail {
+		t.Errorf("observed %v, want a failed run of test", observed)
+	}
+
+	// due again after the interval
+	s3 := other.NewMaintenanceScheduler(MaintenanceJob{Name: "short", Interval: time.Millisecond, Run: job.Run})
+	for i := 0; i < 2; i++ {
+		if n, err := s3.Step(ctx); err != nil || n != 1 {
+			t.Errorf("Step() = %d, %v, want 1, nil", n, err)
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+}
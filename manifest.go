@@ -0,0 +1,314 @@
+package arangodag
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"regexp"
+	"strings"
+)
+
+// ImportGoModGraph imports the output of `go mod graph` read from r. Modules
+// become vertices (see SBOMComponent) and each requirement becomes an edge
+// from the requiring to the required module.
+func (d *DAG) ImportGoModGraph(r io.Reader) error {
+	return d.ImportGoModGraphCtx(context.Background(), r)
+}
+
+// ImportGoModGraphCtx is like ImportGoModGraph but uses the given context.
+func (d *DAG) ImportGoModGraphCtx(ctx context.Context, r io.Reader) error {
+	components, dependencies, err := parseGoModGraph(r)
+	if err != nil {
+		return err
+	}
+	return d.importDependencies(ctx, components, dependencies)
+}
+
+// ImportPackageLock imports the npm package-lock.json (lockfileVersion 1, 2,
+// or 3) read from r. Packages become vertices (see SBOMComponent) and each
+// (resolved) dependency becomes an edge from the dependent to the dependency.
+//
+// Note, version 1 lock files don't list the direct dependencies of the root
+// package. Therefore, the root is connected to all top-level packages not
+// required by any other package.
+func (d *DAG) ImportPackageLock(r io.Reader) error {
+	return d.ImportPackageLockCtx(context.Background(), r)
+}
+
+// ImportPackageLockCtx is like ImportPackageLock but uses the given context.
+func (d *DAG) ImportPackageLockCtx(ctx context.Context, r io.Reader) error {
+	components, dependencies, err := parsePackageLock(r)
+	if err != nil {
+		return err
+	}
+	return d.importDependencies(ctx, components, dependencies)
+}
+
+// ImportRequirementsTree imports the Python requirements read from r. r may
+// either be a plain requirements.txt (pinned via "==") or the tree output of
+// pipdeptree, where dependencies are indented below their dependents (e.g.
+// "  - click [required: >=7.1.2, installed: 8.0.1]"). Packages become vertices
+// (see SBOMComponent) and each dependency becomes an edge from the dependent to
+// the dependency.
+func (d *DAG) ImportRequirementsTree(r io.Reader) error {
+	return d.ImportRequirementsTreeCtx(context.Background(), r)
+}
+
+// ImportRequirementsTreeCtx is like ImportRequirementsTree but uses the given
+// context.
+func (d *DAG) ImportRequirementsTreeCtx(ctx context.Context, r io.Reader) error {
+	components, dependencies, err := parseRequirementsTree(r)
+	if err != nil {
+		return err
+	}
+	return d.importDependencies(ctx, components, dependencies)
+}
+
+// parseGoModGraph returns the modules and dependencies (both by module path
+// and version) listed in the given `go mod graph` output.
+func parseGoModGraph(r io.Reader) (map[string]SBOMComponent, map[string][]string, error) {
+	components := make(map[string]SBOMComponent)
+	dependencies := make(map[string][]string)
+	scanner := bufio.NewScanner(r)
+	line := 0
+	for scanner.Scan() {
+		line++
+		fields := strings.Fields(scanner.Text())
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) != 2 {
+			return nil, nil, fmt.Errorf("line %d: expecting two modules, got %d", line, len(fields))
+		}
+		for _, ref := range fields {
+			if _, ok := components[ref]; !ok {
+				components[ref] = goModule(ref)
+			}
+		}
+		dependencies[fields[0]] = append(dependencies[fields[0]], fields[1])
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return components, dependencies, nil
+}
+
+// goModule returns the component for the given module reference (i.e.
+// "path@version" or "path" for the main module).
+func goModule(ref string) SBOMComponent {
+	name, version := ref, ""
+	if i := strings.LastIndex(ref, "@"); i > 0 {
+		name, version = ref[:i], ref[i+1:]
+	}
+	c := SBOMComponent{Name: name, Version: version, Type: "library", PURL: "pkg:golang/" + name}
+	if version != "" {
+		c.PURL += "@" + version
+	} else {
+		c.Type = "application"
+	}
+	return c
+}
+
+type packageLockV1Dependency struct {
+	Version      string                             `json:"version"`
+	Requires     map[string]string                  `json:"requires"`
+	Dependencies map[string]packageLockV1Dependency `json:"dependencies"`
+}
+
+type packageLockPackage struct {
+	Name                 string            `json:"name"`
+	Version              string            `json:"version"`
+	Dependencies         map[string]string `json:"dependencies"`
+	DevDependencies      map[string]string `json:"devDependencies"`
+	OptionalDependencies map[string]string `json:"optionalDependencies"`
+	PeerDependencies     map[string]string `json:"peerDependencies"`
+}
+
+// parsePackageLock returns the packages and dependencies (both by their path
+// within node_modules) of the given package-lock.json.
+func parsePackageLock(r io.Reader) (map[string]SBOMComponent, map[string][]string, error) {
+	var lock struct {
+		Name            string                             `json:"name"`
+		Version         string                             `json:"version"`
+		LockfileVersion int                                `json:"lockfileVersion"`
+		Packages        map[string]packageLockPackage      `json:"packages"`
+		Dependencies    map[string]packageLockV1Dependency `json:"dependencies"`
+	}
+	if err := json.NewDecoder(r).Decode(&lock); err != nil {
+		return nil, nil, err
+	}
+
+	components := make(map[string]SBOMComponent)
+	dependencies := make(map[string][]string)
+	root := npmPackage(lock.Name, lock.Version)
+	root.Type = "application"
+	components[""] = root
+
+	// lockfileVersion 2 and 3
+	if len(lock.Packages) > 0 {
+		for path, p := range lock.Packages {
+			if path == "" {
+				continue
+			}
+			name := p.Name
+			if i := strings.LastIndex(path, "node_modules/"); i >= 0 && name == "" {
+				name = path[i+len("node_modules/"):]
+			}
+			components[path] = npmPackage(name, p.Version)
+		}
+		for path, p := range lock.Packages {
+			for _, deps := range []map[string]string{p.Dependencies, p.DevDependencies, p.OptionalDependencies, p.PeerDependencies} {
+				for name := range deps {
+					if dep, ok := resolvePackagePath(lock.Packages, path, name); ok {
+						dependencies[path] = append(dependencies[path], dep)
+					}
+				}
+			}
+		}
+		return components, dependencies, nil
+	}
+
+	// lockfileVersion 1
+	required := make(map[string]bool)
+	var add func(scope []string, deps map[string]packageLockV1Dependency)
+	add = func(scope []string, deps map[string]packageLockV1Dependency) {
+		for name, dep := range deps {
+			path := strings.Join(append(append([]string{}, scope...), name), "/node_modules/")
+			path = "node_modules/" + path
+			components[path] = npmPackage(name, dep.Version)
+			nested := append(append([]string{}, scope...), name)
+			for req := range dep.Requires {
+				if p, ok := resolveV1Path(lock.Dependencies, nested, req); ok {
+					dependencies[path] = append(dependencies[path], p)
+					required[p] = true
+				}
+			}
+			add(nested, dep.Dependencies)
+		}
+	}
+	add(nil, lock.Dependencies)
+	for name := range lock.Dependencies {
+		if path := "node_modules/" + name; !required[path] {
+			dependencies[""] = append(dependencies[""], path)
+		}
+	}
+	return components, dependencies, nil
+}
+
+// resolvePackagePath resolves the dependency name of the package at path the
+// way node does (i.e. looking into the nested node_modules first, walking up
+// to the top-level node_modules).
+func resolvePackagePath(packages map[string]packageLockPackage, path, name string) (string, bool) {
+	for {
+		candidate := "node_modules/" + name
+		if path != "" {
+			candidate = path + "/" + candidate
+		}
+		if _, ok := packages[candidate]; ok {
+			return candidate, true
+		}
+		if path == "" {
+			return "", false
+		}
+		i := strings.LastIndex(path, "/node_modules/")
+		if i < 0 {
+			path = ""
+		} else {
+			path = path[:i]
+		}
+	}
+}
+
+// resolveV1Path resolves the dependency name of the (version 1) package
+// nested as given by scope, the way node does.
+func resolveV1Path(deps map[string]packageLockV1Dependency, scope []string, name string) (string, bool) {
+	for i := len(scope); i >= 0; i-- {
+		current := deps
+		for _, s := range scope[:i] {
+			current = current[s].Dependencies
+		}
+		if _, ok := current[name]; ok {
+			return "node_modules/" + strings.Join(append(append([]string{}, scope[:i]...), name), "/node_modules/"), true
+		}
+	}
+	return "", false
+}
+
+func npmPackage(name, version string) SBOMComponent {
+	c := SBOMComponent{Name: name, Version: version, Type: "library", PURL: "pkg:npm/" + name}
+	if version != "" {
+		c.PURL += "@" + version
+	}
+	return c
+}
+
+var (
+	requirementPinned = regexp.MustCompile(`^([A-Za-z0-9_.\-\[\]]+)\s*==\s*([^\s;#]+)`)
+	requirementTree   = regexp.MustCompile(`^-\s+([A-Za-z0-9_.\-]+)\s+\[required:.*installed:\s*([^\],\s]+)`)
+	requirementName   = regexp.MustCompile(`^([A-Za-z0-9_.\-]+)`)
+)
+
+// parseRequirementsTree returns the packages and dependencies (both by name)
+// of the given requirements.txt or pipdeptree output.
+func parseRequirementsTree(r io.Reader) (map[string]SBOMComponent, map[string][]string, error) {
+	components := make(map[string]SBOMComponent)
+	dependencies := make(map[string][]string)
+
+	type entry struct {
+		indent int
+		name   string
+	}
+	var stack []entry
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		raw := scanner.Text()
+		text := strings.TrimSpace(raw)
+		if text == "" || strings.HasPrefix(text, "#") || strings.HasPrefix(text, "-r ") || strings.HasPrefix(text, "--") {
+			continue
+		}
+		indent := len(raw) - len(strings.TrimLeft(raw, " \t"))
+
+		var name, version string
+		if m := requirementTree.FindStringSubmatch(text); m != nil {
+			name, version = m[1], m[2]
+		} else if m := requirementPinned.FindStringSubmatch(text); m != nil {
+			name, version = m[1], m[2]
+		} else if m := requirementName.FindStringSubmatch(strings.TrimPrefix(text, "- ")); m != nil {
+			name = m[1]
+		} else {
+			continue
+		}
+		if i := strings.Index(name, "["); i >= 0 {
+			name = name[:i]
+		}
+		name = strings.ToLower(name)
+		if version == "?" || version == "" {
+			version = components[name].Version
+		}
+		components[name] = pypiPackage(name, version)
+
+		for len(stack) > 0 && stack[len(stack)-1].indent >= indent {
+			stack = stack[:len(stack)-1]
+		}
+		if len(stack) > 0 {
+			parent := stack[len(stack)-1].name
+			dependencies[parent] = append(dependencies[parent], name)
+		}
+		stack = append(stack, entry{indent: indent, name: name})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, nil, err
+	}
+	return components, dependencies, nil
+}
+
+func pypiPackage(name, version string) SBOMComponent {
+	c := SBOMComponent{Name: name, Version: version, Type: "library", PURL: "pkg:pypi/" + name}
+	if version != "" {
+		c.PURL += "@" + version
+	}
+	return c
+}
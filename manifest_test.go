@@ -0,0 +1,117 @@
+package arangodag
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+const goModGraph = `example.com/app github.com/pkg/errors@v0.9.1
+example.com/app golang.org/x/text@v0.3.0
+golang.org/x/text@v0.3.0 golang.org/x/tools@v0.0.0-20180917221912-90fa682c2a6e
+`
+
+const packageLockV2 = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 2,
+  "packages": {
+    "": {"name": "app", "version": "1.0.0", "dependencies": {"a": "^1.0.0"}, "devDependencies": {"b": "^1.0.0"}},
+    "node_modules/a": {"version": "1.0.0", "dependencies": {"b": "^2.0.0"}},
+    "node_modules/a/node_modules/b": {"version": "2.0.0"},
+    "node_modules/b": {"version": "1.0.0"}
+  }
+}`
+
+const packageLockV1 = `{
+  "name": "app",
+  "version": "1.0.0",
+  "lockfileVersion": 1,
+  "dependencies": {
+    "a": {"version": "1.0.0", "requires": {"b": "^2.0.0"}, "dependencies": {"b": {"version": "2.0.0"}}},
+    "b": {"version": "1.0.0"}
+  }
+}`
+
+const requirementsTree = `Flask==2.0.1
+  - click [required: >=7.1.2, installed: 8.0.1]
+  - Jinja2 [required: >=3.0, installed: 3.0.1]
+    - MarkupSafe [required: >=2.0, installed: 2.0.1]
+requests==2.26.0
+`
+
+func TestDAG_ImportGoModGraph(t *testing.T) {
+	d := someNewDag(t)
+	if err := d.ImportGoModGraph(strings.NewReader(goModGraph)); err != nil {
+		t.Fatalf("failed to ImportGoModGraph(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+	if size, _ := d.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want %d", size, 3)
+	}
+}
+
+func Test_parseGoModGraph(t *testing.T) {
+	components, dependencies, err := parseGoModGraph(strings.NewReader(goModGraph))
+	if err != nil {
+		t.Fatalf("failed to parseGoModGraph(): %v", err)
+	}
+	if c := components["golang.org/x/text@v0.3.0"]; c.PURL != "pkg:golang/golang.org/x/text@v0.3.0" {
+		t.Errorf("parseGoModGraph() purl = '%s', want '%s'", c.PURL, "pkg:golang/golang.org/x/text@v0.3.0")
+	}
+	if len(dependencies["example.com/app"]) != 2 {
+		t.Errorf("parseGoModGraph() = %v, want 2 dependencies of the main module", dependencies)
+	}
+	if _, _, err := parseGoModGraph(strings.NewReader("foo")); err == nil {
+		t.Errorf("parseGoModGraph() = nil, want error")
+	}
+}
+
+func Test_parsePackageLock(t *testing.T) {
+	for _, lock := range []string{packageLockV1, packageLockV2} {
+		components, dependencies, err := parsePackageLock(strings.NewReader(lock))
+		if err != nil {
+			t.Fatalf("failed to parsePackageLock(): %v", err)
+		}
+		if len(components) != 4 {
+			t.Errorf("parsePackageLock() returned %d packages, want %d", len(components), 4)
+		}
+		if c := components["node_modules/a/node_modules/b"]; c.PURL != "pkg:npm/b@2.0.0" {
+			t.Errorf("parsePackageLock() purl = '%s', want '%s'", c.PURL, "pkg:npm/b@2.0.0")
+		}
+		want := []string{"node_modules/a/node_modules/b"}
+		if diff := deep.Equal(dependencies["node_modules/a"], want); diff != nil {
+			t.Errorf("parsePackageLock() dependencies = %v, want %v", dependencies["node_modules/a"], want)
+		}
+		root := dependencies[""]
+		sort.Strings(root)
+		want = []string{"node_modules/a", "node_modules/b"}
+		if diff := deep.Equal(root, want); diff != nil {
+			t.Errorf("parsePackageLock() root dependencies = %v, want %v", root, want)
+		}
+	}
+}
+
+func Test_parseRequirementsTree(t *testing.T) {
+	components, dependencies, err := parseRequirementsTree(strings.NewReader(requirementsTree))
+	if err != nil {
+		t.Fatalf("failed to parseRequirementsTree(): %v", err)
+	}
+	if len(components) != 5 {
+		t.Errorf("parseRequirementsTree() returned %d packages, want %d", len(components), 5)
+	}
+	if c := components["markupsafe"]; c.PURL != "pkg:pypi/markupsafe@2.0.1" {
+		t.Errorf("parseRequirementsTree() purl = '%s', want '%s'", c.PURL, "pkg:pypi/markupsafe@2.0.1")
+	}
+	want := map[string][]string{
+		"flask":  {"click", "jinja2"},
+		"jinja2": {"markupsafe"},
+	}
+	if diff := deep.Equal(dependencies, want); diff != nil {
+		t.Errorf("parseRequirementsTree() = %v, want %v", dependencies, want)
+	}
+}
@@ -0,0 +1,267 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arangodb/go-driver"
+)
+
+// MaterializedPathAttribute is the attribute of vertex documents holding the
+// materialized path (see WithMaterializedPaths).
+const MaterializedPathAttribute = "path"
+
+// materializedPathBatchSize is the number of paths written per transaction by
+// RebuildMaterializedPaths.
+const materializedPathBatchSize = 1000
+
+// WithMaterializedPaths enables maintaining materialized paths, speeding up
+// subtree listings of mostly tree-like graphs (see GetSubtree). The path of a
+// vertex with exactly one parent is the path of its parent followed by "/" and
+// the vertex' key. Any other vertex (i.e. roots and vertices with multiple
+// parents) starts a new tree, its path being "/" followed by its key. Thus, the
+// descendants of a vertex within its tree share the vertex' path as prefix and
+// are found by a single (indexed) range lookup. Paths are stored in the
+// attribute MaterializedPathAttribute (outside of the payload) and are updated
+// by all mutations within the transaction of the mutation: adding edges (by
+// any means, e.g. imports) moves their destinations, mutations deleting edges
+// or vertices move the vertices having lost a parent (PruneOlderThan deletes
+// leaves only, which doesn't affect any path) and Restore recomputes all
+// paths. RebuildMaterializedPaths computes the paths of graphs created before
+// enabling materialized paths.
+func WithMaterializedPaths() Option {
+	return func(d *DAG) {
+		d.materializedPaths = true
+	}
+}
+
+// ensureMaterializedPathIndex creates the index backing the prefix lookups of
+// materialized paths.
+func (d *DAG) ensureMaterializedPathIndex(ctx context.Context) error {
+	_, err := d.ensureIndex(ctx, d.vertices, []string{MaterializedPathAttribute}, IndexPersistent)
+	return err
+}
+
+// updatePaths recomputes the materialized paths of the given vertices (if they
+// still exist) from their parents and moves the trees below them along.
+func (d *DAG) updatePaths(ctx context.Context, ids ...driver.DocumentID) error {
+	for _, id := range ids {
+		query := `
+LET v = DOCUMENT(@id)
+FILTER v != null
+LET parents = (FOR p IN 1 INBOUND v @@edges RETURN NOT_NULL(p.@attr, CONCAT("/", p._key)))
+LET old = NOT_NULL(v.@attr, CONCAT("/", v._key))
+LET new = LENGTH(parents) == 1 ? CONCAT(parents[0], "/", v._key) : CONCAT("/", v._key)
+FILTER v.@attr != new
+RETURN {old, new}`
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"id":     id,
+			"attr":   MaterializedPathAttribute,
+		}
+		var item struct {
+			Old string `json:"old"`
+			New string `json:"new"`
+		}
+		found := false
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			found = true
+			return json.Unmarshal(doc, &item)
+		})
+		if err != nil {
+			return err
+		}
+		if !found {
+			continue
+		}
+
+		// move the vertex and the tree below it
+		query = `
+FOR v IN @@vertices
+  FILTER v._key == @key OR (v.@attr >= @lo AND v.@attr < @hi)
+  UPDATE v WITH {@attr: CONCAT(@new, SUBSTRING(NOT_NULL(v.@attr, CONCAT("/", v._key)), LENGTH(@old)))} IN @@vertices`
+		lo, hi := subtreeRange(item.Old)
+		bindVars = map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"key":       id.Key(),
+			"attr":      MaterializedPathAttribute,
+			"lo":        lo,
+			"hi":        hi,
+			"old":       item.Old,
+			"new":       item.New,
+		}
+		if err := d.exec(ctx, query, bindVars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateEdgePaths updates the materialized paths of the destinations of the
+// edges with the given document ids (see updatePaths).
+func (d *DAG) updateEdgePaths(ctx context.Context, ids ...driver.DocumentID) error {
+	if !d.materializedPaths || len(ids) == 0 {
+		return nil
+	}
+	query := `
+FOR id IN @ids
+  LET e = DOCUMENT(id)
+  FILTER e != null
+  RETURN DISTINCT e._to`
+	bindVars := map[string]interface{}{
+		"ids": ids,
+	}
+	dsts, err := d.queryIDs(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	return d.updatePaths(ctx, dsts...)
+}
+
+// rebuildPaths recomputes the materialized paths of all vertices (if enabled)
+// within the transaction of ctx (see RebuildMaterializedPaths).
+func (d *DAG) rebuildPaths(ctx context.Context) error {
+	if !d.materializedPaths {
+		return nil
+	}
+	after := ""
+	for {
+		last, err := d.materializedPathBatch(ctx, after, materializedPathBatchSize)
+		if err != nil || last == "" {
+			return err
+		}
+		after = last
+	}
+}
+
+// subtreeRange returns the (half-open) range of the materialized paths of the
+// vertices below the vertex with the given path.
+func subtreeRange(path string) (string, string) {
+
+	// "0" succeeds "/"
+	return path + "/", path + "0"
+}
+
+// GetSubtree returns the ids of the descendants of the vertex with the given
+// id within its tree (see WithMaterializedPaths) in depth-first order, i.e.
+// the descendants reachable via vertices with exactly one parent. If the graph
+// below the vertex is a tree, these are all of its descendants. GetSubtree
+// returns an error, if id is empty or unknown.
+func (d *DAG) GetSubtree(id string) ([]string, error) {
+	return d.GetSubtreeCtx(context.Background(), id)
+}
+
+// GetSubtreeCtx is like GetSubtree but uses the given context.
+func (d *DAG) GetSubtreeCtx(ctx context.Context, id string) ([]string, error) {
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	docID, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"id":        docID,
+		"attr":      MaterializedPathAttribute,
+	}
+
+	// vertices hidden from the principal hide their subtrees too
+	var hidden, visible string
+	if acl := aclFilter(ctx); acl != nil {
+		hidden = `
+LET hidden = (
+  FOR h IN @@vertices
+    FILTER h.@attr >= CONCAT(prefix, "/") AND h.@attr < CONCAT(prefix, "0")
+    FILTER NOT (` + allMatch(acl, "[h]") + `)
+    RETURN CONCAT(h.@attr, "/")
+)`
+		visible = `
+  FILTER LENGTH(FOR h IN hidden FILTER STARTS_WITH(v.@attr, h) LIMIT 1 RETURN 1) == 0
+  FILTER ` + allMatch(acl, "[v]")
+		addBindVars(bindVars, acl)
+	}
+	query := `
+LET root = DOCUMENT(@id)
+LET prefix = NOT_NULL(root.@attr, CONCAT("/", root._key))` + hidden + `
+FOR v IN @@vertices
+  FILTER v.@attr >= CONCAT(prefix, "/") AND v.@attr < CONCAT(prefix, "0")` + visible + `
+  SORT v.@attr
+  RETURN v._key`
+	ids := []string{}
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		ids = append(ids, d.id(key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// RebuildMaterializedPaths recomputes the materialized paths of all vertices
+// (see WithMaterializedPaths), e.g. after enabling materialized paths for an
+// existing graph or after mutations not maintaining them. The paths are
+// recomputed in batches, each within a transaction of its own (see
+// RebuildDerivedData for a throttled rebuild).
+func (d *DAG) RebuildMaterializedPaths() error {
+	return d.RebuildMaterializedPathsCtx(context.Background())
+}
+
+// RebuildMaterializedPathsCtx is like RebuildMaterializedPaths but uses the given
+// context.
+func (d *DAG) RebuildMaterializedPathsCtx(ctx context.Context) error {
+	if err := d.ensureMaterializedPathIndex(ctx); err != nil {
+		return err
+	}
+	ctx = d.context(ctx)
+	after := ""
+	for {
+		last, err := d.materializedPathBatch(ctx, after, materializedPathBatchSize)
+		if err != nil || last == "" {
+			return err
+		}
+		after = last
+	}
+}
+
+// materializedPathBatch recomputes the materialized paths of (up to) size
+// vertices with keys greater than after and returns the last key processed
+// ("" if none). The paths are computed from the current edges within the
+// transaction writing them, i.e. they don't go stale while a rebuild is
+// running.
+func (d *DAG) materializedPathBatch(ctx context.Context, after string, size int) (string, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER v._key > @after
+  SORT v._key
+  LIMIT @size
+  LET chain = FIRST(
+    FOR a, e, p IN 0..@maxDepth INBOUND v @@edges
+      PRUNE LENGTH(FOR x IN 1 INBOUND a @@edges RETURN 1) != 1
+      FILTER LENGTH(FOR x IN 1 INBOUND a @@edges RETURN 1) != 1
+      RETURN REVERSE(p.vertices[*]._key)
+  )
+  UPDATE v WITH {@attr: CONCAT("/", CONCAT_SEPARATOR("/", chain))} IN @@vertices
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+		"attr":      MaterializedPathAttribute,
+		"after":     after,
+		"size":      size,
+		"maxDepth":  maxDepth,
+	}
+	var last string
+	err := d.writeTransaction(ctx, func(ctx context.Context) error {
+		var err error
+		last, err = d.lastKey(ctx, query, bindVars)
+		return err
+	})
+	return last, err
+}
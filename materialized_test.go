@@ -0,0 +1,108 @@
+package arangodag
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetSubtree(t *testing.T) {
+	d := someNewDag(t, WithMaterializedPaths())
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+
+	// 1 -> 2 -> 3, 1 -> 4
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "4")
+
+	subtree, err := d.GetSubtree("1")
+	if err != nil {
+		t.Fatalf("failed to GetSubtree(): %v", err)
+	}
+	if diff := deep.Equal(subtree, []string{"2", "3", "4"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// 5 -> 2 makes 2 the root of its own tree
+	_ = d.AddEdge("5", "2")
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{"4"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [4]", subtree)
+	}
+	if subtree, _ := d.GetSubtree("2"); deep.Equal(subtree, []string{"3"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [3]", subtree)
+	}
+
+	// deleting 5 joins 2 to the tree of 1 again
+	_ = d.DeleteVertex("5")
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{"2", "3", "4"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [2 3 4]", subtree)
+	}
+
+	if err := d.RebuildMaterializedPaths(); err != nil {
+		t.Fatalf("failed to RebuildMaterializedPaths(): %v", err)
+	}
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{"2", "3", "4"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [2 3 4]", subtree)
+	}
+
+	if _, err := d.GetSubtree("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WithMaterializedPaths_mutations(t *testing.T) {
+	d := someNewDag(t, WithMaterializedPaths())
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+
+	// 1 -> 2 -> 3, 4 -> 2 (added by a batch)
+	errs, err := d.AddEdges([]EdgeSpec{{Src: "1", Dst: "2"}, {Src: "2", Dst: "3"}, {Src: "4", Dst: "2"}})
+	if err != nil || errs[0] != nil {
+		t.Fatalf("failed to AddEdges(): %v %v", err, errs)
+	}
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{}) != nil {
+		t.Errorf("GetSubtree() = %v, want []", subtree)
+	}
+
+	// sweeping 4 joins 2 to the tree of 1
+	if _, err := d.MarkAndSweep([]string{"1"}); err != nil {
+		t.Fatalf("failed to MarkAndSweep(): %v", err)
+	}
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{"2", "3"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [2 3]", subtree)
+	}
+
+	// deleting 2 by a rewrite makes 3 a root
+	_, err = d.Rewrite(RewriteRule{
+		Dst: &ViewFilter{Expression: "CURRENT._key == @dst", BindVars: map[string]interface{}{"dst": "2"}},
+		Replace: func(rw *Rewriter, m RewriteMatch) error {
+			return rw.DeleteVertex(m.Dst)
+		},
+	})
+	if err != nil {
+		t.Fatalf("failed to Rewrite(): %v", err)
+	}
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{}) != nil {
+		t.Errorf("GetSubtree() = %v, want []", subtree)
+	}
+
+	// imported edges
+	var buf bytes.Buffer
+	src := someNewDag(t)
+	_, _ = src.AddVertex(idVertex{MyID: "a"})
+	_, _ = src.AddVertex(idVertex{MyID: "b"})
+	_ = src.AddEdge("a", "b")
+	if err := src.ExportJSON(&buf); err != nil {
+		t.Fatalf("failed to ExportJSON(): %v", err)
+	}
+	if err := d.ImportJSON(&buf, nil); err != nil {
+		t.Fatalf("failed to ImportJSON(): %v", err)
+	}
+	if subtree, _ := d.GetSubtree("a"); deep.Equal(subtree, []string{"b"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [b]", subtree)
+	}
+}
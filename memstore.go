@@ -0,0 +1,231 @@
+package arangodag
+
+import (
+	"encoding/json"
+	"strconv"
+	"sync"
+)
+
+// MemoryStore is an in-memory GraphStore (e.g. for tests). Vertices are stored
+// JSON encoded, i.e. GetVertex behaves like DAG.GetVertex. MemoryStore only
+// implements the operations of GraphStore, i.e. it is no replacement for DAG
+// in general.
+type MemoryStore struct {
+	mu       sync.RWMutex
+	vertices map[string]json.RawMessage
+	children map[string]map[string]struct{}
+	size     uint64
+	nextID   uint64
+}
+
+var _ GraphStore = (*MemoryStore)(nil)
+
+// NewMemoryStore returns a new (empty) MemoryStore.
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		vertices: make(map[string]json.RawMessage),
+		children: make(map[string]map[string]struct{}),
+	}
+}
+
+// AddVertex adds the given vertex and returns its id (see DAG.AddVertex).
+func (s *MemoryStore) AddVertex(vertex interface{}) (string, error) {
+	if vertex == nil {
+		return "", VertexNilError()
+	}
+	data, err := json.Marshal(vertex)
+	if err != nil {
+		return "", err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var id string
+	if i, ok := vertex.(IDInterface); ok {
+		id = i.ID()
+		if id == "" {
+			return "", EmptyIDError()
+		}
+		if _, exists := s.vertices[id]; exists {
+			return "", DuplicateIDError(id)
+		}
+	} else {
+		for {
+			s.nextID++
+			id = strconv.FormatUint(s.nextID, 10)
+			if _, exists := s.vertices[id]; !exists {
+				break
+			}
+		}
+	}
+	s.vertices[id] = data
+	s.children[id] = make(map[string]struct{})
+	return id, nil
+}
+
+// GetVertex reads the vertex with the given id into vertex (see
+// DAG.GetVertex).
+func (s *MemoryStore) GetVertex(id string, vertex interface{}) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	s.mu.RLock()
+	data, exists := s.vertices[id]
+	s.mu.RUnlock()
+	if !exists {
+		return NewUnknownKeyError(id)
+	}
+	return json.Unmarshal(data, vertex)
+}
+
+// AddEdge adds an edge from the vertex with the id srcID to the vertex with
+// the id dstID (see DAG.AddEdge).
+func (s *MemoryStore) AddEdge(srcID, dstID string) error {
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+	if srcID == dstID {
+		return SrcDstEqualError(srcID)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkIDs(srcID, dstID); err != nil {
+		return err
+	}
+	if _, exists := s.children[srcID][dstID]; exists {
+		return DuplicateEdgeError(srcID, dstID)
+	}
+	if s.distance(dstID, srcID) >= 0 {
+		return LoopError(srcID, dstID)
+	}
+	s.children[srcID][dstID] = struct{}{}
+	s.size++
+	return nil
+}
+
+// DeleteEdge deletes the edge from the vertex with the id srcID to the vertex
+// with the id dstID (see DAG.DeleteEdge).
+func (s *MemoryStore) DeleteEdge(srcID, dstID string) error {
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.checkIDs(srcID, dstID); err != nil {
+		return err
+	}
+	if _, exists := s.children[srcID][dstID]; !exists {
+		return UnknownEdgeError(srcID, dstID)
+	}
+	delete(s.children[srcID], dstID)
+	s.size--
+	return nil
+}
+
+// GetOrder returns the number of vertices.
+func (s *MemoryStore) GetOrder() (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return uint64(len(s.vertices)), nil
+}
+
+// GetSize returns the number of edges.
+func (s *MemoryStore) GetSize() (uint64, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.size, nil
+}
+
+// GetShortestPathLength returns the number of edges of the shortest path from
+// the vertex with the id srcID to the vertex with the id dstID (see
+// DAG.GetShortestPathLength).
+func (s *MemoryStore) GetShortestPathLength(srcID, dstID string) (int, error) {
+	if srcID == "" || dstID == "" {
+		return 0, EmptyIDError()
+	}
+
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	if err := s.checkIDs(srcID, dstID); err != nil {
+		return 0, err
+	}
+	return s.distance(srcID, dstID), nil
+}
+
+// WalkDescendantsMulti calls fn for each descendant of any of the vertices with
+// the given ids (see DAG.WalkDescendantsMulti).
+func (s *MemoryStore) WalkDescendantsMulti(ids []string, fn func(id string) error) error {
+	for _, id := range ids {
+		if id == "" {
+			return EmptyIDError()
+		}
+	}
+
+	// collect first, such that fn may access the store
+	s.mu.RLock()
+	if err := s.checkIDs(ids...); err != nil {
+		s.mu.RUnlock()
+		return err
+	}
+	var descendants []string
+	visited := make(map[string]struct{})
+	for _, id := range ids {
+		queue := []string{id}
+		for len(queue) > 0 {
+			current := queue[0]
+			queue = queue[1:]
+			for child := range s.children[current] {
+				if _, ok := visited[child]; !ok {
+					visited[child] = struct{}{}
+					descendants = append(descendants, child)
+					queue = append(queue, child)
+				}
+			}
+		}
+	}
+	s.mu.RUnlock()
+
+	for _, id := range descendants {
+		if err := fn(id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkIDs returns an error, if any of the given ids is unknown.
+func (s *MemoryStore) checkIDs(ids ...string) error {
+	for _, id := range ids {
+		if _, exists := s.vertices[id]; !exists {
+			return NewUnknownKeyError(id)
+		}
+	}
+	return nil
+}
+
+// distance returns the number of edges of the shortest path from src to dst
+// (or -1, if there is no such path).
+func (s *MemoryStore) distance(src, dst string) int {
+	distances := map[string]int{src: 0}
+	queue := []string{src}
+	for len(queue) > 0 {
+		current := queue[0]
+		queue = queue[1:]
+		if current == dst {
+			return distances[current]
+		}
+		for child := range s.children[current] {
+			if _, ok := distances[child]; !ok {
+				distances[child] = distances[current] + 1
+				queue = append(queue, child)
+			}
+		}
+	}
+	return -1
+}
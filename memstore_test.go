@@ -0,0 +1,73 @@
+package arangodag
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestMemoryStore(t *testing.T) {
+	var s GraphStore = NewMemoryStore()
+
+	id1, _ := s.AddVertex(idVertex{MyID: "1"})
+	id2, _ := s.AddVertex(idVertex{MyID: "2"})
+	id3, err := s.AddVertex(foobar{A: "foo", B: "bar"})
+	if err != nil {
+		t.Fatalf("failed to AddVertex(): %v", err)
+	}
+	if _, err := s.AddVertex(idVertex{MyID: "1"}); !IsDuplicateIDError(err) {
+		t.Errorf("want DuplicateIDError, got %v", err)
+	}
+	if _, err := s.AddVertex(nil); !IsVertexNilError(err) {
+		t.Errorf("want VertexNilError, got %v", err)
+	}
+
+	var v foobar
+	if err := s.GetVertex(id3, &v); err != nil || v.A != "foo" {
+		t.Errorf("GetVertex() = %v (%v), want %v", v, err, foobar{A: "foo", B: "bar"})
+	}
+	if err := s.GetVertex("foo", &v); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+
+	_ = s.AddEdge(id1, id2)
+	_ = s.AddEdge(id2, id3)
+	if err := s.AddEdge(id1, id2); !IsDuplicateEdgeError(err) {
+		t.Errorf("want DuplicateEdgeError, got %v", err)
+	}
+	if err := s.AddEdge(id3, id1); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if order, _ := s.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := s.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+	if length, _ := s.GetShortestPathLength(id1, id3); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+
+	var visited []string
+	_ = s.WalkDescendantsMulti([]string{id1}, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	sort.Strings(visited)
+	want := []string{id2, id3}
+	sort.Strings(want)
+	if diff := deep.Equal(visited, want); diff != nil {
+		t.Error(diff)
+	}
+
+	if err := s.DeleteEdge(id2, id3); err != nil {
+		t.Fatalf("failed to DeleteEdge(): %v", err)
+	}
+	if err := s.DeleteEdge(id2, id3); !IsUnknownEdgeError(err) {
+		t.Errorf("want UnknownEdgeError, got %v", err)
+	}
+	if length, _ := s.GetShortestPathLength(id1, id3); length != -1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, -1)
+	}
+}
@@ -0,0 +1,147 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/arangodb/go-driver"
+	"time"
+)
+
+// Default attributes holding the modification timestamps of vertex and edge
+// documents (see WithModificationTimestamps).
+const (
+	CreatedAtAttribute = "createdAt"
+	UpdatedAtAttribute = "updatedAt"
+)
+
+// WithModificationTimestamps enables stamping vertex and edge documents with
+// the time of their creation and of their last update (as ISO 8601 strings,
+// see GetRecentlyModified). The timestamps are stored in the given document
+// attributes (i.e. next to "payload"), empty names referring to the defaults
+// CreatedAtAttribute and UpdatedAtAttribute. With timestamps enabled, each
+// mutation and its stamping are written within a single transaction.
+func WithModificationTimestamps(createdAttr, updatedAttr string) Option {
+	return func(d *DAG) {
+		if createdAttr == "" {
+			createdAttr = CreatedAtAttribute
+		}
+		if updatedAttr == "" {
+			updatedAttr = UpdatedAtAttribute
+		}
+		d.createdAttribute = createdAttr
+		d.updatedAttribute = updatedAttr
+	}
+}
+
+// stampChanges sets the modification timestamps (if enabled) of the vertices
+// or edges (as given by typ) with the given document ids. The creation
+// timestamp is only set, if missing.
+func (d *DAG) stampChanges(ctx context.Context, typ string, ids ...driver.DocumentID) error {
+	if d.updatedAttribute == "" || len(ids) == 0 {
+		return nil
+	}
+	coll := d.vertices
+	if typ == changeEdge {
+		coll = d.edges
+	}
+	query := `
+LET now = DATE_ISO8601(DATE_NOW())
+FOR id IN @ids
+  LET doc = DOCUMENT(id)
+  FILTER doc != null
+  UPDATE doc WITH {
+    [@created]: doc[@created] == null ? now : doc[@created],
+    [@updated]: now
+  } IN @@coll`
+	bindVars := map[string]interface{}{
+		"@coll":   coll.Name(),
+		"ids":     ids,
+		"created": d.createdAttribute,
+		"updated": d.updatedAttribute,
+	}
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return arangoError(err)
+	}
+	closeCursor(cursor)
+	return nil
+}
+
+// Modification is a vertex or an edge modified recently (see
+// GetRecentlyModified).
+type Modification struct {
+
+	// Type is either "vertex" or "edge".
+	Type string `json:"type"`
+
+	// ID is the id of the vertex (empty for edges).
+	ID string `json:"id,omitempty"`
+
+	// Src and Dst are the ids of the source and the destination vertex of the
+	// edge (empty for vertices).
+	Src string `json:"src,omitempty"`
+	Dst string `json:"dst,omitempty"`
+
+	// CreatedAt and UpdatedAt are the modification timestamps.
+	CreatedAt time.Time `json:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt"`
+}
+
+// GetRecentlyModified returns the vertices and edges created or updated at or
+// after since, ordered by the time of their last update. Deletions are not
+// reported (see WithChangeLog). GetRecentlyModified returns an error, if
+// modification timestamps are not enabled (see WithModificationTimestamps).
+func (d *DAG) GetRecentlyModified(since time.Time) ([]Modification, error) {
+	return d.GetRecentlyModifiedCtx(context.Background(), since)
+}
+
+// GetRecentlyModifiedCtx is like GetRecentlyModified but uses the given
+// context.
+func (d *DAG) GetRecentlyModifiedCtx(ctx context.Context, since time.Time) ([]Modification, error) {
+	if d.updatedAttribute == "" {
+		return nil, errors.New("modification timestamps are not enabled")
+	}
+	query := `
+FOR m IN UNION(
+  (FOR v IN @@vertices
+    FILTER v[@updated] != null AND DATE_TIMESTAMP(v[@updated]) >= @since
+    RETURN {type: "vertex", id: v._key, createdAt: v[@created], updatedAt: v[@updated]}),
+  (FOR e IN @@edges
+    FILTER e[@updated] != null AND DATE_TIMESTAMP(e[@updated]) >= @since
+    RETURN {
+      type: "edge",
+      src: PARSE_IDENTIFIER(e._from).key,
+      dst: PARSE_IDENTIFIER(e._to).key,
+      createdAt: e[@created],
+      updatedAt: e[@updated]
+    })
+)
+  SORT DATE_TIMESTAMP(m.updatedAt), m.type DESC, m.id, m.src, m.dst
+  RETURN m`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+		"created":   d.createdAttribute,
+		"updated":   d.updatedAttribute,
+		"since":     milliseconds(since),
+	}
+	modifications := []Modification{}
+	err := d.forEachDocument(d.context(ctx), query, bindVars)(func(doc json.RawMessage) error {
+		var m Modification
+		if err := json.Unmarshal(doc, &m); err != nil {
+			return err
+		}
+		if m.Type == changeVertex {
+			m.ID = d.id(m.ID)
+		} else {
+			m.Src, m.Dst = d.id(m.Src), d.id(m.Dst)
+		}
+		modifications = append(modifications, m)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return modifications, nil
+}
@@ -0,0 +1,44 @@
+package arangodag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDAG_GetRecentlyModified(t *testing.T) {
+	d := someNewDag(t, WithModificationTimestamps("", ""))
+	for _, id := range []string{"1", "2"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	time.Sleep(10 * time.Millisecond)
+	since := time.Now()
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+	time.Sleep(10 * time.Millisecond)
+	_ = d.AddEdge("1", "3")
+	time.Sleep(10 * time.Millisecond)
+	if err := d.Pin("1"); err != nil {
+		t.Fatalf("failed to Pin(): %v", err)
+	}
+
+	modifications, err := d.GetRecentlyModified(since)
+	if err != nil {
+		t.Fatalf("failed to GetRecentlyModified(): %v", err)
+	}
+	if len(modifications) != 3 {
+		t.Fatalf("GetRecentlyModified() = %v, want 3 modifications", modifications)
+	}
+	if m := modifications[0]; m.Type != "vertex" || m.ID != "3" || !m.CreatedAt.Equal(m.UpdatedAt) {
+		t.Errorf("got %v, want creation of vertex 3", m)
+	}
+	if m := modifications[1]; m.Type != "edge" || m.Src != "1" || m.Dst != "3" {
+		t.Errorf("got %v, want edge 1 -> 3", m)
+	}
+	if m := modifications[2]; m.Type != "vertex" || m.ID != "1" || !m.CreatedAt.Before(since) || m.UpdatedAt.Before(since) {
+		t.Errorf("got %v, want update of vertex 1", m)
+	}
+
+	// not enabled
+	if _, err := someNewDag(t).GetRecentlyModified(since); err == nil {
+		t.Error("GetRecentlyModified() should fail without timestamps")
+	}
+}
@@ -0,0 +1,220 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+	"math"
+)
+
+// Page is a page of vertex ids together with the total number of ids (i.e.
+// over all pages).
+type Page struct {
+	IDs   []string
+	Total int64
+}
+
+// GetVerticesPage returns the page of (at most) limit vertex ids (ordered by
+// id) starting at offset. The total number of vertices is determined by the
+// same query.
+func (d *DAG) GetVerticesPage(offset, limit int) (Page, error) {
+	return d.GetVerticesPageCtx(context.Background(), offset, limit)
+}
+
+// GetVerticesPageCtx is like GetVerticesPage but uses the given context.
+func (d *DAG) GetVerticesPageCtx(ctx context.Context, offset, limit int) (Page, error) {
+	ctx = d.context(ctx)
+	acl := aclFilter(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + allMatch(acl, "[v]") + `
+  SORT v._key
+  LIMIT @offset, @limit
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"offset":    offset,
+		"limit":     limit,
+	}
+	addBindVars(bindVars, acl)
+	return d.queryPage(ctx, query, bindVars)
+}
+
+// GetDescendantsPage returns the page of (at most) limit ids of descendants
+// (in breadth-first order) of the vertex with the given id starting at offset.
+// The total number of descendants is determined by the same query.
+// GetDescendantsPage returns an error, if id is empty or unknown.
+func (d *DAG) GetDescendantsPage(id string, offset, limit int) (Page, error) {
+	return d.GetDescendantsPageCtx(context.Background(), id, offset, limit)
+}
+
+// GetDescendantsPageCtx is like GetDescendantsPage but uses the given context.
+func (d *DAG) GetDescendantsPageCtx(ctx context.Context, id string, offset, limit int) (Page, error) {
+	if id == "" {
+		return Page{}, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return Page{}, err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+		"offset":   offset,
+		"limit":    limit,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars) + `
+  LIMIT @offset, @limit
+  RETURN v._key`
+	return d.queryPage(ctx, query, bindVars)
+}
+
+// queryPage runs the given (limited) query and returns its results together
+// with the number of results without limit (i.e. AQL's fullCount).
+func (d *DAG) queryPage(ctx context.Context, query string, bindVars map[string]interface{}) (Page, error) {
+	cursor, err := d.db.Query(driver.WithQueryFullCount(ctx), query, bindVars)
+	if err != nil {
+		return Page{}, arangoError(err)
+	}
+	defer closeCursor(cursor)
+	page := Page{IDs: []string{}}
+	for {
+		var id string
+		_, err := cursor.ReadDocument(ctx, &id)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return Page{}, arangoError(err)
+		}
+		page.IDs = append(page.IDs, d.id(id))
+	}
+	page.Total = cursor.Statistics().FullCount()
+	return page, nil
+}
+
+// unlimited is the count of LIMIT operations with an offset, but no limit.
+const unlimited = math.MaxInt32
+
+// NeighbourOptions configures GetChildrenWith and GetParentsWith.
+type NeighbourOptions struct {
+
+	// SortBy is the attribute to sort by (a dot separated path relative to the
+	// stored document, e.g. "payload.size"). Vertices with equal values (or
+	// all vertices, if SortBy is empty) are sorted by id.
+	SortBy string
+
+	// Descending reverses the order of SortBy.
+	Descending bool
+
+	// Offset is the number of (sorted) vertices to skip.
+	Offset int
+
+	// Limit is the maximum number of vertices returned. Zero returns all.
+	Limit int
+}
+
+// GetChildrenPage returns the page of (at most) limit ids of children (ordered
+// by id) of the vertex with the given id starting at offset. The total number
+// of children is determined by the same query. GetChildrenPage returns an
+// error, if id is empty or unknown.
+func (d *DAG) GetChildrenPage(id string, offset, limit int) (Page, error) {
+	return d.GetChildrenPageCtx(context.Background(), id, offset, limit)
+}
+
+// GetChildrenPageCtx is like GetChildrenPage but uses the given context.
+func (d *DAG) GetChildrenPageCtx(ctx context.Context, id string, offset, limit int) (Page, error) {
+	return d.getNeighboursPage(ctx, id, "OUTBOUND", &NeighbourOptions{Offset: offset, Limit: limit})
+}
+
+// GetParentsPage returns the page of (at most) limit ids of parents (ordered
+// by id) of the vertex with the given id starting at offset. See
+// GetChildrenPage.
+func (d *DAG) GetParentsPage(id string, offset, limit int) (Page, error) {
+	return d.GetParentsPageCtx(context.Background(), id, offset, limit)
+}
+
+// GetParentsPageCtx is like GetParentsPage but uses the given context.
+func (d *DAG) GetParentsPageCtx(ctx context.Context, id string, offset, limit int) (Page, error) {
+	return d.getNeighboursPage(ctx, id, "INBOUND", &NeighbourOptions{Offset: offset, Limit: limit})
+}
+
+// GetChildrenWith returns the page of ids of children of the vertex with the
+// given id as configured by opts (which may be nil), e.g. the 20 largest
+// children:
+//
+//	page, err := d.GetChildrenWith(id, &NeighbourOptions{SortBy: "payload.size", Descending: true, Limit: 20})
+//
+// Sorting and limiting is done server side. The total number of children is
+// determined by the same query. GetChildrenWith returns an error, if id is
+// empty or unknown.
+func (d *DAG) GetChildrenWith(id string, opts *NeighbourOptions) (Page, error) {
+	return d.GetChildrenWithCtx(context.Background(), id, opts)
+}
+
+// GetChildrenWithCtx is like GetChildrenWith but uses the given context.
+func (d *DAG) GetChildrenWithCtx(ctx context.Context, id string, opts *NeighbourOptions) (Page, error) {
+	return d.getNeighboursPage(ctx, id, "OUTBOUND", opts)
+}
+
+// GetParentsWith returns the page of ids of parents of the vertex with the
+// given id as configured by opts (see GetChildrenWith).
+func (d *DAG) GetParentsWith(id string, opts *NeighbourOptions) (Page, error) {
+	return d.GetParentsWithCtx(context.Background(), id, opts)
+}
+
+// GetParentsWithCtx is like GetParentsWith but uses the given context.
+func (d *DAG) GetParentsWithCtx(ctx context.Context, id string, opts *NeighbourOptions) (Page, error) {
+	return d.getNeighboursPage(ctx, id, "INBOUND", opts)
+}
+
+// getNeighboursPage returns the page of neighbours of the vertex with the given
+// id in the given direction ("OUTBOUND" or "INBOUND") as configured by opts.
+func (d *DAG) getNeighboursPage(ctx context.Context, id, direction string, opts *NeighbourOptions) (Page, error) {
+	if id == "" {
+		return Page{}, EmptyIDError()
+	}
+	if opts == nil {
+		opts = &NeighbourOptions{}
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return Page{}, err
+	}
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"start":  start,
+	}
+	sort := "v._key"
+	if opts.SortBy != "" {
+		order := "ASC"
+		if opts.Descending {
+			order = "DESC"
+		}
+		sort = "v.@sortBy " + order + ", v._key"
+		bindVars["sortBy"] = attributePath(opts.SortBy)
+	}
+	limit := ""
+	if opts.Offset > 0 || opts.Limit > 0 {
+		limit = "\n  LIMIT @offset, @limit"
+		bindVars["offset"] = opts.Offset
+		bindVars["limit"] = opts.Limit
+		if opts.Limit <= 0 {
+			bindVars["limit"] = unlimited
+		}
+	}
+	acl := aclFilter(ctx)
+	addBindVars(bindVars, acl)
+	query := `
+FOR v IN 1 ` + direction + ` @start @@edges
+  FILTER ` + allMatch(acl, "[v]") + `
+  SORT ` + sort + limit + `
+  RETURN v._key`
+	return d.queryPage(ctx, query, bindVars)
+}
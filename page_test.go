@@ -0,0 +1,119 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetVerticesPage(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+
+	page, err := d.GetVerticesPage(2, 2)
+	if err != nil {
+		t.Fatalf("failed to GetVerticesPage(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"3", "4"}, Total: 5}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDAG_GetDescendantsPage(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+
+	page, err := d.GetDescendantsPage("1", 0, 2)
+	if err != nil {
+		t.Fatalf("failed to GetDescendantsPage(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"2", "3"}, Total: 3}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	if _, err := d.GetDescendantsPage("foo", 0, 2); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_GetChildrenPage(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("1", "4")
+	_ = d.AddEdge("2", "4")
+
+	page, err := d.GetChildrenPage("1", 1, 5)
+	if err != nil {
+		t.Fatalf("failed to GetChildrenPage(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"3", "4"}, Total: 3}); diff != nil {
+		t.Error(diff)
+	}
+	page, err = d.GetParentsPage("4", 0, 1)
+	if err != nil {
+		t.Fatalf("failed to GetParentsPage(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"1"}, Total: 2}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	if _, err := d.GetChildrenPage("foo", 0, 2); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+type sizedVertex struct {
+	Key  string `json:"key"`
+	Size int    `json:"size"`
+}
+
+func (v sizedVertex) ID() string {
+	return v.Key
+}
+
+func TestDAG_GetChildrenWith(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(sizedVertex{Key: "1"})
+	for i, size := range []int{20, 40, 10, 30} {
+		id, _ := d.AddVertex(sizedVertex{Key: string(rune('a' + i)), Size: size})
+		_ = d.AddEdge("1", id)
+	}
+
+	page, err := d.GetChildrenWith("1", &NeighbourOptions{SortBy: "payload.size", Descending: true, Limit: 2})
+	if err != nil {
+		t.Fatalf("failed to GetChildrenWith(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"b", "d"}, Total: 4}); diff != nil {
+		t.Error(diff)
+	}
+	page, err = d.GetChildrenWith("1", &NeighbourOptions{SortBy: "payload.size", Offset: 1})
+	if err != nil {
+		t.Fatalf("failed to GetChildrenWith(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"a", "d", "b"}, Total: 4}); diff != nil {
+		t.Error(diff)
+	}
+	page, err = d.GetParentsWith("c", nil)
+	if err != nil {
+		t.Fatalf("failed to GetParentsWith(): %v", err)
+	}
+	if diff := deep.Equal(page, Page{IDs: []string{"1"}, Total: 1}); diff != nil {
+		t.Error(diff)
+	}
+	if _, err := d.GetChildrenWith("", nil); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+}
@@ -0,0 +1,213 @@
+package arangodag
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"regexp"
+
+	"github.com/arangodb/go-driver"
+)
+
+// Partitioning is the result of SplitByAttribute.
+type Partitioning struct {
+
+	// Partitions maps attribute values to the DAGs holding the vertices with
+	// this value (and the edges between them).
+	Partitions map[string]*DAG
+
+	// CrossEdges is the name of the edge collection holding the edges between
+	// vertices of different partitions.
+	CrossEdges string
+}
+
+var invalidCollectionChars = regexp.MustCompile(`[^A-Za-z0-9_-]`)
+
+// partitionSuffix returns the suffix of the collection names of the partition
+// of the given value, i.e. the sanitized value followed by a hash of the
+// value itself (such that values sanitized alike don't collide).
+func partitionSuffix(value string) string {
+	if value == "" {
+		return "_default"
+	}
+	sum := sha256.Sum256([]byte(value))
+	return "_" + invalidCollectionChars.ReplaceAllString(value, "_") + "_" + hex.EncodeToString(sum[:4])
+}
+
+// SplitByAttribute copies the vertices into separate DAGs (in the same
+// database) by the value of their attribute attr (a dot separated path
+// relative to the stored document, e.g. "payload.team"). Vertices without
+// this attribute end up in the partition of the empty value. Edges between
+// vertices of the same partition are copied into the partition's edge
+// collection, edges between partitions are copied into a separate edge
+// collection (see Partitioning). The collections are named after the
+// collections of d, suffixed by the sanitized value and a hash of the value
+// (or "_default" for the empty value and "_cross" for the edges between
+// partitions). The partitions are configured by the options d was created
+// with. SplitByAttribute reads a consistent snapshot of the graph and writes
+// the partitions within a single transaction. If the split fails, the
+// collections created by it are removed again. The DAG d itself is left
+// untouched.
+func (d *DAG) SplitByAttribute(attr string) (*Partitioning, error) {
+	return d.SplitByAttributeCtx(context.Background(), attr)
+}
+
+// SplitByAttributeCtx is like SplitByAttribute but uses the given context.
+func (d *DAG) SplitByAttributeCtx(ctx context.Context, attr string) (*Partitioning, error) {
+	ctx = d.context(ctx)
+	p := &Partitioning{
+		CrossEdges: d.edges.Name() + "_cross",
+	}
+
+	// collections created (to be removed on failure)
+	var created []*DAG
+	var cross driver.Collection
+	crossCreated := false
+	cleanup := func() {
+		for _, partition := range created {
+			partition.drop(ctx)
+		}
+		if crossCreated {
+			_ = cross.Remove(d.context(detachedContext{ctx}))
+		}
+	}
+
+	err := d.readTransaction(ctx, func(rctx context.Context) error {
+
+		// partition values
+		query := `
+FOR v IN @@vertices
+  COLLECT value = TO_STRING(v.@attr)
+  RETURN value`
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"attr":      attributePath(attr),
+		}
+		var values []string
+		err := d.forEachDocument(rctx, query, bindVars)(func(doc json.RawMessage) error {
+			var value string
+			if err := json.Unmarshal(doc, &value); err != nil {
+				return err
+			}
+			values = append(values, value)
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+
+		// partitions (collections can't be created within transactions)
+		p.Partitions = make(map[string]*DAG, len(values))
+		collections := make(map[string]string, len(values))
+		cols := driver.TransactionCollections{}
+		for _, value := range values {
+			suffix := partitionSuffix(value)
+			exists, err := d.db.CollectionExists(ctx, d.vertices.Name()+suffix)
+			if err != nil {
+				return arangoError(err)
+			}
+			partition, err := d.derive(ctx, d.vertices.Name()+suffix, d.edges.Name()+suffix)
+			if err != nil {
+				return err
+			}
+			if !exists {
+				created = append(created, partition)
+			}
+			p.Partitions[value] = partition
+			collections[value] = partition.vertices.Name()
+			cols.Write = append(cols.Write, partition.collectionNames()...)
+		}
+		exists, err := d.db.CollectionExists(ctx, p.CrossEdges)
+		if err != nil {
+			return arangoError(err)
+		}
+		if cross, err = useOrCreateCollection(ctx, d.db, p.CrossEdges, edgeCollectionOptions()); err != nil {
+			return arangoError(err)
+		}
+		crossCreated = !exists
+		cols.Write = append(cols.Write, cross.Name())
+
+		return d.runTransaction(ctx, cols, func(wctx context.Context) error {
+
+			// vertices
+			for value, partition := range p.Partitions {
+				query := `
+FOR v IN @@vertices
+  FILTER TO_STRING(v.@attr) == @value
+  RETURN UNSET(v, "_id", "_rev")`
+				bindVars := map[string]interface{}{
+					"@vertices": d.vertices.Name(),
+					"attr":      attributePath(attr),
+					"value":     value,
+				}
+				if err := copyDocuments(wctx, partition.vertices, d.forEachDocument(rctx, query, bindVars), partition.afterCopy(wctx, changeVertex)); err != nil {
+					return err
+				}
+			}
+
+			// edges (within and between partitions)
+			query := `
+FOR e IN @@edges
+  LET from = TO_STRING(DOCUMENT(e._from).@attr)
+  LET to = TO_STRING(DOCUMENT(e._to).@attr)
+  LET edge = MERGE(UNSET(e, "_id", "_key", "_rev"), {
+    _from: CONCAT(@collections[from], "/", PARSE_IDENTIFIER(e._from).key),
+    _to: CONCAT(@collections[to], "/", PARSE_IDENTIFIER(e._to).key)
+  })
+  RETURN {partition: from == to ? from : null, edge: edge}`
+			bindVars := map[string]interface{}{
+				"@edges":      d.edges.Name(),
+				"attr":        attributePath(attr),
+				"collections": collections,
+			}
+			batches := make(map[string][]json.RawMessage)
+			var crossBatch []json.RawMessage
+			err := d.forEachDocument(rctx, query, bindVars)(func(doc json.RawMessage) error {
+				var item struct {
+					Partition *string         `json:"partition"`
+					Edge      json.RawMessage `json:"edge"`
+				}
+				if err := json.Unmarshal(doc, &item); err != nil {
+					return err
+				}
+				if item.Partition == nil {
+					crossBatch = append(crossBatch, item.Edge)
+				} else {
+					batches[*item.Partition] = append(batches[*item.Partition], item.Edge)
+				}
+				return nil
+			})
+			if err != nil {
+				return err
+			}
+			for value, edges := range batches {
+				partition := p.Partitions[value]
+				if err := copyDocuments(wctx, partition.edges, sliceIterator(edges), partition.afterCopy(wctx, changeEdge)); err != nil {
+					return err
+				}
+			}
+			return copyDocuments(wctx, cross, sliceIterator(crossBatch), nil)
+		})
+	})
+	if err != nil {
+		cleanup()
+		return nil, err
+	}
+	for _, partition := range p.Partitions {
+		partition.counts.invalidate()
+	}
+	return p, nil
+}
+
+// sliceIterator returns an iterator over the given documents.
+func sliceIterator(docs []json.RawMessage) documentIterator {
+	return func(fn func(doc json.RawMessage) error) error {
+		for _, doc := range docs {
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
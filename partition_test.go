@@ -0,0 +1,78 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDAG_SplitByAttribute(t *testing.T) {
+	d := someNewDag(t)
+
+	// b1 -> b2 -> a1 -> a2, b1 -> a2
+	for _, id := range []string{"b1", "b2"} {
+		_, _ = d.AddVertex(typedVertex{MyID: id, Type: "build"})
+	}
+	for _, id := range []string{"a1", "a2"} {
+		_, _ = d.AddVertex(typedVertex{MyID: id, Type: "artifact"})
+	}
+	_ = d.AddEdge("b1", "b2")
+	_ = d.AddEdge("b2", "a1")
+	_ = d.AddEdge("a1", "a2")
+	_ = d.AddEdge("b1", "a2")
+
+	p, err := d.SplitByAttribute("payload.type")
+	if err != nil {
+		t.Fatalf("failed to SplitByAttribute(): %v", err)
+	}
+	if len(p.Partitions) != 2 {
+		t.Fatalf("len(Partitions) = %d, want %d", len(p.Partitions), 2)
+	}
+	for _, value := range []string{"build", "artifact"} {
+		partition, ok := p.Partitions[value]
+		if !ok {
+			t.Fatalf("missing partition %s", value)
+		}
+		if order, _ := partition.GetOrder(); order != 2 {
+			t.Errorf("GetOrder() = %d, want %d", order, 2)
+		}
+		if size, _ := partition.GetSize(); size != 1 {
+			t.Errorf("GetSize() = %d, want %d", size, 1)
+		}
+	}
+	var v typedVertex
+	if err := p.Partitions["build"].GetVertex("b1", &v); err != nil {
+		t.Errorf("GetVertex() failed: %v", err)
+	}
+
+	cross, err := d.db.Collection(context.Background(), p.CrossEdges)
+	if err != nil {
+		t.Fatalf("failed to get cross edges collection: %v", err)
+	}
+	if count, _ := cross.Count(context.Background()); count != 2 {
+		t.Errorf("Count() = %d, want %d", count, 2)
+	}
+
+	// the original DAG stays untouched
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+}
+
+func TestDAG_SplitByAttribute_sanitizedCollision(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(typedVertex{MyID: "1", Type: "a b"})
+	_, _ = d.AddVertex(typedVertex{MyID: "2", Type: "a_b"})
+
+	p, err := d.SplitByAttribute("payload.type")
+	if err != nil {
+		t.Fatalf("failed to SplitByAttribute(): %v", err)
+	}
+	if p.Partitions["a b"].vertices.Name() == p.Partitions["a_b"].vertices.Name() {
+		t.Errorf("partitions share the collection %s", p.Partitions["a b"].vertices.Name())
+	}
+	for _, value := range []string{"a b", "a_b"} {
+		if order, _ := p.Partitions[value].GetOrder(); order != 1 {
+			t.Errorf("GetOrder() = %d, want %d", order, 1)
+		}
+	}
+}
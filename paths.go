@@ -0,0 +1,351 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// GetShortestPathLength returns the number of edges of the shortest path from
+// the vertex with the id srcID to the vertex with the id dstID, without
+// transferring the path itself. GetShortestPathLength returns 0, if srcID and
+// dstID are equal, and -1, if there is no such path. GetShortestPathLength
+// returns an error, if srcID or dstID are empty or unknown.
+func (d *DAG) GetShortestPathLength(srcID, dstID string) (int, error) {
+	return d.GetShortestPathLengthCtx(context.Background(), srcID, dstID)
+}
+
+// GetShortestPathLengthCtx is like GetShortestPathLength but uses the given
+// context.
+func (d *DAG) GetShortestPathLengthCtx(ctx context.Context, srcID, dstID string) (int, error) {
+	ctx = d.context(ctx)
+	query := `
+LET path = (FOR v IN OUTBOUND SHORTEST_PATH @src TO @dst @@edges RETURN 1)
+RETURN LENGTH(path) - 1`
+	var bindVars map[string]interface{}
+	if acl := aclFilter(ctx); acl != nil {
+
+		// shortest paths can't skip hidden vertices, thus, search
+		// breadth-first (which reaches dst via a shortest path)
+		query = `
+LET lengths = @src == @dst ? [0] : (
+  FOR v, e, p IN 1..@maxDepth OUTBOUND @src @@edges
+    PRUNE v._id == @dst OR NOT (` + allMatch(acl, "[v]") + `)
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER v._id == @dst
+    LIMIT 1
+    RETURN LENGTH(p.edges)
+)
+RETURN LENGTH(lengths) == 0 ? -1 : FIRST(lengths)`
+		bindVars = map[string]interface{}{"maxDepth": maxDepth}
+	}
+	var length int
+	err := d.shortestPathQuery(ctx, srcID, dstID, query, bindVars, &length)
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// GetShortestPathWeight returns the (summed up) weight of the shortest
+// weighted path from the vertex with the id srcID to the vertex with the id
+// dstID, without transferring the path itself. The weight of an edge is taken
+// from its attribute weightAttribute. Edges without this attribute weigh
+// defaultWeight. GetShortestPathWeight returns 0, if srcID and dstID are equal,
+// and -1, if there is no such path. GetShortestPathWeight returns an error, if
+// srcID or dstID are empty or unknown.
+func (d *DAG) GetShortestPathWeight(srcID, dstID, weightAttribute string, defaultWeight float64) (float64, error) {
+	return d.GetShortestPathWeightCtx(context.Background(), srcID, dstID, weightAttribute, defaultWeight)
+}
+
+// GetShortestPathWeightCtx is like GetShortestPathWeight but uses the given
+// context.
+func (d *DAG) GetShortestPathWeightCtx(ctx context.Context, srcID, dstID, weightAttribute string, defaultWeight float64) (float64, error) {
+	var weight float64
+	bindVars := map[string]interface{}{
+		"weightAttribute": weightAttribute,
+		"defaultWeight":   defaultWeight,
+	}
+	ctx = d.context(ctx)
+	query := `
+LET weights = (
+  FOR v, e IN OUTBOUND SHORTEST_PATH @src TO @dst @@edges
+    OPTIONS {weightAttribute: @weightAttribute, defaultWeight: @defaultWeight}
+    RETURN e == null ? 0 : (HAS(e, @weightAttribute) ? e.@weightAttribute : @defaultWeight)
+)
+RETURN LENGTH(weights) == 0 ? -1 : SUM(weights)`
+	if acl := aclFilter(ctx); acl != nil {
+
+		// the shortest of the paths not passing hidden vertices
+		query = `
+LET weights = @src == @dst ? [0] : (
+  FOR p IN OUTBOUND K_SHORTEST_PATHS @src TO @dst @@edges
+    OPTIONS {weightAttribute: @weightAttribute, defaultWeight: @defaultWeight}
+    FILTER ` + allMatch(acl, "p.vertices") + `
+    LIMIT 1
+    RETURN p.weight
+)
+RETURN LENGTH(weights) == 0 ? -1 : FIRST(weights)`
+	}
+	err := d.shortestPathQuery(ctx, srcID, dstID, query, bindVars, &weight)
+	if err != nil {
+		return 0, err
+	}
+	return weight, nil
+}
+
+// shortestPathQuery resolves srcID and dstID (as "src" and "dst") and decodes
+// the single result of the given query into result. If ctx carries a principal
+// (see WithPrincipal), query has to use the bind variables of aclFilter.
+func (d *DAG) shortestPathQuery(ctx context.Context, srcID, dstID, query string, bindVars map[string]interface{}, result interface{}) error {
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	src, err := d.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return err
+	}
+	dst, err := d.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return err
+	}
+	vars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    src,
+		"dst":    dst,
+	}
+	for k, v := range bindVars {
+		vars[k] = v
+	}
+	addBindVars(vars, aclFilter(ctx))
+	return d.forEachDocument(ctx, query, vars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, result)
+	})
+}
+
+// distancesBatchSize is the number of sources handled per query by
+// GetDistances.
+const distancesBatchSize = 100
+
+// GetDistances returns the number of edges of the shortest paths from each of
+// the vertices with the ids srcIDs to each of the vertices with the ids dstIDs
+// (i.e. result[src][dst]), using one (breadth-first) traversal per source and
+// batching multiple sources per query. Distances of unreachable destinations
+// are -1. GetDistances returns an error, if any of the ids is empty or unknown.
+func (d *DAG) GetDistances(srcIDs, dstIDs []string) (map[string]map[string]int, error) {
+	return d.GetDistancesCtx(context.Background(), srcIDs, dstIDs)
+}
+
+// GetDistancesCtx is like GetDistances but uses the given context.
+func (d *DAG) GetDistancesCtx(ctx context.Context, srcIDs, dstIDs []string) (map[string]map[string]int, error) {
+	ctx = d.context(ctx)
+	srcs, err := d.vertexDocumentIDs(ctx, srcIDs)
+	if err != nil {
+		return nil, err
+	}
+	dsts, err := d.vertexDocumentIDs(ctx, dstIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	distances := make(map[string]map[string]int, len(srcIDs))
+	for _, src := range srcIDs {
+		distances[src] = make(map[string]int, len(dstIDs))
+		for _, dst := range dstIDs {
+			distances[src][dst] = -1
+			if src == dst {
+				distances[src][dst] = 0
+			}
+		}
+	}
+
+	for start := 0; start < len(srcs); start += distancesBatchSize {
+		end := start + distancesBatchSize
+		if end > len(srcs) {
+			end = len(srcs)
+		}
+		bindVars := map[string]interface{}{
+			"srcs":     srcs[start:end],
+			"dsts":     dsts,
+			"maxDepth": maxDepth,
+		}
+		query := `
+LET dsts = ZIP(@dsts, @dsts)
+FOR src IN @srcs
+  ` + d.traverse(ctx, traversalSpec{
+			direction: "OUTBOUND",
+			start:     "src",
+			depth:     "1..@maxDepth",
+			edges:     "edges",
+		}, "    ", bindVars) + `
+    FILTER HAS(dsts, v._id)
+    RETURN {src: PARSE_IDENTIFIER(src).key, dst: v._key, distance: LENGTH(p.edges)}`
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var item struct {
+				Src      string `json:"src"`
+				Dst      string `json:"dst"`
+				Distance int    `json:"distance"`
+			}
+			if err := json.Unmarshal(doc, &item); err != nil {
+				return err
+			}
+			distances[d.id(item.Src)][d.id(item.Dst)] = item.Distance
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return distances, nil
+}
+
+// WeightAttribute is the name of the edge attribute holding the edge weight
+// for weighted path queries (e.g. GetKShortestPaths). Edges without this
+// attribute weigh 1.
+const WeightAttribute = "weight"
+
+// Path is a path through the DAG.
+type Path struct {
+
+	// Vertices are the ids of the vertices along the path (starting with the
+	// source and ending with the destination).
+	Vertices []string `json:"vertices"`
+
+	// Weight is the (summed up) weight of the path's edges (see
+	// WeightAttribute). For unweighted paths, Weight is the number of edges.
+	Weight float64 `json:"weight"`
+}
+
+// GetKShortestPaths returns (up to) the k shortest paths from the vertex with
+// the id srcID to the vertex with the id dstID, ordered by their length (or
+// weight, if weighted is true; see WeightAttribute). GetKShortestPaths returns
+// an error, if srcID or dstID are empty or unknown.
+func (d *DAG) GetKShortestPaths(srcID, dstID string, k int, weighted bool) ([]Path, error) {
+	return d.GetKShortestPathsCtx(context.Background(), srcID, dstID, k, weighted)
+}
+
+// GetKShortestPathsCtx is like GetKShortestPaths but uses the given context.
+func (d *DAG) GetKShortestPathsCtx(ctx context.Context, srcID, dstID string, k int, weighted bool) ([]Path, error) {
+	ctx = d.context(ctx)
+	options := ""
+	if weighted {
+		options = "OPTIONS {weightAttribute: @weightAttribute, defaultWeight: 1}"
+	}
+	query := `
+LET paths = (
+  FOR p IN OUTBOUND K_SHORTEST_PATHS @src TO @dst @@edges ` + options + `
+    FILTER ` + allMatch(aclFilter(ctx), "p.vertices") + `
+    LIMIT @k
+    RETURN {vertices: p.vertices[*]._key, weight: p.weight}
+)
+RETURN paths`
+	bindVars := map[string]interface{}{
+		"k": k,
+	}
+	if weighted {
+		bindVars["weightAttribute"] = WeightAttribute
+	}
+	var paths []Path
+	if err := d.shortestPathQuery(ctx, srcID, dstID, query, bindVars, &paths); err != nil {
+		return nil, err
+	}
+	for _, p := range paths {
+		for i, key := range p.Vertices {
+			p.Vertices[i] = d.id(key)
+		}
+	}
+	return paths, nil
+}
+
+// GetShortestPathExcluding returns the ids of the vertices along a shortest
+// path from the vertex with the id srcID to the vertex with the id dstID not
+// passing any of the vertices with the given excluded ids (e.g. to check
+// whether there is still a path, if these vertices are down). The path starts
+// with srcID and ends with dstID. GetShortestPathExcluding returns nil, if
+// there is no such path (in particular, if srcID or dstID are excluded).
+// GetShortestPathExcluding returns an error, if srcID or dstID are empty or
+// unknown.
+func (d *DAG) GetShortestPathExcluding(srcID, dstID string, excludedIDs []string) ([]string, error) {
+	return d.GetShortestPathExcludingCtx(context.Background(), srcID, dstID, excludedIDs)
+}
+
+// GetShortestPathExcludingCtx is like GetShortestPathExcluding but uses the given
+// context.
+func (d *DAG) GetShortestPathExcludingCtx(ctx context.Context, srcID, dstID string, excludedIDs []string) ([]string, error) {
+	ctx = d.context(ctx)
+	query := `
+LET excluded = ZIP(@excluded, @excluded)
+LET paths = @src == @dst ? [[PARSE_IDENTIFIER(@src).key]] : (
+  FOR v, e, p IN 1..@maxDepth OUTBOUND @src @@edges
+    PRUNE HAS(excluded, v._key) OR v._id == @dst OR NOT (` + allMatch(aclFilter(ctx), "[v]") + `)
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER v._id == @dst
+    LIMIT 1
+    RETURN p.vertices[*]._key
+)
+RETURN HAS(excluded, PARSE_IDENTIFIER(@src).key) OR HAS(excluded, PARSE_IDENTIFIER(@dst).key) ? null : FIRST(paths)`
+	excluded := make([]string, len(excludedIDs))
+	for i, id := range excludedIDs {
+		excluded[i] = d.key(id)
+	}
+	bindVars := map[string]interface{}{
+		"excluded": excluded,
+		"maxDepth": maxDepth,
+	}
+	var path []string
+	if err := d.shortestPathQuery(ctx, srcID, dstID, query, bindVars, &path); err != nil {
+		return nil, err
+	}
+	for i, key := range path {
+		path[i] = d.id(key)
+	}
+	return path, nil
+}
+
+// GetDistancesFrom returns the (hop) distances of all descendants of the
+// vertex with the given id (i.e. the number of edges of their shortest paths),
+// computed by a single traversal. GetDistancesFrom returns an error, if id is
+// empty or unknown.
+func (d *DAG) GetDistancesFrom(id string) (map[string]int, error) {
+	return d.GetDistancesFromCtx(context.Background(), id)
+}
+
+// GetDistancesFromCtx is like GetDistancesFrom but uses the given context.
+func (d *DAG) GetDistancesFromCtx(ctx context.Context, id string) (map[string]int, error) {
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars) + `
+  RETURN {id: v._key, distance: LENGTH(p.edges)}`
+	distances := make(map[string]int)
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID       string `json:"id"`
+			Distance int    `json:"distance"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		distances[d.id(item.ID)] = item.Distance
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return distances, nil
+}
@@ -0,0 +1,197 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetShortestPathLength(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3 -> 4, 1 -> 4, 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "4")
+
+	tests := []struct {
+		src, dst string
+		want     int
+	}{
+		{"1", "1", 0},
+		{"1", "2", 1},
+		{"1", "3", 2},
+		{"1", "4", 1},
+		{"2", "4", 2},
+		{"4", "1", -1},
+		{"1", "5", -1},
+	}
+	for _, test := range tests {
+		length, err := d.GetShortestPathLength(test.src, test.dst)
+		if err != nil {
+			t.Errorf("failed to GetShortestPathLength(): %v", err)
+		}
+		if length != test.want {
+			t.Errorf("GetShortestPathLength(%s, %s) = %d, want %d", test.src, test.dst, length, test.want)
+		}
+	}
+
+	// weighted
+	weight, err := d.GetShortestPathWeight("2", "4", "weight", 1.5)
+	if err != nil {
+		t.Errorf("failed to GetShortestPathWeight(): %v", err)
+	}
+	if weight != 3 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, 3)
+	}
+	weight, _ = d.GetShortestPathWeight("4", "2", "weight", 1.5)
+	if weight != -1 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, -1)
+	}
+
+	// unknown
+	if _, err := d.GetShortestPathLength("1", "foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_GetDistances(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3 -> 4, 1 -> 4, 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "4")
+
+	distances, err := d.GetDistances([]string{"1", "2"}, []string{"2", "3", "4", "5"})
+	if err != nil {
+		t.Fatalf("failed to GetDistances(): %v", err)
+	}
+	want := map[string]map[string]int{
+		"1": {"2": 1, "3": 2, "4": 1, "5": -1},
+		"2": {"2": 0, "3": 1, "4": 2, "5": -1},
+	}
+	if diff := deep.Equal(distances, want); diff != nil {
+		t.Errorf("GetDistances() = %v, want %v", distances, want)
+	}
+
+	// unknown
+	if _, err := d.GetDistances([]string{"1"}, []string{"foo"}); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_GetKShortestPaths(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3 -> 4, 1 -> 4, 1 -> 3
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "4")
+	_ = d.AddEdge("1", "3")
+
+	paths, err := d.GetKShortestPaths("1", "4", 2, false)
+	if err != nil {
+		t.Fatalf("failed to GetKShortestPaths(): %v", err)
+	}
+	want := []Path{
+		{Vertices: []string{"1", "4"}, Weight: 1},
+		{Vertices: []string{"1", "3", "4"}, Weight: 2},
+	}
+	if diff := deep.Equal(paths, want); diff != nil {
+		t.Errorf("GetKShortestPaths() = %v, want %v", paths, want)
+	}
+
+	paths, err = d.GetKShortestPaths("1", "4", 10, true)
+	if err != nil {
+		t.Fatalf("failed to GetKShortestPaths(): %v", err)
+	}
+	if len(paths) != 3 {
+		t.Errorf("GetKShortestPaths() returned %d paths, want %d", len(paths), 3)
+	}
+
+	// no path
+	paths, err = d.GetKShortestPaths("4", "1", 10, false)
+	if err != nil {
+		t.Fatalf("failed to GetKShortestPaths(): %v", err)
+	}
+	if len(paths) != 0 {
+		t.Errorf("GetKShortestPaths() returned %d paths, want %d", len(paths), 0)
+	}
+}
+
+func TestDAG_GetShortestPathExcluding(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 4, 1 -> 3 -> 5 -> 4
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("3", "5")
+	_ = d.AddEdge("5", "4")
+
+	tests := []struct {
+		excluded []string
+		want     []string
+	}{
+		{nil, []string{"1", "2", "4"}},
+		{[]string{"2"}, []string{"1", "3", "5", "4"}},
+		{[]string{"2", "5"}, nil},
+		{[]string{"4"}, nil},
+	}
+	for _, test := range tests {
+		path, err := d.GetShortestPathExcluding("1", "4", test.excluded)
+		if err != nil {
+			t.Errorf("failed to GetShortestPathExcluding(): %v", err)
+		}
+		if diff := deep.Equal(path, test.want); diff != nil {
+			t.Errorf("GetShortestPathExcluding(1, 4, %v): %v", test.excluded, diff)
+		}
+	}
+
+	// unknown
+	if _, err := d.GetShortestPathExcluding("1", "foo", nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_GetDistancesFrom(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 1 -> 3, 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("3", "4")
+
+	distances, err := d.GetDistancesFrom("1")
+	if err != nil {
+		t.Fatalf("failed to GetDistancesFrom(): %v", err)
+	}
+	if diff := deep.Equal(distances, map[string]int{"2": 1, "3": 1, "4": 2}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	if _, err := d.GetDistancesFrom("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
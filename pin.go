@@ -0,0 +1,83 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+)
+
+// Pin protects the vertex with the given id from being deleted by
+// MarkAndSweep, PruneOlderThan, and the cascading deletes of the reference
+// counting mode (see WithRefCounting). Pin returns an error, if id is empty or
+// unknown.
+func (d *DAG) Pin(id string) error {
+	return d.PinCtx(context.Background(), id)
+}
+
+// PinCtx is like Pin but uses the given context.
+func (d *DAG) PinCtx(ctx context.Context, id string) error {
+	return d.setPinned(ctx, id, true)
+}
+
+// Unpin removes the protection added by Pin. Unpin returns an error, if id is
+// empty or unknown.
+func (d *DAG) Unpin(id string) error {
+	return d.UnpinCtx(context.Background(), id)
+}
+
+// UnpinCtx is like Unpin but uses the given context.
+func (d *DAG) UnpinCtx(ctx context.Context, id string) error {
+	return d.setPinned(ctx, id, false)
+}
+
+// IsPinned returns true, if the vertex with the given id is pinned (see Pin).
+// IsPinned returns an error, if id is empty or unknown.
+func (d *DAG) IsPinned(id string) (bool, error) {
+	return d.IsPinnedCtx(context.Background(), id)
+}
+
+// IsPinnedCtx is like IsPinned but uses the given context.
+func (d *DAG) IsPinnedCtx(ctx context.Context, id string) (bool, error) {
+	if id == "" {
+		return false, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	docID, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	query := "FOR v IN @@vertices FILTER v._id == @id AND v.pinned == true RETURN 1"
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"id":        docID,
+	}
+	return d.queryHasResult(ctx, query, bindVars)
+}
+
+func (d *DAG) setPinned(ctx context.Context, id string, pinned bool) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	return d.mutate(d.context(ctx), func(ctx context.Context) error {
+		patch := map[string]interface{}{"pinned": nil}
+		if pinned {
+			patch["pinned"] = true
+		}
+		meta, err := d.vertices.UpdateDocument(driver.WithKeepNull(ctx, false), d.key(id), patch)
+		if err != nil {
+			if driver.IsNotFound(err) {
+				return NewUnknownKeyError(id)
+			}
+			return arangoError(err)
+		}
+		return d.afterWrite(ctx, changeUpsert, changeVertex, meta.ID)
+	})
+}
+
+// pinnedIDs returns the document ids of all pinned vertices.
+func (d *DAG) pinnedIDs(ctx context.Context) ([]driver.DocumentID, error) {
+	query := "FOR v IN @@vertices FILTER v.pinned == true RETURN v._id"
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+	}
+	return d.queryIDs(ctx, query, bindVars)
+}
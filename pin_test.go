@@ -0,0 +1,61 @@
+package arangodag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDAG_Pin(t *testing.T) {
+	d := someNewDag(t, WithRefCounting())
+
+	// 1 -> 2 -> 3, 4 (old)
+	old := time.Now().Add(-time.Hour)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(timestampVertex{MyID: id, Timestamp: old})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	if err := d.Pin("3"); err != nil {
+		t.Fatalf("failed to Pin(): %v", err)
+	}
+	if pinned, _ := d.IsPinned("3"); !pinned {
+		t.Errorf("IsPinned() = %v, want %v", pinned, true)
+	}
+	if err := d.Pin("4"); err != nil {
+		t.Fatalf("failed to Pin(): %v", err)
+	}
+
+	// pruning stops at the pinned leaf 3
+	if count, _ := d.PruneOlderThan(time.Now()); count != 0 {
+		t.Errorf("PruneOlderThan() = %d, want %d", count, 0)
+	}
+
+	// 3 is kept as orphan
+	if err := d.DeleteEdge("2", "3"); err != nil {
+		t.Fatalf("failed to DeleteEdge(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+
+	// 3 and 4 are treated as roots
+	if count, _ := d.MarkAndSweep(nil); count != 2 {
+		t.Errorf("MarkAndSweep() = %d, want %d", count, 2)
+	}
+
+	if err := d.Unpin("4"); err != nil {
+		t.Fatalf("failed to Unpin(): %v", err)
+	}
+	if pinned, _ := d.IsPinned("4"); pinned {
+		t.Errorf("IsPinned() = %v, want %v", pinned, false)
+	}
+	if count, _ := d.PruneOlderThan(time.Now()); count != 1 {
+		t.Errorf("PruneOlderThan() = %d, want %d", count, 1)
+	}
+
+	// unknown
+	if err := d.Pin("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
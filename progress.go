@@ -0,0 +1,136 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Operations reported via progress callbacks (see WithProgress).
+const (
+	ProgressExport   = "export"
+	ProgressImport   = "import"
+	ProgressClone    = "clone"
+	ProgressValidate = "validate"
+)
+
+// progressInterval is the number of documents processed between two progress
+// reports.
+const progressInterval = 1000
+
+// Progress describes the progress of a long running operation (see
+// WithProgress).
+type Progress struct {
+
+	// Operation is the operation in progress (e.g. ProgressExport).
+	Operation string
+
+	// Processed is the number of documents (i.e. vertices and edges) processed
+	// so far.
+	Processed int64
+
+	// Total is the (estimated) number of documents to be processed in total.
+	Total int64
+
+	// Elapsed is the time passed since the operation started.
+	Elapsed time.Duration
+
+	// Done is true for the last report of the operation.
+	Done bool
+}
+
+// WithProgress registers fn to be called repeatedly while exporting (e.g. via
+// ExportJSON), importing (via ImportJSON), cloning (via Clone or Invert), and
+// validating imports (via ValidateImport). fn is called every 1000 documents
+// and once the operation is done.
+func WithProgress(fn func(p Progress)) Option {
+	return func(d *DAG) {
+		d.progress = fn
+	}
+}
+
+// progressTracker reports the progress of a single operation. All methods may
+// be called on a nil tracker (i.e. with progress reporting disabled).
+type progressTracker struct {
+	fn        func(p Progress)
+	operation string
+	start     time.Time
+	total     int64
+	processed int64
+	reported  int64
+}
+
+// newProgress returns a tracker for the given operation expected to process
+// total documents (or nil, if progress reporting is disabled).
+func (d *DAG) newProgress(operation string, total int64) *progressTracker {
+	if d.progress == nil {
+		return nil
+	}
+	return &progressTracker{
+		fn:        d.progress,
+		operation: operation,
+		start:     time.Now(),
+		total:     total,
+	}
+}
+
+// estimatedTotal returns the number of vertices and edges of d (ignoring
+// errors), if progress reporting is enabled.
+func (d *DAG) estimatedTotal(ctx context.Context) int64 {
+	if d.progress == nil {
+		return 0
+	}
+	order, _ := d.GetOrderCtx(ctx)
+	size, _ := d.GetSizeCtx(ctx)
+	return int64(order + size)
+}
+
+// add records n more documents processed and reports the progress, if due.
+func (p *progressTracker) add(n int) {
+	if p == nil {
+		return
+	}
+	p.processed += int64(n)
+	if p.processed-p.reported >= progressInterval {
+		p.report(false)
+	}
+}
+
+// done reports the final progress.
+func (p *progressTracker) done() {
+	if p == nil {
+		return
+	}
+	p.report(true)
+}
+
+func (p *progressTracker) report(done bool) {
+	p.reported = p.processed
+	total := p.total
+	if total < p.processed {
+		total = p.processed
+	}
+	p.fn(Progress{
+		Operation: p.operation,
+		Processed: p.processed,
+		Total:     total,
+		Elapsed:   time.Since(p.start),
+		Done:      done,
+	})
+}
+
+// iterator returns an iterator counting the documents of it as processed.
+func (p *progressTracker) iterator(it documentIterator) documentIterator {
+	if p == nil {
+		return it
+	}
+	return func(fn func(doc json.RawMessage) error) error {
+		return it(func(doc json.RawMessage) error {
+			if err := fn(doc); err != nil {
+				return err
+			}
+			p.add(1)
+			return nil
+		})
+	}
+}
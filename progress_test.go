@@ -0,0 +1,57 @@
+package arangodag
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestProgressTracker(t *testing.T) {
+	var reports []Progress
+	d := &DAG{}
+	WithProgress(func(p Progress) {
+		reports = append(reports, p)
+	})(d)
+
+	p := d.newProgress(ProgressImport, 1500)
+	p.add(999)
+	p.add(1)
+	p.add(600)
+	p.done()
+	if len(reports) != 2 {
+		t.Fatalf("got %d reports, want %d", len(reports), 2)
+	}
+	if reports[0].Processed != 1000 || reports[0].Total != 1500 || reports[0].Done {
+		t.Errorf("first report = %+v, want 1000 of 1500", reports[0])
+	}
+
+	// the total is an estimate
+	if reports[1].Processed != 1600 || reports[1].Total != 1600 || !reports[1].Done {
+		t.Errorf("last report = %+v, want 1600 of 1600 (done)", reports[1])
+	}
+
+	// disabled
+	var disabled *progressTracker
+	disabled.add(1)
+	disabled.done()
+	if (&DAG{}).newProgress(ProgressImport, 1) != nil {
+		t.Errorf("newProgress() != nil, want nil")
+	}
+}
+
+func TestWithProgress(t *testing.T) {
+	var last Progress
+	d := someNewDag(t, WithProgress(func(p Progress) {
+		last = p
+	}))
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+
+	var buf bytes.Buffer
+	if err := d.ExportJSON(&buf); err != nil {
+		t.Fatalf("failed to ExportJSON(): %v", err)
+	}
+	if last.Operation != ProgressExport || last.Processed != 3 || last.Total != 3 || !last.Done {
+		t.Errorf("last report = %+v, want 3 of 3 (done)", last)
+	}
+}
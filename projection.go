@@ -0,0 +1,69 @@
+package arangodag
+
+import "context"
+
+// ProjectOnto materializes the one-mode projection of a bipartite DAG onto the
+// vertices whose attribute typeAttr (a dot separated path relative to the
+// stored document, e.g. "payload.type") equals value. The projection is stored
+// as a new DAG (in the same database and configured by the options d was
+// created with) using the collections with the given names. It contains
+// copies of the projected vertices and an edge from a to b for each path
+// a -> x -> b, where x is of another type (e.g. for builds and artifacts,
+// projecting onto builds connects builds producing artifacts with the builds
+// consuming them). The attribute "weight" (see WeightAttribute) of the
+// projected edges holds the number of such paths. ProjectOnto reads a
+// consistent snapshot of the graph and writes the projection within a single
+// transaction.
+func (d *DAG) ProjectOnto(typeAttr, value, vertexCollName, edgeCollName string) (*DAG, error) {
+	return d.ProjectOntoCtx(context.Background(), typeAttr, value, vertexCollName, edgeCollName)
+}
+
+// ProjectOntoCtx is like ProjectOnto but uses the given context.
+func (d *DAG) ProjectOntoCtx(ctx context.Context, typeAttr, value, vertexCollName, edgeCollName string) (*DAG, error) {
+	target, err := d.derive(ctx, vertexCollName, edgeCollName)
+	if err != nil {
+		return nil, err
+	}
+
+	err = d.readTransaction(d.context(ctx), func(rctx context.Context) error {
+		return target.transaction(target.context(ctx), func(tctx context.Context) error {
+			query := `
+FOR v IN @@vertices
+  FILTER v.@attr == @value
+  RETURN UNSET(v, "_id", "_rev")`
+			bindVars := map[string]interface{}{
+				"@vertices": d.vertices.Name(),
+				"attr":      attributePath(typeAttr),
+				"value":     value,
+			}
+			if err := copyDocuments(tctx, target.vertices, d.forEachDocument(rctx, query, bindVars), target.afterCopy(tctx, changeVertex)); err != nil {
+				return err
+			}
+
+			query = `
+FOR v IN @@vertices
+  FILTER v.@attr == @value
+  FOR w, e, p IN 2..2 OUTBOUND v @@edges
+    FILTER p.vertices[1].@attr != @value AND w.@attr == @value
+    COLLECT from = v._key, to = w._key WITH COUNT INTO count
+    RETURN {
+      _from: CONCAT(@targetVertices, "/", from),
+      _to: CONCAT(@targetVertices, "/", to),
+      [@weight]: count
+    }`
+			bindVars = map[string]interface{}{
+				"@vertices":      d.vertices.Name(),
+				"@edges":         d.edges.Name(),
+				"targetVertices": target.vertices.Name(),
+				"attr":           attributePath(typeAttr),
+				"value":          value,
+				"weight":         WeightAttribute,
+			}
+			return copyDocuments(tctx, target.edges, d.forEachDocument(rctx, query, bindVars), target.afterCopy(tctx, changeEdge))
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return target, nil
+}
@@ -0,0 +1,49 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+type typedVertex struct {
+	MyID string `json:"id"`
+	Type string `json:"type"`
+}
+
+func (v typedVertex) ID() string {
+	return v.MyID
+}
+
+func TestDAG_ProjectOnto(t *testing.T) {
+	d := someNewDag(t)
+
+	// b1 -> a1 -> b2, b1 -> a2 -> b2, b2 -> a3 -> b3
+	for _, id := range []string{"b1", "b2", "b3"} {
+		_, _ = d.AddVertex(typedVertex{MyID: id, Type: "build"})
+	}
+	for _, id := range []string{"a1", "a2", "a3"} {
+		_, _ = d.AddVertex(typedVertex{MyID: id, Type: "artifact"})
+	}
+	_ = d.AddEdge("b1", "a1")
+	_ = d.AddEdge("a1", "b2")
+	_ = d.AddEdge("b1", "a2")
+	_ = d.AddEdge("a2", "b2")
+	_ = d.AddEdge("b2", "a3")
+	_ = d.AddEdge("a3", "b3")
+
+	p, err := d.ProjectOnto("payload.type", "build", someName(), someName())
+	if err != nil {
+		t.Fatalf("failed to ProjectOnto(): %v", err)
+	}
+	if order, _ := p.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := p.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+	if weight, _ := p.GetShortestPathWeight("b1", "b2", WeightAttribute, 0); weight != 2 {
+		t.Errorf("GetShortestPathWeight() = %v, want %v", weight, 2)
+	}
+	if length, _ := p.GetShortestPathLength("b1", "b3"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+}
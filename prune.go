@@ -0,0 +1,105 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+	"time"
+)
+
+// PruneOlderThan deletes all leaves (i.e. vertices without children) whose
+// timestamp (see WithTimestampAttribute) is older than t, together with their
+// inbound edges. As deleting leaves may turn their parents into leaves,
+// PruneOlderThan repeats this until no further vertex qualifies (i.e. until a
+// fixpoint is reached). Vertices without (valid) timestamp and pinned vertices
+// (see Pin) are never pruned.
+// PruneOlderThan runs within a single transaction and returns the number of
+// deleted vertices.
+func (d *DAG) PruneOlderThan(t time.Time) (uint64, error) {
+	return d.PruneOlderThanCtx(context.Background(), t)
+}
+
+// PruneOlderThanCtx is like PruneOlderThan but uses the given context.
+func (d *DAG) PruneOlderThanCtx(ctx context.Context, t time.Time) (uint64, error) {
+	var count uint64
+	err := d.transaction(d.context(ctx), func(ctx context.Context) error {
+		for {
+			ids, err := d.pruneLeavesOlderThan(ctx, t)
+			if err != nil {
+				return err
+			}
+			if len(ids) == 0 {
+				return nil
+			}
+			if err := d.afterWrite(ctx, changeRemove, changeVertex, ids...); err != nil {
+				return err
+			}
+			if err := d.removeInboundEdges(ctx, ids); err != nil {
+				return err
+			}
+			count += uint64(len(ids))
+		}
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// pruneLeavesOlderThan deletes all leaves older than t and returns their
+// document ids.
+func (d *DAG) pruneLeavesOlderThan(ctx context.Context, t time.Time) ([]driver.DocumentID, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER v.pinned != true
+  FILTER v.@attr != null AND DATE_TIMESTAMP(v.@attr) < @t
+  FILTER LENGTH(FOR e IN @@edges FILTER e._from == v._id LIMIT 1 RETURN 1) == 0
+  REMOVE v IN @@vertices
+  RETURN OLD._id`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+		"attr":      attributePath(d.timestampAttribute),
+		"t":         t.UnixNano() / int64(time.Millisecond),
+	}
+	return d.queryIDs(ctx, query, bindVars)
+}
+
+// removeInboundEdges deletes all edges pointing to any of the given vertices.
+func (d *DAG) removeInboundEdges(ctx context.Context, ids []driver.DocumentID) error {
+	query := `
+FOR e IN @@edges
+  FILTER e._to IN @ids
+  REMOVE e IN @@edges
+  RETURN OLD._id`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"ids":    ids,
+	}
+	edgeIDs, err := d.queryIDs(ctx, query, bindVars)
+	if err != nil {
+		return err
+	}
+	return d.afterWrite(ctx, changeRemove, changeEdge, edgeIDs...)
+}
+
+// queryIDs runs the given query and collects the returned document ids.
+func (d *DAG) queryIDs(ctx context.Context, query string, bindVars map[string]interface{}) ([]driver.DocumentID, error) {
+	cursor, err := d.db.Query(ctx, query, bindVars)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	defer closeCursor(cursor)
+
+	var ids []driver.DocumentID
+	for {
+		var id driver.DocumentID
+		_, err := cursor.ReadDocument(ctx, &id)
+		if driver.IsNoMoreDocuments(err) {
+			break
+		} else if err != nil {
+			return nil, arangoError(err)
+		}
+		ids = append(ids, id)
+	}
+	return ids, nil
+}
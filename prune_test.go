@@ -0,0 +1,67 @@
+package arangodag
+
+import (
+	"testing"
+	"time"
+)
+
+type timestampVertex struct {
+	MyID      string    `json:"id"`
+	Timestamp time.Time `json:"timestamp"`
+}
+
+func (v timestampVertex) ID() string {
+	return v.MyID
+}
+
+func TestDAG_PruneOlderThan(t *testing.T) {
+	d := someNewDag(t)
+
+	now := time.Now()
+	old := now.Add(-2 * time.Hour)
+
+	// old -> old -> new, old -> old
+	_, _ = d.AddVertex(timestampVertex{MyID: "1", Timestamp: old})
+	_, _ = d.AddVertex(timestampVertex{MyID: "2", Timestamp: old})
+	_, _ = d.AddVertex(timestampVertex{MyID: "3", Timestamp: now})
+	_, _ = d.AddVertex(timestampVertex{MyID: "4", Timestamp: old})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "4")
+
+	// nothing is older than old
+	count, err := d.PruneOlderThan(old)
+	if err != nil {
+		t.Fatalf("failed to PruneOlderThan(): %v", err)
+	}
+	if count != 0 {
+		t.Errorf("PruneOlderThan() = %d, want %d", count, 0)
+	}
+
+	// only 4 is an old leaf ("1" and "2" lead to the new vertex "3")
+	count, err = d.PruneOlderThan(now.Add(-time.Hour))
+	if err != nil {
+		t.Fatalf("failed to PruneOlderThan(): %v", err)
+	}
+	if count != 1 {
+		t.Errorf("PruneOlderThan() = %d, want %d", count, 1)
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+
+	// everything is old, pruning cascades up to the root
+	count, err = d.PruneOlderThan(now.Add(time.Hour))
+	if err != nil {
+		t.Fatalf("failed to PruneOlderThan(): %v", err)
+	}
+	if count != 3 {
+		t.Errorf("PruneOlderThan() = %d, want %d", count, 3)
+	}
+	if order, _ := d.GetOrder(); order != 0 {
+		t.Errorf("GetOrder() = %d, want %d", order, 0)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+}
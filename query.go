@@ -0,0 +1,351 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arangodb/go-driver"
+)
+
+// QueryBuilder builds (and runs) traversal queries, such that common ad-hoc
+// traversals don't require hand-written AQL. Typical usage:
+//
+//	it, err := d.Query().From(id).Outbound().MaxDepth(3).
+//		FilterVertex(&ViewFilter{Expression: "CURRENT.payload.active"}).
+//		Select("name", "status").
+//		Stream(ctx)
+//	...
+//	defer it.Close()
+//	for it.Next() {
+//		var v MyVertex
+//		err := it.Decode(&v)
+//		...
+//	}
+//	if err := it.Err(); err != nil {
+//		...
+//	}
+//
+// The names of the bind variables of the filters must not collide with each
+// other or with the bind variables of the generated query (which are prefixed
+// by "q", besides "principal", see WithPrincipal).
+type QueryBuilder struct {
+	d             *DAG
+	from          []string
+	direction     string
+	minDepth      int
+	maxDepth      int
+	vertexFilters []*ViewFilter
+	edgeFilters   []*ViewFilter
+	pruneFilters  []*ViewFilter
+	fields        []string
+	limit         int
+	params        map[string]interface{}
+}
+
+// Query returns a new query builder for traversals of d (outbound, up to any
+// depth, starting at no vertex).
+func (d *DAG) Query() *QueryBuilder {
+	return &QueryBuilder{d: d, direction: "OUTBOUND", minDepth: 1, maxDepth: maxDepth}
+}
+
+// From adds the vertices with the given ids to the start vertices.
+func (q *QueryBuilder) From(ids ...string) *QueryBuilder {
+	q.from = append(q.from, ids...)
+	return q
+}
+
+// Outbound traverses from the start vertices to their descendants (default).
+func (q *QueryBuilder) Outbound() *QueryBuilder {
+	q.direction = "OUTBOUND"
+	return q
+}
+
+// Inbound traverses from the start vertices to their ancestors.
+func (q *QueryBuilder) Inbound() *QueryBuilder {
+	q.direction = "INBOUND"
+	return q
+}
+
+// MinDepth sets the minimal depth of the vertices returned (default 1, 0
+// includes the start vertices).
+func (q *QueryBuilder) MinDepth(depth int) *QueryBuilder {
+	q.minDepth = depth
+	return q
+}
+
+// MaxDepth sets the maximal depth of the vertices returned (default
+// unlimited).
+func (q *QueryBuilder) MaxDepth(depth int) *QueryBuilder {
+	q.maxDepth = depth
+	return q
+}
+
+// FilterVertex restricts the vertices returned to the ones matching f (see
+// ViewFilter, CURRENT referring to the vertex document). Traversal continues
+// beyond vertices not matching f (see Prune).
+func (q *QueryBuilder) FilterVertex(f *ViewFilter) *QueryBuilder {
+	q.vertexFilters = append(q.vertexFilters, f)
+	return q
+}
+
+// FilterEdge restricts the edges followed to the ones matching f (see
+// ViewFilter, CURRENT referring to the edge document). As vertices may be
+// reachable via matching as well as non-matching edges, filtering edges
+// traverses all matching paths (deduplicating vertices client side), thus,
+// MaxDepth should be set for dense graphs.
+func (q *QueryBuilder) FilterEdge(f *ViewFilter) *QueryBuilder {
+	q.edgeFilters = append(q.edgeFilters, f)
+	return q
+}
+
+// Prune stops the traversal at vertices matching f (see ViewFilter, CURRENT
+// referring to the vertex document). Matching vertices are returned, but
+// their neighbours aren't traversed.
+func (q *QueryBuilder) Prune(f *ViewFilter) *QueryBuilder {
+	q.pruneFilters = append(q.pruneFilters, f)
+	return q
+}
+
+// Select restricts the payloads returned to the given (top level) attributes.
+func (q *QueryBuilder) Select(attrs ...string) *QueryBuilder {
+	q.fields = append(q.fields, attrs...)
+	return q
+}
+
+// Bind sets bind variables of the filters, overriding the values given by the
+// filters themselves (e.g. to parameterize queries built from a QuerySpec).
+func (q *QueryBuilder) Bind(params map[string]interface{}) *QueryBuilder {
+	if q.params == nil {
+		q.params = make(map[string]interface{}, len(params))
+	}
+	for name, value := range params {
+		q.params[name] = value
+	}
+	return q
+}
+
+// Limit restricts the number of vertices returned by the iterator (0 meaning
+// unlimited).
+func (q *QueryBuilder) Limit(n int) *QueryBuilder {
+	q.limit = n
+	return q
+}
+
+// QuerySpec is the (serializable) specification of a query built by a
+// QueryBuilder (see QueryBuilder.Spec and SavedQuery). The fields correspond
+// to the methods of QueryBuilder, a zero MaxDepth meaning unlimited.
+type QuerySpec struct {
+	Direction     string       `json:"direction"`
+	MinDepth      int          `json:"minDepth"`
+	MaxDepth      int          `json:"maxDepth"`
+	VertexFilters []ViewFilter `json:"vertexFilters,omitempty"`
+	EdgeFilters   []ViewFilter `json:"edgeFilters,omitempty"`
+	PruneFilters  []ViewFilter `json:"pruneFilters,omitempty"`
+	Fields        []string     `json:"fields,omitempty"`
+	Limit         int          `json:"limit,omitempty"`
+}
+
+// Spec returns the specification of the query (without the start vertices).
+func (q *QueryBuilder) Spec() *QuerySpec {
+	spec := &QuerySpec{
+		Direction: q.direction,
+		MinDepth:  q.minDepth,
+		Fields:    q.fields,
+		Limit:     q.limit,
+	}
+	if q.maxDepth != maxDepth {
+		spec.MaxDepth = q.maxDepth
+	}
+	for _, f := range q.vertexFilters {
+		spec.VertexFilters = append(spec.VertexFilters, *f)
+	}
+	for _, f := range q.edgeFilters {
+		spec.EdgeFilters = append(spec.EdgeFilters, *f)
+	}
+	for _, f := range q.pruneFilters {
+		spec.PruneFilters = append(spec.PruneFilters, *f)
+	}
+	return spec
+}
+
+// QueryFromSpec returns a new query builder configured by spec (without
+// start vertices, see From).
+func (d *DAG) QueryFromSpec(spec *QuerySpec) *QueryBuilder {
+	q := d.Query()
+	if spec.Direction == "INBOUND" {
+		q.Inbound()
+	}
+	q.MinDepth(spec.MinDepth)
+	if spec.MaxDepth > 0 {
+		q.MaxDepth(spec.MaxDepth)
+	}
+	for i := range spec.VertexFilters {
+		q.FilterVertex(&spec.VertexFilters[i])
+	}
+	for i := range spec.EdgeFilters {
+		q.FilterEdge(&spec.EdgeFilters[i])
+	}
+	for i := range spec.PruneFilters {
+		q.Prune(&spec.PruneFilters[i])
+	}
+	return q.Select(spec.Fields...).Limit(spec.Limit)
+}
+
+// AQL returns the generated query and its bind variables (e.g. for debugging
+// or for explaining the query).
+func (q *QueryBuilder) AQL() (string, map[string]interface{}) {
+	starts := make([]driver.DocumentID, len(q.from))
+	for i, id := range q.from {
+		starts[i] = driver.NewDocumentID(q.d.vertices.Name(), q.d.key(id))
+	}
+	return q.build(context.Background(), starts)
+}
+
+// build returns the query (and its bind variables) starting at the given
+// vertices, restricted to the vertices visible to the principal carried by ctx
+// (see WithPrincipal).
+func (q *QueryBuilder) build(ctx context.Context, starts []driver.DocumentID) (string, map[string]interface{}) {
+	bindVars := map[string]interface{}{
+		"qStarts":   starts,
+		"qMinDepth": q.minDepth,
+		"qMaxDepth": q.maxDepth,
+	}
+	traversal := q.d.traverse(ctx, traversalSpec{
+		direction:     q.direction,
+		start:         "start",
+		depth:         "@qMinDepth..@qMaxDepth",
+		edges:         "qEdges",
+		edgeScope:     q.edgeFilters,
+		vertexFilters: q.vertexFilters,
+		prune:         q.pruneFilters,
+	}, "    ", bindVars)
+	for name, value := range q.params {
+		bindVars[name] = value
+	}
+	payload := "v.payload"
+	if len(q.fields) > 0 {
+		payload = "KEEP(v.payload, @qFields)"
+		bindVars["qFields"] = q.fields
+	}
+	query := `
+FOR start IN @qStarts
+  ` + traversal + `
+    RETURN {
+      id: v._key,
+      depth: LENGTH(p.edges),
+      payload: ` + payload + `,
+      edge: e == null ? null : UNSET(e, "_id", "_key", "_rev", "_from", "_to")
+    }`
+	return query, bindVars
+}
+
+// Stream runs the query, returning an iterator over the vertices found (in
+// breadth-first order per start vertex, each vertex being returned once). The
+// results are streamed, thus, the iterator must be closed after use. Stream
+// returns an error, if no start vertex is given or any of them is unknown.
+func (q *QueryBuilder) Stream(ctx context.Context) (*QueryIterator, error) {
+	ctx = q.d.context(ctx)
+	if len(q.from) == 0 {
+		return nil, EmptyIDError()
+	}
+	starts, err := q.d.vertexDocumentIDs(ctx, q.from)
+	if err != nil {
+		return nil, err
+	}
+	return q.stream(ctx, starts)
+}
+
+// stream runs the query starting at the given vertices.
+func (q *QueryBuilder) stream(ctx context.Context, starts []driver.DocumentID) (*QueryIterator, error) {
+	query, bindVars := q.build(ctx, starts)
+	cursor, err := q.d.db.Query(driver.WithQueryStream(ctx), query, bindVars)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	return &QueryIterator{d: q.d, ctx: ctx, cursor: cursor, seen: make(map[string]struct{}), limit: q.limit}, nil
+}
+
+// QueryIterator iterates the results of a query (see QueryBuilder.Stream) or
+// of a walk (see AncestorsWalker).
+type QueryIterator struct {
+	d      *DAG
+	ctx    context.Context
+	cursor driver.Cursor
+	seen   map[string]struct{}
+	limit  int
+	item   queryItem
+	err    error
+	closed bool
+}
+
+type queryItem struct {
+	ID      string          `json:"id"`
+	Depth   int             `json:"depth"`
+	Payload json.RawMessage `json:"payload"`
+	Edge    json.RawMessage `json:"edge"`
+}
+
+// Next advances the iterator to the next vertex. Next returns false, if there
+// are no more vertices or an error occurred (see Err).
+func (it *QueryIterator) Next() bool {
+	if it.closed || it.err != nil || it.limit > 0 && len(it.seen) == it.limit {
+		return false
+	}
+	for {
+		var item queryItem
+		_, err := it.cursor.ReadDocument(it.ctx, &item)
+		if driver.IsNoMoreDocuments(err) {
+			return false
+		} else if err != nil {
+			it.err = arangoError(err)
+			return false
+		}
+		if _, ok := it.seen[item.ID]; ok {
+			continue
+		}
+		it.seen[item.ID] = struct{}{}
+		it.item = item
+		return true
+	}
+}
+
+// ID returns the id of the current vertex.
+func (it *QueryIterator) ID() string {
+	return it.d.id(it.item.ID)
+}
+
+// Depth returns the depth of the current vertex (i.e. its distance from the
+// start vertex it was found from).
+func (it *QueryIterator) Depth() int {
+	return it.item.Depth
+}
+
+// Decode decodes the (selected attributes of the) payload of the current
+// vertex into v (which must be a pointer, see GetVertex).
+func (it *QueryIterator) Decode(v interface{}) error {
+	return json.Unmarshal(it.item.Payload, v)
+}
+
+// Edge returns the fields of the edge via which the current vertex was reached
+// (i.e. the last edge of the path from the start vertex), decoded as by
+// GetEdge (see WithEdgeType). Edge returns nil for start vertices.
+func (it *QueryIterator) Edge() (interface{}, error) {
+	if len(it.item.Edge) == 0 || string(it.item.Edge) == "null" {
+		return nil, nil
+	}
+	return it.d.decodeEdge(it.item.Edge)
+}
+
+// Err returns the error occurred while iterating (if any).
+func (it *QueryIterator) Err() error {
+	return it.err
+}
+
+// Close closes the iterator (i.e. the underlying cursor).
+func (it *QueryIterator) Close() error {
+	if it.closed {
+		return nil
+	}
+	it.closed = true
+	return arangoError(it.cursor.Close())
+}
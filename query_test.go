@@ -0,0 +1,101 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_Query(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3 -> 4, 1 -> 5
+	_, _ = d.AddVertex(foobarKey{MyID: "1", A: "a1", B: "on"})
+	_, _ = d.AddVertex(foobarKey{MyID: "2", A: "a2", B: "off"})
+	_, _ = d.AddVertex(foobarKey{MyID: "3", A: "a3", B: "on"})
+	_, _ = d.AddVertex(foobarKey{MyID: "4", A: "a4", B: "on"})
+	_, _ = d.AddVertex(foobarKey{MyID: "5", A: "a5", B: "on"})
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("1", "5")
+
+	collect := func(q *QueryBuilder) (ids []string, payloads []foobarKey) {
+		it, err := q.Stream(context.Background())
+		if err != nil {
+			t.Fatalf("failed to Stream(): %v", err)
+		}
+		defer func() { _ = it.Close() }()
+		for it.Next() {
+			var v foobarKey
+			if err := it.Decode(&v); err != nil {
+				t.Fatalf("failed to Decode(): %v", err)
+			}
+			ids = append(ids, it.ID())
+			payloads = append(payloads, v)
+		}
+		if err := it.Err(); err != nil {
+			t.Fatalf("failed to iterate: %v", err)
+		}
+		return ids, payloads
+	}
+
+	on := &ViewFilter{Expression: "CURRENT.payload.B == @state", BindVars: map[string]interface{}{"state": "on"}}
+	ids, payloads := collect(d.Query().From("1").MaxDepth(2).FilterVertex(on).Select("A"))
+	if diff := deep.Equal(ids, []string{"5", "3"}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(payloads, []foobarKey{{A: "a5"}, {A: "a3"}}); diff != nil {
+		t.Error(diff)
+	}
+
+	off := &ViewFilter{Expression: "CURRENT.payload.B == @off", BindVars: map[string]interface{}{"off": "off"}}
+	ids, _ = collect(d.Query().From("1").Prune(off))
+	if diff := deep.Equal(ids, []string{"2", "5"}); diff != nil {
+		t.Error(diff)
+	}
+
+	ids, _ = collect(d.Query().From("4").Inbound().Limit(2))
+	if diff := deep.Equal(ids, []string{"3", "2"}); diff != nil {
+		t.Error(diff)
+	}
+
+	if _, err := d.Query().Stream(context.Background()); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+	if _, err := d.Query().From("foo").Stream(context.Background()); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_Query_FilterEdge(t *testing.T) {
+	d := someNewDag(t, WithEdgeType(labeledEdge{}))
+
+	// a -(build)-> b, a -(runtime)-> c -(runtime)-> b
+	for _, id := range []string{"a", "b", "c"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdgeWithData("a", "b", labeledEdge{Label: "build"})
+	_ = d.AddEdgeWithData("a", "c", labeledEdge{Label: "runtime"})
+	_ = d.AddEdgeWithData("c", "b", labeledEdge{Label: "runtime"})
+
+	runtime := &ViewFilter{Expression: "CURRENT.label == @label", BindVars: map[string]interface{}{"label": "runtime"}}
+	it, err := d.Query().From("a").FilterEdge(runtime).Stream(context.Background())
+	if err != nil {
+		t.Fatalf("failed to Stream(): %v", err)
+	}
+	defer func() { _ = it.Close() }()
+	depths := make(map[string]int)
+	for it.Next() {
+		depths[it.ID()] = it.Depth()
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("failed to iterate: %v", err)
+	}
+
+	// b is reached via c, although it is reached via the build edge first
+	if diff := deep.Equal(depths, map[string]int{"c": 1, "b": 2}); diff != nil {
+		t.Error(diff)
+	}
+}
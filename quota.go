@@ -0,0 +1,131 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// Quota limits the growth of a DAG (e.g. of a tenant sharing a database with
+// others, see WithQuota). Zero limits are unlimited.
+type Quota struct {
+
+	// MaxVertices and MaxEdges limit the number of vertices and edges.
+	MaxVertices uint64
+	MaxEdges    uint64
+
+	// MaxDepth limits the number of edges of the longest path.
+	MaxDepth int
+
+	// MaxChildren limits the number of children (i.e. the fan-out) of each
+	// vertex.
+	MaxChildren int
+}
+
+// Quota kinds (see QuotaExceededError).
+const (
+	QuotaVertices = "vertices"
+	QuotaEdges    = "edges"
+	QuotaDepth    = "depth"
+	QuotaChildren = "children"
+)
+
+// WithQuota enables enforcing the given quota when adding vertices (via
+// AddVertex) and edges (via AddEdge and the APIs based on it). Violations are
+// reported by errors with the error number ErrQuotaExceeded. The quota is
+// checked within the transaction adding the vertices or edges, which holds an
+// exclusive lock on the respective collection, such that concurrent writers
+// can't exceed it.
+func WithQuota(q Quota) Option {
+	return func(d *DAG) {
+		d.quota = q
+	}
+}
+
+// checkVertexQuota returns an error, if adding n vertices would exceed the
+// quota. checkVertexQuota is meant to be called within the (write) transaction
+// adding the vertices (see exclusiveCollections), thus, it counts the vertices
+// rather than relying on the count cache.
+func (d *DAG) checkVertexQuota(ctx context.Context, n int) error {
+	if d.quota.MaxVertices == 0 {
+		return nil
+	}
+	order, err := d.vertices.Count(ctx)
+	if err != nil {
+		return arangoError(err)
+	}
+	if uint64(order)+uint64(n) > d.quota.MaxVertices {
+		return QuotaExceededError(QuotaVertices, int64(d.quota.MaxVertices))
+	}
+	return nil
+}
+
+// checkEdgeQuota returns an error, if adding an edge from src to dst would
+// exceed the quota. checkEdgeQuota is meant to be called within the
+// transaction adding the edge (see edgeTransaction).
+func (d *DAG) checkEdgeQuota(ctx context.Context, src, dst driver.DocumentID) error {
+	if err := d.checkSizeQuota(ctx, 1); err != nil {
+		return err
+	}
+	if d.quota.MaxChildren > 0 {
+		query := "RETURN LENGTH(FOR c IN 1 OUTBOUND @src @@edges RETURN 1)"
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"src":    src,
+		}
+		var children int
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			return json.Unmarshal(doc, &children)
+		})
+		if err != nil {
+			return err
+		}
+		if children >= d.quota.MaxChildren {
+			return QuotaExceededError(QuotaChildren, int64(d.quota.MaxChildren))
+		}
+	}
+	if d.quota.MaxDepth == 0 {
+		return nil
+	}
+
+	// the longest path via the new edge (traversals are limited to the quota,
+	// as longer paths exceed it anyway)
+	query := `
+LET up = MAX(FOR v, e, p IN 1..@maxDepth INBOUND @src @@edges RETURN LENGTH(p.edges))
+LET down = MAX(FOR v, e, p IN 1..@maxDepth OUTBOUND @dst @@edges RETURN LENGTH(p.edges))
+RETURN (up || 0) + 1 + (down || 0)`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"src":      src,
+		"dst":      dst,
+		"maxDepth": d.quota.MaxDepth,
+	}
+	var depth int
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &depth)
+	})
+	if err != nil {
+		return err
+	}
+	if depth > d.quota.MaxDepth {
+		return QuotaExceededError(QuotaDepth, int64(d.quota.MaxDepth))
+	}
+	return nil
+}
+
+// checkSizeQuota returns an error, if adding n edges would exceed the quota.
+// As checkVertexQuota, it counts the edges rather than relying on the count
+// cache.
+func (d *DAG) checkSizeQuota(ctx context.Context, n int) error {
+	if d.quota.MaxEdges == 0 {
+		return nil
+	}
+	size, err := d.edges.Count(ctx)
+	if err != nil {
+		return arangoError(err)
+	}
+	if uint64(size)+uint64(n) > d.quota.MaxEdges {
+		return QuotaExceededError(QuotaEdges, int64(d.quota.MaxEdges))
+	}
+	return nil
+}
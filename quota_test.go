@@ -0,0 +1,74 @@
+package arangodag
+
+import (
+	"fmt"
+	"sync"
+	"testing"
+)
+
+func TestDAG_WithQuota(t *testing.T) {
+	d := someNewDag(t, WithQuota(Quota{MaxVertices: 4, MaxEdges: 3, MaxDepth: 2}))
+	for _, id := range []string{"1", "2", "3", "4"} {
+		if _, err := d.AddVertex(idVertex{MyID: id}); err != nil {
+			t.Fatalf("failed to AddVertex(): %v", err)
+		}
+	}
+	if _, err := d.AddVertex(idVertex{MyID: "5"}); !IsQuotaExceededError(err) {
+		t.Errorf("want QuotaExceededError, got %v", err)
+	}
+
+	// depth
+	if err := d.AddEdge("1", "2"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("2", "3"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("3", "4"); !IsQuotaExceededError(err) {
+		t.Errorf("want QuotaExceededError, got %v", err)
+	}
+
+	// edges
+	if err := d.AddEdge("1", "3"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("1", "4"); !IsQuotaExceededError(err) {
+		t.Errorf("want QuotaExceededError, got %v", err)
+	}
+}
+
+func TestDAG_WithQuota_MaxChildren(t *testing.T) {
+	d := someNewDag(t, WithQuota(Quota{MaxChildren: 2}))
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	if err := d.AddEdge("1", "2"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("1", "3"); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("1", "4"); !IsQuotaExceededError(err) {
+		t.Errorf("want QuotaExceededError, got %v", err)
+	}
+	if err := d.AddEdge("2", "4"); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+}
+
+func TestDAG_WithQuota_concurrent(t *testing.T) {
+	d := someNewDag(t, WithQuota(Quota{MaxVertices: 5}))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			_, _ = d.AddVertex(idVertex{MyID: fmt.Sprintf("%d", i)})
+		}(i)
+	}
+	wg.Wait()
+	if order, _ := d.GetOrder(); order != 5 {
+		t.Errorf("GetOrder() = %d, want %d", order, 5)
+	}
+}
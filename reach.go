@@ -0,0 +1,269 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"hash/fnv"
+	"sort"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ReachabilityAttribute is the attribute of vertex documents holding the
+// reachability filter (see WithReachabilityFilters).
+const ReachabilityAttribute = "reach"
+
+// Bloom filter parameters of reachability filters.
+const (
+	reachBits   = 4096
+	reachHashes = 3
+)
+
+// WithReachabilityFilters enables probabilistic reachability filters, i.e.
+// Bloom filters of the ancestors of vertices (stored as the positions of the
+// set bits in the attribute ReachabilityAttribute). Filters are computed
+// lazily (when needed first by a loop check) and answer reachability questions
+// with a definite "no" without traversal, if the filter doesn't contain the
+// vertex in question. This speeds up the loop check of AddEdge (and
+// IsReachable) on huge graphs. When edges are added (and recorded as changes,
+// see afterWrite), the filters of the descendants are extended, pruning the
+// propagation at vertices whose filters cover the new ancestors already. To
+// keep filters complete, filters are only computed and extended while holding
+// an exclusive lock on the edge collection, i.e. all write transactions lock
+// the edges exclusively (serializing writes). Deleting edges or vertices
+// leaves filters being supersets (i.e. still correct, but less selective), see
+// ResetReachabilityFilters and RebuildDerivedData.
+func WithReachabilityFilters() Option {
+	return func(d *DAG) {
+		d.reachFilters = true
+	}
+}
+
+// reachPositions returns the (sorted) bit positions of the given key.
+func reachPositions(key string) []int {
+	h := fnv.New64a()
+	_, _ = h.Write([]byte(key))
+	sum := h.Sum64()
+	h1, h2 := uint32(sum), uint32(sum>>32)
+	positions := make([]int, 0, reachHashes)
+	for i := uint32(0); i < reachHashes; i++ {
+		positions = append(positions, int((h1+i*h2)%reachBits))
+	}
+	sort.Ints(positions)
+	return positions
+}
+
+// mayContain returns true, if the given filter (i.e. set bit positions) may
+// contain the given key.
+func mayContain(filter []int, key string) bool {
+	set := make(map[int]struct{}, len(filter))
+	for _, p := range filter {
+		set[p] = struct{}{}
+	}
+	for _, p := range reachPositions(key) {
+		if _, ok := set[p]; !ok {
+			return false
+		}
+	}
+	return true
+}
+
+// reachFilter returns the reachability filter of the vertex with the given
+// document id. If it doesn't exist yet, reachFilter computes (and stores) it,
+// if ctx belongs to a transaction holding the edges exclusively (see
+// edgeTransaction), and returns nil otherwise (as concurrent writes might
+// leave the filter incomplete).
+func (d *DAG) reachFilter(ctx context.Context, id driver.DocumentID) ([]int, error) {
+	query := "RETURN DOCUMENT(@id).@attr"
+	bindVars := map[string]interface{}{
+		"id":   id,
+		"attr": ReachabilityAttribute,
+	}
+	var filter []int
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &filter)
+	})
+	if err != nil || filter != nil || ctx.Value(exclusiveEdgesKey{}) != d {
+		return filter, err
+	}
+
+	// the ancestors (including the vertex itself)
+	query = `
+FOR v IN 0..@maxDepth INBOUND @id @@edges
+  OPTIONS {bfs: true, uniqueVertices: "global"}
+  RETURN v._key`
+	bindVars = map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"id":       id,
+		"maxDepth": maxDepth,
+	}
+	set := make(map[int]struct{})
+	err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		for _, p := range reachPositions(key) {
+			set[p] = struct{}{}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	filter = make([]int, 0, len(set))
+	for p := range set {
+		filter = append(filter, p)
+	}
+	sort.Ints(filter)
+
+	query = "UPDATE PARSE_IDENTIFIER(@id).key WITH {@attr: @filter} IN @@vertices"
+	bindVars = map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"id":        id,
+		"attr":      ReachabilityAttribute,
+		"filter":    filter,
+	}
+	return filter, d.exec(ctx, query, bindVars)
+}
+
+// mayReach returns false, if there is definitely no path from src to dst (as
+// told by the reachability filter of dst), and true otherwise.
+func (d *DAG) mayReach(ctx context.Context, src, dst driver.DocumentID) (bool, error) {
+	if !d.reachFilters {
+		return true, nil
+	}
+	filter, err := d.reachFilter(ctx, dst)
+	if err != nil {
+		return false, err
+	}
+	return filter == nil || mayContain(filter, src.Key()), nil
+}
+
+// propagateReach extends the reachability filters of the descendants of the
+// targets of the edges with the given document ids by the filters of their
+// sources (if reachability filters are enabled). If the filter of a source
+// is unknown (see reachFilter), the filters of the descendants are removed
+// instead.
+func (d *DAG) propagateReach(ctx context.Context, ids ...driver.DocumentID) error {
+	if !d.reachFilters || len(ids) == 0 {
+		return nil
+	}
+	query := `
+FOR id IN @ids
+  LET e = DOCUMENT(id)
+  FILTER e != null
+  RETURN [e._from, e._to]`
+	bindVars := map[string]interface{}{
+		"ids": ids,
+	}
+	var edges [][2]driver.DocumentID
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var edge [2]driver.DocumentID
+		if err := json.Unmarshal(doc, &edge); err != nil {
+			return err
+		}
+		edges = append(edges, edge)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	for _, edge := range edges {
+		filter, err := d.reachFilter(ctx, edge[0])
+		if err != nil {
+			return err
+		}
+		query := `
+LET targets = (
+  FOR v IN 0..@maxDepth OUTBOUND @dst @@edges
+    PRUNE v.@attr != null AND LENGTH(MINUS(@filter, v.@attr)) == 0
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER v.@attr != null AND LENGTH(MINUS(@filter, v.@attr)) > 0
+    RETURN {key: v._key, filter: SORTED(UNION_DISTINCT(v.@attr, @filter))}
+)
+FOR t IN targets
+  UPDATE t.key WITH {@attr: t.filter} IN @@vertices`
+		if filter == nil {
+			query = `
+FOR v IN 0..@maxDepth OUTBOUND @dst @@edges
+  OPTIONS {bfs: true, uniqueVertices: "global"}
+  FILTER v.@attr != null
+  UPDATE v WITH {@attr: null} IN @@vertices OPTIONS {keepNull: false}`
+		}
+		bindVars := map[string]interface{}{
+			"@vertices": d.vertices.Name(),
+			"@edges":    d.edges.Name(),
+			"dst":       edge[1],
+			"attr":      ReachabilityAttribute,
+			"maxDepth":  maxDepth,
+		}
+		if filter != nil {
+			bindVars["filter"] = filter
+		}
+		if err := d.exec(ctx, query, bindVars); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// IsReachable returns true, if there is a path from the vertex with the id
+// srcID to the vertex with the id dstID. With reachability filters enabled
+// (see WithReachabilityFilters), definite "no"s are answered without
+// traversal. IsReachable returns an error, if srcID or dstID are empty or
+// unknown.
+func (d *DAG) IsReachable(srcID, dstID string) (bool, error) {
+	return d.IsReachableCtx(context.Background(), srcID, dstID)
+}
+
+// IsReachableCtx is like IsReachable but uses the given context.
+func (d *DAG) IsReachableCtx(ctx context.Context, srcID, dstID string) (bool, error) {
+	if srcID == "" || dstID == "" {
+		return false, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	src, err := d.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return false, err
+	}
+	dst, err := d.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return false, err
+	}
+	if src == dst {
+		return false, nil
+	}
+	maybe, err := d.mayReach(ctx, src, dst)
+	if err != nil || !maybe {
+		return false, err
+	}
+	query := "FOR v IN OUTBOUND SHORTEST_PATH @src TO @dst @@edges LIMIT 1 RETURN 1"
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+		"src":    src,
+		"dst":    dst,
+	}
+	return d.queryHasResult(ctx, query, bindVars)
+}
+
+// ResetReachabilityFilters removes all reachability filters (see
+// WithReachabilityFilters), such that they are recomputed (lazily) from the
+// current graph, e.g. after deleting many edges.
+func (d *DAG) ResetReachabilityFilters() error {
+	return d.ResetReachabilityFiltersCtx(context.Background())
+}
+
+// ResetReachabilityFiltersCtx is like ResetReachabilityFilters but uses the given
+// context.
+func (d *DAG) ResetReachabilityFiltersCtx(ctx context.Context) error {
+	query := `
+FOR v IN @@vertices
+  FILTER v.@attr != null
+  UPDATE v WITH {@attr: null} IN @@vertices OPTIONS {keepNull: false}`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"attr":      ReachabilityAttribute,
+	}
+	return d.exec(d.context(ctx), query, bindVars)
+}
@@ -0,0 +1,91 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/arangodb/go-driver"
+)
+
+func TestMayContain(t *testing.T) {
+	var filter []int
+	for _, key := range []string{"a", "b", "c"} {
+		filter = append(filter, reachPositions(key)...)
+	}
+	for _, key := range []string{"a", "b", "c"} {
+		if !mayContain(filter, key) {
+			t.Errorf("mayContain(%s) = false, want true", key)
+		}
+	}
+	if mayContain(nil, "a") {
+		t.Errorf("mayContain(nil, a) = true, want false")
+	}
+}
+
+func TestDAG_IsReachable(t *testing.T) {
+	d := someNewDag(t, WithReachabilityFilters())
+
+	v1, _ := d.AddVertex(someName())
+	v2, _ := d.AddVertex(someName())
+	v3, _ := d.AddVertex(someName())
+	v4, _ := d.AddVertex(someName())
+	v5, _ := d.AddVertex(someName())
+	_ = d.AddEdge(v1, v2)
+
+	// compute the filter of v3 (by the loop check) before connecting it
+	_ = d.AddEdge(v3, v4)
+	if reachable, err := d.IsReachable(v1, v3); err != nil || reachable {
+		t.Errorf("IsReachable(v1, v3) = %v, %v, want false", reachable, err)
+	}
+	_ = d.AddEdge(v2, v3)
+	if reachable, err := d.IsReachable(v1, v3); err != nil || !reachable {
+		t.Errorf("IsReachable(v1, v3) = %v, %v, want true", reachable, err)
+	}
+	if reachable, err := d.IsReachable(v3, v1); err != nil || reachable {
+		t.Errorf("IsReachable(v3, v1) = %v, %v, want false", reachable, err)
+	}
+	if reachable, err := d.IsReachable(v1, v4); err != nil || !reachable {
+		t.Errorf("IsReachable(v1, v4) = %v, %v, want true", reachable, err)
+	}
+	if reachable, err := d.IsReachable(v1, v5); err != nil || reachable {
+		t.Errorf("IsReachable(v1, v5) = %v, %v, want false", reachable, err)
+	}
+	if err := d.AddEdge(v3, v1); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if _, err := d.IsReachable("", v1); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+
+	if err := d.ResetReachabilityFilters(); err != nil {
+		t.Fatalf("failed to ResetReachabilityFilters(): %v", err)
+	}
+
+	// filters are computed by loop checks only (holding the edges exclusively)
+	if reachable, err := d.IsReachable(v1, v5); err != nil || reachable {
+		t.Errorf("IsReachable(v1, v5) = %v, %v, want false", reachable, err)
+	}
+	if filter, err := d.reachFilter(context.Background(), driver.NewDocumentID(d.vertices.Name(), v5)); err != nil || filter != nil {
+		t.Errorf("reachFilter(v5) = %v, %v, want nil", filter, err)
+	}
+	if err := d.AddEdge(v3, v1); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+}
+
+func TestDAG_RebuildDerivedData_reachabilityFilters(t *testing.T) {
+	d := someNewDag(t, WithReachabilityFilters())
+
+	v1, _ := d.AddVertex(someName())
+	v2, _ := d.AddVertex(someName())
+	_ = d.AddEdge(v1, v2)
+	if filter, _ := d.reachFilter(context.Background(), driver.NewDocumentID(d.vertices.Name(), v1)); filter == nil {
+		t.Fatalf("reachFilter(v1) = nil, want the filter computed by AddEdge")
+	}
+	if err := d.RebuildDerivedData(context.Background(), nil); err != nil {
+		t.Fatalf("failed to RebuildDerivedData(): %v", err)
+	}
+	if filter, err := d.reachFilter(context.Background(), driver.NewDocumentID(d.vertices.Name(), v1)); err != nil || filter != nil {
+		t.Errorf("reachFilter(v1) = %v, %v, want nil", filter, err)
+	}
+}
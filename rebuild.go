@@ -0,0 +1,226 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"time"
+)
+
+// Phases of RebuildDerivedData.
+const (
+	PhaseMaterializedPaths   = "materializedPaths"
+	PhaseInverseEdges        = "inverseEdges"
+	PhaseInverseEdgesCleanup = "inverseEdgesCleanup"
+	PhaseReachabilityFilters = "reachabilityFilters"
+)
+
+// defaultRebuildBatchSize is the default number of documents written per batch
+// by RebuildDerivedData.
+const defaultRebuildBatchSize = 1000
+
+// RebuildCheckpoint is the progress of RebuildDerivedData, i.e. the phase and
+// the last key processed within that phase.
+type RebuildCheckpoint struct {
+	Phase string `json:"phase"`
+	Key   string `json:"key"`
+}
+
+// RebuildOptions configures RebuildDerivedData.
+type RebuildOptions struct {
+
+	// BatchSize is the number of documents written per batch (default 1000).
+	BatchSize int
+
+	// Pause is the time to wait between two batches, limiting the load put on
+	// the database.
+	Pause time.Duration
+
+	// Resume, if not nil, resumes a prior rebuild after the given checkpoint.
+	Resume *RebuildCheckpoint
+
+	// OnCheckpoint, if not nil, is called after each batch (e.g. to persist
+	// the checkpoint). Returning an error stops the rebuild.
+	OnCheckpoint func(c RebuildCheckpoint) error
+}
+
+// RebuildDerivedData recomputes all enabled derived data, i.e. the
+// materialized paths (see WithMaterializedPaths), the inverse edges (see
+// WithInverseEdges) and the reachability filters (see
+// WithReachabilityFilters, which are removed to be recomputed lazily), in
+// batches, such that rebuilds don't require downtime
+// nor spike the load. The phases are run in the order of the constants above,
+// the documents of each phase in the order of their keys. As the graph isn't
+// locked, concurrent mutations are reflected (by the mutations themselves
+// maintaining the derived data). RebuildDerivedData returns the context's
+// error, if ctx is done before the rebuild completed.
+func (d *DAG) RebuildDerivedData(ctx context.Context, opts *RebuildOptions) error {
+	ctx = d.context(ctx)
+	if opts == nil {
+		opts = &RebuildOptions{}
+	}
+	size := opts.BatchSize
+	if size <= 0 {
+		size = defaultRebuildBatchSize
+	}
+
+	type phase struct {
+		name  string
+		batch func(after string) (string, error)
+	}
+	var phases []phase
+	if d.materializedPaths {
+		phases = append(phases, phase{PhaseMaterializedPaths, func(after string) (string, error) {
+			return d.materializedPathBatch(ctx, after, size)
+		}})
+	}
+	if d.inverse != nil {
+		phases = append(phases, phase{PhaseInverseEdges, func(after string) (string, error) {
+			return d.mirrorBatch(ctx, after, size)
+		}}, phase{PhaseInverseEdgesCleanup, func(after string) (string, error) {
+			return d.cleanupInverseBatch(ctx, after, size)
+		}})
+	}
+	if d.reachFilters {
+		phases = append(phases, phase{PhaseReachabilityFilters, func(after string) (string, error) {
+			return d.resetReachBatch(ctx, after, size)
+		}})
+	}
+
+	// skip the phases completed already
+	if opts.Resume != nil {
+		for i, p := range phases {
+			if p.name == opts.Resume.Phase {
+				phases = phases[i:]
+				break
+			}
+		}
+	}
+
+	for _, p := range phases {
+		after := ""
+		if opts.Resume != nil && opts.Resume.Phase == p.name {
+			after = opts.Resume.Key
+		}
+		for {
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			last, err := p.batch(after)
+			if err != nil {
+				return err
+			}
+			if last == "" {
+				break
+			}
+			after = last
+			if opts.OnCheckpoint != nil {
+				if err := opts.OnCheckpoint(RebuildCheckpoint{Phase: p.name, Key: last}); err != nil {
+					return err
+				}
+			}
+			if opts.Pause > 0 {
+				select {
+				case <-ctx.Done():
+					return ctx.Err()
+				case <-time.After(opts.Pause):
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// mirrorBatch mirrors (up to) size edges with keys greater than after into the
+// inverse edges and returns the last key mirrored ("" if none).
+func (d *DAG) mirrorBatch(ctx context.Context, after string, size int) (string, error) {
+	query := `
+FOR e IN @@edges
+  FILTER e._key > @after
+  SORT e._key
+  LIMIT @size
+  LET inverse = MERGE(UNSET(e, "_id", "_rev"), {_from: e._to, _to: e._from})
+  UPSERT {_key: e._key} INSERT inverse REPLACE inverse IN @@inverse
+  RETURN e._key`
+	bindVars := map[string]interface{}{
+		"@edges":   d.edges.Name(),
+		"@inverse": d.inverse.Name(),
+		"after":    after,
+		"size":     size,
+	}
+	return d.lastKey(ctx, query, bindVars)
+}
+
+// resetReachBatch removes the reachability filters of (up to) size vertices
+// with keys greater than after and returns the last key processed ("" if
+// none).
+func (d *DAG) resetReachBatch(ctx context.Context, after string, size int) (string, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER v._key > @after
+  SORT v._key
+  LIMIT @size
+  UPDATE v WITH {@attr: null} IN @@vertices OPTIONS {keepNull: false}
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"attr":      ReachabilityAttribute,
+		"after":     after,
+		"size":      size,
+	}
+	return d.lastKey(ctx, query, bindVars)
+}
+
+// cleanupInverseBatch removes those of (up to) size inverse edges with keys
+// greater than after, whose edge doesn't exist anymore, and returns the last
+// key checked ("" if none).
+func (d *DAG) cleanupInverseBatch(ctx context.Context, after string, size int) (string, error) {
+	query := `
+FOR i IN @@inverse
+  FILTER i._key > @after
+  SORT i._key
+  LIMIT @size
+  RETURN {key: i._key, orphan: DOCUMENT(@edges, i._key) == null}`
+	bindVars := map[string]interface{}{
+		"@inverse": d.inverse.Name(),
+		"edges":    d.edges.Name(),
+		"after":    after,
+		"size":     size,
+	}
+	var last string
+	var orphans []string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			Key    string `json:"key"`
+			Orphan bool   `json:"orphan"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		last = item.Key
+		if item.Orphan {
+			orphans = append(orphans, item.Key)
+		}
+		return nil
+	})
+	if err != nil || len(orphans) == 0 {
+		return last, err
+	}
+	query = `
+FOR key IN @keys
+  REMOVE key IN @@inverse OPTIONS {ignoreErrors: true}`
+	bindVars = map[string]interface{}{
+		"@inverse": d.inverse.Name(),
+		"keys":     orphans,
+	}
+	return last, d.exec(ctx, query, bindVars)
+}
+
+// lastKey returns the last of the keys returned by the given query ("" if
+// none).
+func (d *DAG) lastKey(ctx context.Context, query string, bindVars map[string]interface{}) (string, error) {
+	var last string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &last)
+	})
+	return last, err
+}
@@ -0,0 +1,62 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_RebuildDerivedData(t *testing.T) {
+	d := someNewDag(t, WithMaterializedPaths(), WithInverseEdges())
+	ctx := context.Background()
+
+	// 1 -> 2 -> 3, 1 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "4")
+
+	// break the derived data by writing directly
+	if err := d.exec(ctx, "FOR v IN @@vertices UPDATE v WITH {path: null} IN @@vertices OPTIONS {keepNull: false}",
+		map[string]interface{}{"@vertices": d.vertices.Name()}); err != nil {
+		t.Fatalf("failed to reset paths: %v", err)
+	}
+	if err := d.exec(ctx, "FOR e IN @@inverse REMOVE e IN @@inverse",
+		map[string]interface{}{"@inverse": d.inverse.Name()}); err != nil {
+		t.Fatalf("failed to reset inverse edges: %v", err)
+	}
+
+	var checkpoints []RebuildCheckpoint
+	opts := &RebuildOptions{
+		BatchSize: 2,
+		OnCheckpoint: func(c RebuildCheckpoint) error {
+			checkpoints = append(checkpoints, c)
+			return nil
+		},
+	}
+	if err := d.RebuildDerivedData(ctx, opts); err != nil {
+		t.Fatalf("failed to RebuildDerivedData(): %v", err)
+	}
+	if len(checkpoints) != 6 {
+		t.Errorf("checkpoints = %v, want 6", checkpoints)
+	}
+	if subtree, _ := d.GetSubtree("1"); deep.Equal(subtree, []string{"2", "3", "4"}) != nil {
+		t.Errorf("GetSubtree() = %v, want [2 3 4]", subtree)
+	}
+	if count, _ := d.InverseEdgeCollection().Count(ctx); count != 3 {
+		t.Errorf("inverse edges = %d, want 3", count)
+	}
+
+	// resuming after the last checkpoint does nothing
+	checkpoints = nil
+	opts.Resume = &RebuildCheckpoint{Phase: PhaseInverseEdgesCleanup, Key: "\uffff"}
+	if err := d.RebuildDerivedData(ctx, opts); err != nil {
+		t.Fatalf("failed to RebuildDerivedData(): %v", err)
+	}
+	if len(checkpoints) != 0 {
+		t.Errorf("checkpoints = %v, want none", checkpoints)
+	}
+}
@@ -0,0 +1,113 @@
+package arangodag
+
+import (
+	"context"
+	"github.com/arangodb/go-driver"
+)
+
+// VertexRef is a lightweight handle of a vertex. It carries the vertex id
+// (i.e. key) together with its document id and revision, such that the
+// document id doesn't have to be resolved again (e.g. when adding edges).
+type VertexRef struct {
+	ID         string
+	DocumentID driver.DocumentID
+	Rev        string
+}
+
+// newVertexRef returns the reference of the vertex with the given meta data.
+func (d *DAG) newVertexRef(meta driver.DocumentMeta) VertexRef {
+	return VertexRef{
+		ID:         d.id(meta.Key),
+		DocumentID: meta.ID,
+		Rev:        meta.Rev,
+	}
+}
+
+// AddVertexRef adds the given vertex (see AddVertex) and returns its
+// reference.
+func (d *DAG) AddVertexRef(vertex interface{}) (VertexRef, error) {
+	return d.AddVertexRefCtx(context.Background(), vertex)
+}
+
+// AddVertexRefCtx is like AddVertexRef but uses the given context.
+func (d *DAG) AddVertexRefCtx(ctx context.Context, vertex interface{}) (VertexRef, error) {
+	meta, err := d.addVertex(ctx, vertex)
+	if err != nil {
+		return VertexRef{}, err
+	}
+	return d.newVertexRef(meta), nil
+}
+
+// GetVertexRef reads the vertex with the given id into vertex (see GetVertex)
+// and returns its reference.
+func (d *DAG) GetVertexRef(id string, vertex interface{}) (VertexRef, error) {
+	return d.GetVertexRefCtx(context.Background(), id, vertex)
+}
+
+// GetVertexRefCtx is like GetVertexRef but uses the given context.
+func (d *DAG) GetVertexRefCtx(ctx context.Context, id string, vertex interface{}) (VertexRef, error) {
+	meta, err := d.getVertex(ctx, id, vertex)
+	if err != nil {
+		return VertexRef{}, err
+	}
+	return d.newVertexRef(meta), nil
+}
+
+// AddEdgeRef adds an edge from the vertex referenced by src to the vertex
+// referenced by dst without resolving the document ids again (see
+// AddEdgeByID).
+func (d *DAG) AddEdgeRef(src, dst VertexRef) error {
+	return d.AddEdgeRefCtx(context.Background(), src, dst)
+}
+
+// AddEdgeRefCtx is like AddEdgeRef but uses the given context.
+func (d *DAG) AddEdgeRefCtx(ctx context.Context, src, dst VertexRef) error {
+	return d.AddEdgeByIDCtx(ctx, src.DocumentID, dst.DocumentID)
+}
+
+// WalkAncestorsRef is like WalkAncestors but starts at the vertex referenced by
+// ref without resolving its document id again (see AddEdgeRef). Walking from
+// a reference to a vertex deleted in the meantime visits no vertices.
+func (d *DAG) WalkAncestorsRef(ref VertexRef, vertex interface{}, fn WalkFunc) error {
+	return d.WalkAncestorsRefCtx(context.Background(), ref, vertex, fn)
+}
+
+// WalkAncestorsRefCtx is like WalkAncestorsRef but uses the given context.
+func (d *DAG) WalkAncestorsRefCtx(ctx context.Context, ref VertexRef, vertex interface{}, fn WalkFunc) error {
+	if ref.DocumentID == "" {
+		return EmptyIDError()
+	}
+	return d.walkFrom(d.context(ctx), ref.DocumentID, "INBOUND", false, vertex, fn)
+}
+
+// WalkDescendantsRef is like WalkDescendants but starts at the vertex
+// referenced by ref (see WalkAncestorsRef).
+func (d *DAG) WalkDescendantsRef(ref VertexRef, vertex interface{}, fn WalkFunc, dfs bool) error {
+	return d.WalkDescendantsRefCtx(context.Background(), ref, vertex, fn, dfs)
+}
+
+// WalkDescendantsRefCtx is like WalkDescendantsRef but uses the given context.
+func (d *DAG) WalkDescendantsRefCtx(ctx context.Context, ref VertexRef, vertex interface{}, fn WalkFunc, dfs bool) error {
+	if ref.DocumentID == "" {
+		return EmptyIDError()
+	}
+	return d.walkFrom(d.context(ctx), ref.DocumentID, "OUTBOUND", dfs, vertex, fn)
+}
+
+// AncestorsWalkerRef is like AncestorsWalker but starts at the vertex
+// referenced by ref (see WalkAncestorsRef).
+func (d *DAG) AncestorsWalkerRef(ctx context.Context, ref VertexRef, opts *WalkerOptions) (*QueryIterator, error) {
+	if ref.DocumentID == "" {
+		return nil, EmptyIDError()
+	}
+	return d.walkerFrom(d.context(ctx), ref.DocumentID, "INBOUND", opts)
+}
+
+// DescendantsWalkerRef is like DescendantsWalker but starts at the vertex
+// referenced by ref (see WalkAncestorsRef).
+func (d *DAG) DescendantsWalkerRef(ctx context.Context, ref VertexRef, opts *WalkerOptions) (*QueryIterator, error) {
+	if ref.DocumentID == "" {
+		return nil, EmptyIDError()
+	}
+	return d.walkerFrom(d.context(ctx), ref.DocumentID, "OUTBOUND", opts)
+}
@@ -0,0 +1,77 @@
+package arangodag
+
+import (
+	"context"
+	"testing"
+)
+
+func TestDAG_VertexRef(t *testing.T) {
+	d := someNewDag(t)
+	src, err := d.AddVertexRef(idVertex{MyID: "1"})
+	if err != nil {
+		t.Fatalf("failed to AddVertexRef(): %v", err)
+	}
+	if src.ID != "1" || src.DocumentID.Key() != "1" || src.Rev == "" {
+		t.Errorf("AddVertexRef() = %v, want a reference to '1'", src)
+	}
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+
+	var v idVertex
+	dst, err := d.GetVertexRef("2", &v)
+	if err != nil {
+		t.Fatalf("failed to GetVertexRef(): %v", err)
+	}
+	if v.MyID != "2" || dst.ID != "2" {
+		t.Errorf("GetVertexRef() = %v, want a reference to '2'", dst)
+	}
+
+	if err := d.AddEdgeRef(src, dst); err != nil {
+		t.Fatalf("failed to AddEdgeRef(): %v", err)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+	if err := d.AddEdgeRef(dst, src); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+}
+
+func TestDAG_WalkRef(t *testing.T) {
+	d := someNewDag(t)
+	src, _ := d.AddVertexRef(idVertex{MyID: "1"})
+	dst, _ := d.AddVertexRef(idVertex{MyID: "2"})
+	_ = d.AddEdgeRef(src, dst)
+
+	var ids []string
+	var v idVertex
+	err := d.WalkDescendantsRef(src, &v, func(id string, err error) error {
+		ids = append(ids, id+"/"+v.MyID)
+		return err
+	}, false)
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsRef(): %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "2/2" {
+		t.Errorf("WalkDescendantsRef() visited %v, want %v", ids, []string{"2/2"})
+	}
+
+	it, err := d.AncestorsWalkerRef(context.Background(), dst, nil)
+	if err != nil {
+		t.Fatalf("failed to AncestorsWalkerRef(): %v", err)
+	}
+	defer it.Close()
+	ids = nil
+	for it.Next() {
+		ids = append(ids, it.ID())
+	}
+	if err := it.Err(); err != nil {
+		t.Fatalf("AncestorsWalkerRef() failed: %v", err)
+	}
+	if len(ids) != 1 || ids[0] != "1" {
+		t.Errorf("AncestorsWalkerRef() visited %v, want %v", ids, []string{"1"})
+	}
+
+	if err := d.WalkAncestorsRef(VertexRef{}, nil, nil); !IsEmptyIDError(err) {
+		t.Errorf("WalkAncestorsRef() = %v, want empty id error", err)
+	}
+}
@@ -0,0 +1,91 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// WithRefCounting enables the reference counting mode, where vertices that
+// lose their last parent (by deleting their last inbound edge) are deleted
+// automatically, cascading down to all descendants left without parents. This
+// allows to use the DAG in a cache-like manner, where unreferenced content is
+// cleaned up automatically. Roots and pinned vertices (see Pin) are never
+// deleted automatically.
+func WithRefCounting() Option {
+	return func(d *DAG) {
+		d.refCounting = true
+	}
+}
+
+// deleteOrphans deletes those of the given (unpinned) vertices without parents
+// and cascades down to their children, until no further vertex is left without
+// parents.
+func (d *DAG) deleteOrphans(ctx context.Context, candidates []driver.DocumentID) error {
+	for len(candidates) > 0 {
+
+		// delete candidates without parents
+		query := `
+FOR id IN @candidates
+  FILTER LENGTH(FOR e IN @@edges FILTER e._to == id LIMIT 1 RETURN 1) == 0
+  FILTER DOCUMENT(id).pinned != true
+  REMOVE PARSE_IDENTIFIER(id).key IN @@vertices
+  RETURN OLD._id`
+		bindVars := map[string]interface{}{
+			"@vertices":  d.vertices.Name(),
+			"@edges":     d.edges.Name(),
+			"candidates": candidates,
+		}
+		orphans, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		if len(orphans) == 0 {
+			return nil
+		}
+		if err := d.afterWrite(ctx, changeRemove, changeVertex, orphans...); err != nil {
+			return err
+		}
+
+		// delete their outbound edges, their children are the next candidates
+		query = `
+FOR e IN @@edges
+  FILTER e._from IN @orphans
+  REMOVE e IN @@edges
+  RETURN {id: OLD._id, child: OLD._to}`
+		bindVars = map[string]interface{}{
+			"@edges":  d.edges.Name(),
+			"orphans": orphans,
+		}
+		var edgeIDs []driver.DocumentID
+		children := make(map[driver.DocumentID]struct{})
+		err = d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var item struct {
+				ID    driver.DocumentID `json:"id"`
+				Child driver.DocumentID `json:"child"`
+			}
+			if err := json.Unmarshal(doc, &item); err != nil {
+				return err
+			}
+			edgeIDs = append(edgeIDs, item.ID)
+			children[item.Child] = struct{}{}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if err := d.afterWrite(ctx, changeRemove, changeEdge, edgeIDs...); err != nil {
+			return err
+		}
+		candidates = make([]driver.DocumentID, 0, len(children))
+		for child := range children {
+			candidates = append(candidates, child)
+		}
+		if d.materializedPaths {
+			if err := d.updatePaths(ctx, candidates...); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,67 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_DeleteEdge(t *testing.T) {
+	d := someNewDag(t)
+
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+
+	if err := d.DeleteEdge("1", "2"); err != nil {
+		t.Errorf("failed to DeleteEdge(): %v", err)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want %d", order, 2)
+	}
+
+	// unknown edge
+	errUnknown := d.DeleteEdge("1", "2")
+	if !IsUnknownEdgeError(errUnknown) {
+		t.Errorf("want UnknownEdgeError, got %v", errUnknown)
+	}
+
+	// unknown vertex
+	errUnknown = d.DeleteEdge("1", "foo")
+	if !IsUnknownIDError(errUnknown) {
+		t.Errorf("want UnknownIDError, got %v", errUnknown)
+	}
+}
+
+func TestDAG_DeleteEdge_RefCounting(t *testing.T) {
+	d := someNewDag(t, WithRefCounting())
+
+	// 1 -> 2 -> 3 -> 4, 5 -> 4
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("5", "4")
+
+	// 2 and 3 become orphans, 4 is still referenced by 5
+	if err := d.DeleteEdge("1", "2"); err != nil {
+		t.Fatalf("failed to DeleteEdge(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := d.GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+
+	// 4 becomes an orphan, 5 is a root and, thus, kept
+	if err := d.DeleteEdge("5", "4"); err != nil {
+		t.Fatalf("failed to DeleteEdge(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 2 {
+		t.Errorf("GetOrder() = %d, want %d", order, 2)
+	}
+}
@@ -0,0 +1,180 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// GetParents returns the ids of the parents of the vertex with the given id.
+// GetParents returns an error, if id is empty or unknown.
+func (d *DAG) GetParents(id string) (map[string]struct{}, error) {
+	return d.GetParentsCtx(context.Background(), id)
+}
+
+// GetParentsCtx is like GetParents but uses the given context.
+func (d *DAG) GetParentsCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return d.getRelatives(ctx, id, "INBOUND", 1)
+}
+
+// GetChildren returns the ids of the children of the vertex with the given id
+// (see GetParents).
+func (d *DAG) GetChildren(id string) (map[string]struct{}, error) {
+	return d.GetChildrenCtx(context.Background(), id)
+}
+
+// GetChildrenCtx is like GetChildren but uses the given context.
+func (d *DAG) GetChildrenCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return d.getRelatives(ctx, id, "OUTBOUND", 1)
+}
+
+// GetAncestors returns the ids of the ancestors of the vertex with the given
+// id, as determined by a single (server side) traversal (see GetParents).
+func (d *DAG) GetAncestors(id string) (map[string]struct{}, error) {
+	return d.GetAncestorsCtx(context.Background(), id)
+}
+
+// GetAncestorsCtx is like GetAncestors but uses the given context.
+func (d *DAG) GetAncestorsCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return d.getRelatives(ctx, id, "INBOUND", maxDepth)
+}
+
+// GetDescendants returns the ids of the descendants of the vertex with the
+// given id (see GetAncestors).
+func (d *DAG) GetDescendants(id string) (map[string]struct{}, error) {
+	return d.GetDescendantsCtx(context.Background(), id)
+}
+
+// GetDescendantsCtx is like GetDescendants but uses the given context.
+func (d *DAG) GetDescendantsCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return d.getRelatives(ctx, id, "OUTBOUND", maxDepth)
+}
+
+// GetParentsDocuments decodes the payloads of the parents (ordered by id) of
+// the vertex with the given id into out, which must be a pointer to a slice
+// (e.g. *[]MyVertex, see GetVertex). The payloads are restricted by projection
+// (which may be nil). GetParentsDocuments returns an error, if id is empty or
+// unknown.
+func (d *DAG) GetParentsDocuments(id string, out interface{}, projection *PayloadProjection) error {
+	return d.GetParentsDocumentsCtx(context.Background(), id, out, projection)
+}
+
+// GetParentsDocumentsCtx is like GetParentsDocuments but uses the given
+// context.
+func (d *DAG) GetParentsDocumentsCtx(ctx context.Context, id string, out interface{}, projection *PayloadProjection) error {
+	return d.getRelativesDocuments(ctx, id, "INBOUND", 1, out, projection)
+}
+
+// GetChildrenDocuments decodes the payloads of the children (ordered by id) of
+// the vertex with the given id into out (see GetParentsDocuments).
+func (d *DAG) GetChildrenDocuments(id string, out interface{}, projection *PayloadProjection) error {
+	return d.GetChildrenDocumentsCtx(context.Background(), id, out, projection)
+}
+
+// GetChildrenDocumentsCtx is like GetChildrenDocuments but uses the given
+// context.
+func (d *DAG) GetChildrenDocumentsCtx(ctx context.Context, id string, out interface{}, projection *PayloadProjection) error {
+	return d.getRelativesDocuments(ctx, id, "OUTBOUND", 1, out, projection)
+}
+
+// GetAncestorsDocuments decodes the payloads of the ancestors (in
+// breadth-first order) of the vertex with the given id into out (see
+// GetParentsDocuments).
+func (d *DAG) GetAncestorsDocuments(id string, out interface{}, projection *PayloadProjection) error {
+	return d.GetAncestorsDocumentsCtx(context.Background(), id, out, projection)
+}
+
+// GetAncestorsDocumentsCtx is like GetAncestorsDocuments but uses the given
+// context.
+func (d *DAG) GetAncestorsDocumentsCtx(ctx context.Context, id string, out interface{}, projection *PayloadProjection) error {
+	return d.getRelativesDocuments(ctx, id, "INBOUND", maxDepth, out, projection)
+}
+
+// GetDescendantsDocuments decodes the payloads of the descendants (in
+// breadth-first order) of the vertex with the given id into out (see
+// GetParentsDocuments).
+func (d *DAG) GetDescendantsDocuments(id string, out interface{}, projection *PayloadProjection) error {
+	return d.GetDescendantsDocumentsCtx(context.Background(), id, out, projection)
+}
+
+// GetDescendantsDocumentsCtx is like GetDescendantsDocuments but uses the given
+// context.
+func (d *DAG) GetDescendantsDocumentsCtx(ctx context.Context, id string, out interface{}, projection *PayloadProjection) error {
+	return d.getRelativesDocuments(ctx, id, "OUTBOUND", maxDepth, out, projection)
+}
+
+// getRelatives returns the ids of the vertices reachable from the vertex with
+// the given id in the given direction ("OUTBOUND" or "INBOUND") within depth
+// steps.
+func (d *DAG) getRelatives(ctx context.Context, id, direction string, depth int) (map[string]struct{}, error) {
+	ids := make(map[string]struct{})
+	err := d.forEachRelative(ctx, id, direction, depth, "v._key", nil)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		ids[d.id(key)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// getRelativesDocuments decodes the payloads of the vertices reachable from
+// the vertex with the given id in the given direction within depth steps into
+// out.
+func (d *DAG) getRelativesDocuments(ctx context.Context, id, direction string, depth int, out interface{}, projection *PayloadProjection) error {
+	payload, bindVars := projection.expression("v.payload")
+	payloads := []json.RawMessage{}
+	err := d.forEachRelative(ctx, id, direction, depth, payload, bindVars)(func(doc json.RawMessage) error {
+		payloads = append(payloads, doc)
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(payloads)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// forEachRelative returns an iterator over the given expression evaluated for
+// each vertex (as v) reachable from the vertex with the given id in the given
+// direction within depth steps (i.e. neighbours ordered by id, if depth is 1,
+// and all reachable vertices in breadth-first order otherwise). bindVars
+// holds the bind variables of the expression (and may be nil).
+func (d *DAG) forEachRelative(ctx context.Context, id, direction string, depth int, expression string, bindVars map[string]interface{}) documentIterator {
+	return func(fn func(doc json.RawMessage) error) error {
+		if id == "" {
+			return EmptyIDError()
+		}
+		ctx = d.context(ctx)
+		start, err := d.vertexDocumentID(ctx, id)
+		if err != nil {
+			return err
+		}
+		vars := map[string]interface{}{
+			"start": start,
+			"depth": depth,
+		}
+		sort := ""
+		if depth == 1 {
+			sort = "\n  SORT v._key"
+		}
+		query := `
+` + d.traverse(ctx, traversalSpec{
+			direction: direction,
+			start:     "@start",
+			depth:     "1..@depth",
+			edges:     "edges",
+		}, "  ", vars) + sort + `
+  RETURN ` + expression
+		for name, value := range bindVars {
+			vars[name] = value
+		}
+		return d.forEachDocument(ctx, query, vars)(fn)
+	}
+}
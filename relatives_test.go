@@ -0,0 +1,65 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetRelatives(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 3 -> 2, 2 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(foobarKey{MyID: id, A: "a" + id, B: "b" + id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("3", "2")
+	_ = d.AddEdge("2", "4")
+
+	tests := []struct {
+		name string
+		fn   func(id string) (map[string]struct{}, error)
+		id   string
+		want map[string]struct{}
+	}{
+		{"GetParents", d.GetParents, "2", map[string]struct{}{"1": {}, "3": {}}},
+		{"GetChildren", d.GetChildren, "2", map[string]struct{}{"4": {}}},
+		{"GetAncestors", d.GetAncestors, "4", map[string]struct{}{"1": {}, "2": {}, "3": {}}},
+		{"GetDescendants", d.GetDescendants, "1", map[string]struct{}{"2": {}, "4": {}}},
+		{"GetChildren", d.GetChildren, "4", map[string]struct{}{}},
+	}
+	for _, tt := range tests {
+		got, err := tt.fn(tt.id)
+		if err != nil {
+			t.Fatalf("failed to %s(): %v", tt.name, err)
+		}
+		if diff := deep.Equal(got, tt.want); diff != nil {
+			t.Errorf("%s(%s): %v", tt.name, tt.id, diff)
+		}
+	}
+
+	var parents []foobarKey
+	if err := d.GetParentsDocuments("2", &parents, &PayloadProjection{Exclude: []string{"B"}}); err != nil {
+		t.Fatalf("failed to GetParentsDocuments(): %v", err)
+	}
+	if diff := deep.Equal(parents, []foobarKey{{MyID: "1", A: "a1"}, {MyID: "3", A: "a3"}}); diff != nil {
+		t.Error(diff)
+	}
+	var descendants []foobarKey
+	if err := d.GetDescendantsDocuments("1", &descendants, nil); err != nil {
+		t.Fatalf("failed to GetDescendantsDocuments(): %v", err)
+	}
+	want := []foobarKey{{MyID: "2", A: "a2", B: "b2"}, {MyID: "4", A: "a4", B: "b4"}}
+	if diff := deep.Equal(descendants, want); diff != nil {
+		t.Error(diff)
+	}
+
+	// errors
+	if _, err := d.GetParents(""); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+	if err := d.GetAncestorsDocuments("foo", &descendants, nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
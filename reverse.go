@@ -0,0 +1,107 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ReverseEdge atomically reverses the direction of the edge from the vertex
+// with the id srcID to the vertex with the id dstID (i.e. replaces it by an
+// edge from dstID to srcID holding the same fields), e.g. to fix a
+// relationship entered backwards without racing with other writers between
+// deleting and re-adding the edge. The reversed edge is subject to the usual
+// checks (see WithBeforeAddEdge and WithVertexTypes). ReverseEdge doesn't
+// delete vertices (see WithRefCounting). ReverseEdge returns an error, if
+// srcID or dstID are empty or unknown, if there is no such edge, or if the
+// reversed edge would create a loop (i.e. if there is another path from srcID
+// to dstID).
+func (d *DAG) ReverseEdge(srcID, dstID string) error {
+	return d.ReverseEdgeCtx(context.Background(), srcID, dstID)
+}
+
+// ReverseEdgeCtx is like ReverseEdge but uses the given context.
+func (d *DAG) ReverseEdgeCtx(ctx context.Context, srcID, dstID string) error {
+
+	// sanity checking
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+	if srcID == dstID {
+		return SrcDstEqualError(srcID)
+	}
+
+	ctx = d.context(ctx)
+	src, err := d.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return err
+	}
+	dst, err := d.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return err
+	}
+	if d.beforeAddEdge != nil {
+		if err := d.beforeAddEdge(d.id(dst.Key()), d.id(src.Key())); err != nil {
+			return err
+		}
+	}
+
+	return d.edgeTransaction(ctx, func(ctx context.Context) error {
+		query := `
+FOR e IN @@edges
+  FILTER e._from == @src AND e._to == @dst
+  REMOVE e IN @@edges
+  RETURN {id: OLD._id, doc: UNSET(OLD, "_id", "_key", "_rev")}`
+		bindVars := map[string]interface{}{
+			"@edges": d.edges.Name(),
+			"src":    src,
+			"dst":    dst,
+		}
+		var old struct {
+			ID  driver.DocumentID      `json:"id"`
+			Doc map[string]interface{} `json:"doc"`
+		}
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			return json.Unmarshal(doc, &old)
+		})
+		if err != nil {
+			return err
+		}
+		if old.Doc == nil {
+			return UnknownEdgeError(srcID, dstID)
+		}
+		if err := d.afterWrite(ctx, changeRemove, changeEdge, old.ID); err != nil {
+			return err
+		}
+
+		// the reversed edge (within the transaction, the old one is gone already)
+		if err := d.checkEdgeTypes(ctx, dst, src); err != nil {
+			return err
+		}
+		if err := d.checkLoop(ctx, dst, src); err != nil {
+			return err
+		}
+		doc := old.Doc
+		doc["_from"], doc["_to"] = dst, src
+		if d.shardPath != "" {
+			shard, err := d.edgeShard(ctx, dst)
+			if err != nil {
+				return err
+			}
+			doc[ShardAttribute] = shard
+		}
+		meta, err := d.edges.CreateDocument(ctx, doc)
+		if err != nil {
+			return arangoError(err)
+		}
+		if err := d.afterWrite(ctx, changeUpsert, changeEdge, meta.ID); err != nil {
+			return err
+		}
+		// src is updated by afterWrite, dst lost its parent
+		if d.materializedPaths {
+			return d.updatePaths(ctx, dst)
+		}
+		return nil
+	})
+}
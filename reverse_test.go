@@ -0,0 +1,48 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_ReverseEdge(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("1", "3")
+
+	if err := d.ReverseEdge("1", "2"); err != nil {
+		t.Fatalf("failed to ReverseEdge(): %v", err)
+	}
+	if _, err := d.GetEdge("2", "1"); err != nil {
+		t.Errorf("reversed edge is missing")
+	}
+	if _, err := d.GetEdge("1", "2"); !IsUnknownEdgeError(err) {
+		t.Errorf("original edge still exists")
+	}
+	if size, _ := d.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want 3", size)
+	}
+
+	// 1 -> 3 is implied by 2 -> 3 and 1 -> 2, thus, reversing it creates a loop
+	_ = d.ReverseEdge("2", "1")
+	if err := d.ReverseEdge("1", "3"); !IsLoopError(err) {
+		t.Errorf("want LoopError, got %v", err)
+	}
+	if _, err := d.GetEdge("1", "3"); err != nil {
+		t.Errorf("edge removed despite the loop")
+	}
+
+	// errors
+	if err := d.ReverseEdge("3", "1"); !IsUnknownEdgeError(err) {
+		t.Errorf("want UnknownEdgeError, got %v", err)
+	}
+	if err := d.ReverseEdge("", "1"); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+	if err := d.ReverseEdge("1", "foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
@@ -0,0 +1,62 @@
+package arangodag
+
+import (
+	"context"
+)
+
+// DeleteVertexRewire deletes the vertex with the given id (see DeleteVertex),
+// but, preserving reachability, connects each of its parents to each of its
+// children (skipping edges that already exist) within the same transaction.
+// As each new edge bypasses the deleted vertex, rewiring can't create loops.
+// However, new edges are subject to the usual checks (see WithBeforeAddEdge
+// and WithVertexTypes) and, if any of them fails, nothing is deleted.
+// DeleteVertexRewire returns an error, if id is empty or unknown, or if the
+// deletion is vetoed (see WithBeforeDeleteVertex).
+func (d *DAG) DeleteVertexRewire(id string) error {
+	return d.DeleteVertexRewireCtx(context.Background(), id)
+}
+
+// DeleteVertexRewireCtx is like DeleteVertexRewire but uses the given context.
+func (d *DAG) DeleteVertexRewireCtx(ctx context.Context, id string) error {
+
+	// sanity checking
+	if id == "" {
+		return EmptyIDError()
+	}
+
+	ctx = d.context(ctx)
+	docID, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	if d.beforeDeleteVertex != nil {
+		if err := d.beforeDeleteVertex(id); err != nil {
+			return err
+		}
+	}
+
+	return d.exclusiveTransaction(ctx, func(ctx context.Context) error {
+		parents, children, err := d.removeVertex(ctx, docID)
+		if err != nil {
+			return err
+		}
+		for _, parent := range parents {
+			for _, child := range children {
+				if err := d.addEdge(ctx, parent, child, nil); err != nil && !IsDuplicateEdgeError(err) {
+					return err
+				}
+			}
+		}
+		if d.refCounting && len(parents) == 0 {
+			if err := d.deleteOrphans(ctx, children); err != nil {
+				return err
+			}
+		}
+
+		// children already having been children of the parents lost a parent
+		if d.materializedPaths {
+			return d.updatePaths(ctx, children...)
+		}
+		return nil
+	})
+}
@@ -0,0 +1,58 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_DeleteVertexRewire(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 3, 2 -> 3, 3 -> 4, 3 -> 5, 1 -> 4
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("3", "5")
+	_ = d.AddEdge("1", "4")
+
+	if err := d.DeleteVertexRewire("3"); err != nil {
+		t.Fatalf("failed to DeleteVertexRewire(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+
+	// 1 -> 4 (existing), 1 -> 5, 2 -> 4, 2 -> 5
+	if size, _ := d.GetSize(); size != 4 {
+		t.Errorf("GetSize() = %d, want %d", size, 4)
+	}
+	for _, edge := range [][2]string{{"1", "4"}, {"1", "5"}, {"2", "4"}, {"2", "5"}} {
+		if length, _ := d.GetShortestPathLength(edge[0], edge[1]); length != 1 {
+			t.Errorf("GetShortestPathLength(%s, %s) = %d, want %d", edge[0], edge[1], length, 1)
+		}
+	}
+
+	// rewiring is subject to type checks
+	typed := someNewDag(t, WithVertexTypes("payload.type", map[string][]string{
+		"build":    {"artifact"},
+		"artifact": {"build"},
+	}))
+	_, _ = typed.AddVertex(typedVertex{MyID: "b1", Type: "build"})
+	_, _ = typed.AddVertex(typedVertex{MyID: "a1", Type: "artifact"})
+	_, _ = typed.AddVertex(typedVertex{MyID: "b2", Type: "build"})
+	_ = typed.AddEdge("b1", "a1")
+	_ = typed.AddEdge("a1", "b2")
+	if err := typed.DeleteVertexRewire("a1"); !IsEdgeForbiddenError(err) {
+		t.Errorf("want EdgeForbiddenError, got %v", err)
+	}
+	if order, _ := typed.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+
+	// unknown
+	if err := d.DeleteVertexRewire("foo"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
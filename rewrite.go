@@ -0,0 +1,247 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/arangodb/go-driver"
+)
+
+// maxRewritePasses is the maximal number of passes of Rewrite.
+const maxRewritePasses = 100
+
+// RewriteRule is a rule applied by Rewrite. The rule matches the edges matching
+// Edge whose source vertex matches Src and whose destination vertex matches
+// Dst (see ViewFilter; nil filters match everything). The names of the bind
+// variables of the filters must not collide. Replace is called for each
+// match.
+type RewriteRule struct {
+	Src     *ViewFilter
+	Edge    *ViewFilter
+	Dst     *ViewFilter
+	Replace func(rw *Rewriter, m RewriteMatch) error
+}
+
+// RewriteMatch is an edge matched by a RewriteRule.
+type RewriteMatch struct {
+
+	// Src and Dst are the ids of the source and the destination vertex.
+	Src string `json:"src"`
+	Dst string `json:"dst"`
+
+	// Edge holds the fields of the edge (besides "_from" and "_to").
+	Edge map[string]interface{} `json:"edge"`
+}
+
+// Rewriter applies replacements within the transaction of Rewrite.
+type Rewriter struct {
+	d   *DAG
+	ctx context.Context
+}
+
+// RewriteContract is a replacement (see RewriteRule) merging the destination
+// of the matched edge into its source (see Rewriter.ContractEdge).
+func RewriteContract(rw *Rewriter, m RewriteMatch) error {
+	return rw.ContractEdge(m.Src, m.Dst)
+}
+
+// RewriteDeleteEdge is a replacement (see RewriteRule) deleting the matched
+// edge.
+func RewriteDeleteEdge(rw *Rewriter, m RewriteMatch) error {
+	return rw.DeleteEdge(m.Src, m.Dst)
+}
+
+// Rewrite applies the given rules within a single transaction and returns the
+// number of replacements (i.e. matches) applied. Rewrite runs in passes, where
+// each pass applies the rules one after another. The matches of a rule are
+// determined before applying any of them. Matches whose edge was removed by a
+// prior replacement are skipped. Passes are repeated until no rule matches
+// anymore (e.g. such that chains get collapsed entirely). If any replacement
+// returns an error or the rules don't settle within 100 passes, the
+// transaction is aborted (i.e. nothing is changed) and the error is returned.
+func (d *DAG) Rewrite(rules ...RewriteRule) (int, error) {
+	return d.RewriteCtx(context.Background(), rules...)
+}
+
+// RewriteCtx is like Rewrite but uses the given context.
+func (d *DAG) RewriteCtx(ctx context.Context, rules ...RewriteRule) (int, error) {
+	count := 0
+	err := d.exclusiveTransaction(d.context(ctx), func(ctx context.Context) error {
+		rw := &Rewriter{d: d, ctx: ctx}
+		for pass := 0; pass < maxRewritePasses; pass++ {
+			applied := 0
+			for _, rule := range rules {
+				n, err := rw.apply(rule)
+				if err != nil {
+					return err
+				}
+				applied += n
+			}
+			count += applied
+			if applied == 0 {
+				return nil
+			}
+		}
+		return errors.New("rewrite rules don't settle")
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// apply applies the given rule once to all of its matches and returns the
+// number of matches applied.
+func (rw *Rewriter) apply(rule RewriteRule) (int, error) {
+	d := rw.d
+	query := `
+FOR e IN @@edges
+  LET src = DOCUMENT(e._from)
+  LET dst = DOCUMENT(e._to)
+  FILTER ` + allMatch(rule.Edge, "[e]") + `
+  FILTER ` + allMatch(rule.Src, "[src]") + ` AND ` + allMatch(rule.Dst, "[dst]") + `
+  RETURN {src: src._key, dst: dst._key, edge: UNSET(e, "_id", "_key", "_rev", "_from", "_to")}`
+	bindVars := map[string]interface{}{
+		"@edges": d.edges.Name(),
+	}
+	for _, f := range []*ViewFilter{rule.Src, rule.Edge, rule.Dst} {
+		if f != nil {
+			for name, value := range f.BindVars {
+				bindVars[name] = value
+			}
+		}
+	}
+	var matches []RewriteMatch
+	err := d.forEachDocument(rw.ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var m RewriteMatch
+		if err := json.Unmarshal(doc, &m); err != nil {
+			return err
+		}
+		m.Src, m.Dst = d.id(m.Src), d.id(m.Dst)
+		matches = append(matches, m)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, m := range matches {
+		exists, err := d.edgeExists(rw.ctx, rw.documentID(m.Src), rw.documentID(m.Dst))
+		if err != nil {
+			return 0, err
+		}
+		if !exists {
+			continue
+		}
+		if err := rule.Replace(rw, m); err != nil {
+			return 0, err
+		}
+		applied++
+	}
+	return applied, nil
+}
+
+// AddEdge adds an edge from the vertex with the id srcID to the vertex with the
+// id dstID (see DAG.AddEdge).
+func (rw *Rewriter) AddEdge(srcID, dstID string) error {
+	src, dst, err := rw.resolve(srcID, dstID)
+	if err != nil {
+		return err
+	}
+	return rw.d.addEdge(rw.ctx, src, dst, nil)
+}
+
+// DeleteEdge deletes the edge from the vertex with the id srcID to the vertex
+// with the id dstID (see DAG.DeleteEdge).
+func (rw *Rewriter) DeleteEdge(srcID, dstID string) error {
+	src, dst, err := rw.resolve(srcID, dstID)
+	if err != nil {
+		return err
+	}
+	return rw.d.deleteEdge(rw.ctx, src, dst)
+}
+
+// DeleteVertex deletes the vertex with the given id and all of its edges (see
+// DAG.DeleteVertex).
+func (rw *Rewriter) DeleteVertex(id string) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	docID, err := rw.d.vertexDocumentID(rw.ctx, id)
+	if err != nil {
+		return err
+	}
+	_, children, err := rw.d.removeVertex(rw.ctx, docID)
+	if err != nil {
+		return err
+	}
+	if rw.d.refCounting {
+		if err := rw.d.deleteOrphans(rw.ctx, children); err != nil {
+			return err
+		}
+	}
+	if rw.d.materializedPaths {
+		return rw.d.updatePaths(rw.ctx, children...)
+	}
+	return nil
+}
+
+// ContractEdge merges the vertex with the id dstID into the vertex with the id
+// srcID, i.e. the edges of dstID are moved to srcID (skipping edges that
+// already exist) and dstID is deleted. ContractEdge returns an error, if srcID
+// or dstID are empty or unknown, or if moving an edge would create a loop.
+func (rw *Rewriter) ContractEdge(srcID, dstID string) error {
+	src, dst, err := rw.resolve(srcID, dstID)
+	if err != nil {
+		return err
+	}
+	parents, children, err := rw.d.removeVertex(rw.ctx, dst)
+	if err != nil {
+		return err
+	}
+	for _, parent := range parents {
+		if parent == src {
+			continue
+		}
+		if err := rw.d.addEdge(rw.ctx, parent, src, nil); err != nil && !IsDuplicateEdgeError(err) {
+			return err
+		}
+	}
+	for _, child := range children {
+		if err := rw.d.addEdge(rw.ctx, src, child, nil); err != nil && !IsDuplicateEdgeError(err) {
+			return err
+		}
+	}
+
+	// children already having been children of src lost a parent
+	if rw.d.materializedPaths {
+		return rw.d.updatePaths(rw.ctx, children...)
+	}
+	return nil
+}
+
+// resolve returns the document ids of the vertices with the given ids.
+func (rw *Rewriter) resolve(srcID, dstID string) (driver.DocumentID, driver.DocumentID, error) {
+	if srcID == "" || dstID == "" {
+		return "", "", EmptyIDError()
+	}
+	if srcID == dstID {
+		return "", "", SrcDstEqualError(srcID)
+	}
+	src, err := rw.d.vertexDocumentID(rw.ctx, srcID)
+	if err != nil {
+		return "", "", err
+	}
+	dst, err := rw.d.vertexDocumentID(rw.ctx, dstID)
+	if err != nil {
+		return "", "", err
+	}
+	return src, dst, nil
+}
+
+// documentID returns the document id of the vertex with the given id (without
+// checking whether it exists).
+func (rw *Rewriter) documentID(id string) driver.DocumentID {
+	return driver.NewDocumentID(rw.d.vertices.Name(), rw.d.key(id))
+}
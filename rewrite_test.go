@@ -0,0 +1,71 @@
+package arangodag
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestDAG_Rewrite(t *testing.T) {
+	d := someNewDag(t)
+
+	// collapse the chain of aliases: 1 -> a1 -> a2 -> 2, a2 -> 3
+	_, _ = d.AddVertex(typedVertex{MyID: "1", Type: "node"})
+	_, _ = d.AddVertex(typedVertex{MyID: "a1", Type: "alias"})
+	_, _ = d.AddVertex(typedVertex{MyID: "a2", Type: "alias"})
+	_, _ = d.AddVertex(typedVertex{MyID: "2", Type: "node"})
+	_, _ = d.AddVertex(typedVertex{MyID: "3", Type: "node"})
+	_ = d.AddEdge("1", "a1")
+	_ = d.AddEdge("a1", "a2")
+	_ = d.AddEdge("a2", "2")
+	_ = d.AddEdge("a2", "3")
+
+	collapse := RewriteRule{
+		Dst: &ViewFilter{
+			Expression: "CURRENT.payload.type == @alias",
+			BindVars:   map[string]interface{}{"alias": "alias"},
+		},
+		Replace: RewriteContract,
+	}
+	count, err := d.Rewrite(collapse)
+	if err != nil {
+		t.Fatalf("failed to Rewrite(): %v", err)
+	}
+	if count != 2 {
+		t.Errorf("Rewrite() = %d, want %d", count, 2)
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+	if length, _ := d.GetShortestPathLength("1", "3"); length != 1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 1)
+	}
+
+	// failing replacements abort the rewrite
+	fail := errors.New("fail")
+	_, err = d.Rewrite(RewriteRule{
+		Src: &ViewFilter{Expression: "CURRENT._key == @src", BindVars: map[string]interface{}{"src": "1"}},
+		Replace: func(rw *Rewriter, m RewriteMatch) error {
+			if err := rw.DeleteEdge(m.Src, m.Dst); err != nil {
+				return err
+			}
+			return fail
+		},
+	})
+	if err != fail {
+		t.Errorf("Rewrite() = %v, want %v", err, fail)
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+
+	// deleting all edges
+	if count, _ := d.Rewrite(RewriteRule{Replace: RewriteDeleteEdge}); count != 2 {
+		t.Errorf("Rewrite() = %d, want %d", count, 2)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+}
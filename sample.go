@@ -0,0 +1,94 @@
+package arangodag
+
+import "context"
+
+// SampleVertices returns a page of (at most) n vertex ids chosen uniformly at
+// random together with the total number of vertices.
+func (d *DAG) SampleVertices(n int) (Page, error) {
+	return d.SampleVerticesCtx(context.Background(), n)
+}
+
+// SampleVerticesCtx is like SampleVertices but uses the given context.
+func (d *DAG) SampleVerticesCtx(ctx context.Context, n int) (Page, error) {
+	ctx = d.context(ctx)
+	acl := aclFilter(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + allMatch(acl, "[v]") + `
+  SORT RAND()
+  LIMIT @n
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"n":         n,
+	}
+	addBindVars(bindVars, acl)
+	return d.queryPage(ctx, query, bindVars)
+}
+
+// SampleVerticesWeighted returns a page of (at most) n vertex ids chosen at
+// random (without replacement) with probabilities proportional to the numeric
+// attribute weightAttr (a dot separated path relative to the stored document,
+// e.g. "payload.size") together with the total number of vertices with
+// positive weight (others are never chosen).
+func (d *DAG) SampleVerticesWeighted(n int, weightAttr string) (Page, error) {
+	return d.SampleVerticesWeightedCtx(context.Background(), n, weightAttr)
+}
+
+// SampleVerticesWeightedCtx is like SampleVerticesWeighted but uses the given
+// context.
+func (d *DAG) SampleVerticesWeightedCtx(ctx context.Context, n int, weightAttr string) (Page, error) {
+	ctx = d.context(ctx)
+	acl := aclFilter(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + allMatch(acl, "[v]") + `
+  LET weight = TO_NUMBER(v.@attr)
+  FILTER weight > 0
+  SORT POW(RAND(), 1 / weight) DESC
+  LIMIT @n
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"attr":      attributePath(weightAttr),
+		"n":         n,
+	}
+	addBindVars(bindVars, acl)
+	return d.queryPage(ctx, query, bindVars)
+}
+
+// SampleDescendants returns a page of (at most) n ids of descendants of the
+// vertex with the given id chosen uniformly at random together with the total
+// number of descendants. SampleDescendants returns an error, if id is empty or
+// unknown.
+func (d *DAG) SampleDescendants(id string, n int) (Page, error) {
+	return d.SampleDescendantsCtx(context.Background(), id, n)
+}
+
+// SampleDescendantsCtx is like SampleDescendants but uses the given context.
+func (d *DAG) SampleDescendantsCtx(ctx context.Context, id string, n int) (Page, error) {
+	if id == "" {
+		return Page{}, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return Page{}, err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+		"n":        n,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars) + `
+  SORT RAND()
+  LIMIT @n
+  RETURN v._key`
+	return d.queryPage(ctx, query, bindVars)
+}
@@ -0,0 +1,67 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+type weightedVertex struct {
+	MyID   string  `json:"id"`
+	Weight float64 `json:"weight"`
+}
+
+func (v weightedVertex) ID() string {
+	return v.MyID
+}
+
+func TestDAG_SampleVertices(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+
+	page, err := d.SampleVertices(2)
+	if err != nil {
+		t.Fatalf("failed to SampleVertices(): %v", err)
+	}
+	if len(page.IDs) != 2 || page.Total != 5 {
+		t.Errorf("SampleVertices() = %+v, want 2 of 5 ids", page)
+	}
+	if page.IDs[0] == page.IDs[1] {
+		t.Errorf("SampleVertices() = %v, want distinct ids", page.IDs)
+	}
+}
+
+func TestDAG_SampleVerticesWeighted(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(weightedVertex{MyID: "1", Weight: 1})
+	_, _ = d.AddVertex(weightedVertex{MyID: "2", Weight: 0})
+	_, _ = d.AddVertex(idVertex{MyID: "3"})
+
+	page, err := d.SampleVerticesWeighted(2, "payload.weight")
+	if err != nil {
+		t.Fatalf("failed to SampleVerticesWeighted(): %v", err)
+	}
+	if len(page.IDs) != 1 || page.IDs[0] != "1" || page.Total != 1 {
+		t.Errorf("SampleVerticesWeighted() = %+v, want [1] of 1", page)
+	}
+}
+
+func TestDAG_SampleDescendants(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+
+	page, err := d.SampleDescendants("1", 5)
+	if err != nil {
+		t.Fatalf("failed to SampleDescendants(): %v", err)
+	}
+	if len(page.IDs) != 2 || page.Total != 2 {
+		t.Errorf("SampleDescendants() = %+v, want 2 of 2 ids", page)
+	}
+	if _, err := d.SampleDescendants("foo", 1); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
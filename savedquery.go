@@ -0,0 +1,319 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"sort"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ParamType is the type of a parameter of a saved query (see SavedQuery).
+type ParamType string
+
+// Parameter types.
+const (
+	ParamString ParamType = "string"
+	ParamNumber ParamType = "number"
+	ParamBool   ParamType = "bool"
+	ParamAny    ParamType = "any"
+
+	// ParamIDs parameters take a vertex id or a list of vertex ids, which are
+	// passed to the query as (a list of) vertex document ids (e.g. to be used
+	// as start vertices of traversals).
+	ParamIDs ParamType = "ids"
+)
+
+// fromParam is the (implicitly declared) parameter holding the start vertices
+// of saved queries given by a QuerySpec.
+const fromParam = "from"
+
+// SavedQuery is a named, parameterized query stored with the DAG (see
+// SaveQuery), such that canonical queries (e.g. "runtime dependencies of X")
+// may be shared across services. A saved query is given either as AQL or as
+// QuerySpec.
+type SavedQuery struct {
+	Name string `json:"name"`
+
+	// AQL is the query. The vertex and edge collections are bound to
+	// @@vertices and @@edges.
+	AQL string `json:"aql,omitempty"`
+
+	// Spec is the specification of a traversal (see QueryBuilder), starting
+	// at the vertices given by the parameter "from" (which is implicitly
+	// declared as ParamIDs).
+	Spec *QuerySpec `json:"spec,omitempty"`
+
+	// Params declares the parameters (i.e. bind variables) of the query by
+	// name. All declared parameters are required and no other parameters are
+	// accepted.
+	Params map[string]ParamType `json:"params,omitempty"`
+}
+
+// SaveQuery saves (or replaces) the given query. SaveQuery returns an error,
+// if the name is empty or if neither or both, AQL and Spec, are given.
+func (d *DAG) SaveQuery(q SavedQuery) error {
+	return d.SaveQueryCtx(context.Background(), q)
+}
+
+// SaveQueryCtx is like SaveQuery but uses the given context.
+func (d *DAG) SaveQueryCtx(ctx context.Context, q SavedQuery) error {
+	if q.Name == "" {
+		return EmptyIDError()
+	}
+	if (q.AQL == "") == (q.Spec == nil) {
+		return InvalidParameterError(q.Name, "either AQL or a spec must be given")
+	}
+	queries, err := d.queryCollection(ctx)
+	if err != nil {
+		return err
+	}
+	doc := struct {
+		Key string `json:"_key"`
+		SavedQuery
+	}{hashKey("query", q.Name), q}
+	ctx = d.context(ctx)
+	_, err = queries.CreateDocument(driver.WithOverwriteMode(ctx, driver.OverwriteModeReplace), doc)
+	return arangoError(err)
+}
+
+// GetSavedQuery returns the saved query with the given name. GetSavedQuery
+// returns an error, if name is empty or unknown.
+func (d *DAG) GetSavedQuery(name string) (*SavedQuery, error) {
+	return d.GetSavedQueryCtx(context.Background(), name)
+}
+
+// GetSavedQueryCtx is like GetSavedQuery but uses the given context.
+func (d *DAG) GetSavedQueryCtx(ctx context.Context, name string) (*SavedQuery, error) {
+	if name == "" {
+		return nil, EmptyIDError()
+	}
+	queries, err := d.queryCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var q SavedQuery
+	if _, err := queries.ReadDocument(d.context(ctx), hashKey("query", name), &q); err != nil {
+		if driver.IsNotFound(err) {
+			return nil, NewUnknownKeyError(name)
+		}
+		return nil, arangoError(err)
+	}
+	return &q, nil
+}
+
+// DeleteSavedQuery deletes the saved query with the given name.
+// DeleteSavedQuery returns an error, if name is empty or unknown.
+func (d *DAG) DeleteSavedQuery(name string) error {
+	return d.DeleteSavedQueryCtx(context.Background(), name)
+}
+
+// DeleteSavedQueryCtx is like DeleteSavedQuery but uses the given context.
+func (d *DAG) DeleteSavedQueryCtx(ctx context.Context, name string) error {
+	if name == "" {
+		return EmptyIDError()
+	}
+	queries, err := d.queryCollection(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := queries.RemoveDocument(d.context(ctx), hashKey("query", name)); err != nil {
+		if driver.IsNotFound(err) {
+			return NewUnknownKeyError(name)
+		}
+		return arangoError(err)
+	}
+	return nil
+}
+
+// GetSavedQueryNames returns the (sorted) names of all saved queries.
+func (d *DAG) GetSavedQueryNames() ([]string, error) {
+	return d.GetSavedQueryNamesCtx(context.Background())
+}
+
+// GetSavedQueryNamesCtx is like GetSavedQueryNames but uses the given context.
+func (d *DAG) GetSavedQueryNamesCtx(ctx context.Context) ([]string, error) {
+	queries, err := d.queryCollection(ctx)
+	if err != nil {
+		return nil, err
+	}
+	query := "FOR q IN @@queries RETURN q.name"
+	bindVars := map[string]interface{}{
+		"@queries": queries.Name(),
+	}
+	names := []string{}
+	err = d.forEachDocument(d.context(ctx), query, bindVars)(func(doc json.RawMessage) error {
+		var name string
+		if err := json.Unmarshal(doc, &name); err != nil {
+			return err
+		}
+		names = append(names, name)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// RunSavedQuery runs the saved query with the given name with the given
+// parameters and calls fn for each result. Results of spec queries are
+// objects with the attributes "id", "depth" and "payload" (see
+// QueryIterator). Running stops at the first error returned by fn, which is
+// returned by RunSavedQuery. Saved queries run within a read-only
+// transaction, thus, data-modification queries (INSERT, UPDATE, ...) fail.
+// Id parameters may be given as string, []string or (e.g. as decoded from
+// JSON) []interface{} of strings. RunSavedQuery returns an error, if name is
+// empty or unknown, or if the parameters don't match the declared ones.
+func (d *DAG) RunSavedQuery(name string, params map[string]interface{}, fn func(doc json.RawMessage) error) error {
+	return d.RunSavedQueryCtx(context.Background(), name, params, fn)
+}
+
+// RunSavedQueryCtx is like RunSavedQuery but uses the given context. Spec
+// queries hide the vertices hidden from the principal carried by ctx (see
+// WithPrincipal). AQL queries would bypass these ACLs, thus, RunSavedQueryCtx
+// refuses to run them on behalf of a principal.
+func (d *DAG) RunSavedQueryCtx(ctx context.Context, name string, params map[string]interface{}, fn func(doc json.RawMessage) error) error {
+	ctx = d.context(ctx)
+	q, err := d.GetSavedQueryCtx(ctx, name)
+	if err != nil {
+		return err
+	}
+	if _, ok := PrincipalFromContext(ctx); ok && q.Spec == nil {
+		return InvalidParameterError(name, "AQL queries bypass ACLs and can't be run on behalf of a principal")
+	}
+	declared := q.Params
+	if q.Spec != nil {
+		declared = make(map[string]ParamType, len(q.Params)+1)
+		for param, typ := range q.Params {
+			declared[param] = typ
+		}
+		declared[fromParam] = ParamIDs
+	}
+	bindVars, err := d.bindParams(ctx, declared, params)
+	if err != nil {
+		return err
+	}
+
+	// saved queries only read, i.e. data-modification queries fail
+	return d.readTransaction(ctx, func(ctx context.Context) error {
+		if q.Spec == nil {
+			bindVars["@vertices"] = d.vertices.Name()
+			bindVars["@edges"] = d.edges.Name()
+			return d.forEachDocument(ctx, q.AQL, bindVars)(fn)
+		}
+
+		starts := bindVars[fromParam].([]driver.DocumentID)
+		delete(bindVars, fromParam)
+		it, err := d.QueryFromSpec(q.Spec).Bind(bindVars).stream(ctx, starts)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = it.Close() }()
+		for it.Next() {
+			doc, err := json.Marshal(queryItem{ID: it.ID(), Depth: it.Depth(), Payload: it.item.Payload})
+			if err != nil {
+				return err
+			}
+			if err := fn(doc); err != nil {
+				return err
+			}
+		}
+		return it.Err()
+	})
+}
+
+// bindParams checks the given parameters against the declared ones and returns
+// them as bind variables (converting ParamIDs parameters to document ids).
+func (d *DAG) bindParams(ctx context.Context, declared map[string]ParamType, params map[string]interface{}) (map[string]interface{}, error) {
+	for name := range params {
+		if _, ok := declared[name]; !ok {
+			return nil, InvalidParameterError(name, "not declared")
+		}
+	}
+	bindVars := make(map[string]interface{}, len(declared))
+	for name, typ := range declared {
+		value, ok := params[name]
+		if !ok {
+			return nil, InvalidParameterError(name, "missing")
+		}
+		if typ == ParamIDs {
+			ids, ok := stringList(value)
+			if !ok {
+				return nil, InvalidParameterError(name, "want id or ids")
+			}
+			docIDs, err := d.vertexDocumentIDs(ctx, ids)
+			if err != nil {
+				return nil, err
+			}
+			bindVars[name] = docIDs
+			continue
+		}
+		if !typ.matches(value) {
+			return nil, InvalidParameterError(name, "want "+string(typ))
+		}
+		bindVars[name] = value
+	}
+	return bindVars, nil
+}
+
+// matches returns true, if the given value is of type t.
+func (t ParamType) matches(value interface{}) bool {
+	if t == ParamAny {
+		return true
+	}
+	if value == nil {
+		return false
+	}
+	switch reflect.TypeOf(value).Kind() {
+	case reflect.String:
+		return t == ParamString
+	case reflect.Bool:
+		return t == ParamBool
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return t == ParamNumber
+	}
+	return false
+}
+
+// queryCollection returns the collection holding saved queries, creating it,
+// if it doesn't exist.
+func (d *DAG) queryCollection(ctx context.Context) (driver.Collection, error) {
+	d.queriesMu.Lock()
+	defer d.queriesMu.Unlock()
+	if d.queries == nil {
+		queries, err := useOrCreateCollection(d.context(ctx), d.db, d.vertices.Name()+"_queries", nil)
+		if err != nil {
+			return nil, arangoError(err)
+		}
+		d.queries = queries
+	}
+	return d.queries, nil
+}
+
+// stringList returns the given value (a string, a []string or, as decoded from
+// JSON, a []interface{} holding strings only) as list of strings. The second
+// return value is false, if value is none of these.
+func stringList(value interface{}) ([]string, bool) {
+	switch v := value.(type) {
+	case string:
+		return []string{v}, true
+	case []string:
+		return v, true
+	case []interface{}:
+		list := make([]string, 0, len(v))
+		for _, item := range v {
+			s, ok := item.(string)
+			if !ok {
+				return nil, false
+			}
+			list = append(list, s)
+		}
+		return list, true
+	}
+	return nil, false
+}
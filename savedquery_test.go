@@ -0,0 +1,122 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_SavedQuery(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(foobarKey{MyID: id, A: "a" + id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+
+	deps := SavedQuery{
+		Name:   "deps",
+		Spec:   d.Query().MaxDepth(1).Select("A").Spec(),
+		Params: map[string]ParamType{},
+	}
+	if err := d.SaveQuery(deps); err != nil {
+		t.Fatalf("failed to SaveQuery(): %v", err)
+	}
+	count := SavedQuery{
+		Name:   "count",
+		AQL:    "RETURN LENGTH(FOR v IN @@vertices FILTER v.payload.A >= @min RETURN 1)",
+		Params: map[string]ParamType{"min": ParamString},
+	}
+	if err := d.SaveQuery(count); err != nil {
+		t.Fatalf("failed to SaveQuery(): %v", err)
+	}
+	if names, _ := d.GetSavedQueryNames(); deep.Equal(names, []string{"count", "deps"}) != nil {
+		t.Errorf("GetSavedQueryNames() = %v, want [count deps]", names)
+	}
+
+	var results []string
+	err := d.RunSavedQuery("deps", map[string]interface{}{"from": "2"}, func(doc json.RawMessage) error {
+		results = append(results, string(doc))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to RunSavedQuery(): %v", err)
+	}
+	if diff := deep.Equal(results, []string{`{"id":"3","depth":1,"payload":{"A":"a3"}}`}); diff != nil {
+		t.Error(diff)
+	}
+
+	results = nil
+	err = d.RunSavedQuery("count", map[string]interface{}{"min": "a2"}, func(doc json.RawMessage) error {
+		results = append(results, string(doc))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to RunSavedQuery(): %v", err)
+	}
+	if diff := deep.Equal(results, []string{"2"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// id lists as decoded from JSON
+	results = nil
+	err = d.RunSavedQuery("deps", map[string]interface{}{"from": []interface{}{"1", "2"}}, func(doc json.RawMessage) error {
+		results = append(results, string(doc))
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to RunSavedQuery(): %v", err)
+	}
+	if len(results) != 2 {
+		t.Errorf("RunSavedQuery() returned %d results, want 2", len(results))
+	}
+
+	noop := func(json.RawMessage) error { return nil }
+	if err := d.RunSavedQuery("deps", map[string]interface{}{"from": []interface{}{"1", 2}}, noop); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+
+	// saved queries run read-only
+	drop := SavedQuery{
+		Name: "drop",
+		AQL:  "FOR v IN @@vertices REMOVE v IN @@vertices",
+	}
+	if err := d.SaveQuery(drop); err != nil {
+		t.Fatalf("failed to SaveQuery(): %v", err)
+	}
+	if err := d.RunSavedQuery("drop", nil, noop); err == nil {
+		t.Error("RunSavedQuery() of a data-modification query succeeded")
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want 3", order)
+	}
+	if err := d.RunSavedQuery("count", map[string]interface{}{"min": 1}, noop); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+	if err := d.RunSavedQuery("count", nil, noop); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+	if err := d.RunSavedQuery("foo", nil, noop); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+
+	// AQL queries bypass ACLs
+	alice := WithPrincipal(context.Background(), "alice")
+	if err := d.RunSavedQueryCtx(alice, "count", map[string]interface{}{"min": "a2"}, noop); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+	if err := d.RunSavedQueryCtx(alice, "deps", map[string]interface{}{"from": "2"}, noop); err != nil {
+		t.Errorf("failed to RunSavedQueryCtx(): %v", err)
+	}
+
+	if err := d.DeleteSavedQuery("count"); err != nil {
+		t.Fatalf("failed to DeleteSavedQuery(): %v", err)
+	}
+	if _, err := d.GetSavedQuery("count"); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
@@ -0,0 +1,209 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"strings"
+)
+
+// SBOMComponent is the payload of vertices created from SBOMs.
+type SBOMComponent struct {
+	Name    string `json:"name"`
+	Version string `json:"version,omitempty"`
+	PURL    string `json:"purl,omitempty"`
+	Type    string `json:"type,omitempty"`
+}
+
+// SBOMKey returns the id of the vertex representing the given component.
+// Components are identified by their package URL (purl) or, if there is none,
+// by their name and version.
+func SBOMKey(c SBOMComponent) string {
+	if c.PURL != "" {
+		return hashKey("purl", c.PURL)
+	}
+	return hashKey("component", c.Name, c.Version)
+}
+
+// ImportSBOM adds the components of the CycloneDX or SPDX SBOM (JSON) read
+// from r as vertices and their dependencies as edges (pointing from the
+// dependent component to its dependency). Components are deduplicated by
+// their purl (see SBOMKey), i.e. importing multiple SBOMs results in a single
+// dependency graph. The SBOM is imported within a single transaction.
+// ImportSBOM returns an error (importing nothing), if the format of the SBOM
+// is not supported or if a dependency would create a loop.
+func (d *DAG) ImportSBOM(r io.Reader) error {
+	return d.ImportSBOMCtx(context.Background(), r)
+}
+
+// ImportSBOMCtx is like ImportSBOM but uses the given context.
+func (d *DAG) ImportSBOMCtx(ctx context.Context, r io.Reader) error {
+	data, err := ioutil.ReadAll(r)
+	if err != nil {
+		return err
+	}
+	var format struct {
+		BOMFormat   string `json:"bomFormat"`
+		SPDXVersion string `json:"spdxVersion"`
+	}
+	if err := json.Unmarshal(data, &format); err != nil {
+		return err
+	}
+
+	var components map[string]SBOMComponent
+	var dependencies map[string][]string
+	switch {
+	case format.BOMFormat == "CycloneDX":
+		components, dependencies, err = parseCycloneDX(data)
+	case format.SPDXVersion != "":
+		components, dependencies, err = parseSPDX(data)
+	default:
+		return errors.New("unsupported SBOM format (expecting CycloneDX or SPDX JSON)")
+	}
+	if err != nil {
+		return err
+	}
+
+	return d.importDependencies(ctx, components, dependencies)
+}
+
+// importDependencies adds the given components (by reference) as vertices and
+// connects each component to its dependencies (by reference) within a single
+// transaction. References not referring to a component are ignored.
+func (d *DAG) importDependencies(ctx context.Context, components map[string]SBOMComponent, dependencies map[string][]string) error {
+	err := d.edgeTransaction(d.context(ctx), func(ctx context.Context) error {
+		return d.importComponents(ctx, components, dependencies)
+	})
+	d.counts.invalidate()
+	return err
+}
+
+// importComponents imports the given components and dependencies (see
+// importDependencies) within the transaction carried by ctx.
+func (d *DAG) importComponents(ctx context.Context, components map[string]SBOMComponent, dependencies map[string][]string) error {
+	ids := make(map[string]string, len(components))
+	for ref, c := range components {
+		id := SBOMKey(c)
+		if err := d.upsertVertex(ctx, id, c); err != nil {
+			return err
+		}
+		ids[ref] = id
+	}
+	for ref, dependsOn := range dependencies {
+		src, ok := ids[ref]
+		if !ok {
+			continue
+		}
+		for _, dep := range dependsOn {
+			dst, ok := ids[dep]
+			if !ok || src == dst {
+				continue
+			}
+			if err := d.AddEdgeCtx(ctx, src, dst); err != nil && !IsDuplicateEdgeError(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+type cycloneDXComponent struct {
+	BOMRef     string               `json:"bom-ref"`
+	Type       string               `json:"type"`
+	Name       string               `json:"name"`
+	Version    string               `json:"version"`
+	PURL       string               `json:"purl"`
+	Components []cycloneDXComponent `json:"components"`
+}
+
+// parseCycloneDX returns the components and dependencies (both by bom-ref) of
+// the given CycloneDX document.
+func parseCycloneDX(data []byte) (map[string]SBOMComponent, map[string][]string, error) {
+	var bom struct {
+		Metadata struct {
+			Component *cycloneDXComponent `json:"component"`
+		} `json:"metadata"`
+		Components   []cycloneDXComponent `json:"components"`
+		Dependencies []struct {
+			Ref       string   `json:"ref"`
+			DependsOn []string `json:"dependsOn"`
+		} `json:"dependencies"`
+	}
+	if err := json.Unmarshal(data, &bom); err != nil {
+		return nil, nil, err
+	}
+
+	components := make(map[string]SBOMComponent)
+	var add func(cs []cycloneDXComponent)
+	add = func(cs []cycloneDXComponent) {
+		for _, c := range cs {
+			ref := c.BOMRef
+			if ref == "" {
+				ref = c.PURL
+			}
+			if ref == "" {
+				ref = c.Name + "@" + c.Version
+			}
+			components[ref] = SBOMComponent{Name: c.Name, Version: c.Version, PURL: c.PURL, Type: c.Type}
+			add(c.Components)
+		}
+	}
+	if bom.Metadata.Component != nil {
+		add([]cycloneDXComponent{*bom.Metadata.Component})
+	}
+	add(bom.Components)
+
+	dependencies := make(map[string][]string)
+	for _, dep := range bom.Dependencies {
+		dependencies[dep.Ref] = append(dependencies[dep.Ref], dep.DependsOn...)
+	}
+	return components, dependencies, nil
+}
+
+// parseSPDX returns the packages and dependencies (both by SPDXID) of the
+// given SPDX document.
+func parseSPDX(data []byte) (map[string]SBOMComponent, map[string][]string, error) {
+	var doc struct {
+		Packages []struct {
+			SPDXID       string `json:"SPDXID"`
+			Name         string `json:"name"`
+			VersionInfo  string `json:"versionInfo"`
+			ExternalRefs []struct {
+				ReferenceType    string `json:"referenceType"`
+				ReferenceLocator string `json:"referenceLocator"`
+			} `json:"externalRefs"`
+		} `json:"packages"`
+		Relationships []struct {
+			Element          string `json:"spdxElementId"`
+			RelationshipType string `json:"relationshipType"`
+			Related          string `json:"relatedSpdxElement"`
+		} `json:"relationships"`
+	}
+	if err := json.Unmarshal(data, &doc); err != nil {
+		return nil, nil, err
+	}
+
+	components := make(map[string]SBOMComponent)
+	for _, p := range doc.Packages {
+		c := SBOMComponent{Name: p.Name, Version: p.VersionInfo, Type: "library"}
+		for _, ref := range p.ExternalRefs {
+			if ref.ReferenceType == "purl" {
+				c.PURL = ref.ReferenceLocator
+			}
+		}
+		components[p.SPDXID] = c
+	}
+
+	dependencies := make(map[string][]string)
+	for _, r := range doc.Relationships {
+		switch strings.ToUpper(r.RelationshipType) {
+		case "DEPENDS_ON", "CONTAINS", "DESCRIBES":
+			dependencies[r.Element] = append(dependencies[r.Element], r.Related)
+		case "DEPENDENCY_OF", "CONTAINED_BY", "DESCRIBED_BY":
+			dependencies[r.Related] = append(dependencies[r.Related], r.Element)
+		}
+	}
+	return components, dependencies, nil
+}
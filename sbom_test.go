@@ -0,0 +1,84 @@
+package arangodag
+
+import (
+	"strings"
+	"testing"
+)
+
+const cycloneDXBOM = `{
+  "bomFormat": "CycloneDX",
+  "specVersion": "1.4",
+  "metadata": {"component": {"bom-ref": "app", "type": "application", "name": "app", "version": "1.0.0"}},
+  "components": [
+    {"bom-ref": "lodash", "type": "library", "name": "lodash", "version": "4.17.21", "purl": "pkg:npm/lodash@4.17.21"},
+    {"bom-ref": "express", "type": "library", "name": "express", "version": "4.18.2", "purl": "pkg:npm/express@4.18.2"}
+  ],
+  "dependencies": [
+    {"ref": "app", "dependsOn": ["lodash", "express"]},
+    {"ref": "express", "dependsOn": ["lodash"]}
+  ]
+}`
+
+const spdxBOM = `{
+  "spdxVersion": "SPDX-2.3",
+  "SPDXID": "SPDXRef-DOCUMENT",
+  "packages": [
+    {"SPDXID": "SPDXRef-other", "name": "other", "versionInfo": "0.1.0"},
+    {"SPDXID": "SPDXRef-lodash", "name": "lodash", "versionInfo": "4.17.21",
+     "externalRefs": [{"referenceCategory": "PACKAGE-MANAGER", "referenceType": "purl", "referenceLocator": "pkg:npm/lodash@4.17.21"}]}
+  ],
+  "relationships": [
+    {"spdxElementId": "SPDXRef-lodash", "relationshipType": "DEPENDENCY_OF", "relatedSpdxElement": "SPDXRef-other"}
+  ]
+}`
+
+func TestDAG_ImportSBOM(t *testing.T) {
+	d := someNewDag(t)
+
+	if err := d.ImportSBOM(strings.NewReader(cycloneDXBOM)); err != nil {
+		t.Fatalf("failed to ImportSBOM(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := d.GetSize(); size != 3 {
+		t.Errorf("GetSize() = %d, want %d", size, 3)
+	}
+
+	// lodash is deduplicated by its purl
+	if err := d.ImportSBOM(strings.NewReader(spdxBOM)); err != nil {
+		t.Fatalf("failed to ImportSBOM(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want %d", order, 4)
+	}
+	if size, _ := d.GetSize(); size != 4 {
+		t.Errorf("GetSize() = %d, want %d", size, 4)
+	}
+
+	var c SBOMComponent
+	if err := d.GetVertex(SBOMKey(SBOMComponent{PURL: "pkg:npm/lodash@4.17.21"}), &c); err != nil {
+		t.Fatalf("failed to GetVertex(): %v", err)
+	}
+	if c.Name != "lodash" {
+		t.Errorf("GetVertex() = %v, want 'lodash'", c)
+	}
+
+	// unsupported format
+	if err := d.ImportSBOM(strings.NewReader(`{"foo": "bar"}`)); err == nil {
+		t.Errorf("ImportSBOM() = nil, want error")
+	}
+}
+
+func Test_parseCycloneDX(t *testing.T) {
+	components, dependencies, err := parseCycloneDX([]byte(cycloneDXBOM))
+	if err != nil {
+		t.Fatalf("failed to parseCycloneDX(): %v", err)
+	}
+	if len(components) != 3 {
+		t.Errorf("parseCycloneDX() returned %d components, want %d", len(components), 3)
+	}
+	if len(dependencies["app"]) != 2 {
+		t.Errorf("parseCycloneDX() returned %v, want 2 dependencies of 'app'", dependencies)
+	}
+}
@@ -0,0 +1,112 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/arangodb/go-driver"
+)
+
+// ShardAttribute is the attribute of vertex and edge documents holding the
+// shard value (see WithSharding).
+const ShardAttribute = "shard"
+
+// WithSharding configures the DAG for co-located subgraphs in cluster setups.
+// Vertex documents hold the value of the vertex attribute at the given (dot
+// separated) path (e.g. "tenant") in the attribute ShardAttribute, edge
+// documents hold the value of their source vertex. If the collections don't
+// exist yet, they are created with ShardAttribute as shard key and the given
+// number of shards (0 meaning the server's default), the edge collection
+// distributing its shards like the vertex collection. Thus, vertices with the
+// same shard value and their outbound edges are stored on the same shard.
+// AddVertex returns an error, if a vertex lacks the attribute (or the value
+// isn't a string or number). Note, that ArangoDB clusters reject user-defined
+// keys for collections sharded by other attributes than "_key", thus, vertices
+// must not implement IDInterface (use the generated ids instead).
+func WithSharding(path string, numberOfShards int) Option {
+	return func(d *DAG) {
+		d.shardPath = path
+		d.numberOfShards = numberOfShards
+	}
+}
+
+// collectionOptions returns the options for creating the vertex and the edge
+// collection with the given names.
+func (d *DAG) collectionOptions(vertexCollName string) (*driver.CreateCollectionOptions, *driver.CreateCollectionOptions) {
+	if d.shardPath == "" {
+		return nil, edgeCollectionOptions()
+	}
+	vertexOptions := &driver.CreateCollectionOptions{
+		ShardKeys:      []string{ShardAttribute},
+		NumberOfShards: d.numberOfShards,
+	}
+	edgeOptions := edgeCollectionOptions()
+	edgeOptions.ShardKeys = []string{ShardAttribute}
+	edgeOptions.NumberOfShards = d.numberOfShards
+	edgeOptions.DistributeShardsLike = vertexCollName
+	return vertexOptions, edgeOptions
+}
+
+// vertexShard returns the shard value of the given vertex.
+func (d *DAG) vertexShard(vertex interface{}) (string, error) {
+	data, err := json.Marshal(vertex)
+	if err != nil {
+		return "", err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return "", err
+	}
+	for _, attr := range attributePath(d.shardPath) {
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = obj[attr]
+	}
+	switch v := value.(type) {
+	case string:
+		if v != "" {
+			return v, nil
+		}
+	case float64:
+		return fmt.Sprint(v), nil
+	}
+	return "", InvalidParameterError(d.shardPath, "missing shard value")
+}
+
+// edgeShard returns the shard value of edges starting at the given vertex
+// (i.e. its own shard value).
+func (d *DAG) edgeShard(ctx context.Context, src driver.DocumentID) (string, error) {
+	query := "RETURN DOCUMENT(@src).@attr"
+	bindVars := map[string]interface{}{
+		"src":  src,
+		"attr": ShardAttribute,
+	}
+	var shard string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &shard)
+	})
+	return shard, err
+}
+
+// setEdgeShard sets the shard of the given edge document (see edgeDocument) to
+// the shard of its source src (if sharding is enabled).
+func (d *DAG) setEdgeShard(ctx context.Context, doc interface{}, src driver.DocumentID) error {
+	if d.shardPath == "" {
+		return nil
+	}
+	shard, err := d.edgeShard(ctx, src)
+	if err != nil {
+		return err
+	}
+	switch e := doc.(type) {
+	case *myEdge:
+		e.Shard = shard
+	case map[string]interface{}:
+		e[ShardAttribute] = shard
+	}
+	return nil
+}
@@ -0,0 +1,40 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestWithSharding(t *testing.T) {
+	d := someNewDag(t, WithSharding("A", 0))
+
+	id1, err := d.AddVertex(foobarKey{A: "tenant1"})
+	if err != nil {
+		t.Fatalf("failed to AddVertex(): %v", err)
+	}
+	id2, _ := d.AddVertex(foobarKey{A: "tenant2"})
+	if _, err := d.AddVertex(foobarKey{B: "b"}); !IsInvalidParameterError(err) {
+		t.Errorf("want InvalidParameterError, got %v", err)
+	}
+	if err := d.AddEdge(id1, id2); err != nil {
+		t.Fatalf("failed to AddEdge(): %v", err)
+	}
+
+	var vertex map[string]interface{}
+	if _, err := d.vertices.ReadDocument(context.Background(), id1, &vertex); err != nil {
+		t.Fatalf("failed to read vertex: %v", err)
+	}
+	if vertex[ShardAttribute] != "tenant1" {
+		t.Errorf("vertex shard = %v, want tenant1", vertex[ShardAttribute])
+	}
+	query := "FOR e IN @@edges RETURN e.@attr"
+	bindVars := map[string]interface{}{"@edges": d.edges.Name(), "attr": ShardAttribute}
+	var shard string
+	err = d.forEachDocument(context.Background(), query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &shard)
+	})
+	if err != nil || shard != "tenant1" {
+		t.Errorf("edge shard = %v, %v, want tenant1", shard, err)
+	}
+}
@@ -0,0 +1,263 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"time"
+)
+
+// Snapshot describes a saved state of the graph (see SnapshotRegistry).
+type Snapshot struct {
+	Key     string    `json:"key"`
+	Message string    `json:"message"`
+	Created time.Time `json:"created"`
+
+	// Order and Size are the number of vertices and edges saved.
+	Order uint64 `json:"order"`
+	Size  uint64 `json:"size"`
+
+	// Parents are the keys of the snapshots this snapshot was derived from,
+	// Children are the keys of the snapshots derived from this snapshot.
+	Parents  []string `json:"parents,omitempty"`
+	Children []string `json:"children,omitempty"`
+}
+
+// ID implements the IDInterface (i.e. snapshots are vertices of the meta DAG).
+func (s Snapshot) ID() string {
+	return s.Key
+}
+
+// SnapshotRegistry manages the snapshots of a DAG (see Snapshots). Similar to
+// git commits, the snapshots form a DAG themselves (the meta DAG), with edges
+// from each snapshot to the snapshots derived from it.
+type SnapshotRegistry struct {
+	d    *DAG
+	meta *DAG
+	head string
+}
+
+// Snapshots returns the snapshot registry of d. The meta DAG is stored in the
+// collections named after the vertex collection of d with the suffixes
+// "_snapshots" and "_snapshot_links". The vertices and edges of each snapshot
+// are stored in collections named after the collections of d, suffixed by
+// "_snapshot_" and the key of the snapshot. Initially, the head (see Head) is
+// the most recently created snapshot.
+func (d *DAG) Snapshots() (*SnapshotRegistry, error) {
+	return d.SnapshotsCtx(context.Background())
+}
+
+// SnapshotsCtx is like Snapshots but uses the given context.
+func (d *DAG) SnapshotsCtx(ctx context.Context) (*SnapshotRegistry, error) {
+	meta, err := NewDAGWithContext(ctx, d.db.Name(), d.vertices.Name()+"_snapshots", d.vertices.Name()+"_snapshot_links", d.client)
+	if err != nil {
+		return nil, err
+	}
+	r := &SnapshotRegistry{d: d, meta: meta}
+	snapshots, err := r.ListCtx(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) > 0 {
+		r.head = snapshots[len(snapshots)-1].Key
+	}
+	return r, nil
+}
+
+// Head returns the key of the snapshot most recently created or checked out
+// (or an empty string, if there are no snapshots).
+func (r *SnapshotRegistry) Head() string {
+	return r.head
+}
+
+// Create saves the current state of the graph as new snapshot with the given
+// message and makes it the head. The parents of the new snapshot are the
+// snapshots with the given keys or, if none are given, the head (if any).
+// Create returns an error, if one of the parents is unknown.
+func (r *SnapshotRegistry) Create(message string, parents ...string) (*Snapshot, error) {
+	return r.CreateCtx(context.Background(), message, parents...)
+}
+
+// CreateCtx is like Create but uses the given context.
+func (r *SnapshotRegistry) CreateCtx(ctx context.Context, message string, parents ...string) (*Snapshot, error) {
+	if len(parents) == 0 && r.head != "" {
+		parents = []string{r.head}
+	}
+	missing, err := r.meta.missingVertices(r.meta.context(ctx), parents)
+	if err != nil {
+		return nil, err
+	}
+	if len(missing) > 0 {
+		return nil, NewUnknownKeyError(missing[0])
+	}
+
+	// save the graph
+	s := &Snapshot{
+		Key:     randomKey(),
+		Message: message,
+		Created: time.Now().UTC().Truncate(time.Millisecond),
+	}
+	data, err := r.data(ctx, s.Key)
+	if err != nil {
+		return nil, err
+	}
+	if err := r.d.CloneCtx(ctx, data); err != nil {
+		data.drop(ctx)
+		return nil, err
+	}
+	if s.Order, err = data.GetOrderCtx(ctx); err != nil {
+		return nil, err
+	}
+	if s.Size, err = data.GetSizeCtx(ctx); err != nil {
+		return nil, err
+	}
+
+	// register the snapshot
+	err = r.meta.transaction(r.meta.context(ctx), func(ctx context.Context) error {
+		doc := &arangoDocKeyContainer{Payload: s, Key: s.Key}
+		if _, err := r.meta.vertices.CreateDocument(ctx, doc); err != nil {
+			return arangoError(err)
+		}
+		dst := driver.NewDocumentID(r.meta.vertices.Name(), s.Key)
+		for _, parent := range parents {
+			src := driver.NewDocumentID(r.meta.vertices.Name(), parent)
+			if err := r.meta.addEdge(ctx, src, dst, nil); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		data.drop(ctx)
+		return nil, err
+	}
+	s.Parents = parents
+	r.head = s.Key
+	return s, nil
+}
+
+// Get returns the snapshot with the given key. Get returns an error, if key is
+// empty or unknown.
+func (r *SnapshotRegistry) Get(key string) (*Snapshot, error) {
+	return r.GetCtx(context.Background(), key)
+}
+
+// GetCtx is like Get but uses the given context.
+func (r *SnapshotRegistry) GetCtx(ctx context.Context, key string) (*Snapshot, error) {
+	if key == "" {
+		return nil, EmptyIDError()
+	}
+	snapshots, err := r.list(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	if len(snapshots) == 0 {
+		return nil, NewUnknownKeyError(key)
+	}
+	return &snapshots[0], nil
+}
+
+// List returns all snapshots ordered by the time of their creation.
+func (r *SnapshotRegistry) List() ([]Snapshot, error) {
+	return r.ListCtx(context.Background())
+}
+
+// ListCtx is like List but uses the given context.
+func (r *SnapshotRegistry) ListCtx(ctx context.Context) ([]Snapshot, error) {
+	return r.list(ctx, "")
+}
+
+// list returns the snapshot with the given key or, if key is empty, all
+// snapshots (ordered by the time of their creation).
+func (r *SnapshotRegistry) list(ctx context.Context, key string) ([]Snapshot, error) {
+	query := `
+FOR v IN @@vertices
+  FILTER @key == "" OR v._key == @key
+  SORT DATE_TIMESTAMP(v.payload.created), v._key
+  RETURN MERGE(v.payload, {
+    parents: (FOR p IN 1 INBOUND v @@edges SORT p._key RETURN p._key),
+    children: (FOR c IN 1 OUTBOUND v @@edges SORT c._key RETURN c._key)
+  })`
+	bindVars := map[string]interface{}{
+		"@vertices": r.meta.vertices.Name(),
+		"@edges":    r.meta.edges.Name(),
+		"key":       key,
+	}
+	snapshots := []Snapshot{}
+	err := r.meta.forEachDocument(r.meta.context(ctx), query, bindVars)(func(doc json.RawMessage) error {
+		var s Snapshot
+		if err := json.Unmarshal(doc, &s); err != nil {
+			return err
+		}
+		snapshots = append(snapshots, s)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return snapshots, nil
+}
+
+// Checkout replaces all vertices and edges of the DAG by those saved in the
+// snapshot with the given key (within a single transaction) and makes it the
+// head. Checkout returns an error, if key is empty or unknown.
+func (r *SnapshotRegistry) Checkout(key string) error {
+	return r.CheckoutCtx(context.Background(), key)
+}
+
+// CheckoutCtx is like Checkout but uses the given context.
+func (r *SnapshotRegistry) CheckoutCtx(ctx context.Context, key string) error {
+	if _, err := r.GetCtx(ctx, key); err != nil {
+		return err
+	}
+	data, err := r.data(ctx, key)
+	if err != nil {
+		return err
+	}
+	d := r.d
+	err = d.transaction(d.context(ctx), func(ctx context.Context) error {
+		for _, c := range []struct {
+			coll driver.Collection
+			typ  string
+		}{{d.edges, changeEdge}, {d.vertices, changeVertex}} {
+			query := "FOR doc IN @@coll REMOVE doc IN @@coll RETURN OLD._id"
+			bindVars := map[string]interface{}{
+				"@coll": c.coll.Name(),
+			}
+			ids, err := d.queryIDs(ctx, query, bindVars)
+			if err != nil {
+				return err
+			}
+			if err := d.afterWrite(ctx, changeRemove, c.typ, ids...); err != nil {
+				return err
+			}
+		}
+		if err := copyDocuments(ctx, d.vertices, data.forEachVertexDocument(ctx, nil), d.afterCopy(ctx, changeVertex)); err != nil {
+			return err
+		}
+		return copyDocuments(ctx, d.edges, data.forEachEdgeDocument(ctx, d.vertices.Name(), false), d.afterCopy(ctx, changeEdge))
+	})
+	if err != nil {
+		return err
+	}
+	r.head = key
+	return nil
+}
+
+// data returns the DAG holding the vertices and edges of the snapshot with the
+// given key.
+func (r *SnapshotRegistry) data(ctx context.Context, key string) (*DAG, error) {
+	suffix := "_snapshot_" + key
+	return NewDAGWithContext(ctx, r.d.db.Name(), r.d.vertices.Name()+suffix, r.d.edges.Name()+suffix, r.d.client)
+}
+
+// drop removes the collections of d (ignoring errors), also if ctx is
+// cancelled already (e.g. when cleaning up after a failure).
+func (d *DAG) drop(ctx context.Context) {
+	ctx = d.context(detachedContext{ctx})
+	for _, coll := range []driver.Collection{d.vertices, d.edges, d.changes, d.inverse} {
+		if coll != nil {
+			_ = coll.Remove(ctx)
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestSnapshotRegistry(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+
+	r, err := d.Snapshots()
+	if err != nil {
+		t.Fatalf("failed to Snapshots(): %v", err)
+	}
+	if r.Head() != "" {
+		t.Errorf("Head() = %s, want empty", r.Head())
+	}
+	s1, err := r.Create("first")
+	if err != nil {
+		t.Fatalf("failed to Create(): %v", err)
+	}
+	if len(s1.Parents) != 0 || s1.Order != 1 || s1.Size != 0 {
+		t.Errorf("Create() = %+v, want no parents, order 1, size 0", s1)
+	}
+
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	_ = d.AddEdge("1", "2")
+	s2, err := r.Create("second")
+	if err != nil {
+		t.Fatalf("failed to Create(): %v", err)
+	}
+	if len(s2.Parents) != 1 || s2.Parents[0] != s1.Key {
+		t.Errorf("Parents = %v, want [%s]", s2.Parents, s1.Key)
+	}
+	if r.Head() != s2.Key {
+		t.Errorf("Head() = %s, want %s", r.Head(), s2.Key)
+	}
+
+	// double links
+	s, err := r.Get(s1.Key)
+	if err != nil {
+		t.Fatalf("failed to Get(): %v", err)
+	}
+	if len(s.Children) != 1 || s.Children[0] != s2.Key {
+		t.Errorf("Children = %v, want [%s]", s.Children, s2.Key)
+	}
+	if snapshots, _ := r.List(); len(snapshots) != 2 || snapshots[0].Key != s1.Key {
+		t.Errorf("List() = %v, want [%s %s]", snapshots, s1.Key, s2.Key)
+	}
+
+	// checking out the first snapshot restores the graph
+	if err := r.Checkout(s1.Key); err != nil {
+		t.Fatalf("failed to Checkout(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 1 {
+		t.Errorf("GetOrder() = %d, want %d", order, 1)
+	}
+	if size, _ := d.GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+
+	// new snapshots branch off the head
+	s3, err := r.Create("branch")
+	if err != nil {
+		t.Fatalf("failed to Create(): %v", err)
+	}
+	if len(s3.Parents) != 1 || s3.Parents[0] != s1.Key {
+		t.Errorf("Parents = %v, want [%s]", s3.Parents, s1.Key)
+	}
+
+	// merges
+	s4, err := r.Create("merge", s2.Key, s3.Key)
+	if err != nil {
+		t.Fatalf("failed to Create(): %v", err)
+	}
+	if len(s4.Parents) != 2 {
+		t.Errorf("Parents = %v, want 2 parents", s4.Parents)
+	}
+
+	// reopening the registry keeps the snapshots
+	r, _ = d.Snapshots()
+	if r.Head() != s4.Key {
+		t.Errorf("Head() = %s, want %s", r.Head(), s4.Key)
+	}
+
+	// errors
+	if _, err := r.Create("broken", "foo"); !IsUnknownIDError(err) {
+		t.Errorf("Create() = %v, want unknown id error", err)
+	}
+	if err := r.Checkout("foo"); !IsUnknownIDError(err) {
+		t.Errorf("Checkout() = %v, want unknown id error", err)
+	}
+	if _, err := r.Get(""); !IsEmptyIDError(err) {
+		t.Errorf("Get() = %v, want empty id error", err)
+	}
+}
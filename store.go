@@ -0,0 +1,48 @@
+package arangodag
+
+// GraphStore describes a small set of core graph operations, i.e. adding and
+// reading vertices, adding and deleting edges, counting and reachability.
+// Code using only these operations may depend on GraphStore rather than on DAG
+// and, thus, run against alternative backends, e.g. the in-memory MemoryStore
+// in tests.
+//
+// Note, GraphStore is not a storage layer DAG is built upon: DAG implements
+// these operations directly via AQL, and all other methods of DAG (views,
+// traversals with options, transactions, exporters, the change log, ACLs, ...)
+// remain ArangoDB specific and have no GraphStore counterpart. Thus, a backend
+// (e.g. Neo4j or SQLite) implementing GraphStore replaces DAG only for code
+// restricted to the operations above, it doesn't make DAG itself pluggable.
+type GraphStore interface {
+
+	// AddVertex adds the given vertex and returns its id (see DAG.AddVertex).
+	AddVertex(vertex interface{}) (string, error)
+
+	// GetVertex reads the vertex with the given id into vertex (see
+	// DAG.GetVertex).
+	GetVertex(id string, vertex interface{}) error
+
+	// AddEdge adds an edge from the vertex with the id srcID to the vertex
+	// with the id dstID (see DAG.AddEdge).
+	AddEdge(srcID, dstID string) error
+
+	// DeleteEdge deletes the edge from the vertex with the id srcID to the
+	// vertex with the id dstID (see DAG.DeleteEdge).
+	DeleteEdge(srcID, dstID string) error
+
+	// GetOrder returns the number of vertices.
+	GetOrder() (uint64, error)
+
+	// GetSize returns the number of edges.
+	GetSize() (uint64, error)
+
+	// GetShortestPathLength returns the number of edges of the shortest path
+	// from the vertex with the id srcID to the vertex with the id dstID (see
+	// DAG.GetShortestPathLength).
+	GetShortestPathLength(srcID, dstID string) (int, error)
+
+	// WalkDescendantsMulti calls fn for each descendant of any of the vertices
+	// with the given ids (see DAG.WalkDescendantsMulti).
+	WalkDescendantsMulti(ids []string, fn func(id string) error) error
+}
+
+var _ GraphStore = (*DAG)(nil)
@@ -0,0 +1,102 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+	"strings"
+)
+
+// InstantiateTemplate copies the vertices and edges of the template DAG into
+// d, within a single transaction. Each copied vertex gets a fresh (random) key
+// and all occurrences of "{{name}}" within the strings of its payload are
+// replaced by params[name] (for all names of params). Edges are copied with
+// all of their fields. InstantiateTemplate returns a map from the ids of the
+// template's vertices to the ids of their copies.
+func (d *DAG) InstantiateTemplate(template *DAG, params map[string]string) (map[string]string, error) {
+	return d.InstantiateTemplateCtx(context.Background(), template, params)
+}
+
+// InstantiateTemplateCtx is like InstantiateTemplate but uses the given
+// context.
+func (d *DAG) InstantiateTemplateCtx(ctx context.Context, template *DAG, params map[string]string) (map[string]string, error) {
+	var vertices, edges []map[string]interface{}
+	collect := func(docs *[]map[string]interface{}) func(doc json.RawMessage) error {
+		return func(doc json.RawMessage) error {
+			var m map[string]interface{}
+			if err := json.Unmarshal(doc, &m); err != nil {
+				return err
+			}
+			*docs = append(*docs, m)
+			return nil
+		}
+	}
+	err := template.readTransaction(template.context(ctx), func(ctx context.Context) error {
+		if err := template.forEachVertexDocument(ctx, nil)(collect(&vertices)); err != nil {
+			return err
+		}
+		return template.forEachEdgeDocument(ctx, "", false)(collect(&edges))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// fresh keys and substituted payloads
+	replacer := placeholderReplacer(params)
+	keys := make(map[string]string, len(vertices))
+	ids := make(map[string]string, len(vertices))
+	for _, v := range vertices {
+		key, _ := v["_key"].(string)
+		keys[key] = randomKey()
+		ids[template.id(key)] = d.id(keys[key])
+		v["_key"] = keys[key]
+		v["payload"] = substitute(v["payload"], replacer)
+	}
+	for _, e := range edges {
+		for _, attr := range []string{"_from", "_to"} {
+			key, _ := e[attr].(string)
+			e[attr] = string(driver.NewDocumentID(d.vertices.Name(), keys[key]))
+		}
+	}
+
+	err = d.transaction(d.context(ctx), func(ctx context.Context) error {
+		if err := copyDocuments(ctx, d.vertices, mapIterator(vertices), d.afterCopy(ctx, changeVertex)); err != nil {
+			return err
+		}
+		return copyDocuments(ctx, d.edges, mapIterator(edges), d.afterCopy(ctx, changeEdge))
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// placeholderReplacer returns a replacer replacing "{{name}}" by params[name].
+func placeholderReplacer(params map[string]string) *strings.Replacer {
+	pairs := make([]string, 0, 2*len(params))
+	for name, value := range params {
+		pairs = append(pairs, "{{"+name+"}}", value)
+	}
+	return strings.NewReplacer(pairs...)
+}
+
+// substitute returns the given (decoded) JSON value with all of its strings
+// (recursively) replaced by r.
+func substitute(value interface{}, r *strings.Replacer) interface{} {
+	switch v := value.(type) {
+	case string:
+		return r.Replace(v)
+	case map[string]interface{}:
+		for k, x := range v {
+			v[k] = substitute(x, r)
+		}
+		return v
+	case []interface{}:
+		for i, x := range v {
+			v[i] = substitute(x, r)
+		}
+		return v
+	default:
+		return value
+	}
+}
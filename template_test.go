@@ -0,0 +1,62 @@
+package arangodag
+
+import (
+	"strings"
+	"testing"
+)
+
+type stepVertex struct {
+	Name    string   `json:"name"`
+	Command []string `json:"command"`
+}
+
+func TestSubstitute(t *testing.T) {
+	r := placeholderReplacer(map[string]string{"run": "42"})
+	v := substitute(map[string]interface{}{
+		"a": "run-{{run}}",
+		"b": []interface{}{"{{run}}", 1.0, "{{other}}"},
+	}, r).(map[string]interface{})
+	if v["a"] != "run-42" {
+		t.Errorf("got %v, want run-42", v["a"])
+	}
+	if b := v["b"].([]interface{}); b[0] != "42" || b[1] != 1.0 || b[2] != "{{other}}" {
+		t.Errorf("got %v, want [42 1 {{other}}]", b)
+	}
+}
+
+func TestDAG_InstantiateTemplate(t *testing.T) {
+	template := someNewDag(t)
+	build, _ := template.AddVertex(stepVertex{Name: "build", Command: []string{"make", "VERSION={{version}}"}})
+	deploy, _ := template.AddVertex(stepVertex{Name: "deploy {{version}}"})
+	_ = template.AddEdge(build, deploy)
+
+	d := someNewDag(t)
+	ids, err := d.InstantiateTemplate(template, map[string]string{"version": "1.2"})
+	if err != nil {
+		t.Fatalf("failed to InstantiateTemplate(): %v", err)
+	}
+	if len(ids) != 2 || ids[build] == "" || ids[build] == build {
+		t.Fatalf("InstantiateTemplate() = %v, want fresh ids for %s and %s", ids, build, deploy)
+	}
+	var v stepVertex
+	if err := d.GetVertex(ids[build], &v); err != nil {
+		t.Fatalf("failed to GetVertex(): %v", err)
+	}
+	if strings.Join(v.Command, " ") != "make VERSION=1.2" {
+		t.Errorf("got %v, want make VERSION=1.2", v.Command)
+	}
+	if err := d.GetVertex(ids[deploy], &v); err != nil || v.Name != "deploy 1.2" {
+		t.Errorf("got %v (%v), want deploy 1.2", v, err)
+	}
+	if _, err := d.GetEdge(ids[build], ids[deploy]); err != nil {
+		t.Errorf("failed to GetEdge(): %v", err)
+	}
+
+	// instantiating again creates another copy
+	if _, err := d.InstantiateTemplate(template, nil); err != nil {
+		t.Fatalf("failed to InstantiateTemplate(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 4 {
+		t.Errorf("GetOrder() = %d, want 4", order)
+	}
+}
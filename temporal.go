@@ -0,0 +1,192 @@
+package arangodag
+
+import (
+	"context"
+	"time"
+)
+
+// Attributes holding the validity interval of edges (and of vertices, see
+// AsOfTime) and the recording interval of history entries (see AsOf).
+const (
+	ValidFromAttribute    = "validFrom"
+	ValidToAttribute      = "validTo"
+	RecordedFromAttribute = "recordedFrom"
+	RecordedToAttribute   = "recordedTo"
+	HistoryAttribute      = "history"
+)
+
+// Validity is the validity interval [ValidFrom, ValidTo) of an edge. Nil
+// bounds are unbounded. Custom edge types (see WithEdgeType) may embed
+// Validity.
+type Validity struct {
+	ValidFrom *time.Time `json:"validFrom,omitempty"`
+	ValidTo   *time.Time `json:"validTo,omitempty"`
+}
+
+// HistoryEntry records the validity interval of an edge as believed (i.e.
+// recorded) within the interval [RecordedFrom, RecordedTo) (see AsOf).
+type HistoryEntry struct {
+	Validity
+	RecordedFrom *time.Time `json:"recordedFrom,omitempty"`
+	RecordedTo   *time.Time `json:"recordedTo,omitempty"`
+}
+
+// AddEdgeValid adds an edge from the vertex with the id srcID to the vertex
+// with the id dstID (see AddEdge), which is valid from validFrom (inclusive)
+// to validTo (exclusive). Zero times are unbounded. The validity interval is
+// recorded in the edge's history (see AsOf). If an edge type is registered
+// (see WithEdgeType), use AddEdgeWithData instead.
+func (d *DAG) AddEdgeValid(srcID, dstID string, validFrom, validTo time.Time) error {
+	return d.AddEdgeValidCtx(context.Background(), srcID, dstID, validFrom, validTo)
+}
+
+// AddEdgeValidCtx is like AddEdgeValid but uses the given context.
+func (d *DAG) AddEdgeValidCtx(ctx context.Context, srcID, dstID string, validFrom, validTo time.Time) error {
+	v := newValidity(validFrom, validTo)
+	edge := map[string]interface{}{
+		ValidFromAttribute: v.ValidFrom,
+		ValidToAttribute:   v.ValidTo,
+		HistoryAttribute: []HistoryEntry{{
+			Validity:     v,
+			RecordedFrom: timestamp(time.Now()),
+		}},
+	}
+	return d.addEdgeBetween(ctx, srcID, dstID, edge)
+}
+
+// UpdateEdgeValidity changes the validity interval of the edge from the vertex
+// with the id srcID to the vertex with the id dstID (see AddEdgeValid). The
+// previous validity interval is kept in the edge's history, such that AsOf
+// still reflects what was believed before. UpdateEdgeValidity returns an
+// error, if srcID or dstID are empty or unknown, or if there is no such edge.
+func (d *DAG) UpdateEdgeValidity(srcID, dstID string, validFrom, validTo time.Time) error {
+	return d.UpdateEdgeValidityCtx(context.Background(), srcID, dstID, validFrom, validTo)
+}
+
+// UpdateEdgeValidityCtx is like UpdateEdgeValidity but uses the given context.
+func (d *DAG) UpdateEdgeValidityCtx(ctx context.Context, srcID, dstID string, validFrom, validTo time.Time) error {
+	if srcID == "" || dstID == "" {
+		return EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	ids, err := d.vertexDocumentIDs(ctx, []string{srcID, dstID})
+	if err != nil {
+		return err
+	}
+	v := newValidity(validFrom, validTo)
+	query := `
+FOR e IN @@edges
+  FILTER e._from == @src AND e._to == @dst
+  LET history = e.@history == null
+    ? [{[@validFrom]: e.@validFrom, [@validTo]: e.@validTo}]
+    : e.@history
+  LET closed = (
+    FOR h IN history
+      RETURN h.@recordedTo == null ? MERGE(h, {[@recordedTo]: @now}) : h
+  )
+  UPDATE e WITH {
+    [@validFrom]: @from,
+    [@validTo]: @to,
+    [@history]: APPEND(closed, [{[@validFrom]: @from, [@validTo]: @to, [@recordedFrom]: @now}])
+  } IN @@edges OPTIONS {keepNull: false, mergeObjects: false}
+  RETURN NEW._id`
+	bindVars := map[string]interface{}{
+		"@edges":       d.edges.Name(),
+		"src":          ids[0],
+		"dst":          ids[1],
+		"from":         v.ValidFrom,
+		"to":           v.ValidTo,
+		"now":          timestamp(time.Now()),
+		"validFrom":    ValidFromAttribute,
+		"validTo":      ValidToAttribute,
+		"recordedFrom": RecordedFromAttribute,
+		"recordedTo":   RecordedToAttribute,
+		"history":      HistoryAttribute,
+	}
+	return d.mutate(ctx, func(ctx context.Context) error {
+		edgeIDs, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		if len(edgeIDs) == 0 {
+			return UnknownEdgeError(srcID, dstID)
+		}
+		return d.afterWrite(ctx, changeUpsert, changeEdge, edgeIDs...)
+	})
+}
+
+// AsOfTime returns a view (see DAGView) of the graph as of time t, i.e.
+// restricted to the edges valid at t (see Validity) and to the vertices valid
+// at t. Vertices are valid at t, unless their attributes "validFrom" or
+// "validTo" say otherwise. Edges and vertices without validity interval are
+// always valid.
+func (d *DAG) AsOfTime(t time.Time) *DAGView {
+	return d.NewView(d.vertexValidAt(t), &ViewFilter{
+		Expression: within("CURRENT", ValidFromAttribute, ValidToAttribute, "@asOfEdge"),
+		BindVars:   map[string]interface{}{"asOfEdge": milliseconds(t)},
+	})
+}
+
+// AsOf returns a view (see DAGView) of the graph as of the (valid) time
+// validTime as believed at (transaction) time recordedTime. That is, the
+// view is restricted to the edges, whose history (see UpdateEdgeValidity)
+// holds an entry recorded at recordedTime and valid at validTime. Edges
+// without history are treated as recorded forever (see AsOfTime). Vertices
+// have no history and are restricted as by AsOfTime.
+func (d *DAG) AsOf(validTime, recordedTime time.Time) *DAGView {
+	entry := within("CURRENT", ValidFromAttribute, ValidToAttribute, "@bitemporalValid") + " AND " +
+		within("CURRENT", RecordedFromAttribute, RecordedToAttribute, "@bitemporalRecorded")
+	expression := "(CURRENT." + HistoryAttribute + " == null AND " +
+		within("CURRENT", ValidFromAttribute, ValidToAttribute, "@bitemporalValid") + ") OR " +
+		"LENGTH(CURRENT." + HistoryAttribute + "[* FILTER " + entry + "]) > 0"
+	return d.NewView(d.vertexValidAt(validTime), &ViewFilter{
+		Expression: expression,
+		BindVars: map[string]interface{}{
+			"bitemporalValid":    milliseconds(validTime),
+			"bitemporalRecorded": milliseconds(recordedTime),
+		},
+	})
+}
+
+// vertexValidAt returns the filter restricting vertices to those valid at t.
+func (d *DAG) vertexValidAt(t time.Time) *ViewFilter {
+	return &ViewFilter{
+		Expression: within("CURRENT.payload", ValidFromAttribute, ValidToAttribute, "@asOfVertex"),
+		BindVars:   map[string]interface{}{"asOfVertex": milliseconds(t)},
+	}
+}
+
+// within returns an AQL expression being true, if the interval given by the
+// attributes from and to of doc includes the time (in milliseconds) given by
+// the bind variable t. Missing bounds are unbounded.
+func within(doc, from, to, t string) string {
+	from = doc + "." + from
+	to = doc + "." + to
+	return "(" + from + " == null OR DATE_TIMESTAMP(" + from + ") <= " + t + ") AND " +
+		"(" + to + " == null OR " + t + " < DATE_TIMESTAMP(" + to + "))"
+}
+
+// newValidity returns the validity interval with the given bounds (zero times
+// being unbounded).
+func newValidity(validFrom, validTo time.Time) Validity {
+	var v Validity
+	if !validFrom.IsZero() {
+		v.ValidFrom = timestamp(validFrom)
+	}
+	if !validTo.IsZero() {
+		v.ValidTo = timestamp(validTo)
+	}
+	return v
+}
+
+// timestamp returns t in UTC truncated to milliseconds (i.e. as supported by
+// AQL date functions).
+func timestamp(t time.Time) *time.Time {
+	t = t.UTC().Truncate(time.Millisecond)
+	return &t
+}
+
+// milliseconds returns t as milliseconds since the epoch.
+func milliseconds(t time.Time) int64 {
+	return t.UnixNano() / int64(time.Millisecond)
+}
@@ -0,0 +1,78 @@
+package arangodag
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDAG_AsOfTime(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"ceo", "cto", "dev"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	jan := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// the developer reports to the CTO until July and to the CEO afterwards
+	_ = d.AddEdge("ceo", "cto")
+	_ = d.AddEdgeValid("cto", "dev", jan, jul)
+	if err := d.AddEdgeValid("ceo", "dev", jul, time.Time{}); err != nil {
+		t.Fatalf("failed to AddEdgeValid(): %v", err)
+	}
+
+	march := d.AsOfTime(time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC))
+	if length, _ := march.GetShortestPathLength("ceo", "dev"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+	if size, _ := march.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+
+	august := d.AsOfTime(time.Date(2020, 8, 1, 0, 0, 0, 0, time.UTC))
+	if length, _ := august.GetShortestPathLength("ceo", "dev"); length != 1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 1)
+	}
+	if descendants, _ := august.GetDescendants("cto"); len(descendants) != 0 {
+		t.Errorf("GetDescendants() = %v, want none", descendants)
+	}
+
+	before := d.AsOfTime(time.Date(2019, 1, 1, 0, 0, 0, 0, time.UTC))
+	if leaves, _ := before.GetLeaves(); len(leaves) != 2 {
+		t.Errorf("GetLeaves() = %v, want [cto dev]", leaves)
+	}
+}
+
+func TestDAG_AsOf(t *testing.T) {
+	d := someNewDag(t)
+	_, _ = d.AddVertex(idVertex{MyID: "1"})
+	_, _ = d.AddVertex(idVertex{MyID: "2"})
+	jan := time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	mar := time.Date(2020, 3, 1, 0, 0, 0, 0, time.UTC)
+	may := time.Date(2020, 5, 1, 0, 0, 0, 0, time.UTC)
+	jul := time.Date(2020, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	// believed to be valid from January to July
+	_ = d.AddEdgeValid("1", "2", jan, jul)
+	time.Sleep(10 * time.Millisecond)
+	believed := time.Now()
+	time.Sleep(10 * time.Millisecond)
+
+	// corrected to be valid from January to March
+	if err := d.UpdateEdgeValidity("1", "2", jan, mar); err != nil {
+		t.Fatalf("failed to UpdateEdgeValidity(): %v", err)
+	}
+	if size, _ := d.AsOf(may, believed).GetSize(); size != 1 {
+		t.Errorf("GetSize() = %d, want %d", size, 1)
+	}
+	if size, _ := d.AsOf(may, time.Now()).GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+	if size, _ := d.AsOfTime(may).GetSize(); size != 0 {
+		t.Errorf("GetSize() = %d, want %d", size, 0)
+	}
+
+	// unknown
+	if err := d.UpdateEdgeValidity("2", "1", jan, mar); !IsUnknownEdgeError(err) {
+		t.Errorf("want UnknownEdgeError, got %v", err)
+	}
+}
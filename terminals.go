@@ -0,0 +1,117 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+)
+
+// TerminalOptions configures GetRootsWith and GetLeavesWith.
+type TerminalOptions struct {
+
+	// Filter restricts the vertices returned (see ViewFilter, CURRENT
+	// referring to the vertex document, e.g. `CURRENT.payload.active`). Nil
+	// returns all vertices.
+	Filter *ViewFilter
+
+	// SortBy is the attribute to sort by (a dot separated path relative to the
+	// stored document, e.g. "payload.name"). Vertices with equal values (or
+	// all vertices, if SortBy is empty) are sorted by id.
+	SortBy string
+
+	// Descending reverses the order of SortBy.
+	Descending bool
+}
+
+// GetRootsWith returns the ids of the roots (i.e. vertices without parents)
+// matching opts (which may be nil), sorted as configured by opts. Filtering
+// and sorting is done server side.
+func (d *DAG) GetRootsWith(opts *TerminalOptions) ([]string, error) {
+	return d.GetRootsWithCtx(context.Background(), opts)
+}
+
+// GetRootsWithCtx is like GetRootsWith but uses the given context.
+func (d *DAG) GetRootsWithCtx(ctx context.Context, opts *TerminalOptions) ([]string, error) {
+	return d.getTerminals(ctx, "INBOUND", opts)
+}
+
+// GetLeavesWith returns the ids of the leaves (i.e. vertices without children)
+// matching opts (see GetRootsWith).
+func (d *DAG) GetLeavesWith(opts *TerminalOptions) ([]string, error) {
+	return d.GetLeavesWithCtx(context.Background(), opts)
+}
+
+// GetLeavesWithCtx is like GetLeavesWith but uses the given context.
+func (d *DAG) GetLeavesWithCtx(ctx context.Context, opts *TerminalOptions) ([]string, error) {
+	return d.getTerminals(ctx, "OUTBOUND", opts)
+}
+
+// GetIsolatedVertices returns the (sorted) ids of the vertices without any
+// edges (i.e. vertices being both, roots and leaves), e.g. to find vertices
+// that were added but never connected.
+func (d *DAG) GetIsolatedVertices() ([]string, error) {
+	return d.GetIsolatedVerticesCtx(context.Background())
+}
+
+// GetIsolatedVerticesCtx is like GetIsolatedVertices but uses the given
+// context.
+func (d *DAG) GetIsolatedVerticesCtx(ctx context.Context) ([]string, error) {
+	return d.getTerminals(ctx, "ANY", nil)
+}
+
+// getTerminals returns the ids of the vertices without neighbours in the given
+// direction ("OUTBOUND", "INBOUND" or "ANY") matching opts.
+func (d *DAG) getTerminals(ctx context.Context, direction string, opts *TerminalOptions) ([]string, error) {
+	ctx = d.context(ctx)
+	query, bindVars := d.terminalQuery(ctx, direction, opts, "v._key")
+	ids := []string{}
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		ids = append(ids, d.id(key))
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return ids, nil
+}
+
+// terminalQuery returns the query (and its bind variables) returning the given
+// expression for each vertex (as v) without neighbours in the given direction
+// matching opts (which may be nil). Vertices (and neighbours) hidden from the
+// principal carried by ctx (see WithPrincipal) are skipped.
+func (d *DAG) terminalQuery(ctx context.Context, direction string, opts *TerminalOptions, expression string) (string, map[string]interface{}) {
+	if opts == nil {
+		opts = &TerminalOptions{}
+	}
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+	}
+	sort := "v._key"
+	if opts.SortBy != "" {
+		order := "ASC"
+		if opts.Descending {
+			order = "DESC"
+		}
+		sort = "v.@sortBy " + order + ", v._key"
+		bindVars["sortBy"] = attributePath(opts.SortBy)
+	}
+	acl := aclFilter(ctx)
+	addBindVars(bindVars, opts.Filter)
+	addBindVars(bindVars, acl)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + allMatch(opts.Filter, "[v]") + ` AND ` + allMatch(acl, "[v]") + `
+  FILTER LENGTH(
+    FOR n IN 1 ` + direction + ` v @@edges
+      FILTER ` + allMatch(acl, "[n]") + `
+      LIMIT 1
+      RETURN 1
+  ) == 0
+  SORT ` + sort + `
+  RETURN ` + expression
+	return query, bindVars
+}
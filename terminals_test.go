@@ -0,0 +1,69 @@
+package arangodag
+
+import (
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_GetRootsWith(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 4, 2 -> 4, 3 -> 4
+	_, _ = d.AddVertex(foobarKey{MyID: "1", A: "c", B: "active"})
+	_, _ = d.AddVertex(foobarKey{MyID: "2", A: "a", B: "active"})
+	_, _ = d.AddVertex(foobarKey{MyID: "3", A: "b", B: "inactive"})
+	_, _ = d.AddVertex(foobarKey{MyID: "4", A: "d", B: "active"})
+	for _, src := range []string{"1", "2", "3"} {
+		_ = d.AddEdge(src, "4")
+	}
+
+	roots, err := d.GetRootsWith(nil)
+	if err != nil {
+		t.Fatalf("failed to GetRootsWith(): %v", err)
+	}
+	if diff := deep.Equal(roots, []string{"1", "2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	opts := &TerminalOptions{
+		Filter: &ViewFilter{
+			Expression: "CURRENT.payload.B == @state",
+			BindVars:   map[string]interface{}{"state": "active"},
+		},
+		SortBy: "payload.A",
+	}
+	roots, err = d.GetRootsWith(opts)
+	if err != nil {
+		t.Fatalf("failed to GetRootsWith(): %v", err)
+	}
+	if diff := deep.Equal(roots, []string{"2", "1"}); diff != nil {
+		t.Error(diff)
+	}
+
+	leaves, err := d.GetLeavesWith(&TerminalOptions{SortBy: "payload.A", Descending: true})
+	if err != nil {
+		t.Fatalf("failed to GetLeavesWith(): %v", err)
+	}
+	if diff := deep.Equal(leaves, []string{"4"}); diff != nil {
+		t.Error(diff)
+	}
+}
+
+func TestDAG_GetIsolatedVertices(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+
+	isolated, err := d.GetIsolatedVertices()
+	if err != nil {
+		t.Fatalf("failed to GetIsolatedVertices(): %v", err)
+	}
+	if diff := deep.Equal(isolated, []string{"3"}); diff != nil {
+		t.Error(diff)
+	}
+}
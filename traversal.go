@@ -0,0 +1,115 @@
+package arangodag
+
+import (
+	"context"
+	"strings"
+)
+
+// traversalSpec describes a traversal generated by traverse.
+type traversalSpec struct {
+
+	// direction is "OUTBOUND" or "INBOUND" (see DAG.traversal).
+	direction string
+
+	// start and depth are the AQL expressions of the start vertex and of the
+	// depth range (e.g. "@start" and "1..@maxDepth").
+	start string
+	depth string
+
+	// edges is the name of the bind parameter of the edge collection (without
+	// the leading "@@").
+	edges string
+
+	// dfs traverses depth-first rather than breadth-first.
+	dfs bool
+
+	// vertexScope and edgeScope restrict the sub-graph traversed, i.e.
+	// vertices or edges not matching any of them are neither returned nor
+	// traversed beyond.
+	vertexScope []*ViewFilter
+	edgeScope   []*ViewFilter
+
+	// vertexFilters restrict the vertices returned (traversing beyond
+	// vertices not matching them), prune stops the traversal at the vertices
+	// matching any of them (returning these vertices).
+	vertexFilters []*ViewFilter
+	prune         []*ViewFilter
+}
+
+// traverse returns the AQL of the traversal described by t (i.e. the FOR
+// statement binding v, e and p, followed by its PRUNE, OPTIONS and FILTER
+// clauses) with the given indentation of the continuation lines, and adds its
+// bind variables to bindVars. Vertices not visible to the principal carried by
+// ctx (see WithPrincipal) are out of scope.
+//
+// Breadth-first traversals without edge scopes make vertices globally unique,
+// i.e. each vertex is returned once (via a shortest path). As a vertex out of
+// the vertex scope is out of scope via any path, pruning it doesn't hide
+// other vertices. Edge scopes and depth-first traversals need vertices to be
+// unique per path (ArangoDB requires breadth-first traversals for global
+// uniqueness, and a vertex reached first via an edge out of scope may still be
+// reachable via edges in scope), thus, vertices may be returned repeatedly
+// and have to be de-duplicated by the caller (breadth-first, the first
+// occurrence is the one of the shortest path).
+func (d *DAG) traverse(ctx context.Context, t traversalSpec, indent string, bindVars map[string]interface{}) string {
+	direction, edges := d.traversal(t.direction)
+	bindVars["@"+t.edges] = edges
+	vertexScope := append([]*ViewFilter{aclFilter(ctx)}, t.vertexScope...)
+
+	var prune, filter []string
+	edgeScoped := false
+	for _, f := range t.edgeScope {
+		if f != nil {
+			edgeScoped = true
+			prune = append(prune, "(e != null AND NOT ("+allMatch(f, "[e]")+"))")
+			filter = append(filter, "(e == null OR "+allMatch(f, "[e]")+")")
+			addBindVars(bindVars, f)
+		}
+	}
+	for _, f := range vertexScope {
+		if f != nil {
+			prune = append(prune, "NOT ("+allMatch(f, "[v]")+")")
+			filter = append(filter, allMatch(f, "[v]"))
+			addBindVars(bindVars, f)
+		}
+	}
+	for _, f := range t.prune {
+		if f != nil {
+			prune = append(prune, "LENGTH([v][* FILTER ("+f.Expression+")]) > 0")
+			addBindVars(bindVars, f)
+		}
+	}
+	for _, f := range t.vertexFilters {
+		if f != nil {
+			filter = append(filter, allMatch(f, "[v]"))
+			addBindVars(bindVars, f)
+		}
+	}
+
+	var b strings.Builder
+	b.WriteString("FOR v, e, p IN " + t.depth + " " + direction + " " + t.start + " @@" + t.edges)
+	if len(prune) > 0 {
+		b.WriteString("\n" + indent + "PRUNE " + strings.Join(prune, " OR "))
+	}
+	options := `{bfs: true, uniqueVertices: "global"}`
+	if t.dfs {
+		options = `{bfs: false, uniqueVertices: "path"}`
+	} else if edgeScoped {
+		options = `{bfs: true, uniqueVertices: "path"}`
+	}
+	b.WriteString("\n" + indent + "OPTIONS " + options)
+	for _, f := range filter {
+		b.WriteString("\n" + indent + "FILTER " + f)
+	}
+	return b.String()
+}
+
+// addBindVars adds the bind variables of f (which may be nil) to bindVars.
+func addBindVars(bindVars map[string]interface{}, f *ViewFilter) {
+	if f == nil {
+		return
+	}
+	for name, value := range f.BindVars {
+		bindVars[name] = value
+	}
+}
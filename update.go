@@ -0,0 +1,67 @@
+package arangodag
+
+import (
+	"context"
+)
+
+// UpdateDescendants merges patch into the payloads of all descendants of the
+// vertex with the given id matching filter (which may be nil, see ViewFilter,
+// CURRENT referring to the vertex document), e.g. to mark an entire subtree as
+// quarantined:
+//
+//	n, err := d.UpdateDescendants(id, map[string]interface{}{"status": "quarantined"}, nil)
+//
+// Attributes of patch set to nil are removed. The descendants are updated by a
+// single (server side) statement, i.e. atomically, and their number is
+// returned. UpdateDescendants returns an error, if id is empty or unknown, or
+// if the payload of a matching descendant isn't an object.
+func (d *DAG) UpdateDescendants(id string, patch map[string]interface{}, filter *ViewFilter) (int, error) {
+	return d.UpdateDescendantsCtx(context.Background(), id, patch, filter)
+}
+
+// UpdateDescendantsCtx is like UpdateDescendants but uses the given context.
+func (d *DAG) UpdateDescendantsCtx(ctx context.Context, id string, patch map[string]interface{}, filter *ViewFilter) (int, error) {
+	if id == "" {
+		return 0, EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+	query := `
+LET targets = (
+  FOR v IN 1..@maxDepth OUTBOUND @start @@edges
+    OPTIONS {bfs: true, uniqueVertices: "global"}
+    FILTER ` + allMatch(filter, "[v]") + `
+    RETURN v
+)
+FOR v IN targets
+  UPDATE v WITH {payload: MERGE(v.payload, @patch)} IN @@vertices OPTIONS {keepNull: false}
+  RETURN NEW._id`
+	bindVars := map[string]interface{}{
+		"@vertices": d.vertices.Name(),
+		"@edges":    d.edges.Name(),
+		"start":     start,
+		"patch":     patch,
+		"maxDepth":  maxDepth,
+	}
+	if filter != nil {
+		for name, value := range filter.BindVars {
+			bindVars[name] = value
+		}
+	}
+	var count int
+	err = d.mutate(ctx, func(ctx context.Context) error {
+		ids, err := d.queryIDs(ctx, query, bindVars)
+		if err != nil {
+			return err
+		}
+		count = len(ids)
+		return d.afterWrite(ctx, changeUpsert, changeVertex, ids...)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
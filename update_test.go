@@ -0,0 +1,49 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAG_UpdateDescendants(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3, 2 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(foobarKey{MyID: id, A: "a" + id, B: "b" + id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("2", "4")
+
+	filter := &ViewFilter{Expression: "CURRENT.payload.B != @skip", BindVars: map[string]interface{}{"skip": "b4"}}
+	n, err := d.UpdateDescendants("1", map[string]interface{}{"A": "quarantined", "B": nil}, filter)
+	if err != nil {
+		t.Fatalf("failed to UpdateDescendants(): %v", err)
+	}
+	if n != 2 {
+		t.Errorf("UpdateDescendants() = %d, want 2", n)
+	}
+	want := map[string]foobarKey{
+		"1": {MyID: "1", A: "a1", B: "b1"},
+		"2": {MyID: "2", A: "quarantined"},
+		"3": {MyID: "3", A: "quarantined"},
+		"4": {MyID: "4", A: "a4", B: "b4"},
+	}
+	for id, w := range want {
+		var v foobarKey
+		if err := d.GetVertex(id, &v); err != nil {
+			t.Fatalf("failed to GetVertex(): %v", err)
+		}
+		if v != w {
+			t.Errorf("GetVertex(%s) = %+v, want %+v", id, v, w)
+		}
+	}
+
+	// errors
+	if _, err := d.UpdateDescendants("", nil, nil); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+	if _, err := d.UpdateDescendants("foo", nil, nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
@@ -0,0 +1,53 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// WithVertexTypes enables checking the types of the vertices connected by new
+// edges. The type of a vertex is the value of its attribute typeAttr (a dot
+// separated path relative to the stored document, e.g. "payload.type").
+// allowed maps source types to the destination types edges may lead to (e.g.
+// {"build": {"artifact"}, "artifact": {"build"}}). Vertices without type
+// attribute are of the empty type. Adding edges not allowed results in an
+// error (see IsEdgeForbiddenError). Bulk operations (e.g. ImportJSON) don't
+// check types.
+func WithVertexTypes(typeAttr string, allowed map[string][]string) Option {
+	return func(d *DAG) {
+		d.typeAttribute = typeAttr
+		d.allowedEdges = make(map[string]map[string]struct{}, len(allowed))
+		for srcType, dstTypes := range allowed {
+			d.allowedEdges[srcType] = make(map[string]struct{}, len(dstTypes))
+			for _, dstType := range dstTypes {
+				d.allowedEdges[srcType][dstType] = struct{}{}
+			}
+		}
+	}
+}
+
+// checkEdgeTypes returns an error, if the types of src and dst don't allow an
+// edge between them (see WithVertexTypes).
+func (d *DAG) checkEdgeTypes(ctx context.Context, src, dst driver.DocumentID) error {
+	if d.allowedEdges == nil {
+		return nil
+	}
+	query := "RETURN [TO_STRING(DOCUMENT(@src).@attr), TO_STRING(DOCUMENT(@dst).@attr)]"
+	bindVars := map[string]interface{}{
+		"src":  src,
+		"dst":  dst,
+		"attr": attributePath(d.typeAttribute),
+	}
+	var types [2]string
+	err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &types)
+	})
+	if err != nil {
+		return err
+	}
+	if _, ok := d.allowedEdges[types[0]][types[1]]; !ok {
+		return EdgeForbiddenError(types[0], types[1])
+	}
+	return nil
+}
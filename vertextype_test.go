@@ -0,0 +1,36 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestWithVertexTypes(t *testing.T) {
+	d := someNewDag(t, WithVertexTypes("payload.type", map[string][]string{
+		"build":    {"artifact"},
+		"artifact": {"build"},
+	}))
+	_, _ = d.AddVertex(typedVertex{MyID: "b1", Type: "build"})
+	_, _ = d.AddVertex(typedVertex{MyID: "b2", Type: "build"})
+	_, _ = d.AddVertex(typedVertex{MyID: "a1", Type: "artifact"})
+	_, _ = d.AddVertex(idVertex{MyID: "x"})
+
+	if err := d.AddEdge("b1", "a1"); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+	if err := d.AddEdge("a1", "b2"); err != nil {
+		t.Errorf("failed to AddEdge(): %v", err)
+	}
+
+	// forbidden
+	if err := d.AddEdge("b1", "b2"); !IsEdgeForbiddenError(err) {
+		t.Errorf("want EdgeForbiddenError, got %v", err)
+	}
+
+	// untyped
+	if err := d.AddEdge("x", "b1"); !IsEdgeForbiddenError(err) {
+		t.Errorf("want EdgeForbiddenError, got %v", err)
+	}
+	if size, _ := d.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+}
@@ -0,0 +1,374 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// ViewFilter restricts the vertices or edges of a DAGView. Expression is an
+// AQL boolean expression referring to the (stored) document as CURRENT (e.g.
+// `CURRENT.payload.team == @team`). BindVars holds the bind variables used by
+// Expression. Their names must not collide with those of the view's other
+// filter nor with the internal ones (starting with an "@" or one of "id",
+// "src", "dst", "frontier", "principal").
+type ViewFilter struct {
+	Expression string
+	BindVars   map[string]interface{}
+}
+
+// DAGView is a virtual, read-only sub-DAG of a DAG consisting of the vertices
+// and edges matching the view's filters. Edges are part of the view, only if
+// both of their vertices are part of the view. The filters are injected into
+// the generated AQL, i.e. creating a view doesn't copy any data.
+type DAGView struct {
+	dag          *DAG
+	vertexFilter *ViewFilter
+	edgeFilter   *ViewFilter
+}
+
+// NewView returns a view on d restricted to the vertices matching
+// vertexFilter and the edges matching edgeFilter. Nil filters match
+// everything.
+func (d *DAG) NewView(vertexFilter, edgeFilter *ViewFilter) *DAGView {
+	return &DAGView{
+		dag:          d,
+		vertexFilter: vertexFilter,
+		edgeFilter:   edgeFilter,
+	}
+}
+
+// GetVertex returns the vertex with the given id. GetVertex returns an error,
+// if id is empty, unknown or not part of the view.
+func (v *DAGView) GetVertex(id string, vertex interface{}) error {
+	return v.GetVertexCtx(context.Background(), id, vertex)
+}
+
+// GetVertexCtx is like GetVertex but uses the given context.
+func (v *DAGView) GetVertexCtx(ctx context.Context, id string, vertex interface{}) error {
+	ctx = v.dag.context(ctx)
+	if _, err := v.vertexDocumentID(ctx, id); err != nil {
+		return err
+	}
+	return v.dag.GetVertexCtx(ctx, id, vertex)
+}
+
+// GetOrder returns the number of vertices in the view.
+func (v *DAGView) GetOrder() (uint64, error) {
+	return v.GetOrderCtx(context.Background())
+}
+
+// GetOrderCtx is like GetOrder but uses the given context.
+func (v *DAGView) GetOrderCtx(ctx context.Context) (uint64, error) {
+	ctx = v.dag.context(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + v.vertexExpr(ctx, "v") + `
+  COLLECT WITH COUNT INTO count
+  RETURN count`
+	bindVars := map[string]interface{}{
+		"@vertices": v.dag.vertices.Name(),
+	}
+	var count uint64
+	err := v.query(ctx, query, bindVars, func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &count)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetSize returns the number of edges in the view.
+func (v *DAGView) GetSize() (uint64, error) {
+	return v.GetSizeCtx(context.Background())
+}
+
+// GetSizeCtx is like GetSize but uses the given context.
+func (v *DAGView) GetSizeCtx(ctx context.Context) (uint64, error) {
+	ctx = v.dag.context(ctx)
+	query := `
+FOR e IN @@edges
+  FILTER ` + v.edgeExpr("e") + `
+  FILTER ` + v.vertexExpr(ctx, "DOCUMENT(e._from)") + ` AND ` + v.vertexExpr(ctx, "DOCUMENT(e._to)") + `
+  COLLECT WITH COUNT INTO count
+  RETURN count`
+	bindVars := map[string]interface{}{
+		"@edges": v.dag.edges.Name(),
+	}
+	var count uint64
+	err := v.query(ctx, query, bindVars, func(doc json.RawMessage) error {
+		return json.Unmarshal(doc, &count)
+	})
+	if err != nil {
+		return 0, err
+	}
+	return count, nil
+}
+
+// GetRoots returns the ids of all vertices of the view without parents (in
+// the view).
+func (v *DAGView) GetRoots() (map[string]struct{}, error) {
+	return v.GetRootsCtx(context.Background())
+}
+
+// GetRootsCtx is like GetRoots but uses the given context.
+func (v *DAGView) GetRootsCtx(ctx context.Context) (map[string]struct{}, error) {
+	return v.vertexIDsWithoutEdges(ctx, "_to", "_from")
+}
+
+// GetLeaves returns the ids of all vertices of the view without children (in
+// the view).
+func (v *DAGView) GetLeaves() (map[string]struct{}, error) {
+	return v.GetLeavesCtx(context.Background())
+}
+
+// GetLeavesCtx is like GetLeaves but uses the given context.
+func (v *DAGView) GetLeavesCtx(ctx context.Context) (map[string]struct{}, error) {
+	return v.vertexIDsWithoutEdges(ctx, "_from", "_to")
+}
+
+// GetDescendants returns the ids of all descendants (in the view) of the
+// vertex with the given id. GetDescendants returns an error, if id is empty,
+// unknown or not part of the view.
+func (v *DAGView) GetDescendants(id string) (map[string]struct{}, error) {
+	return v.GetDescendantsCtx(context.Background(), id)
+}
+
+// GetDescendantsCtx is like GetDescendants but uses the given context.
+func (v *DAGView) GetDescendantsCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return v.walk(ctx, id, "OUTBOUND")
+}
+
+// GetAncestors returns the ids of all ancestors (in the view) of the vertex
+// with the given id. GetAncestors returns an error, if id is empty, unknown
+// or not part of the view.
+func (v *DAGView) GetAncestors(id string) (map[string]struct{}, error) {
+	return v.GetAncestorsCtx(context.Background(), id)
+}
+
+// GetAncestorsCtx is like GetAncestors but uses the given context.
+func (v *DAGView) GetAncestorsCtx(ctx context.Context, id string) (map[string]struct{}, error) {
+	return v.walk(ctx, id, "INBOUND")
+}
+
+// GetShortestPathLength returns the number of edges of the shortest path (in
+// the view) from the vertex with the id srcID to the vertex with the id dstID.
+// GetShortestPathLength returns 0, if srcID and dstID are equal, and -1, if
+// there is no such path. GetShortestPathLength returns an error, if srcID or
+// dstID are empty, unknown or not part of the view.
+func (v *DAGView) GetShortestPathLength(srcID, dstID string) (int, error) {
+	return v.GetShortestPathLengthCtx(context.Background(), srcID, dstID)
+}
+
+// GetShortestPathLengthCtx is like GetShortestPathLength but uses the given
+// context.
+func (v *DAGView) GetShortestPathLengthCtx(ctx context.Context, srcID, dstID string) (int, error) {
+	ctx = v.dag.context(ctx)
+	src, err := v.vertexDocumentID(ctx, srcID)
+	if err != nil {
+		return 0, err
+	}
+	dst, err := v.vertexDocumentID(ctx, dstID)
+	if err != nil {
+		return 0, err
+	}
+	if src == dst {
+		return 0, nil
+	}
+
+	// level by level, the depth dst is reached at is its distance from src
+	length := -1
+	err = v.walkLevels(ctx, src, "OUTBOUND", func(depth int, keys []string) bool {
+		for _, key := range keys {
+			if key == dst.Key() {
+				length = depth
+				return false
+			}
+		}
+		return true
+	})
+	if err != nil {
+		return 0, err
+	}
+	return length, nil
+}
+
+// vertexIDsWithoutEdges returns the ids of all vertices of the view not being
+// the "to" end of any edge of the view (where "to" is either "_from" or "_to"
+// and "from" is the opposite).
+func (v *DAGView) vertexIDsWithoutEdges(ctx context.Context, to, from string) (map[string]struct{}, error) {
+	ctx = v.dag.context(ctx)
+	query := `
+FOR v IN @@vertices
+  FILTER ` + v.vertexExpr(ctx, "v") + `
+  FILTER LENGTH(
+    FOR e IN @@edges
+      FILTER e.` + to + ` == v._id AND ` + v.edgeExpr("e") + `
+      FILTER ` + v.vertexExpr(ctx, "DOCUMENT(e."+from+")") + `
+      LIMIT 1
+      RETURN 1
+  ) == 0
+  RETURN v._key`
+	bindVars := map[string]interface{}{
+		"@vertices": v.dag.vertices.Name(),
+		"@edges":    v.dag.edges.Name(),
+	}
+	return v.queryKeys(ctx, query, bindVars)
+}
+
+// walk returns the ids of all vertices (in the view) reachable from the vertex
+// with the given id in the given direction ("OUTBOUND" or "INBOUND"). The
+// traversal runs level by level (see walkLevels), such that vertices reached
+// via edges or vertices outside of the view may still be reached via paths
+// within the view.
+func (v *DAGView) walk(ctx context.Context, id string, direction string) (map[string]struct{}, error) {
+	ctx = v.dag.context(ctx)
+	start, err := v.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	visited := make(map[string]struct{})
+	err = v.walkLevels(ctx, start, direction, func(_ int, keys []string) bool {
+		for _, key := range keys {
+			visited[v.dag.id(key)] = struct{}{}
+		}
+		return true
+	})
+	if err != nil {
+		return nil, err
+	}
+	return visited, nil
+}
+
+// walkLevels walks the view from start in the given direction ("OUTBOUND" or
+// "INBOUND") level by level, calling fn with the depth and the keys of the
+// vertices first reached at that depth (1 for the neighbours of start). Each
+// level is traversed by a single query following only edges and vertices of
+// the view, thus, a vertex is reached at the length of its shortest path
+// within the view. Walking stops, if fn returns false.
+func (v *DAGView) walkLevels(ctx context.Context, start driver.DocumentID, direction string, fn func(depth int, keys []string) bool) error {
+	direction, edges := v.dag.traversal(direction)
+	query := `
+FOR id IN @frontier
+  FOR v, e IN 1..1 ` + direction + ` id @@edges
+    FILTER ` + v.edgeExpr("e") + ` AND ` + v.vertexExpr(ctx, "v") + `
+    RETURN DISTINCT v._key`
+	visited := map[string]struct{}{start.Key(): {}}
+	frontier := []driver.DocumentID{start}
+	for depth := 1; len(frontier) > 0; depth++ {
+		bindVars := map[string]interface{}{
+			"@edges":   edges,
+			"frontier": frontier,
+		}
+		var keys []string
+		err := v.query(ctx, query, bindVars, func(doc json.RawMessage) error {
+			var key string
+			if err := json.Unmarshal(doc, &key); err != nil {
+				return err
+			}
+			if _, ok := visited[key]; !ok {
+				visited[key] = struct{}{}
+				keys = append(keys, key)
+			}
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+		if len(keys) == 0 || !fn(depth, keys) {
+			return nil
+		}
+		frontier = make([]driver.DocumentID, len(keys))
+		for i, key := range keys {
+			frontier[i] = driver.NewDocumentID(v.dag.vertices.Name(), key)
+		}
+	}
+	return nil
+}
+
+// vertexDocumentID returns the document id of the vertex with the given id.
+// vertexDocumentID returns an error, if id is empty, unknown or not part of
+// the view.
+func (v *DAGView) vertexDocumentID(ctx context.Context, id string) (driver.DocumentID, error) {
+	if id == "" {
+		return "", EmptyIDError()
+	}
+	query := `
+LET v = DOCUMENT(@@vertices, @id)
+FILTER v != null AND ` + v.vertexExpr(ctx, "v") + `
+RETURN 1`
+	bindVars := map[string]interface{}{
+		"@vertices": v.dag.vertices.Name(),
+		"id":        v.dag.key(id),
+	}
+	found := false
+	err := v.query(ctx, query, bindVars, func(json.RawMessage) error {
+		found = true
+		return nil
+	})
+	if err != nil {
+		return "", err
+	}
+	if !found {
+		return "", NewUnknownKeyError(id)
+	}
+	return driver.NewDocumentID(v.dag.vertices.Name(), v.dag.key(id)), nil
+}
+
+// queryKeys returns the (string) results of the given query, i.e. vertex keys,
+// as set of ids (see WithKeyEncoding).
+func (v *DAGView) queryKeys(ctx context.Context, query string, bindVars map[string]interface{}) (map[string]struct{}, error) {
+	keys := make(map[string]struct{})
+	err := v.query(ctx, query, bindVars, func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		keys[v.dag.id(key)] = struct{}{}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return keys, nil
+}
+
+// query runs the given query (adding the bind variables of the view's
+// filters and of the ACL filter, see vertexExpr) and calls fn for each result.
+func (v *DAGView) query(ctx context.Context, query string, bindVars map[string]interface{}, fn func(doc json.RawMessage) error) error {
+	vars := make(map[string]interface{}, len(bindVars))
+	for _, f := range []*ViewFilter{v.vertexFilter, v.edgeFilter, aclFilter(ctx)} {
+		if f != nil {
+			for name, value := range f.BindVars {
+				vars[name] = value
+			}
+		}
+	}
+	for name, value := range bindVars {
+		vars[name] = value
+	}
+	return v.dag.forEachDocument(ctx, query, vars)(fn)
+}
+
+// vertexExpr returns an AQL expression being true, if the vertex doc matches
+// the view's vertex filter and is visible to the principal carried by ctx (see
+// WithPrincipal).
+func (v *DAGView) vertexExpr(ctx context.Context, doc string) string {
+	return allMatch(v.vertexFilter, "["+doc+"]") + " AND " + allMatch(aclFilter(ctx), "["+doc+"]")
+}
+
+// edgeExpr returns an AQL expression being true, if the edge doc matches the
+// view's edge filter.
+func (v *DAGView) edgeExpr(doc string) string {
+	return allMatch(v.edgeFilter, "["+doc+"]")
+}
+
+// allMatch returns an AQL expression being true, if all elements of the given
+// array match the given filter.
+func allMatch(f *ViewFilter, array string) string {
+	if f == nil {
+		return "true"
+	}
+	return "LENGTH(" + array + "[* FILTER NOT (" + f.Expression + ")]) == 0"
+}
@@ -0,0 +1,88 @@
+package arangodag
+
+import (
+	"testing"
+)
+
+func TestDAGView(t *testing.T) {
+	d := someNewDag(t)
+
+	// b1 -> b2 -> b3, b1 -> a1 -> b3
+	for _, id := range []string{"b1", "b2", "b3"} {
+		_, _ = d.AddVertex(typedVertex{MyID: id, Type: "build"})
+	}
+	_, _ = d.AddVertex(typedVertex{MyID: "a1", Type: "artifact"})
+	_ = d.AddEdge("b1", "b2")
+	_ = d.AddEdge("b2", "b3")
+	_ = d.AddEdge("b1", "a1")
+	_ = d.AddEdge("a1", "b3")
+
+	v := d.NewView(&ViewFilter{
+		Expression: "CURRENT.payload.type == @type",
+		BindVars:   map[string]interface{}{"type": "build"},
+	}, nil)
+
+	if order, _ := v.GetOrder(); order != 3 {
+		t.Errorf("GetOrder() = %d, want %d", order, 3)
+	}
+	if size, _ := v.GetSize(); size != 2 {
+		t.Errorf("GetSize() = %d, want %d", size, 2)
+	}
+	var vertex typedVertex
+	if err := v.GetVertex("a1", &vertex); !IsUnknownIDError(err) {
+		t.Errorf("GetVertex() = %v, want unknown id error", err)
+	}
+	if roots, _ := v.GetRoots(); len(roots) != 1 {
+		t.Errorf("GetRoots() = %v, want [b1]", roots)
+	}
+	if leaves, _ := v.GetLeaves(); len(leaves) != 1 {
+		t.Errorf("GetLeaves() = %v, want [b3]", leaves)
+	}
+	if descendants, _ := v.GetDescendants("b1"); len(descendants) != 2 {
+		t.Errorf("GetDescendants() = %v, want [b2 b3]", descendants)
+	}
+	if ancestors, _ := v.GetAncestors("b3"); len(ancestors) != 2 {
+		t.Errorf("GetAncestors() = %v, want [b1 b2]", ancestors)
+	}
+
+	if length, _ := v.GetShortestPathLength("b1", "b3"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+
+	// b3 is now reachable via a1 only (which is not part of the view)
+	_ = d.DeleteEdge("b2", "b3")
+	if length, _ := v.GetShortestPathLength("b1", "b3"); length != -1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, -1)
+	}
+}
+
+func TestDAGView_edgeFilter(t *testing.T) {
+	d := someNewDag(t, WithEdgeType(labeledEdge{}))
+
+	// a -(build)-> b, a -(runtime)-> c -(runtime)-> b
+	for _, id := range []string{"a", "b", "c"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdgeWithData("a", "b", labeledEdge{Label: "build"})
+	_ = d.AddEdgeWithData("a", "c", labeledEdge{Label: "runtime"})
+	_ = d.AddEdgeWithData("c", "b", labeledEdge{Label: "runtime"})
+
+	v := d.NewView(nil, &ViewFilter{
+		Expression: "CURRENT.label == @label",
+		BindVars:   map[string]interface{}{"label": "runtime"},
+	})
+
+	// b is reached via c, although the direct edge is not part of the view
+	if descendants, _ := v.GetDescendants("a"); len(descendants) != 2 {
+		t.Errorf("GetDescendants() = %v, want [b c]", descendants)
+	}
+	if ancestors, _ := v.GetAncestors("b"); len(ancestors) != 2 {
+		t.Errorf("GetAncestors() = %v, want [a c]", ancestors)
+	}
+	if length, _ := v.GetShortestPathLength("a", "b"); length != 2 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, 2)
+	}
+	if length, _ := v.GetShortestPathLength("b", "a"); length != -1 {
+		t.Errorf("GetShortestPathLength() = %d, want %d", length, -1)
+	}
+}
@@ -0,0 +1,235 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strconv"
+)
+
+// viewerPageSize is the default number of ids per page served by the viewer.
+const viewerPageSize = 50
+
+// ViewerHandler returns an HTTP handler serving an interactive viewer of the
+// graph (a force-directed layout, expanding vertices on click). The handler
+// serves the viewer at "/" and the paged neighbourhood queries it is backed by
+// at "/api/vertices", "/api/children" and "/api/parents" (with the query
+// parameters "id", "offset" and "limit"). To mount the viewer below some
+// path, strip the path prefix, e.g.:
+//
+//	http.Handle("/dag/", http.StripPrefix("/dag", d.ViewerHandler()))
+func (d *DAG) ViewerHandler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = io.WriteString(w, viewerHTML)
+	})
+	mux.HandleFunc("/api/vertices", d.viewerPage(func(ctx context.Context, _ string, offset, limit int) (Page, error) {
+		return d.GetVerticesPageCtx(ctx, offset, limit)
+	}))
+	mux.HandleFunc("/api/children", d.viewerPage(d.GetChildrenPageCtx))
+	mux.HandleFunc("/api/parents", d.viewerPage(d.GetParentsPageCtx))
+	return mux
+}
+
+// viewerPage returns an HTTP handler func serving the pages returned by fn as
+// JSON.
+func (d *DAG) viewerPage(fn func(ctx context.Context, id string, offset, limit int) (Page, error)) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		q := r.URL.Query()
+		offset, err := viewerParam(q.Get("offset"), 0)
+		if err != nil {
+			http.Error(w, "invalid offset", http.StatusBadRequest)
+			return
+		}
+		limit, err := viewerParam(q.Get("limit"), viewerPageSize)
+		if err != nil {
+			http.Error(w, "invalid limit", http.StatusBadRequest)
+			return
+		}
+		page, err := fn(r.Context(), q.Get("id"), offset, limit)
+		switch {
+		case IsEmptyIDError(err):
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		case IsUnknownIDError(err):
+			http.Error(w, err.Error(), http.StatusNotFound)
+			return
+		case err != nil:
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(struct {
+			IDs   []string `json:"ids"`
+			Total int64    `json:"total"`
+		}{page.IDs, page.Total})
+	}
+}
+
+// viewerParam parses the given (non-negative) query parameter, returning def
+// for empty parameters.
+func viewerParam(s string, def int) (int, error) {
+	if s == "" {
+		return def, nil
+	}
+	i, err := strconv.Atoi(s)
+	if err != nil || i < 0 {
+		return 0, strconv.ErrSyntax
+	}
+	return i, nil
+}
+
+// viewerHTML is the (self-contained) viewer served by ViewerHandler.
+const viewerHTML = `<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>DAG Viewer</title>
+<style>
+  body { margin: 0; font-family: sans-serif; }
+  #bar { position: absolute; top: 8px; left: 8px; background: rgba(255,255,255,.9); padding: 4px 8px; }
+  canvas { display: block; }
+</style>
+</head>
+<body>
+<div id="bar">
+  <span id="status"></span>
+  <button id="more">more vertices</button>
+</div>
+<canvas id="canvas"></canvas>
+<script>
+(function () {
+  var canvas = document.getElementById("canvas");
+  var ctx = canvas.getContext("2d");
+  var nodes = {}, edges = {}, offset = 0, total = 0;
+
+  function resize() {
+    canvas.width = window.innerWidth;
+    canvas.height = window.innerHeight;
+  }
+  window.addEventListener("resize", resize);
+  resize();
+
+  function node(id, near) {
+    if (!nodes[id]) {
+      var x = near ? near.x : canvas.width / 2, y = near ? near.y : canvas.height / 2;
+      nodes[id] = {id: id, x: x + Math.random() * 60 - 30, y: y + Math.random() * 60 - 30, vx: 0, vy: 0, expanded: false};
+    }
+    return nodes[id];
+  }
+
+  function edge(src, dst) {
+    edges[src + "\u0000" + dst] = {src: src, dst: dst};
+  }
+
+  function get(path, params) {
+    var q = Object.keys(params).map(function (k) {
+      return encodeURIComponent(k) + "=" + encodeURIComponent(params[k]);
+    }).join("&");
+    return fetch("api/" + path + "?" + q).then(function (r) {
+      if (!r.ok) { throw new Error(r.statusText); }
+      return r.json();
+    });
+  }
+
+  function status() {
+    document.getElementById("status").textContent =
+      Object.keys(nodes).length + " of " + total + " vertices shown";
+  }
+
+  function loadVertices() {
+    get("vertices", {offset: offset}).then(function (page) {
+      total = page.total;
+      offset += page.ids.length;
+      page.ids.forEach(function (id) { node(id); });
+      status();
+    });
+  }
+
+  function expand(n) {
+    n.expanded = true;
+    get("children", {id: n.id}).then(function (page) {
+      page.ids.forEach(function (id) { node(id, n); edge(n.id, id); });
+      status();
+    });
+    get("parents", {id: n.id}).then(function (page) {
+      page.ids.forEach(function (id) { node(id, n); edge(id, n.id); });
+      status();
+    });
+  }
+
+  function step() {
+    var list = Object.keys(nodes).map(function (k) { return nodes[k]; });
+    list.forEach(function (a) {
+      list.forEach(function (b) {
+        if (a === b) { return; }
+        var dx = a.x - b.x, dy = a.y - b.y, d2 = dx * dx + dy * dy + 0.01;
+        a.vx += dx / d2 * 200;
+        a.vy += dy / d2 * 200;
+      });
+      a.vx += (canvas.width / 2 - a.x) * 0.001;
+      a.vy += (canvas.height / 2 - a.y) * 0.001;
+    });
+    Object.keys(edges).forEach(function (k) {
+      var a = nodes[edges[k].src], b = nodes[edges[k].dst];
+      var dx = b.x - a.x, dy = b.y - a.y;
+      a.vx += dx * 0.01; a.vy += dy * 0.01;
+      b.vx -= dx * 0.01; b.vy -= dy * 0.01;
+    });
+    list.forEach(function (n) {
+      n.vx *= 0.8; n.vy *= 0.8;
+      n.x += n.vx; n.y += n.vy;
+    });
+  }
+
+  function draw() {
+    ctx.clearRect(0, 0, canvas.width, canvas.height);
+    ctx.strokeStyle = "#999";
+    Object.keys(edges).forEach(function (k) {
+      var a = nodes[edges[k].src], b = nodes[edges[k].dst];
+      var angle = Math.atan2(b.y - a.y, b.x - a.x);
+      var x = b.x - Math.cos(angle) * 8, y = b.y - Math.sin(angle) * 8;
+      ctx.beginPath();
+      ctx.moveTo(a.x, a.y);
+      ctx.lineTo(x, y);
+      ctx.lineTo(x - Math.cos(angle - 0.4) * 8, y - Math.sin(angle - 0.4) * 8);
+      ctx.moveTo(x, y);
+      ctx.lineTo(x - Math.cos(angle + 0.4) * 8, y - Math.sin(angle + 0.4) * 8);
+      ctx.stroke();
+    });
+    Object.keys(nodes).forEach(function (k) {
+      var n = nodes[k];
+      ctx.fillStyle = n.expanded ? "#369" : "#9bd";
+      ctx.beginPath();
+      ctx.arc(n.x, n.y, 6, 0, 2 * Math.PI);
+      ctx.fill();
+      ctx.fillStyle = "#000";
+      ctx.fillText(n.id, n.x + 8, n.y + 4);
+    });
+  }
+
+  canvas.addEventListener("click", function (e) {
+    Object.keys(nodes).forEach(function (k) {
+      var n = nodes[k], dx = n.x - e.offsetX, dy = n.y - e.offsetY;
+      if (!n.expanded && dx * dx + dy * dy < 64) { expand(n); }
+    });
+  });
+  document.getElementById("more").addEventListener("click", loadVertices);
+
+  (function loop() {
+    step();
+    draw();
+    window.requestAnimationFrame(loop);
+  })();
+  loadVertices();
+})();
+</script>
+</body>
+</html>
+`
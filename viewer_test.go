@@ -0,0 +1,74 @@
+package arangodag
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_ViewerHandler(t *testing.T) {
+	d := someNewDag(t)
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+
+	server := httptest.NewServer(d.ViewerHandler())
+	defer server.Close()
+
+	// viewer
+	resp, err := http.Get(server.URL + "/")
+	if err != nil {
+		t.Fatalf("failed to get viewer: %v", err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusOK || !strings.HasPrefix(resp.Header.Get("Content-Type"), "text/html") {
+		t.Errorf("got %d (%s), want 200 (text/html)", resp.StatusCode, resp.Header.Get("Content-Type"))
+	}
+
+	// pages
+	for path, want := range map[string][]string{
+		"/api/vertices?offset=1":     {"2", "3"},
+		"/api/children?id=1&limit=1": {"2"},
+		"/api/parents?id=3":          {"1"},
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", path, err)
+		}
+		var page struct {
+			IDs []string `json:"ids"`
+		}
+		err = json.NewDecoder(resp.Body).Decode(&page)
+		_ = resp.Body.Close()
+		if err != nil {
+			t.Fatalf("failed to decode %s: %v", path, err)
+		}
+		if diff := deep.Equal(page.IDs, want); diff != nil {
+			t.Errorf("%s: %v", path, diff)
+		}
+	}
+
+	// errors
+	for path, want := range map[string]int{
+		"/api/children?id=foo":     http.StatusNotFound,
+		"/api/children":            http.StatusBadRequest,
+		"/api/vertices?limit=-1":   http.StatusBadRequest,
+		"/api/vertices?offset=foo": http.StatusBadRequest,
+		"/foo":                     http.StatusNotFound,
+	} {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatalf("failed to get %s: %v", path, err)
+		}
+		_ = resp.Body.Close()
+		if resp.StatusCode != want {
+			t.Errorf("%s: got %d, want %d", path, resp.StatusCode, want)
+		}
+	}
+}
@@ -0,0 +1,440 @@
+package arangodag
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/arangodb/go-driver"
+)
+
+// WalkDescendantsMulti calls fn for each descendant of any of the vertices with
+// the given ids. All descendants are collected by a single query and each
+// descendant is visited exactly once (even if reachable from multiple of the
+// given vertices). Vertices are visited in breadth-first order per start
+// vertex. Walking stops at the first error returned by fn, which is returned by
+// WalkDescendantsMulti. WalkDescendantsMulti returns an error, if any of the
+// ids is empty or unknown.
+func (d *DAG) WalkDescendantsMulti(ids []string, fn func(id string) error) error {
+	return d.WalkDescendantsMultiCtx(context.Background(), ids, fn)
+}
+
+// WalkDescendantsMultiCtx is like WalkDescendantsMulti but uses the given
+// context.
+func (d *DAG) WalkDescendantsMultiCtx(ctx context.Context, ids []string, fn func(id string) error) error {
+	ctx = d.context(ctx)
+	starts, err := d.vertexDocumentIDs(ctx, ids)
+	if err != nil {
+		return err
+	}
+	bindVars := map[string]interface{}{
+		"starts":   starts,
+		"maxDepth": maxDepth,
+	}
+	query := `
+FOR start IN @starts
+  ` + d.traverse(ctx, traversalSpec{
+		direction: "OUTBOUND",
+		start:     "start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "    ", bindVars) + `
+    RETURN DISTINCT v._key`
+	return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var id string
+		if err := json.Unmarshal(doc, &id); err != nil {
+			return err
+		}
+		return fn(d.id(id))
+	})
+}
+
+// WalkAncestorsWithDepth calls fn for each ancestor of the vertex with the
+// given id together with its depth (i.e. 1 for parents) and the path from the
+// given vertex to the ancestor (i.e. the ids of the vertices in between, both
+// ends included). Ancestors are visited in breadth-first order and each
+// ancestor is visited exactly once (via a shortest path). Walking stops at the
+// first error returned by fn, which is returned by WalkAncestorsWithDepth.
+// WalkAncestorsWithDepth returns an error, if id is empty or unknown.
+func (d *DAG) WalkAncestorsWithDepth(id string, fn func(id string, depth int, path []string) error) error {
+	return d.WalkAncestorsWithDepthCtx(context.Background(), id, fn)
+}
+
+// WalkAncestorsWithDepthCtx is like WalkAncestorsWithDepth but uses the given
+// context.
+func (d *DAG) WalkAncestorsWithDepthCtx(ctx context.Context, id string, fn func(id string, depth int, path []string) error) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: "INBOUND",
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars) + `
+  RETURN {id: v._key, path: p.vertices[*]._key}`
+	return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID   string   `json:"id"`
+			Path []string `json:"path"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		for i, key := range item.Path {
+			item.Path[i] = d.id(key)
+		}
+		return fn(d.id(item.ID), len(item.Path)-1, item.Path)
+	})
+}
+
+// WalkFunc is the type of the function called by WalkAncestors for each
+// visited vertex. If the vertex couldn't be read (e.g. because an edge refers
+// to a vertex that doesn't exist anymore) or its payload couldn't be decoded,
+// err holds the respective error. Returning nil skips such vertices, returning
+// an error (e.g. err itself) stops walking.
+type WalkFunc func(id string, err error) error
+
+// WalkAncestors calls fn for each ancestor of the vertex with the given id in
+// breadth-first order, each ancestor being visited exactly once. If vertex is
+// not nil, the payload of each ancestor is decoded into vertex (which must be
+// a pointer, see GetVertex) before calling fn. Walking stops at the first error
+// returned by fn, which is returned by WalkAncestors. WalkAncestors returns an
+// error, if id is empty or unknown, or if the query fails.
+func (d *DAG) WalkAncestors(id string, vertex interface{}, fn WalkFunc) error {
+	return d.WalkAncestorsCtx(context.Background(), id, vertex, fn)
+}
+
+// WalkAncestorsCtx is like WalkAncestors but uses the given context.
+func (d *DAG) WalkAncestorsCtx(ctx context.Context, id string, vertex interface{}, fn WalkFunc) error {
+	return d.walk(ctx, id, "INBOUND", false, vertex, fn)
+}
+
+// WalkDescendants calls fn for each descendant of the vertex with the given id
+// in breadth-first order (or depth-first order, if dfs is true), each
+// descendant being visited exactly once (see WalkAncestors).
+func (d *DAG) WalkDescendants(id string, vertex interface{}, fn WalkFunc, dfs bool) error {
+	return d.WalkDescendantsCtx(context.Background(), id, vertex, fn, dfs)
+}
+
+// WalkDescendantsCtx is like WalkDescendants but uses the given context.
+func (d *DAG) WalkDescendantsCtx(ctx context.Context, id string, vertex interface{}, fn WalkFunc, dfs bool) error {
+	return d.walk(ctx, id, "OUTBOUND", dfs, vertex, fn)
+}
+
+// walk calls fn for each vertex reachable from the vertex with the given id in
+// the given direction ("OUTBOUND" or "INBOUND", see WalkAncestors).
+func (d *DAG) walk(ctx context.Context, id, direction string, dfs bool, vertex interface{}, fn WalkFunc) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	return d.walkFrom(ctx, start, direction, dfs, vertex, fn)
+}
+
+// walkFrom is like walk but starts at the vertex with the given document id
+// (without checking whether it exists) and expects ctx to be prepared (see
+// DAG.context).
+func (d *DAG) walkFrom(ctx context.Context, start driver.DocumentID, direction string, dfs bool, vertex interface{}, fn WalkFunc) error {
+	end := "_to"
+	if traversed, _ := d.traversal(direction); traversed == "INBOUND" {
+		end = "_from"
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: direction,
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+		dfs:       dfs,
+	}, "  ", bindVars) + `
+  RETURN {id: PARSE_IDENTIFIER(e.` + end + `).key, exists: v != null, payload: v.payload}`
+
+	// edges referring to missing vertices may yield the same id repeatedly
+	seen := make(map[string]struct{})
+	return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var item struct {
+			ID      string          `json:"id"`
+			Exists  bool            `json:"exists"`
+			Payload json.RawMessage `json:"payload"`
+		}
+		if err := json.Unmarshal(doc, &item); err != nil {
+			return err
+		}
+		if _, ok := seen[item.ID]; ok {
+			return nil
+		}
+		seen[item.ID] = struct{}{}
+		id := d.id(item.ID)
+		if !item.Exists {
+			return fn(id, NewUnknownKeyError(id))
+		}
+		if vertex != nil {
+			if err := json.Unmarshal(item.Payload, vertex); err != nil {
+				return fn(id, err)
+			}
+		}
+		return fn(id, nil)
+	})
+}
+
+// WalkerOptions configures AncestorsWalker and DescendantsWalker.
+type WalkerOptions struct {
+
+	// BatchSize is the number of vertices fetched per round trip. Zero refers
+	// to the server's default.
+	BatchSize int
+
+	// DFS walks depth-first rather than breadth-first.
+	DFS bool
+}
+
+// AncestorsWalker returns an iterator over the ancestors of the vertex with
+// the given id (see WalkAncestors) streaming them from a server side cursor,
+// as configured by opts (which may be nil). Cancelling ctx stops the iterator
+// (see QueryIterator.Err). Callers stopping early must close the iterator
+// (which releases the cursor):
+//
+//	it, err := d.AncestorsWalker(ctx, id, nil)
+//	if err != nil {
+//		return err
+//	}
+//	defer it.Close()
+//	for it.Next() {
+//		...
+//	}
+//	return it.Err()
+//
+// AncestorsWalker returns an error, if id is empty or unknown.
+func (d *DAG) AncestorsWalker(ctx context.Context, id string, opts *WalkerOptions) (*QueryIterator, error) {
+	return d.walker(ctx, id, "INBOUND", opts)
+}
+
+// DescendantsWalker returns an iterator over the descendants of the vertex
+// with the given id (see AncestorsWalker).
+func (d *DAG) DescendantsWalker(ctx context.Context, id string, opts *WalkerOptions) (*QueryIterator, error) {
+	return d.walker(ctx, id, "OUTBOUND", opts)
+}
+
+// walker returns an iterator over the vertices reachable from the vertex with
+// the given id in the given direction ("OUTBOUND" or "INBOUND").
+func (d *DAG) walker(ctx context.Context, id, direction string, opts *WalkerOptions) (*QueryIterator, error) {
+	ctx = d.context(ctx)
+	if id == "" {
+		return nil, EmptyIDError()
+	}
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	return d.walkerFrom(ctx, start, direction, opts)
+}
+
+// walkerFrom is like walker but starts at the vertex with the given document
+// id (without checking whether it exists) and expects ctx to be prepared (see
+// DAG.context).
+func (d *DAG) walkerFrom(ctx context.Context, start driver.DocumentID, direction string, opts *WalkerOptions) (*QueryIterator, error) {
+	if opts == nil {
+		opts = &WalkerOptions{}
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	query := `
+` + d.traverse(ctx, traversalSpec{
+		direction: direction,
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+		dfs:       opts.DFS,
+	}, "  ", bindVars) + `
+  RETURN {
+    id: v._key,
+    depth: LENGTH(p.edges),
+    payload: v.payload,
+    edge: UNSET(e, "_id", "_key", "_rev", "_from", "_to")
+  }`
+	qctx := driver.WithQueryStream(ctx)
+	if opts.BatchSize > 0 {
+		qctx = driver.WithQueryBatchSize(qctx, opts.BatchSize)
+	}
+	cursor, err := d.db.Query(qctx, query, bindVars)
+	if err != nil {
+		return nil, arangoError(err)
+	}
+	return &QueryIterator{d: d, ctx: ctx, cursor: cursor, seen: make(map[string]struct{})}, nil
+}
+
+// WalkLeavesOf calls fn for each leaf among the descendants of the vertex with
+// the given id (i.e. the descendants without children). Leaves are filtered
+// server side and visited in breadth-first order. Walking stops at the first
+// error returned by fn, which is returned by WalkLeavesOf. WalkLeavesOf returns
+// an error, if id is empty or unknown.
+func (d *DAG) WalkLeavesOf(id string, fn func(id string) error) error {
+	return d.WalkLeavesOfCtx(context.Background(), id, fn)
+}
+
+// WalkLeavesOfCtx is like WalkLeavesOf but uses the given context.
+func (d *DAG) WalkLeavesOfCtx(ctx context.Context, id string, fn func(id string) error) error {
+	return d.walkTerminals(ctx, id, "OUTBOUND", fn)
+}
+
+// WalkRootsOf calls fn for each root among the ancestors of the vertex with the
+// given id (i.e. the ancestors without parents). See WalkLeavesOf.
+func (d *DAG) WalkRootsOf(id string, fn func(id string) error) error {
+	return d.WalkRootsOfCtx(context.Background(), id, fn)
+}
+
+// WalkRootsOfCtx is like WalkRootsOf but uses the given context.
+func (d *DAG) WalkRootsOfCtx(ctx context.Context, id string, fn func(id string) error) error {
+	return d.walkTerminals(ctx, id, "INBOUND", fn)
+}
+
+// walkTerminals calls fn for each vertex reachable from the vertex with the
+// given id in the given direction ("OUTBOUND" or "INBOUND") that has no
+// further neighbours in that direction.
+func (d *DAG) walkTerminals(ctx context.Context, id, direction string, fn func(id string) error) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	bindVars := map[string]interface{}{
+		"start":    start,
+		"maxDepth": maxDepth,
+	}
+	traversal := d.traverse(ctx, traversalSpec{
+		direction: direction,
+		start:     "@start",
+		depth:     "1..@maxDepth",
+		edges:     "edges",
+	}, "  ", bindVars)
+
+	// neighbours hidden from the principal don't count
+	traversed, _ := d.traversal(direction)
+	query := `
+` + traversal + `
+  FILTER LENGTH(
+    FOR n IN 1 ` + traversed + ` v @@edges
+      FILTER ` + allMatch(aclFilter(ctx), "[n]") + `
+      LIMIT 1
+      RETURN 1
+  ) == 0
+  RETURN v._key`
+	return d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+		var key string
+		if err := json.Unmarshal(doc, &key); err != nil {
+			return err
+		}
+		return fn(d.id(key))
+	})
+}
+
+// WalkOptions configures WalkDescendantsWith and WalkAncestorsWith.
+type WalkOptions struct {
+
+	// EdgeFilter restricts the edges followed (see ViewFilter, CURRENT
+	// referring to the edge document, e.g. `CURRENT.scope == @scope`). Nil
+	// follows all edges.
+	EdgeFilter *ViewFilter
+}
+
+// WalkDescendantsWith calls fn for each descendant of the vertex with the
+// given id reachable via edges matching opts (which may be nil). Descendants
+// are visited in breadth-first order (i.e. level by level, such that vertices
+// reachable via both, matching and non-matching edges are visited), each
+// descendant being visited exactly once. Walking stops at the first error
+// returned by fn, which is returned by WalkDescendantsWith.
+// WalkDescendantsWith returns an error, if id is empty or unknown.
+func (d *DAG) WalkDescendantsWith(id string, opts *WalkOptions, fn func(id string) error) error {
+	return d.WalkDescendantsWithCtx(context.Background(), id, opts, fn)
+}
+
+// WalkDescendantsWithCtx is like WalkDescendantsWith but uses the given
+// context.
+func (d *DAG) WalkDescendantsWithCtx(ctx context.Context, id string, opts *WalkOptions, fn func(id string) error) error {
+	return d.walkFiltered(ctx, id, "OUTBOUND", opts, fn)
+}
+
+// WalkAncestorsWith calls fn for each ancestor of the vertex with the given id
+// reachable via edges matching opts (see WalkDescendantsWith).
+func (d *DAG) WalkAncestorsWith(id string, opts *WalkOptions, fn func(id string) error) error {
+	return d.WalkAncestorsWithCtx(context.Background(), id, opts, fn)
+}
+
+// WalkAncestorsWithCtx is like WalkAncestorsWith but uses the given context.
+func (d *DAG) WalkAncestorsWithCtx(ctx context.Context, id string, opts *WalkOptions, fn func(id string) error) error {
+	return d.walkFiltered(ctx, id, "INBOUND", opts, fn)
+}
+
+// walkFiltered calls fn for each vertex reachable from the vertex with the
+// given id in the given direction ("OUTBOUND" or "INBOUND") via edges matching
+// opts. The edge filter is applied within the traversal of each level.
+func (d *DAG) walkFiltered(ctx context.Context, id, direction string, opts *WalkOptions, fn func(id string) error) error {
+	if id == "" {
+		return EmptyIDError()
+	}
+	if opts == nil {
+		opts = &WalkOptions{}
+	}
+	ctx = d.context(ctx)
+	start, err := d.vertexDocumentID(ctx, id)
+	if err != nil {
+		return err
+	}
+	direction, edges := d.traversal(direction)
+	acl := aclFilter(ctx)
+	query := `
+FOR id IN @frontier
+  FOR v, e IN 1..1 ` + direction + ` id @@edges
+    FILTER ` + allMatch(opts.EdgeFilter, "[e]") + ` AND ` + allMatch(acl, "[v]") + `
+    RETURN DISTINCT v._key`
+	visited := map[string]struct{}{start.Key(): {}}
+	frontier := []driver.DocumentID{start}
+	for len(frontier) > 0 {
+		bindVars := map[string]interface{}{
+			"@edges":   edges,
+			"frontier": frontier,
+		}
+		addBindVars(bindVars, opts.EdgeFilter)
+		addBindVars(bindVars, acl)
+		var next []driver.DocumentID
+		err := d.forEachDocument(ctx, query, bindVars)(func(doc json.RawMessage) error {
+			var key string
+			if err := json.Unmarshal(doc, &key); err != nil {
+				return err
+			}
+			if _, ok := visited[key]; ok {
+				return nil
+			}
+			visited[key] = struct{}{}
+			next = append(next, driver.NewDocumentID(d.vertices.Name(), key))
+			return fn(d.id(key))
+		})
+		if err != nil {
+			return err
+		}
+		frontier = next
+	}
+	return nil
+}
@@ -0,0 +1,401 @@
+package arangodag
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"testing"
+
+	"github.com/go-test/deep"
+)
+
+func TestDAG_WalkDescendantsMulti(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 3, 2 -> 3, 3 -> 4, 2 -> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+	_ = d.AddEdge("2", "5")
+
+	var visited []string
+	err := d.WalkDescendantsMulti([]string{"1", "2"}, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsMulti(): %v", err)
+	}
+	sort.Strings(visited)
+	if diff := deep.Equal(visited, []string{"3", "4", "5"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// stop walking
+	stop := errors.New("stop")
+	count := 0
+	err = d.WalkDescendantsMulti([]string{"1", "2"}, func(id string) error {
+		count++
+		return stop
+	})
+	if err != stop || count != 1 {
+		t.Errorf("WalkDescendantsMulti() = %v (after %d visits), want %v (after 1 visit)", err, count, stop)
+	}
+
+	// unknown
+	err = d.WalkDescendantsMulti([]string{"1", "foo"}, func(string) error { return nil })
+	if !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkAncestorsWithDepth(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 4, 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "4")
+
+	depths := make(map[string]int)
+	paths := make(map[string][]string)
+	err := d.WalkAncestorsWithDepth("4", func(id string, depth int, path []string) error {
+		depths[id] = depth
+		paths[id] = path
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkAncestorsWithDepth(): %v", err)
+	}
+	if diff := deep.Equal(depths, map[string]int{"1": 2, "2": 1, "3": 1}); diff != nil {
+		t.Error(diff)
+	}
+	if diff := deep.Equal(paths["1"], []string{"4", "2", "1"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// stop walking
+	stop := errors.New("stop")
+	err = d.WalkAncestorsWithDepth("4", func(string, int, []string) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("WalkAncestorsWithDepth() = %v, want %v", err, stop)
+	}
+
+	// unknown
+	err = d.WalkAncestorsWithDepth("foo", func(string, int, []string) error { return nil })
+	if !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkAncestors(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 3, 2 -> 3
+	for _, id := range []string{"1", "2", "3"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "3")
+
+	// dangling edge
+	if _, err := d.vertices.RemoveDocument(context.Background(), "1"); err != nil {
+		t.Fatalf("failed to remove vertex: %v", err)
+	}
+	errs := make(map[string]error)
+	var v idVertex
+	err := d.WalkAncestors("3", &v, func(id string, err error) error {
+		errs[id] = err
+		if err == nil && v.MyID != id {
+			t.Errorf("got vertex %v for id %s", v, id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkAncestors(): %v", err)
+	}
+	if len(errs) != 2 || errs["2"] != nil || !IsUnknownIDError(errs["1"]) {
+		t.Errorf("WalkAncestors() visited %v, want 1 (UnknownIDError) and 2 (nil)", errs)
+	}
+
+	// decoding errors
+	var n int
+	err = d.WalkAncestors("3", &n, func(id string, err error) error {
+		if err == nil {
+			t.Errorf("want error for %s", id)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Errorf("failed to WalkAncestors(): %v", err)
+	}
+
+	// stop walking
+	stop := errors.New("stop")
+	err = d.WalkAncestors("3", nil, func(string, error) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("WalkAncestors() = %v, want %v", err, stop)
+	}
+
+	// unknown
+	err = d.WalkAncestors("foo", nil, func(string, error) error { return nil })
+	if !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkDescendants(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 4, 1 -> 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("3", "4")
+
+	for _, dfs := range []bool{false, true} {
+		var visited []string
+		var v idVertex
+		err := d.WalkDescendants("1", &v, func(id string, err error) error {
+			if err != nil || v.MyID != id {
+				t.Errorf("got vertex %v (%v) for id %s", v, err, id)
+			}
+			visited = append(visited, id)
+			return nil
+		}, dfs)
+		if err != nil {
+			t.Fatalf("failed to WalkDescendants(): %v", err)
+		}
+		sort.Strings(visited)
+		if diff := deep.Equal(visited, []string{"2", "3", "4"}); diff != nil {
+			t.Errorf("WalkDescendants(dfs = %v): %v", dfs, diff)
+		}
+	}
+	if err := d.WalkDescendants("", nil, nil, false); !IsEmptyIDError(err) {
+		t.Errorf("want EmptyIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkAncestors_inverseEdges(t *testing.T) {
+	d := someNewDag(t, WithInverseEdges())
+
+	// 1 -> 3, 2 -> 3, 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "3")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+
+	for _, dfs := range []bool{false, true} {
+		var visited []string
+		err := d.walk(context.Background(), "4", "INBOUND", dfs, nil, func(id string, err error) error {
+			if err != nil {
+				t.Errorf("got error %v for id %s", err, id)
+			}
+			visited = append(visited, id)
+			return nil
+		})
+		if err != nil {
+			t.Fatalf("failed to walk(): %v", err)
+		}
+		sort.Strings(visited)
+		if diff := deep.Equal(visited, []string{"1", "2", "3"}); diff != nil {
+			t.Errorf("walk(dfs = %v): %v", dfs, diff)
+		}
+	}
+}
+
+func TestDAG_DescendantsWalker(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2 -> 3 -> 4
+	for _, id := range []string{"1", "2", "3", "4"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("3", "4")
+
+	it, err := d.DescendantsWalker(context.Background(), "1", &WalkerOptions{BatchSize: 1})
+	if err != nil {
+		t.Fatalf("failed to DescendantsWalker(): %v", err)
+	}
+	var visited []string
+	for it.Next() {
+		visited = append(visited, it.ID())
+	}
+	if err := it.Err(); err != nil {
+		t.Errorf("Err() = %v", err)
+	}
+	_ = it.Close()
+	if diff := deep.Equal(visited, []string{"2", "3", "4"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// cancelling stops walking
+	ctx, cancel := context.WithCancel(context.Background())
+	it, err = d.AncestorsWalker(ctx, "4", &WalkerOptions{BatchSize: 1, DFS: true})
+	if err != nil {
+		t.Fatalf("failed to AncestorsWalker(): %v", err)
+	}
+	defer it.Close()
+	if !it.Next() || it.ID() != "3" || it.Depth() != 1 {
+		t.Errorf("Next() visited %s (depth %d), want 3 (depth 1)", it.ID(), it.Depth())
+	}
+	cancel()
+	if it.Next() {
+		t.Errorf("Next() = true after cancelling")
+	}
+	if it.Err() == nil {
+		t.Errorf("Err() = nil after cancelling")
+	}
+
+	if _, err := d.DescendantsWalker(context.Background(), "foo", nil); !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkLeavesOf(t *testing.T) {
+	d := someNewDag(t)
+
+	// 1 -> 2, 2 -> 3, 2 -> 4, 5 -> 2
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdge("1", "2")
+	_ = d.AddEdge("2", "3")
+	_ = d.AddEdge("2", "4")
+	_ = d.AddEdge("5", "2")
+
+	var leaves []string
+	err := d.WalkLeavesOf("1", func(id string) error {
+		leaves = append(leaves, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkLeavesOf(): %v", err)
+	}
+	sort.Strings(leaves)
+	if diff := deep.Equal(leaves, []string{"3", "4"}); diff != nil {
+		t.Error(diff)
+	}
+
+	var roots []string
+	err = d.WalkRootsOf("3", func(id string) error {
+		roots = append(roots, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkRootsOf(): %v", err)
+	}
+	sort.Strings(roots)
+	if diff := deep.Equal(roots, []string{"1", "5"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// a leaf has no leaves
+	err = d.WalkLeavesOf("3", func(id string) error {
+		t.Errorf("unexpected leaf %s", id)
+		return nil
+	})
+	if err != nil {
+		t.Errorf("failed to WalkLeavesOf(): %v", err)
+	}
+
+	// unknown
+	err = d.WalkLeavesOf("foo", func(string) error { return nil })
+	if !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
+
+func TestDAG_WalkDescendantsWith(t *testing.T) {
+	d := someNewDag(t, WithEdgeType(labeledEdge{}))
+
+	// 1 -(runtime)-> 2 -(runtime)-> 3, 1 -(build)-> 4 -(runtime)-> 3, 4 -(runtime)-> 5
+	for _, id := range []string{"1", "2", "3", "4", "5"} {
+		_, _ = d.AddVertex(idVertex{MyID: id})
+	}
+	_ = d.AddEdgeWithData("1", "4", labeledEdge{Label: "build"})
+	_ = d.AddEdgeWithData("4", "3", labeledEdge{Label: "runtime"})
+	_ = d.AddEdgeWithData("4", "5", labeledEdge{Label: "runtime"})
+	_ = d.AddEdgeWithData("1", "2", labeledEdge{Label: "runtime"})
+	_ = d.AddEdgeWithData("2", "3", labeledEdge{Label: "runtime"})
+
+	opts := &WalkOptions{EdgeFilter: &ViewFilter{
+		Expression: "CURRENT.label == @label",
+		BindVars:   map[string]interface{}{"label": "runtime"},
+	}}
+	var visited []string
+	err := d.WalkDescendantsWith("1", opts, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsWith(): %v", err)
+	}
+	if diff := deep.Equal(visited, []string{"2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	visited = nil
+	err = d.WalkAncestorsWith("3", opts, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkAncestorsWith(): %v", err)
+	}
+	sort.Strings(visited)
+	if diff := deep.Equal(visited, []string{"1", "2", "4"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// no filter
+	visited = nil
+	err = d.WalkDescendantsWith("1", nil, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsWith(): %v", err)
+	}
+	if len(visited) != 4 {
+		t.Errorf("WalkDescendantsWith() visited %v, want 4 vertices", visited)
+	}
+
+	// 3 is reachable via a direct non-matching edge as well as via runtime
+	// edges
+	_ = d.AddEdgeWithData("1", "3", labeledEdge{Label: "build"})
+	visited = nil
+	err = d.WalkDescendantsWith("1", opts, func(id string) error {
+		visited = append(visited, id)
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to WalkDescendantsWith(): %v", err)
+	}
+	if diff := deep.Equal(visited, []string{"2", "3"}); diff != nil {
+		t.Error(diff)
+	}
+
+	// unknown
+	err = d.WalkDescendantsWith("foo", opts, func(string) error { return nil })
+	if !IsUnknownIDError(err) {
+		t.Errorf("want UnknownIDError, got %v", err)
+	}
+}
@@ -0,0 +1,237 @@
+package arangodag
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"sort"
+
+	"gopkg.in/yaml.v2"
+)
+
+// WorkflowTask is the payload of vertices created from workflow definitions.
+type WorkflowTask struct {
+
+	// Workflow is the name of the workflow (i.e. of the Argo Workflow or the
+	// Tekton Pipeline).
+	Workflow string `json:"workflow"`
+
+	// Template is the name of the Argo template containing the task (empty
+	// for Tekton).
+	Template string `json:"template,omitempty"`
+
+	// Name is the name of the task (or step).
+	Name string `json:"name"`
+
+	// Ref is the template (Argo) or the task (Tekton) run by the task.
+	Ref string `json:"ref,omitempty"`
+
+	// Finally is true for tasks of Tekton's finally section.
+	Finally bool `json:"finally,omitempty"`
+}
+
+// WorkflowKey returns the id of the vertex representing the given task.
+func WorkflowKey(t WorkflowTask) string {
+	return hashKey("task", t.Workflow, t.Template, t.Name)
+}
+
+type workflowDocument struct {
+	Kind     string `yaml:"kind"`
+	Metadata struct {
+		Name         string `yaml:"name"`
+		GenerateName string `yaml:"generateName"`
+	} `yaml:"metadata"`
+	Spec struct {
+		argoWorkflowSpec   `yaml:",inline"`
+		tektonPipelineSpec `yaml:",inline"`
+		WorkflowSpec       *argoWorkflowSpec   `yaml:"workflowSpec"`
+		PipelineSpec       *tektonPipelineSpec `yaml:"pipelineSpec"`
+	} `yaml:"spec"`
+}
+
+type argoWorkflowSpec struct {
+	Templates []struct {
+		Name string `yaml:"name"`
+		DAG  *struct {
+			Tasks []struct {
+				Name         string   `yaml:"name"`
+				Template     string   `yaml:"template"`
+				Dependencies []string `yaml:"dependencies"`
+				Depends      string   `yaml:"depends"`
+			} `yaml:"tasks"`
+		} `yaml:"dag"`
+		Steps [][]struct {
+			Name     string `yaml:"name"`
+			Template string `yaml:"template"`
+		} `yaml:"steps"`
+	} `yaml:"templates"`
+}
+
+type tektonPipelineSpec struct {
+	Tasks   []tektonTask `yaml:"tasks"`
+	Finally []tektonTask `yaml:"finally"`
+}
+
+type tektonTask struct {
+	Name    string `yaml:"name"`
+	TaskRef struct {
+		Name string `yaml:"name"`
+	} `yaml:"taskRef"`
+	RunAfter []string      `yaml:"runAfter"`
+	Params   []interface{} `yaml:"params"`
+	When     []interface{} `yaml:"when"`
+}
+
+// ImportWorkflow imports the Argo Workflows (Workflow, WorkflowTemplate,
+// CronWorkflow) or Tekton (Pipeline, PipelineRun) definitions read from r.
+// r may contain multiple YAML (or JSON) documents. Each task becomes a vertex
+// (see WorkflowTask) and each dependency becomes an edge from the task that
+// has to run first to the dependent task, i.e. roots are the tasks to start
+// with. All documents are imported within a single transaction.
+//
+// For Argo, dependencies are taken from DAG tasks (dependencies and depends)
+// and steps (each step depends on all steps of the previous group). For
+// Tekton, dependencies are taken from runAfter and from references to the
+// results of other tasks. Tasks of the finally section depend on all
+// (regular) tasks.
+func (d *DAG) ImportWorkflow(r io.Reader) error {
+	return d.ImportWorkflowCtx(context.Background(), r)
+}
+
+// ImportWorkflowCtx is like ImportWorkflow but uses the given context.
+func (d *DAG) ImportWorkflowCtx(ctx context.Context, r io.Reader) error {
+	dec := yaml.NewDecoder(r)
+	err := d.edgeTransaction(d.context(ctx), func(ctx context.Context) error {
+		for {
+			var doc workflowDocument
+			err := dec.Decode(&doc)
+			if err == io.EOF {
+				return nil
+			}
+			if err != nil {
+				return err
+			}
+			tasks, dependencies, err := parseWorkflow(doc)
+			if err != nil {
+				return err
+			}
+			if err := d.importWorkflowTasks(ctx, tasks, dependencies); err != nil {
+				return err
+			}
+		}
+	})
+	d.counts.invalidate()
+	return err
+}
+
+// importWorkflowTasks adds the given tasks as vertices and connects each task
+// to its dependent tasks.
+func (d *DAG) importWorkflowTasks(ctx context.Context, tasks map[string]WorkflowTask, dependencies map[string][]string) error {
+	for _, t := range tasks {
+		if err := d.upsertVertex(ctx, WorkflowKey(t), t); err != nil {
+			return err
+		}
+	}
+	// edges point from the dependency to the dependent task
+	refs := make([]string, 0, len(dependencies))
+	for ref := range dependencies {
+		refs = append(refs, ref)
+	}
+	sort.Strings(refs)
+	for _, ref := range refs {
+		dst := WorkflowKey(tasks[ref])
+		for _, dep := range dependencies[ref] {
+			t, ok := tasks[dep]
+			if !ok {
+				continue
+			}
+			if err := d.AddEdgeCtx(ctx, WorkflowKey(t), dst); err != nil && !IsDuplicateEdgeError(err) {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+var (
+	argoDependsTask  = regexp.MustCompile(`([A-Za-z0-9_-]+)(\.[A-Za-z]+)?`)
+	tektonResultTask = regexp.MustCompile(`\$\(tasks\.([A-Za-z0-9_-]+)\.results\.`)
+)
+
+// parseWorkflow returns the tasks and dependencies (both by template and task
+// name) of the given workflow document.
+func parseWorkflow(doc workflowDocument) (map[string]WorkflowTask, map[string][]string, error) {
+	name := doc.Metadata.Name
+	if name == "" {
+		name = doc.Metadata.GenerateName
+	}
+	tasks := make(map[string]WorkflowTask)
+	dependencies := make(map[string][]string)
+
+	switch doc.Kind {
+	case "Workflow", "WorkflowTemplate", "ClusterWorkflowTemplate", "CronWorkflow":
+		spec := doc.Spec.argoWorkflowSpec
+		if doc.Spec.WorkflowSpec != nil {
+			spec = *doc.Spec.WorkflowSpec
+		}
+		for _, tmpl := range spec.Templates {
+			ref := func(task string) string { return tmpl.Name + "/" + task }
+			if tmpl.DAG != nil {
+				for _, task := range tmpl.DAG.Tasks {
+					tasks[ref(task.Name)] = WorkflowTask{Workflow: name, Template: tmpl.Name, Name: task.Name, Ref: task.Template}
+					for _, dep := range task.Dependencies {
+						dependencies[ref(task.Name)] = append(dependencies[ref(task.Name)], ref(dep))
+					}
+					for _, m := range argoDependsTask.FindAllStringSubmatch(task.Depends, -1) {
+						dependencies[ref(task.Name)] = append(dependencies[ref(task.Name)], ref(m[1]))
+					}
+				}
+			}
+			var previous []string
+			for _, group := range tmpl.Steps {
+				var current []string
+				for _, step := range group {
+					tasks[ref(step.Name)] = WorkflowTask{Workflow: name, Template: tmpl.Name, Name: step.Name, Ref: step.Template}
+					dependencies[ref(step.Name)] = append(dependencies[ref(step.Name)], previous...)
+					current = append(current, ref(step.Name))
+				}
+				previous = current
+			}
+		}
+	case "Pipeline", "PipelineRun":
+		spec := doc.Spec.tektonPipelineSpec
+		if doc.Spec.PipelineSpec != nil {
+			spec = *doc.Spec.PipelineSpec
+		}
+		var regular []string
+		for _, task := range spec.Tasks {
+			tasks[task.Name] = WorkflowTask{Workflow: name, Name: task.Name, Ref: task.TaskRef.Name}
+			regular = append(regular, task.Name)
+			dependencies[task.Name] = append(dependencies[task.Name], task.RunAfter...)
+			dependencies[task.Name] = append(dependencies[task.Name], tektonResultReferences(task)...)
+		}
+		for _, task := range spec.Finally {
+			tasks[task.Name] = WorkflowTask{Workflow: name, Name: task.Name, Ref: task.TaskRef.Name, Finally: true}
+			dependencies[task.Name] = append(dependencies[task.Name], regular...)
+		}
+	default:
+		return nil, nil, fmt.Errorf("unsupported workflow kind '%s'", doc.Kind)
+	}
+	if len(tasks) == 0 {
+		return nil, nil, errors.New("workflow without tasks")
+	}
+	return tasks, dependencies, nil
+}
+
+// tektonResultReferences returns the names of the tasks whose results are
+// referenced by the params or when expressions of the given task.
+func tektonResultReferences(task tektonTask) []string {
+	out, _ := yaml.Marshal([]interface{}{task.Params, task.When})
+	var refs []string
+	for _, m := range tektonResultTask.FindAllStringSubmatch(string(out), -1) {
+		refs = append(refs, m[1])
+	}
+	return refs
+}
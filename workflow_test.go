@@ -0,0 +1,133 @@
+package arangodag
+
+import (
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/go-test/deep"
+	"gopkg.in/yaml.v2"
+)
+
+const argoWorkflow = `
+apiVersion: argoproj.io/v1alpha1
+kind: Workflow
+metadata:
+  generateName: dag-diamond-
+spec:
+  entrypoint: diamond
+  templates:
+  - name: diamond
+    dag:
+      tasks:
+      - name: A
+        template: echo
+      - name: B
+        dependencies: [A]
+        template: echo
+      - name: C
+        depends: "A.Succeeded"
+        template: echo
+      - name: D
+        depends: "B && (C.Succeeded || C.Failed)"
+        template: echo
+  - name: steps
+    steps:
+    - - name: hello
+        template: echo
+    - - name: left
+        template: echo
+      - name: right
+        template: echo
+  - name: echo
+    container:
+      image: alpine
+`
+
+const tektonPipeline = `
+apiVersion: tekton.dev/v1beta1
+kind: Pipeline
+metadata:
+  name: build
+spec:
+  tasks:
+  - name: fetch
+    taskRef:
+      name: git-clone
+  - name: test
+    runAfter: [fetch]
+    taskRef:
+      name: go-test
+  - name: image
+    taskRef:
+      name: kaniko
+    params:
+    - name: revision
+      value: $(tasks.fetch.results.commit)
+  finally:
+  - name: notify
+    taskRef:
+      name: slack
+`
+
+func parseWorkflowString(t *testing.T, s string) (map[string]WorkflowTask, map[string][]string) {
+	var doc workflowDocument
+	if err := yaml.Unmarshal([]byte(s), &doc); err != nil {
+		t.Fatalf("failed to unmarshal workflow: %v", err)
+	}
+	tasks, dependencies, err := parseWorkflow(doc)
+	if err != nil {
+		t.Fatalf("failed to parseWorkflow(): %v", err)
+	}
+	for _, deps := range dependencies {
+		sort.Strings(deps)
+	}
+	return tasks, dependencies
+}
+
+func Test_parseWorkflow(t *testing.T) {
+
+	// argo
+	tasks, dependencies := parseWorkflowString(t, argoWorkflow)
+	if len(tasks) != 7 {
+		t.Errorf("parseWorkflow() returned %d tasks, want %d", len(tasks), 7)
+	}
+	if tasks["diamond/A"].Workflow != "dag-diamond-" {
+		t.Errorf("parseWorkflow() workflow = '%s', want '%s'", tasks["diamond/A"].Workflow, "dag-diamond-")
+	}
+	want := []string{"diamond/B", "diamond/C", "diamond/C"}
+	if diff := deep.Equal(dependencies["diamond/D"], want); diff != nil {
+		t.Errorf("parseWorkflow() = %v, want %v", dependencies["diamond/D"], want)
+	}
+	want = []string{"steps/hello"}
+	if diff := deep.Equal(dependencies["steps/right"], want); diff != nil {
+		t.Errorf("parseWorkflow() = %v, want %v", dependencies["steps/right"], want)
+	}
+
+	// tekton
+	tasks, dependencies = parseWorkflowString(t, tektonPipeline)
+	if len(tasks) != 4 || !tasks["notify"].Finally {
+		t.Errorf("parseWorkflow() = %v", tasks)
+	}
+	want = []string{"fetch"}
+	if diff := deep.Equal(dependencies["image"], want); diff != nil {
+		t.Errorf("parseWorkflow() = %v, want %v", dependencies["image"], want)
+	}
+	want = []string{"fetch", "image", "test"}
+	if diff := deep.Equal(dependencies["notify"], want); diff != nil {
+		t.Errorf("parseWorkflow() = %v, want %v", dependencies["notify"], want)
+	}
+}
+
+func TestDAG_ImportWorkflow(t *testing.T) {
+	d := someNewDag(t)
+	if err := d.ImportWorkflow(strings.NewReader(argoWorkflow + "---" + tektonPipeline)); err != nil {
+		t.Fatalf("failed to ImportWorkflow(): %v", err)
+	}
+	if order, _ := d.GetOrder(); order != 11 {
+		t.Errorf("GetOrder() = %d, want %d", order, 11)
+	}
+	if size, _ := d.GetSize(); size != 11 {
+		t.Errorf("GetSize() = %d, want %d", size, 11)
+	}
+}